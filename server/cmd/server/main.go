@@ -2,6 +2,7 @@
 package main
 
 import (
+	"context"
 	"log"
 	"net/http"
 	"os"
@@ -18,11 +19,22 @@ import (
 	// Your application packages
 	"github.com/bhavyajaix/BalkanID-filevault/graph"
 	"github.com/bhavyajaix/BalkanID-filevault/graph/generated"
+	"github.com/bhavyajaix/BalkanID-filevault/internal/accessrequest"
+	"github.com/bhavyajaix/BalkanID-filevault/internal/activity"
+	"github.com/bhavyajaix/BalkanID-filevault/internal/audit"
 	"github.com/bhavyajaix/BalkanID-filevault/internal/database"
+	"github.com/bhavyajaix/BalkanID-filevault/internal/dedup"
 	"github.com/bhavyajaix/BalkanID-filevault/internal/file"
 	"github.com/bhavyajaix/BalkanID-filevault/internal/folders"
+	"github.com/bhavyajaix/BalkanID-filevault/internal/group"
+	"github.com/bhavyajaix/BalkanID-filevault/internal/indexer"
+	"github.com/bhavyajaix/BalkanID-filevault/internal/loaders"
+	"github.com/bhavyajaix/BalkanID-filevault/internal/metadata"
 	"github.com/bhavyajaix/BalkanID-filevault/internal/middleware"
+	"github.com/bhavyajaix/BalkanID-filevault/internal/ownership"
 	"github.com/bhavyajaix/BalkanID-filevault/internal/permission"
+	"github.com/bhavyajaix/BalkanID-filevault/internal/rules"
+	"github.com/bhavyajaix/BalkanID-filevault/internal/savedsearch"
 	"github.com/bhavyajaix/BalkanID-filevault/internal/search"
 	"github.com/bhavyajaix/BalkanID-filevault/internal/share"
 	"github.com/bhavyajaix/BalkanID-filevault/internal/tag"
@@ -31,6 +43,27 @@ import (
 
 const defaultPort = "4007"
 
+const (
+	defaultExpiryInterval = time.Minute
+	defaultExpiryNotice   = 24 * time.Hour
+	defaultIndexInterval  = 15 * time.Second
+)
+
+// durationFromEnv reads a Go duration (e.g. "36h") from the environment, falling back
+// to the default when the variable is unset or invalid.
+func durationFromEnv(key string, fallback time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		log.Printf("invalid %s %q, using default: %s", key, value, fallback)
+		return fallback
+	}
+	return d
+}
+
 func createRateLimiter() func(http.Handler) http.Handler {
 	lmt := tollbooth.NewLimiter(2, &limiter.ExpirableOptions{DefaultExpirationTTL: time.Hour})
 
@@ -62,8 +95,17 @@ func main() {
 	// 1. Initialize Database connection
 	db := database.Connect()
 
-	// 2. Run Migrations
-	// database.Migrate(db)
+	// 2. Run Migrations. "server migrate ..." manages the schema and exits; otherwise
+	// pending migrations are applied at startup unless AUTO_MIGRATE=false.
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		runMigrateCommand(db, os.Args[2:])
+		return
+	}
+	if os.Getenv("AUTO_MIGRATE") == "false" {
+		checkSchema(db)
+	} else {
+		database.Migrate(db)
+	}
 
 	storagePath := os.Getenv("FILEVAULT_STORAGE_PATH")
 	if storagePath == "" {
@@ -81,26 +123,67 @@ func main() {
 	fileRepo := file.NewRepository(db)
 	permissionRepo := permission.NewRepository(db)
 	foldersRepo := folders.NewRepository(db)
-	foldersService := folders.NewService(foldersRepo)
-	permissionService := permission.NewService(permissionRepo, foldersRepo, userRepo)
-	fileService := file.NewService(fileRepo, userRepo, db, storagePath, permissionRepo)
-	shareRepo := share.NewRepository(db)
-	shareService := share.NewService(shareRepo, foldersRepo, fileRepo, db)
+	foldersService := folders.NewService(foldersRepo, permissionRepo)
+	activityRepo := activity.NewRepository(db)
+	activityService := activity.NewService(activityRepo, foldersService)
+	groupRepo := group.NewRepository(db)
+	groupService := group.NewService(groupRepo, userRepo)
+	permissionService := permission.NewService(permissionRepo, foldersRepo, userRepo, groupRepo)
+	fileService := file.NewService(fileRepo, userRepo, db, storagePath, permissionRepo, foldersService, activityRepo)
+	shareRepo := share.NewRepository(db, permissionRepo)
+	shareService := share.NewService(shareRepo, foldersRepo, fileRepo, db, activityRepo)
 	tagRepo := tag.NewTagRepository(db)
-	tagService := tag.NewTagService(tagRepo)
+	tagService := tag.NewTagService(tagRepo, groupRepo, foldersService)
 	searchRepo := search.NewSearchRepository(db)
-	searchService := search.NewSearchService(searchRepo)
+	searchService := search.NewSearchService(searchRepo, foldersService)
+	savedSearchRepo := savedsearch.NewRepository(db)
+	savedSearchService := savedsearch.NewService(savedSearchRepo, userRepo)
+	ruleRepo := rules.NewRepository(db)
+	ruleService := rules.NewService(ruleRepo, foldersService, tagService, fileService)
+	metadataRepo := metadata.NewRepository(db)
+	metadataService := metadata.NewService(metadataRepo, groupRepo, foldersService)
+	dedupRepo := dedup.NewRepository(db)
+	dedupService := dedup.NewService(dedupRepo, fileService)
+	auditRepo := audit.NewRepository(db)
+	accessRequestRepo := accessrequest.NewRepository(db)
+	accessRequestService := accessrequest.NewService(accessRequestRepo, permissionRepo)
+	ownershipRepo := ownership.NewRepository(db)
+	ownershipService := ownership.NewService(ownershipRepo, foldersRepo, userRepo, permissionRepo, auditRepo, db)
+
+	// Background job: notify owners of lapsing grants and purge expired ones.
+	expiryWorker := permission.NewExpiryWorker(
+		db, permissionRepo, auditRepo, permission.LogNotifier{},
+		durationFromEnv("PERMISSION_EXPIRY_INTERVAL", defaultExpiryInterval),
+		durationFromEnv("PERMISSION_EXPIRY_NOTICE", defaultExpiryNotice),
+	)
+	go expiryWorker.Run(context.Background())
+
+	// Background job: extract the text of new uploads for content search.
+	contentIndexer := indexer.NewIndexer(
+		indexer.NewRepository(db),
+		durationFromEnv("CONTENT_INDEX_INTERVAL", defaultIndexInterval),
+	)
+	go contentIndexer.Run(context.Background())
+
 	// 4. Inject Dependencies into the Resolver
 	// The resolver now has access to the user service.
 	resolver := &graph.Resolver{
-		DB:                db, // Keep DB for other features you'll build
-		UserService:       userService,
-		FileService:       fileService,
-		FolderService:     foldersService,
-		PermissionService: permissionService,
-		ShareService:      shareService,
-		TagService:        tagService,
-		SearchService:     searchService,
+		DB:                   db, // Keep DB for other features you'll build
+		UserService:          userService,
+		FileService:          fileService,
+		FolderService:        foldersService,
+		PermissionService:    permissionService,
+		ShareService:         shareService,
+		TagService:           tagService,
+		SearchService:        searchService,
+		GroupService:         groupService,
+		AccessRequestService: accessRequestService,
+		OwnershipService:     ownershipService,
+		ActivityService:      activityService,
+		SavedSearchService:   savedSearchService,
+		RuleService:          ruleService,
+		MetadataService:      metadataService,
+		DedupService:         dedupService,
 	}
 
 	// --- Server Setup ---
@@ -118,13 +201,14 @@ func main() {
 	router.Use(createRateLimiter())
 
 	srv := handler.NewDefaultServer(generated.NewExecutableSchema(generated.Config{Resolvers: resolver}))
-	authedSrv := middleware.AuthMiddleware(srv)
+	loaderRepo := loaders.NewRepository(db)
+	authedSrv := middleware.AuthMiddleware(loaders.Middleware(loaderRepo, srv))
 
 	router.Handle("/", playground.Handler("GraphQL playground", "/query"))
 	router.Handle("/query", authedSrv)
 
 	router.Get("/download/{resourceID}", func(w http.ResponseWriter, r *http.Request) {
-		middleware.AuthMiddleware(file.DownloadFileHandler(db, permissionRepo)).ServeHTTP(w, r)
+		middleware.AuthMiddleware(file.DownloadFileHandler(db, permissionRepo, activityRepo)).ServeHTTP(w, r)
 	})
 
 	log.Printf("connect to http://localhost:%s/ for GraphQL playground", port)
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+
+	"github.com/bhavyajaix/BalkanID-filevault/internal/database"
+	"gorm.io/gorm"
+)
+
+const migrateUsage = "usage: server migrate up | down [steps] | status"
+
+// runMigrateCommand implements the "migrate" subcommand.
+func runMigrateCommand(db *gorm.DB, args []string) {
+	if len(args) == 0 {
+		log.Fatal(migrateUsage)
+	}
+
+	switch args[0] {
+	case "up":
+		database.Migrate(db)
+
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			n, err := strconv.Atoi(args[1])
+			if err != nil || n <= 0 {
+				log.Fatalf("invalid number of steps %q", args[1])
+			}
+			steps = n
+		}
+		if err := database.MigrateDown(db, steps); err != nil {
+			log.Fatal("Failed to revert migrations: ", err)
+		}
+
+	case "status":
+		states, err := database.MigrationStatus(db)
+		if err != nil {
+			log.Fatal("Failed to read migration status: ", err)
+		}
+		for _, state := range states {
+			appliedAt := "pending"
+			if state.AppliedAt != nil {
+				appliedAt = state.AppliedAt.Format("2006-01-02 15:04:05 MST")
+			}
+			fmt.Fprintf(os.Stdout, "%04d  %-40s %s\n", state.Version, state.Name, appliedAt)
+		}
+
+	default:
+		log.Fatal(migrateUsage)
+	}
+}
+
+// checkSchema is used at startup when automatic migrations are switched off. It
+// refuses to start against a database migrated by a newer release and warns about
+// migrations that still need to be applied.
+func checkSchema(db *gorm.DB) {
+	states, err := database.MigrationStatus(db)
+	if err != nil {
+		log.Fatal("Failed to check database schema: ", err)
+	}
+	for _, state := range states {
+		if state.AppliedAt == nil {
+			log.Printf("warning: migration %04d_%s has not been applied; run `server migrate up`", state.Version, state.Name)
+		}
+	}
+}
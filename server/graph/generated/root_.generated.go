@@ -33,97 +33,407 @@ type Config struct {
 }
 
 type ResolverRoot interface {
+	File() FileResolver
+	Folder() FolderResolver
 	Mutation() MutationResolver
 	Query() QueryResolver
+	SavedSearch() SavedSearchResolver
 }
 
 type DirectiveRoot struct {
 }
 
 type ComplexityRoot struct {
+	AccessRequest struct {
+		CreatedAt func(childComplexity int) int
+		DecidedAt func(childComplexity int) int
+		ID        func(childComplexity int) int
+		Message   func(childComplexity int) int
+		Requester func(childComplexity int) int
+		Resource  func(childComplexity int) int
+		Role      func(childComplexity int) int
+		Status    func(childComplexity int) int
+	}
+
 	AuthPayload struct {
 		Token func(childComplexity int) int
 		User  func(childComplexity int) int
 	}
 
-	File struct {
-		CreatedAt   func(childComplexity int) int
-		ID          func(childComplexity int) int
-		IsPublic    func(childComplexity int) int
+	Breadcrumb struct {
+		ID   func(childComplexity int) int
+		Name func(childComplexity int) int
+	}
+
+	BulkItemResult struct {
+		Error    func(childComplexity int) int
+		ID       func(childComplexity int) int
+		Resource func(childComplexity int) int
+		Success  func(childComplexity int) int
+	}
+
+	BulkResult struct {
+		FailureCount func(childComplexity int) int
+		Results      func(childComplexity int) int
+		SuccessCount func(childComplexity int) int
+	}
+
+	CollapseResult struct {
+		DryRun     func(childComplexity int) int
+		FreedBytes func(childComplexity int) int
+		Kept       func(childComplexity int) int
+		Removed    func(childComplexity int) int
+	}
+
+	DedupSummary struct {
+		DuplicateFiles  func(childComplexity int) int
+		DuplicateGroups func(childComplexity int) int
+		FileCount       func(childComplexity int) int
+		LogicalBytes    func(childComplexity int) int
+		SavedBytes      func(childComplexity int) int
+		SavedPercentage func(childComplexity int) int
+		StoredBytes     func(childComplexity int) int
+		UniqueBytes     func(childComplexity int) int
+		WastedBytes     func(childComplexity int) int
+	}
+
+	DuplicateGroup struct {
+		FileHash    func(childComplexity int) int
+		Files       func(childComplexity int) int
 		MimeType    func(childComplexity int) int
-		Name        func(childComplexity int) int
-		Owner       func(childComplexity int) int
-		Parent      func(childComplexity int) int
-		Permissions func(childComplexity int) int
-		ShareToken  func(childComplexity int) int
 		SizeBytes   func(childComplexity int) int
-		Storage     func(childComplexity int) int
-		Tags        func(childComplexity int) int
-		Type        func(childComplexity int) int
-		UpdatedAt   func(childComplexity int) int
+		WastedBytes func(childComplexity int) int
+	}
+
+	EffectivePermission struct {
+		ExpiresAt     func(childComplexity int) int
+		GrantedOnID   func(childComplexity int) int
+		GrantedOnName func(childComplexity int) int
+		Group         func(childComplexity int) int
+		Inherited     func(childComplexity int) int
+		Role          func(childComplexity int) int
+		Source        func(childComplexity int) int
+		User          func(childComplexity int) int
+	}
+
+	FacetBucket struct {
+		Count  func(childComplexity int) int
+		Filter func(childComplexity int) int
+		Label  func(childComplexity int) int
+	}
+
+	File struct {
+		Breadcrumbs        func(childComplexity int) int
+		CreatedAt          func(childComplexity int) int
+		ID                 func(childComplexity int) int
+		InheritPermissions func(childComplexity int) int
+		IsPublic           func(childComplexity int) int
+		Metadata           func(childComplexity int) int
+		MimeType           func(childComplexity int) int
+		Name               func(childComplexity int) int
+		Owner              func(childComplexity int) int
+		Parent             func(childComplexity int) int
+		Path               func(childComplexity int) int
+		Permissions        func(childComplexity int) int
+		ShareToken         func(childComplexity int) int
+		SizeBytes          func(childComplexity int) int
+		Storage            func(childComplexity int) int
+		Tags               func(childComplexity int) int
+		Type               func(childComplexity int) int
+		UpdatedAt          func(childComplexity int) int
 	}
 
 	Folder struct {
-		Children    func(childComplexity int) int
-		CreatedAt   func(childComplexity int) int
-		ID          func(childComplexity int) int
-		IsPublic    func(childComplexity int) int
-		Name        func(childComplexity int) int
-		Owner       func(childComplexity int) int
-		Parent      func(childComplexity int) int
-		Permissions func(childComplexity int) int
-		ShareToken  func(childComplexity int) int
-		Tags        func(childComplexity int) int
-		Type        func(childComplexity int) int
-		UpdatedAt   func(childComplexity int) int
+		Breadcrumbs        func(childComplexity int) int
+		Children           func(childComplexity int) int
+		CreatedAt          func(childComplexity int) int
+		DescendantCount    func(childComplexity int) int
+		ID                 func(childComplexity int) int
+		InheritPermissions func(childComplexity int) int
+		IsPublic           func(childComplexity int) int
+		Metadata           func(childComplexity int) int
+		Name               func(childComplexity int) int
+		Owner              func(childComplexity int) int
+		Parent             func(childComplexity int) int
+		Path               func(childComplexity int) int
+		Permissions        func(childComplexity int) int
+		ShareToken         func(childComplexity int) int
+		Tags               func(childComplexity int) int
+		TotalSizeBytes     func(childComplexity int) int
+		Type               func(childComplexity int) int
+		UpdatedAt          func(childComplexity int) int
+	}
+
+	Group struct {
+		CreatedAt func(childComplexity int) int
+		ID        func(childComplexity int) int
+		Members   func(childComplexity int) int
+		Name      func(childComplexity int) int
+		Owner     func(childComplexity int) int
+		UpdatedAt func(childComplexity int) int
+	}
+
+	GroupMember struct {
+		AddedAt func(childComplexity int) int
+		IsAdmin func(childComplexity int) int
+		User    func(childComplexity int) int
+	}
+
+	HierarchyReport struct {
+		CycleResourceIds func(childComplexity int) int
+		MissingRows      func(childComplexity int) int
+		Repaired         func(childComplexity int) int
+		StaleRows        func(childComplexity int) int
+	}
+
+	MetadataField struct {
+		CreatedAt func(childComplexity int) int
+		GroupID   func(childComplexity int) int
+		ID        func(childComplexity int) int
+		Name      func(childComplexity int) int
+		Options   func(childComplexity int) int
+		Required  func(childComplexity int) int
+		Type      func(childComplexity int) int
+	}
+
+	MetadataValue struct {
+		Field func(childComplexity int) int
+		Value func(childComplexity int) int
 	}
 
 	Mutation struct {
-		AddTagToResource           func(childComplexity int, resourceID string, tagName string) int
-		CreateFolder               func(childComplexity int, name string, parentID *string) int
+		AcceptOwnershipTransfer    func(childComplexity int, id string) int
+		AddGroupMember             func(childComplexity int, groupID string, email string, isAdmin *bool) int
+		AddTagToResource           func(childComplexity int, resourceID string, tagName string, groupID *string) int
+		ApplyRules                 func(childComplexity int, ruleID *string) int
+		ApproveAccessRequest       func(childComplexity int, id string, role *model.Role) int
+		BulkDelete                 func(childComplexity int, ids []string) int
+		BulkGrantPermission        func(childComplexity int, resourceIds []string, email string, role model.Role, expiresAt *string) int
+		BulkMove                   func(childComplexity int, ids []string, newParentID *string, conflictStrategy *model.ConflictStrategy) int
+		BulkTag                    func(childComplexity int, ids []string, tagName string, groupID *string) int
+		CancelOwnershipTransfer    func(childComplexity int, id string) int
+		CollapseDuplicates         func(childComplexity int, keepID string, removeIds []string, dryRun *bool, force *bool) int
+		CopyResource               func(childComplexity int, id string, destinationParentID *string, conflictStrategy *model.ConflictStrategy) int
+		CreateFolder               func(childComplexity int, name string, parentID *string, conflictStrategy *model.ConflictStrategy) int
+		CreateGroup                func(childComplexity int, name string) int
+		CreateMetadataField        func(childComplexity int, name string, typeArg model.MetadataFieldType, required *bool, options []string, groupID *string) int
+		CreateRule                 func(childComplexity int, input model.RuleInput) int
+		CreateTag                  func(childComplexity int, name string, color *string, description *string, groupID *string) int
+		DeclineOwnershipTransfer   func(childComplexity int, id string) int
 		DeleteFile                 func(childComplexity int, id string) int
 		DeleteFolder               func(childComplexity int, id string) int
-		GrantPermission            func(childComplexity int, resourceID string, email string, role model.Role) int
+		DeleteGroup                func(childComplexity int, id string) int
+		DeleteMetadataField        func(childComplexity int, id string) int
+		DeleteRule                 func(childComplexity int, id string) int
+		DeleteSavedSearch          func(childComplexity int, id string) int
+		DeleteTag                  func(childComplexity int, id string) int
+		DenyAccessRequest          func(childComplexity int, id string) int
+		GrantGroupPermission       func(childComplexity int, resourceID string, groupID string, role model.Role, expiresAt *string) int
+		GrantPermission            func(childComplexity int, resourceID string, email string, role model.Role, expiresAt *string) int
 		Login                      func(childComplexity int, email string, password string) int
 		MakeResourcePublic         func(childComplexity int, resourceID string) int
-		MoveFile                   func(childComplexity int, fileID string, newParentID *string) int
-		MoveFolder                 func(childComplexity int, folderID string, newParentID *string) int
+		MergeTags                  func(childComplexity int, sourceIds []string, targetID string) int
+		MkdirP                     func(childComplexity int, path string) int
+		MoveFile                   func(childComplexity int, fileID string, newParentID *string, conflictStrategy *model.ConflictStrategy) int
+		MoveFolder                 func(childComplexity int, folderID string, newParentID *string, conflictStrategy *model.ConflictStrategy) int
 		Register                   func(childComplexity int, username string, email string, password string) int
+		RemoveGroupMember          func(childComplexity int, groupID string, email string) int
 		RemoveResourcePublicAccess func(childComplexity int, resourceID string) int
 		RemoveTagFromResource      func(childComplexity int, resourceID string, tagID string) int
-		RenameFile                 func(childComplexity int, id string, newName string) int
-		RenameFolder               func(childComplexity int, id string, newName string) int
+		RenameFile                 func(childComplexity int, id string, newName string, conflictStrategy *model.ConflictStrategy) int
+		RenameFolder               func(childComplexity int, id string, newName string, conflictStrategy *model.ConflictStrategy) int
+		RenameGroup                func(childComplexity int, id string, name string) int
+		RenameTag                  func(childComplexity int, id string, name string) int
+		RequestAccess              func(childComplexity int, token string, role model.Role, message *string) int
+		RevokeGroupPermission      func(childComplexity int, resourceID string, groupID string) int
 		RevokePermission           func(childComplexity int, resourceID string, email string) int
-		UploadFile                 func(childComplexity int, file graphql.Upload, parentID *string) int
+		SaveSearch                 func(childComplexity int, name string, filters model.SearchFilters) int
+		SetInheritPermissions      func(childComplexity int, resourceID string, inherit bool) int
+		SetMetadata                func(childComplexity int, resourceID string, values []*model.MetadataValueInput) int
+		ShareSavedSearch           func(childComplexity int, id string, email string) int
+		StarResource               func(childComplexity int, id string) int
+		TransferOwnership          func(childComplexity int, resourceID string, newOwnerEmail string, keepEditorAccess *bool) int
+		UnshareSavedSearch         func(childComplexity int, id string, email string) int
+		UnstarResource             func(childComplexity int, id string) int
+		UpdateRule                 func(childComplexity int, id string, input model.RuleInput) int
+		UpdateTag                  func(childComplexity int, id string, color *string, description *string) int
+		UploadFile                 func(childComplexity int, file graphql.Upload, parentID *string, path *string, conflictStrategy *model.ConflictStrategy) int
+		VerifyHierarchy            func(childComplexity int, repair *bool) int
+	}
+
+	OwnershipTransfer struct {
+		CreatedAt        func(childComplexity int) int
+		DecidedAt        func(childComplexity int) int
+		FromUser         func(childComplexity int) int
+		ID               func(childComplexity int) int
+		KeepEditorAccess func(childComplexity int) int
+		Resource         func(childComplexity int) int
+		Status           func(childComplexity int) int
+		ToUser           func(childComplexity int) int
+	}
+
+	PageInfo struct {
+		EndCursor       func(childComplexity int) int
+		HasNextPage     func(childComplexity int) int
+		HasPreviousPage func(childComplexity int) int
+		StartCursor     func(childComplexity int) int
 	}
 
 	Permission struct {
-		Role func(childComplexity int) int
-		User func(childComplexity int) int
+		ExpiresAt     func(childComplexity int) int
+		Group         func(childComplexity int) int
+		PrincipalType func(childComplexity int) int
+		Role          func(childComplexity int) int
+		User          func(childComplexity int) int
 	}
 
 	Query struct {
-		AllResources     func(childComplexity int) int
-		File             func(childComplexity int, id string) int
-		Folder           func(childComplexity int, id string) int
-		Me               func(childComplexity int) int
-		ResolveShareLink func(childComplexity int, token string, expectedType string) int
-		Resources        func(childComplexity int, folderID *string) int
-		SearchResources  func(childComplexity int, filters model.SearchFilters, offset *int, limit *int) int
+		AdminResources            func(childComplexity int, ownerID *string, first *int, after *string, sort *model.ResourceSort) int
+		AllResources              func(childComplexity int) int
+		DedupSummary              func(childComplexity int) int
+		DryRunRules               func(childComplexity int, ruleID *string, limit *int) int
+		Duplicates                func(childComplexity int, limit *int) int
+		EffectivePermissions      func(childComplexity int, resourceID string) int
+		File                      func(childComplexity int, id string) int
+		Folder                    func(childComplexity int, id string) int
+		Group                     func(childComplexity int, id string) int
+		Me                        func(childComplexity int) int
+		MetadataFields            func(childComplexity int) int
+		MyGroups                  func(childComplexity int) int
+		MyTags                    func(childComplexity int) int
+		PendingAccessRequests     func(childComplexity int, resourceID *string) int
+		PendingOwnershipTransfers func(childComplexity int) int
+		Recent                    func(childComplexity int, limit *int) int
+		ResolveShareLink          func(childComplexity int, token string, expectedType string) int
+		ResourceByPath            func(childComplexity int, path string, shareToken *string) int
+		Resources                 func(childComplexity int, folderID *string, first *int, after *string, sort *model.ResourceSort) int
+		Rules                     func(childComplexity int) int
+		SavedSearch               func(childComplexity int, id string) int
+		SavedSearches             func(childComplexity int) int
+		SearchFacets              func(childComplexity int, filters *model.SearchFilters, savedSearchID *string) int
+		SearchResources           func(childComplexity int, filters *model.SearchFilters, savedSearchID *string, first *int, after *string, sort *model.ResourceSort) int
+		Starred                   func(childComplexity int, first *int, after *string, sort *model.ResourceSort) int
+		SuggestResources          func(childComplexity int, prefix string, limit *int) int
+	}
+
+	RecentItem struct {
+		Action     func(childComplexity int) int
+		OccurredAt func(childComplexity int) int
+		Resource   func(childComplexity int) int
+	}
+
+	ResourceConnection struct {
+		Edges      func(childComplexity int) int
+		PageInfo   func(childComplexity int) int
+		TotalCount func(childComplexity int) int
+	}
+
+	ResourceEdge struct {
+		Cursor  func(childComplexity int) int
+		Node    func(childComplexity int) int
+		Snippet func(childComplexity int) int
+	}
+
+	Rule struct {
+		Actions    func(childComplexity int) int
+		Conditions func(childComplexity int) int
+		CreatedAt  func(childComplexity int) int
+		Enabled    func(childComplexity int) int
+		ID         func(childComplexity int) int
+		Name       func(childComplexity int) int
+	}
+
+	RuleActions struct {
+		AddTags        func(childComplexity int) int
+		MakePublic     func(childComplexity int) int
+		MoveToFolderID func(childComplexity int) int
+	}
+
+	RuleApplication struct {
+		FailureCount func(childComplexity int) int
+		Failures     func(childComplexity int) int
+		MatchedCount func(childComplexity int) int
+		ScannedCount func(childComplexity int) int
+	}
+
+	RuleConditions struct {
+		Contains          func(childComplexity int) int
+		FolderID          func(childComplexity int) int
+		IncludeSubfolders func(childComplexity int) int
+		MaxSizeBytes      func(childComplexity int) int
+		MimeTypes         func(childComplexity int) int
+		MinSizeBytes      func(childComplexity int) int
+		NameGlob          func(childComplexity int) int
+		NameRegex         func(childComplexity int) int
+	}
+
+	RuleMatch struct {
+		Resource func(childComplexity int) int
+		Rule     func(childComplexity int) int
+	}
+
+	SavedMetadataFilter struct {
+		FieldID func(childComplexity int) int
+		Op      func(childComplexity int) int
+		Value   func(childComplexity int) int
+	}
+
+	SavedSearch struct {
+		CreatedAt  func(childComplexity int) int
+		Filters    func(childComplexity int) int
+		ID         func(childComplexity int) int
+		Name       func(childComplexity int) int
+		Owner      func(childComplexity int) int
+		Results    func(childComplexity int, first *int, after *string, sort *model.ResourceSort) int
+		SharedWith func(childComplexity int) int
+	}
+
+	SavedSearchFilters struct {
+		AfterDate      func(childComplexity int) int
+		BeforeDate     func(childComplexity int) int
+		FolderID       func(childComplexity int) int
+		MaxSizeBytes   func(childComplexity int) int
+		Metadata       func(childComplexity int) int
+		MimeTypes      func(childComplexity int) int
+		MinSizeBytes   func(childComplexity int) int
+		Name           func(childComplexity int) int
+		NameSimilarity func(childComplexity int) int
+		Query          func(childComplexity int) int
+		Tags           func(childComplexity int) int
+		Types          func(childComplexity int) int
+		UploaderName   func(childComplexity int) int
+	}
+
+	SearchFacets struct {
+		MimeTypes  func(childComplexity int) int
+		Months     func(childComplexity int) int
+		Owners     func(childComplexity int) int
+		Sizes      func(childComplexity int) int
+		Tags       func(childComplexity int) int
+		TotalCount func(childComplexity int) int
 	}
 
 	StorageStats struct {
 		DeduplicatedSizeBytes func(childComplexity int) int
 		OriginalSizeBytes     func(childComplexity int) int
+		ReferenceCount        func(childComplexity int) int
 		SavedBytes            func(childComplexity int) int
 		SavedPercentage       func(childComplexity int) int
 	}
 
 	Tag struct {
-		CreatedAt func(childComplexity int) int
-		ID        func(childComplexity int) int
-		Name      func(childComplexity int) int
-		UpdatedAt func(childComplexity int) int
+		Color       func(childComplexity int) int
+		CreatedAt   func(childComplexity int) int
+		Description func(childComplexity int) int
+		GroupID     func(childComplexity int) int
+		ID          func(childComplexity int) int
+		Name        func(childComplexity int) int
+		UpdatedAt   func(childComplexity int) int
+	}
+
+	TagUsage struct {
+		Tag        func(childComplexity int) int
+		UsageCount func(childComplexity int) int
 	}
 
 	User struct {
@@ -161,481 +471,2249 @@ func (e *executableSchema) Complexity(ctx context.Context, typeName, field strin
 	_ = ec
 	switch typeName + "." + field {
 
-	case "AuthPayload.token":
-		if e.complexity.AuthPayload.Token == nil {
+	case "AccessRequest.createdAt":
+		if e.complexity.AccessRequest.CreatedAt == nil {
 			break
 		}
 
-		return e.complexity.AuthPayload.Token(childComplexity), true
+		return e.complexity.AccessRequest.CreatedAt(childComplexity), true
 
-	case "AuthPayload.user":
-		if e.complexity.AuthPayload.User == nil {
+	case "AccessRequest.decidedAt":
+		if e.complexity.AccessRequest.DecidedAt == nil {
 			break
 		}
 
-		return e.complexity.AuthPayload.User(childComplexity), true
+		return e.complexity.AccessRequest.DecidedAt(childComplexity), true
 
-	case "File.createdAt":
-		if e.complexity.File.CreatedAt == nil {
+	case "AccessRequest.id":
+		if e.complexity.AccessRequest.ID == nil {
 			break
 		}
 
-		return e.complexity.File.CreatedAt(childComplexity), true
+		return e.complexity.AccessRequest.ID(childComplexity), true
 
-	case "File.id":
-		if e.complexity.File.ID == nil {
+	case "AccessRequest.message":
+		if e.complexity.AccessRequest.Message == nil {
 			break
 		}
 
-		return e.complexity.File.ID(childComplexity), true
+		return e.complexity.AccessRequest.Message(childComplexity), true
 
-	case "File.isPublic":
-		if e.complexity.File.IsPublic == nil {
+	case "AccessRequest.requester":
+		if e.complexity.AccessRequest.Requester == nil {
 			break
 		}
 
-		return e.complexity.File.IsPublic(childComplexity), true
+		return e.complexity.AccessRequest.Requester(childComplexity), true
 
-	case "File.mimeType":
-		if e.complexity.File.MimeType == nil {
+	case "AccessRequest.resource":
+		if e.complexity.AccessRequest.Resource == nil {
 			break
 		}
 
-		return e.complexity.File.MimeType(childComplexity), true
+		return e.complexity.AccessRequest.Resource(childComplexity), true
 
-	case "File.name":
-		if e.complexity.File.Name == nil {
+	case "AccessRequest.role":
+		if e.complexity.AccessRequest.Role == nil {
 			break
 		}
 
-		return e.complexity.File.Name(childComplexity), true
+		return e.complexity.AccessRequest.Role(childComplexity), true
 
-	case "File.owner":
-		if e.complexity.File.Owner == nil {
+	case "AccessRequest.status":
+		if e.complexity.AccessRequest.Status == nil {
 			break
 		}
 
-		return e.complexity.File.Owner(childComplexity), true
+		return e.complexity.AccessRequest.Status(childComplexity), true
 
-	case "File.parent":
-		if e.complexity.File.Parent == nil {
+	case "AuthPayload.token":
+		if e.complexity.AuthPayload.Token == nil {
 			break
 		}
 
-		return e.complexity.File.Parent(childComplexity), true
+		return e.complexity.AuthPayload.Token(childComplexity), true
 
-	case "File.permissions":
-		if e.complexity.File.Permissions == nil {
+	case "AuthPayload.user":
+		if e.complexity.AuthPayload.User == nil {
 			break
 		}
 
-		return e.complexity.File.Permissions(childComplexity), true
+		return e.complexity.AuthPayload.User(childComplexity), true
 
-	case "File.shareToken":
-		if e.complexity.File.ShareToken == nil {
+	case "Breadcrumb.id":
+		if e.complexity.Breadcrumb.ID == nil {
 			break
 		}
 
-		return e.complexity.File.ShareToken(childComplexity), true
+		return e.complexity.Breadcrumb.ID(childComplexity), true
 
-	case "File.sizeBytes":
-		if e.complexity.File.SizeBytes == nil {
+	case "Breadcrumb.name":
+		if e.complexity.Breadcrumb.Name == nil {
 			break
 		}
 
-		return e.complexity.File.SizeBytes(childComplexity), true
+		return e.complexity.Breadcrumb.Name(childComplexity), true
 
-	case "File.storage":
-		if e.complexity.File.Storage == nil {
+	case "BulkItemResult.error":
+		if e.complexity.BulkItemResult.Error == nil {
 			break
 		}
 
-		return e.complexity.File.Storage(childComplexity), true
+		return e.complexity.BulkItemResult.Error(childComplexity), true
 
-	case "File.tags":
-		if e.complexity.File.Tags == nil {
+	case "BulkItemResult.id":
+		if e.complexity.BulkItemResult.ID == nil {
 			break
 		}
 
-		return e.complexity.File.Tags(childComplexity), true
+		return e.complexity.BulkItemResult.ID(childComplexity), true
 
-	case "File.type":
-		if e.complexity.File.Type == nil {
+	case "BulkItemResult.resource":
+		if e.complexity.BulkItemResult.Resource == nil {
 			break
 		}
 
-		return e.complexity.File.Type(childComplexity), true
+		return e.complexity.BulkItemResult.Resource(childComplexity), true
 
-	case "File.updatedAt":
-		if e.complexity.File.UpdatedAt == nil {
+	case "BulkItemResult.success":
+		if e.complexity.BulkItemResult.Success == nil {
 			break
 		}
 
-		return e.complexity.File.UpdatedAt(childComplexity), true
+		return e.complexity.BulkItemResult.Success(childComplexity), true
 
-	case "Folder.children":
-		if e.complexity.Folder.Children == nil {
+	case "BulkResult.failureCount":
+		if e.complexity.BulkResult.FailureCount == nil {
 			break
 		}
 
-		return e.complexity.Folder.Children(childComplexity), true
+		return e.complexity.BulkResult.FailureCount(childComplexity), true
 
-	case "Folder.createdAt":
-		if e.complexity.Folder.CreatedAt == nil {
+	case "BulkResult.results":
+		if e.complexity.BulkResult.Results == nil {
 			break
 		}
 
-		return e.complexity.Folder.CreatedAt(childComplexity), true
+		return e.complexity.BulkResult.Results(childComplexity), true
 
-	case "Folder.id":
-		if e.complexity.Folder.ID == nil {
+	case "BulkResult.successCount":
+		if e.complexity.BulkResult.SuccessCount == nil {
 			break
 		}
 
-		return e.complexity.Folder.ID(childComplexity), true
+		return e.complexity.BulkResult.SuccessCount(childComplexity), true
 
-	case "Folder.isPublic":
-		if e.complexity.Folder.IsPublic == nil {
+	case "CollapseResult.dryRun":
+		if e.complexity.CollapseResult.DryRun == nil {
 			break
 		}
 
-		return e.complexity.Folder.IsPublic(childComplexity), true
+		return e.complexity.CollapseResult.DryRun(childComplexity), true
 
-	case "Folder.name":
-		if e.complexity.Folder.Name == nil {
+	case "CollapseResult.freedBytes":
+		if e.complexity.CollapseResult.FreedBytes == nil {
 			break
 		}
 
-		return e.complexity.Folder.Name(childComplexity), true
+		return e.complexity.CollapseResult.FreedBytes(childComplexity), true
 
-	case "Folder.owner":
-		if e.complexity.Folder.Owner == nil {
+	case "CollapseResult.kept":
+		if e.complexity.CollapseResult.Kept == nil {
 			break
 		}
 
-		return e.complexity.Folder.Owner(childComplexity), true
+		return e.complexity.CollapseResult.Kept(childComplexity), true
 
-	case "Folder.parent":
-		if e.complexity.Folder.Parent == nil {
+	case "CollapseResult.removed":
+		if e.complexity.CollapseResult.Removed == nil {
 			break
 		}
 
-		return e.complexity.Folder.Parent(childComplexity), true
+		return e.complexity.CollapseResult.Removed(childComplexity), true
 
-	case "Folder.permissions":
-		if e.complexity.Folder.Permissions == nil {
+	case "DedupSummary.duplicateFiles":
+		if e.complexity.DedupSummary.DuplicateFiles == nil {
 			break
 		}
 
-		return e.complexity.Folder.Permissions(childComplexity), true
+		return e.complexity.DedupSummary.DuplicateFiles(childComplexity), true
 
-	case "Folder.shareToken":
-		if e.complexity.Folder.ShareToken == nil {
+	case "DedupSummary.duplicateGroups":
+		if e.complexity.DedupSummary.DuplicateGroups == nil {
 			break
 		}
 
-		return e.complexity.Folder.ShareToken(childComplexity), true
+		return e.complexity.DedupSummary.DuplicateGroups(childComplexity), true
 
-	case "Folder.tags":
-		if e.complexity.Folder.Tags == nil {
+	case "DedupSummary.fileCount":
+		if e.complexity.DedupSummary.FileCount == nil {
 			break
 		}
 
-		return e.complexity.Folder.Tags(childComplexity), true
+		return e.complexity.DedupSummary.FileCount(childComplexity), true
 
-	case "Folder.type":
-		if e.complexity.Folder.Type == nil {
+	case "DedupSummary.logicalBytes":
+		if e.complexity.DedupSummary.LogicalBytes == nil {
 			break
 		}
 
-		return e.complexity.Folder.Type(childComplexity), true
+		return e.complexity.DedupSummary.LogicalBytes(childComplexity), true
 
-	case "Folder.updatedAt":
-		if e.complexity.Folder.UpdatedAt == nil {
+	case "DedupSummary.savedBytes":
+		if e.complexity.DedupSummary.SavedBytes == nil {
 			break
 		}
 
-		return e.complexity.Folder.UpdatedAt(childComplexity), true
+		return e.complexity.DedupSummary.SavedBytes(childComplexity), true
 
-	case "Mutation.addTagToResource":
-		if e.complexity.Mutation.AddTagToResource == nil {
+	case "DedupSummary.savedPercentage":
+		if e.complexity.DedupSummary.SavedPercentage == nil {
 			break
 		}
 
-		args, err := ec.field_Mutation_addTagToResource_args(ctx, rawArgs)
-		if err != nil {
-			return 0, false
+		return e.complexity.DedupSummary.SavedPercentage(childComplexity), true
+
+	case "DedupSummary.storedBytes":
+		if e.complexity.DedupSummary.StoredBytes == nil {
+			break
 		}
 
-		return e.complexity.Mutation.AddTagToResource(childComplexity, args["resourceID"].(string), args["tagName"].(string)), true
+		return e.complexity.DedupSummary.StoredBytes(childComplexity), true
 
-	case "Mutation.createFolder":
-		if e.complexity.Mutation.CreateFolder == nil {
+	case "DedupSummary.uniqueBytes":
+		if e.complexity.DedupSummary.UniqueBytes == nil {
 			break
 		}
 
-		args, err := ec.field_Mutation_createFolder_args(ctx, rawArgs)
-		if err != nil {
-			return 0, false
+		return e.complexity.DedupSummary.UniqueBytes(childComplexity), true
+
+	case "DedupSummary.wastedBytes":
+		if e.complexity.DedupSummary.WastedBytes == nil {
+			break
 		}
 
-		return e.complexity.Mutation.CreateFolder(childComplexity, args["name"].(string), args["parentId"].(*string)), true
+		return e.complexity.DedupSummary.WastedBytes(childComplexity), true
 
-	case "Mutation.deleteFile":
-		if e.complexity.Mutation.DeleteFile == nil {
+	case "DuplicateGroup.fileHash":
+		if e.complexity.DuplicateGroup.FileHash == nil {
 			break
 		}
 
-		args, err := ec.field_Mutation_deleteFile_args(ctx, rawArgs)
-		if err != nil {
-			return 0, false
+		return e.complexity.DuplicateGroup.FileHash(childComplexity), true
+
+	case "DuplicateGroup.files":
+		if e.complexity.DuplicateGroup.Files == nil {
+			break
 		}
 
-		return e.complexity.Mutation.DeleteFile(childComplexity, args["id"].(string)), true
+		return e.complexity.DuplicateGroup.Files(childComplexity), true
 
-	case "Mutation.deleteFolder":
-		if e.complexity.Mutation.DeleteFolder == nil {
+	case "DuplicateGroup.mimeType":
+		if e.complexity.DuplicateGroup.MimeType == nil {
 			break
 		}
 
-		args, err := ec.field_Mutation_deleteFolder_args(ctx, rawArgs)
-		if err != nil {
-			return 0, false
+		return e.complexity.DuplicateGroup.MimeType(childComplexity), true
+
+	case "DuplicateGroup.sizeBytes":
+		if e.complexity.DuplicateGroup.SizeBytes == nil {
+			break
 		}
 
-		return e.complexity.Mutation.DeleteFolder(childComplexity, args["id"].(string)), true
+		return e.complexity.DuplicateGroup.SizeBytes(childComplexity), true
 
-	case "Mutation.grantPermission":
-		if e.complexity.Mutation.GrantPermission == nil {
+	case "DuplicateGroup.wastedBytes":
+		if e.complexity.DuplicateGroup.WastedBytes == nil {
 			break
 		}
 
-		args, err := ec.field_Mutation_grantPermission_args(ctx, rawArgs)
-		if err != nil {
-			return 0, false
+		return e.complexity.DuplicateGroup.WastedBytes(childComplexity), true
+
+	case "EffectivePermission.expiresAt":
+		if e.complexity.EffectivePermission.ExpiresAt == nil {
+			break
 		}
 
-		return e.complexity.Mutation.GrantPermission(childComplexity, args["resourceId"].(string), args["email"].(string), args["role"].(model.Role)), true
+		return e.complexity.EffectivePermission.ExpiresAt(childComplexity), true
 
-	case "Mutation.login":
-		if e.complexity.Mutation.Login == nil {
+	case "EffectivePermission.grantedOnId":
+		if e.complexity.EffectivePermission.GrantedOnID == nil {
 			break
 		}
 
-		args, err := ec.field_Mutation_login_args(ctx, rawArgs)
-		if err != nil {
-			return 0, false
+		return e.complexity.EffectivePermission.GrantedOnID(childComplexity), true
+
+	case "EffectivePermission.grantedOnName":
+		if e.complexity.EffectivePermission.GrantedOnName == nil {
+			break
 		}
 
-		return e.complexity.Mutation.Login(childComplexity, args["email"].(string), args["password"].(string)), true
+		return e.complexity.EffectivePermission.GrantedOnName(childComplexity), true
 
-	case "Mutation.makeResourcePublic":
-		if e.complexity.Mutation.MakeResourcePublic == nil {
+	case "EffectivePermission.group":
+		if e.complexity.EffectivePermission.Group == nil {
 			break
 		}
 
-		args, err := ec.field_Mutation_makeResourcePublic_args(ctx, rawArgs)
-		if err != nil {
-			return 0, false
+		return e.complexity.EffectivePermission.Group(childComplexity), true
+
+	case "EffectivePermission.inherited":
+		if e.complexity.EffectivePermission.Inherited == nil {
+			break
 		}
 
-		return e.complexity.Mutation.MakeResourcePublic(childComplexity, args["resourceId"].(string)), true
+		return e.complexity.EffectivePermission.Inherited(childComplexity), true
 
-	case "Mutation.moveFile":
-		if e.complexity.Mutation.MoveFile == nil {
+	case "EffectivePermission.role":
+		if e.complexity.EffectivePermission.Role == nil {
 			break
 		}
 
-		args, err := ec.field_Mutation_moveFile_args(ctx, rawArgs)
-		if err != nil {
-			return 0, false
+		return e.complexity.EffectivePermission.Role(childComplexity), true
+
+	case "EffectivePermission.source":
+		if e.complexity.EffectivePermission.Source == nil {
+			break
 		}
 
-		return e.complexity.Mutation.MoveFile(childComplexity, args["fileId"].(string), args["newParentId"].(*string)), true
+		return e.complexity.EffectivePermission.Source(childComplexity), true
 
-	case "Mutation.moveFolder":
-		if e.complexity.Mutation.MoveFolder == nil {
+	case "EffectivePermission.user":
+		if e.complexity.EffectivePermission.User == nil {
 			break
 		}
 
-		args, err := ec.field_Mutation_moveFolder_args(ctx, rawArgs)
-		if err != nil {
-			return 0, false
+		return e.complexity.EffectivePermission.User(childComplexity), true
+
+	case "FacetBucket.count":
+		if e.complexity.FacetBucket.Count == nil {
+			break
 		}
 
-		return e.complexity.Mutation.MoveFolder(childComplexity, args["folderId"].(string), args["newParentId"].(*string)), true
+		return e.complexity.FacetBucket.Count(childComplexity), true
 
-	case "Mutation.register":
-		if e.complexity.Mutation.Register == nil {
+	case "FacetBucket.filter":
+		if e.complexity.FacetBucket.Filter == nil {
 			break
 		}
 
-		args, err := ec.field_Mutation_register_args(ctx, rawArgs)
-		if err != nil {
-			return 0, false
+		return e.complexity.FacetBucket.Filter(childComplexity), true
+
+	case "FacetBucket.label":
+		if e.complexity.FacetBucket.Label == nil {
+			break
+		}
+
+		return e.complexity.FacetBucket.Label(childComplexity), true
+
+	case "File.breadcrumbs":
+		if e.complexity.File.Breadcrumbs == nil {
+			break
+		}
+
+		return e.complexity.File.Breadcrumbs(childComplexity), true
+
+	case "File.createdAt":
+		if e.complexity.File.CreatedAt == nil {
+			break
+		}
+
+		return e.complexity.File.CreatedAt(childComplexity), true
+
+	case "File.id":
+		if e.complexity.File.ID == nil {
+			break
+		}
+
+		return e.complexity.File.ID(childComplexity), true
+
+	case "File.inheritPermissions":
+		if e.complexity.File.InheritPermissions == nil {
+			break
+		}
+
+		return e.complexity.File.InheritPermissions(childComplexity), true
+
+	case "File.isPublic":
+		if e.complexity.File.IsPublic == nil {
+			break
+		}
+
+		return e.complexity.File.IsPublic(childComplexity), true
+
+	case "File.metadata":
+		if e.complexity.File.Metadata == nil {
+			break
+		}
+
+		return e.complexity.File.Metadata(childComplexity), true
+
+	case "File.mimeType":
+		if e.complexity.File.MimeType == nil {
+			break
+		}
+
+		return e.complexity.File.MimeType(childComplexity), true
+
+	case "File.name":
+		if e.complexity.File.Name == nil {
+			break
+		}
+
+		return e.complexity.File.Name(childComplexity), true
+
+	case "File.owner":
+		if e.complexity.File.Owner == nil {
+			break
+		}
+
+		return e.complexity.File.Owner(childComplexity), true
+
+	case "File.parent":
+		if e.complexity.File.Parent == nil {
+			break
+		}
+
+		return e.complexity.File.Parent(childComplexity), true
+
+	case "File.path":
+		if e.complexity.File.Path == nil {
+			break
+		}
+
+		return e.complexity.File.Path(childComplexity), true
+
+	case "File.permissions":
+		if e.complexity.File.Permissions == nil {
+			break
+		}
+
+		return e.complexity.File.Permissions(childComplexity), true
+
+	case "File.shareToken":
+		if e.complexity.File.ShareToken == nil {
+			break
+		}
+
+		return e.complexity.File.ShareToken(childComplexity), true
+
+	case "File.sizeBytes":
+		if e.complexity.File.SizeBytes == nil {
+			break
+		}
+
+		return e.complexity.File.SizeBytes(childComplexity), true
+
+	case "File.storage":
+		if e.complexity.File.Storage == nil {
+			break
+		}
+
+		return e.complexity.File.Storage(childComplexity), true
+
+	case "File.tags":
+		if e.complexity.File.Tags == nil {
+			break
+		}
+
+		return e.complexity.File.Tags(childComplexity), true
+
+	case "File.type":
+		if e.complexity.File.Type == nil {
+			break
+		}
+
+		return e.complexity.File.Type(childComplexity), true
+
+	case "File.updatedAt":
+		if e.complexity.File.UpdatedAt == nil {
+			break
+		}
+
+		return e.complexity.File.UpdatedAt(childComplexity), true
+
+	case "Folder.breadcrumbs":
+		if e.complexity.Folder.Breadcrumbs == nil {
+			break
+		}
+
+		return e.complexity.Folder.Breadcrumbs(childComplexity), true
+
+	case "Folder.children":
+		if e.complexity.Folder.Children == nil {
+			break
+		}
+
+		return e.complexity.Folder.Children(childComplexity), true
+
+	case "Folder.createdAt":
+		if e.complexity.Folder.CreatedAt == nil {
+			break
+		}
+
+		return e.complexity.Folder.CreatedAt(childComplexity), true
+
+	case "Folder.descendantCount":
+		if e.complexity.Folder.DescendantCount == nil {
+			break
+		}
+
+		return e.complexity.Folder.DescendantCount(childComplexity), true
+
+	case "Folder.id":
+		if e.complexity.Folder.ID == nil {
+			break
+		}
+
+		return e.complexity.Folder.ID(childComplexity), true
+
+	case "Folder.inheritPermissions":
+		if e.complexity.Folder.InheritPermissions == nil {
+			break
+		}
+
+		return e.complexity.Folder.InheritPermissions(childComplexity), true
+
+	case "Folder.isPublic":
+		if e.complexity.Folder.IsPublic == nil {
+			break
+		}
+
+		return e.complexity.Folder.IsPublic(childComplexity), true
+
+	case "Folder.metadata":
+		if e.complexity.Folder.Metadata == nil {
+			break
+		}
+
+		return e.complexity.Folder.Metadata(childComplexity), true
+
+	case "Folder.name":
+		if e.complexity.Folder.Name == nil {
+			break
+		}
+
+		return e.complexity.Folder.Name(childComplexity), true
+
+	case "Folder.owner":
+		if e.complexity.Folder.Owner == nil {
+			break
+		}
+
+		return e.complexity.Folder.Owner(childComplexity), true
+
+	case "Folder.parent":
+		if e.complexity.Folder.Parent == nil {
+			break
+		}
+
+		return e.complexity.Folder.Parent(childComplexity), true
+
+	case "Folder.path":
+		if e.complexity.Folder.Path == nil {
+			break
+		}
+
+		return e.complexity.Folder.Path(childComplexity), true
+
+	case "Folder.permissions":
+		if e.complexity.Folder.Permissions == nil {
+			break
+		}
+
+		return e.complexity.Folder.Permissions(childComplexity), true
+
+	case "Folder.shareToken":
+		if e.complexity.Folder.ShareToken == nil {
+			break
+		}
+
+		return e.complexity.Folder.ShareToken(childComplexity), true
+
+	case "Folder.tags":
+		if e.complexity.Folder.Tags == nil {
+			break
+		}
+
+		return e.complexity.Folder.Tags(childComplexity), true
+
+	case "Folder.totalSizeBytes":
+		if e.complexity.Folder.TotalSizeBytes == nil {
+			break
+		}
+
+		return e.complexity.Folder.TotalSizeBytes(childComplexity), true
+
+	case "Folder.type":
+		if e.complexity.Folder.Type == nil {
+			break
+		}
+
+		return e.complexity.Folder.Type(childComplexity), true
+
+	case "Folder.updatedAt":
+		if e.complexity.Folder.UpdatedAt == nil {
+			break
+		}
+
+		return e.complexity.Folder.UpdatedAt(childComplexity), true
+
+	case "Group.createdAt":
+		if e.complexity.Group.CreatedAt == nil {
+			break
+		}
+
+		return e.complexity.Group.CreatedAt(childComplexity), true
+
+	case "Group.id":
+		if e.complexity.Group.ID == nil {
+			break
+		}
+
+		return e.complexity.Group.ID(childComplexity), true
+
+	case "Group.members":
+		if e.complexity.Group.Members == nil {
+			break
+		}
+
+		return e.complexity.Group.Members(childComplexity), true
+
+	case "Group.name":
+		if e.complexity.Group.Name == nil {
+			break
+		}
+
+		return e.complexity.Group.Name(childComplexity), true
+
+	case "Group.owner":
+		if e.complexity.Group.Owner == nil {
+			break
+		}
+
+		return e.complexity.Group.Owner(childComplexity), true
+
+	case "Group.updatedAt":
+		if e.complexity.Group.UpdatedAt == nil {
+			break
+		}
+
+		return e.complexity.Group.UpdatedAt(childComplexity), true
+
+	case "GroupMember.addedAt":
+		if e.complexity.GroupMember.AddedAt == nil {
+			break
+		}
+
+		return e.complexity.GroupMember.AddedAt(childComplexity), true
+
+	case "GroupMember.isAdmin":
+		if e.complexity.GroupMember.IsAdmin == nil {
+			break
+		}
+
+		return e.complexity.GroupMember.IsAdmin(childComplexity), true
+
+	case "GroupMember.user":
+		if e.complexity.GroupMember.User == nil {
+			break
+		}
+
+		return e.complexity.GroupMember.User(childComplexity), true
+
+	case "HierarchyReport.cycleResourceIds":
+		if e.complexity.HierarchyReport.CycleResourceIds == nil {
+			break
+		}
+
+		return e.complexity.HierarchyReport.CycleResourceIds(childComplexity), true
+
+	case "HierarchyReport.missingRows":
+		if e.complexity.HierarchyReport.MissingRows == nil {
+			break
+		}
+
+		return e.complexity.HierarchyReport.MissingRows(childComplexity), true
+
+	case "HierarchyReport.repaired":
+		if e.complexity.HierarchyReport.Repaired == nil {
+			break
+		}
+
+		return e.complexity.HierarchyReport.Repaired(childComplexity), true
+
+	case "HierarchyReport.staleRows":
+		if e.complexity.HierarchyReport.StaleRows == nil {
+			break
+		}
+
+		return e.complexity.HierarchyReport.StaleRows(childComplexity), true
+
+	case "MetadataField.createdAt":
+		if e.complexity.MetadataField.CreatedAt == nil {
+			break
+		}
+
+		return e.complexity.MetadataField.CreatedAt(childComplexity), true
+
+	case "MetadataField.groupId":
+		if e.complexity.MetadataField.GroupID == nil {
+			break
+		}
+
+		return e.complexity.MetadataField.GroupID(childComplexity), true
+
+	case "MetadataField.id":
+		if e.complexity.MetadataField.ID == nil {
+			break
+		}
+
+		return e.complexity.MetadataField.ID(childComplexity), true
+
+	case "MetadataField.name":
+		if e.complexity.MetadataField.Name == nil {
+			break
+		}
+
+		return e.complexity.MetadataField.Name(childComplexity), true
+
+	case "MetadataField.options":
+		if e.complexity.MetadataField.Options == nil {
+			break
+		}
+
+		return e.complexity.MetadataField.Options(childComplexity), true
+
+	case "MetadataField.required":
+		if e.complexity.MetadataField.Required == nil {
+			break
+		}
+
+		return e.complexity.MetadataField.Required(childComplexity), true
+
+	case "MetadataField.type":
+		if e.complexity.MetadataField.Type == nil {
+			break
+		}
+
+		return e.complexity.MetadataField.Type(childComplexity), true
+
+	case "MetadataValue.field":
+		if e.complexity.MetadataValue.Field == nil {
+			break
+		}
+
+		return e.complexity.MetadataValue.Field(childComplexity), true
+
+	case "MetadataValue.value":
+		if e.complexity.MetadataValue.Value == nil {
+			break
+		}
+
+		return e.complexity.MetadataValue.Value(childComplexity), true
+
+	case "Mutation.acceptOwnershipTransfer":
+		if e.complexity.Mutation.AcceptOwnershipTransfer == nil {
+			break
+		}
+
+		args, err := ec.field_Mutation_acceptOwnershipTransfer_args(ctx, rawArgs)
+		if err != nil {
+			return 0, false
+		}
+
+		return e.complexity.Mutation.AcceptOwnershipTransfer(childComplexity, args["id"].(string)), true
+
+	case "Mutation.addGroupMember":
+		if e.complexity.Mutation.AddGroupMember == nil {
+			break
+		}
+
+		args, err := ec.field_Mutation_addGroupMember_args(ctx, rawArgs)
+		if err != nil {
+			return 0, false
+		}
+
+		return e.complexity.Mutation.AddGroupMember(childComplexity, args["groupId"].(string), args["email"].(string), args["isAdmin"].(*bool)), true
+
+	case "Mutation.addTagToResource":
+		if e.complexity.Mutation.AddTagToResource == nil {
+			break
+		}
+
+		args, err := ec.field_Mutation_addTagToResource_args(ctx, rawArgs)
+		if err != nil {
+			return 0, false
+		}
+
+		return e.complexity.Mutation.AddTagToResource(childComplexity, args["resourceID"].(string), args["tagName"].(string), args["groupId"].(*string)), true
+
+	case "Mutation.applyRules":
+		if e.complexity.Mutation.ApplyRules == nil {
+			break
+		}
+
+		args, err := ec.field_Mutation_applyRules_args(ctx, rawArgs)
+		if err != nil {
+			return 0, false
+		}
+
+		return e.complexity.Mutation.ApplyRules(childComplexity, args["ruleId"].(*string)), true
+
+	case "Mutation.approveAccessRequest":
+		if e.complexity.Mutation.ApproveAccessRequest == nil {
+			break
+		}
+
+		args, err := ec.field_Mutation_approveAccessRequest_args(ctx, rawArgs)
+		if err != nil {
+			return 0, false
+		}
+
+		return e.complexity.Mutation.ApproveAccessRequest(childComplexity, args["id"].(string), args["role"].(*model.Role)), true
+
+	case "Mutation.bulkDelete":
+		if e.complexity.Mutation.BulkDelete == nil {
+			break
+		}
+
+		args, err := ec.field_Mutation_bulkDelete_args(ctx, rawArgs)
+		if err != nil {
+			return 0, false
+		}
+
+		return e.complexity.Mutation.BulkDelete(childComplexity, args["ids"].([]string)), true
+
+	case "Mutation.bulkGrantPermission":
+		if e.complexity.Mutation.BulkGrantPermission == nil {
+			break
+		}
+
+		args, err := ec.field_Mutation_bulkGrantPermission_args(ctx, rawArgs)
+		if err != nil {
+			return 0, false
+		}
+
+		return e.complexity.Mutation.BulkGrantPermission(childComplexity, args["resourceIds"].([]string), args["email"].(string), args["role"].(model.Role), args["expiresAt"].(*string)), true
+
+	case "Mutation.bulkMove":
+		if e.complexity.Mutation.BulkMove == nil {
+			break
+		}
+
+		args, err := ec.field_Mutation_bulkMove_args(ctx, rawArgs)
+		if err != nil {
+			return 0, false
+		}
+
+		return e.complexity.Mutation.BulkMove(childComplexity, args["ids"].([]string), args["newParentId"].(*string), args["conflictStrategy"].(*model.ConflictStrategy)), true
+
+	case "Mutation.bulkTag":
+		if e.complexity.Mutation.BulkTag == nil {
+			break
+		}
+
+		args, err := ec.field_Mutation_bulkTag_args(ctx, rawArgs)
+		if err != nil {
+			return 0, false
+		}
+
+		return e.complexity.Mutation.BulkTag(childComplexity, args["ids"].([]string), args["tagName"].(string), args["groupId"].(*string)), true
+
+	case "Mutation.cancelOwnershipTransfer":
+		if e.complexity.Mutation.CancelOwnershipTransfer == nil {
+			break
+		}
+
+		args, err := ec.field_Mutation_cancelOwnershipTransfer_args(ctx, rawArgs)
+		if err != nil {
+			return 0, false
+		}
+
+		return e.complexity.Mutation.CancelOwnershipTransfer(childComplexity, args["id"].(string)), true
+
+	case "Mutation.collapseDuplicates":
+		if e.complexity.Mutation.CollapseDuplicates == nil {
+			break
+		}
+
+		args, err := ec.field_Mutation_collapseDuplicates_args(ctx, rawArgs)
+		if err != nil {
+			return 0, false
+		}
+
+		return e.complexity.Mutation.CollapseDuplicates(childComplexity, args["keepId"].(string), args["removeIds"].([]string), args["dryRun"].(*bool), args["force"].(*bool)), true
+
+	case "Mutation.copyResource":
+		if e.complexity.Mutation.CopyResource == nil {
+			break
+		}
+
+		args, err := ec.field_Mutation_copyResource_args(ctx, rawArgs)
+		if err != nil {
+			return 0, false
+		}
+
+		return e.complexity.Mutation.CopyResource(childComplexity, args["id"].(string), args["destinationParentId"].(*string), args["conflictStrategy"].(*model.ConflictStrategy)), true
+
+	case "Mutation.createFolder":
+		if e.complexity.Mutation.CreateFolder == nil {
+			break
+		}
+
+		args, err := ec.field_Mutation_createFolder_args(ctx, rawArgs)
+		if err != nil {
+			return 0, false
+		}
+
+		return e.complexity.Mutation.CreateFolder(childComplexity, args["name"].(string), args["parentId"].(*string), args["conflictStrategy"].(*model.ConflictStrategy)), true
+
+	case "Mutation.createGroup":
+		if e.complexity.Mutation.CreateGroup == nil {
+			break
+		}
+
+		args, err := ec.field_Mutation_createGroup_args(ctx, rawArgs)
+		if err != nil {
+			return 0, false
+		}
+
+		return e.complexity.Mutation.CreateGroup(childComplexity, args["name"].(string)), true
+
+	case "Mutation.createMetadataField":
+		if e.complexity.Mutation.CreateMetadataField == nil {
+			break
+		}
+
+		args, err := ec.field_Mutation_createMetadataField_args(ctx, rawArgs)
+		if err != nil {
+			return 0, false
+		}
+
+		return e.complexity.Mutation.CreateMetadataField(childComplexity, args["name"].(string), args["type"].(model.MetadataFieldType), args["required"].(*bool), args["options"].([]string), args["groupId"].(*string)), true
+
+	case "Mutation.createRule":
+		if e.complexity.Mutation.CreateRule == nil {
+			break
+		}
+
+		args, err := ec.field_Mutation_createRule_args(ctx, rawArgs)
+		if err != nil {
+			return 0, false
+		}
+
+		return e.complexity.Mutation.CreateRule(childComplexity, args["input"].(model.RuleInput)), true
+
+	case "Mutation.createTag":
+		if e.complexity.Mutation.CreateTag == nil {
+			break
+		}
+
+		args, err := ec.field_Mutation_createTag_args(ctx, rawArgs)
+		if err != nil {
+			return 0, false
+		}
+
+		return e.complexity.Mutation.CreateTag(childComplexity, args["name"].(string), args["color"].(*string), args["description"].(*string), args["groupId"].(*string)), true
+
+	case "Mutation.declineOwnershipTransfer":
+		if e.complexity.Mutation.DeclineOwnershipTransfer == nil {
+			break
+		}
+
+		args, err := ec.field_Mutation_declineOwnershipTransfer_args(ctx, rawArgs)
+		if err != nil {
+			return 0, false
+		}
+
+		return e.complexity.Mutation.DeclineOwnershipTransfer(childComplexity, args["id"].(string)), true
+
+	case "Mutation.deleteFile":
+		if e.complexity.Mutation.DeleteFile == nil {
+			break
+		}
+
+		args, err := ec.field_Mutation_deleteFile_args(ctx, rawArgs)
+		if err != nil {
+			return 0, false
+		}
+
+		return e.complexity.Mutation.DeleteFile(childComplexity, args["id"].(string)), true
+
+	case "Mutation.deleteFolder":
+		if e.complexity.Mutation.DeleteFolder == nil {
+			break
+		}
+
+		args, err := ec.field_Mutation_deleteFolder_args(ctx, rawArgs)
+		if err != nil {
+			return 0, false
+		}
+
+		return e.complexity.Mutation.DeleteFolder(childComplexity, args["id"].(string)), true
+
+	case "Mutation.deleteGroup":
+		if e.complexity.Mutation.DeleteGroup == nil {
+			break
+		}
+
+		args, err := ec.field_Mutation_deleteGroup_args(ctx, rawArgs)
+		if err != nil {
+			return 0, false
+		}
+
+		return e.complexity.Mutation.DeleteGroup(childComplexity, args["id"].(string)), true
+
+	case "Mutation.deleteMetadataField":
+		if e.complexity.Mutation.DeleteMetadataField == nil {
+			break
+		}
+
+		args, err := ec.field_Mutation_deleteMetadataField_args(ctx, rawArgs)
+		if err != nil {
+			return 0, false
+		}
+
+		return e.complexity.Mutation.DeleteMetadataField(childComplexity, args["id"].(string)), true
+
+	case "Mutation.deleteRule":
+		if e.complexity.Mutation.DeleteRule == nil {
+			break
+		}
+
+		args, err := ec.field_Mutation_deleteRule_args(ctx, rawArgs)
+		if err != nil {
+			return 0, false
+		}
+
+		return e.complexity.Mutation.DeleteRule(childComplexity, args["id"].(string)), true
+
+	case "Mutation.deleteSavedSearch":
+		if e.complexity.Mutation.DeleteSavedSearch == nil {
+			break
+		}
+
+		args, err := ec.field_Mutation_deleteSavedSearch_args(ctx, rawArgs)
+		if err != nil {
+			return 0, false
+		}
+
+		return e.complexity.Mutation.DeleteSavedSearch(childComplexity, args["id"].(string)), true
+
+	case "Mutation.deleteTag":
+		if e.complexity.Mutation.DeleteTag == nil {
+			break
+		}
+
+		args, err := ec.field_Mutation_deleteTag_args(ctx, rawArgs)
+		if err != nil {
+			return 0, false
+		}
+
+		return e.complexity.Mutation.DeleteTag(childComplexity, args["id"].(string)), true
+
+	case "Mutation.denyAccessRequest":
+		if e.complexity.Mutation.DenyAccessRequest == nil {
+			break
+		}
+
+		args, err := ec.field_Mutation_denyAccessRequest_args(ctx, rawArgs)
+		if err != nil {
+			return 0, false
+		}
+
+		return e.complexity.Mutation.DenyAccessRequest(childComplexity, args["id"].(string)), true
+
+	case "Mutation.grantGroupPermission":
+		if e.complexity.Mutation.GrantGroupPermission == nil {
+			break
+		}
+
+		args, err := ec.field_Mutation_grantGroupPermission_args(ctx, rawArgs)
+		if err != nil {
+			return 0, false
+		}
+
+		return e.complexity.Mutation.GrantGroupPermission(childComplexity, args["resourceId"].(string), args["groupId"].(string), args["role"].(model.Role), args["expiresAt"].(*string)), true
+
+	case "Mutation.grantPermission":
+		if e.complexity.Mutation.GrantPermission == nil {
+			break
+		}
+
+		args, err := ec.field_Mutation_grantPermission_args(ctx, rawArgs)
+		if err != nil {
+			return 0, false
+		}
+
+		return e.complexity.Mutation.GrantPermission(childComplexity, args["resourceId"].(string), args["email"].(string), args["role"].(model.Role), args["expiresAt"].(*string)), true
+
+	case "Mutation.login":
+		if e.complexity.Mutation.Login == nil {
+			break
+		}
+
+		args, err := ec.field_Mutation_login_args(ctx, rawArgs)
+		if err != nil {
+			return 0, false
+		}
+
+		return e.complexity.Mutation.Login(childComplexity, args["email"].(string), args["password"].(string)), true
+
+	case "Mutation.makeResourcePublic":
+		if e.complexity.Mutation.MakeResourcePublic == nil {
+			break
+		}
+
+		args, err := ec.field_Mutation_makeResourcePublic_args(ctx, rawArgs)
+		if err != nil {
+			return 0, false
+		}
+
+		return e.complexity.Mutation.MakeResourcePublic(childComplexity, args["resourceId"].(string)), true
+
+	case "Mutation.mergeTags":
+		if e.complexity.Mutation.MergeTags == nil {
+			break
+		}
+
+		args, err := ec.field_Mutation_mergeTags_args(ctx, rawArgs)
+		if err != nil {
+			return 0, false
+		}
+
+		return e.complexity.Mutation.MergeTags(childComplexity, args["sourceIds"].([]string), args["targetId"].(string)), true
+
+	case "Mutation.mkdirP":
+		if e.complexity.Mutation.MkdirP == nil {
+			break
+		}
+
+		args, err := ec.field_Mutation_mkdirP_args(ctx, rawArgs)
+		if err != nil {
+			return 0, false
+		}
+
+		return e.complexity.Mutation.MkdirP(childComplexity, args["path"].(string)), true
+
+	case "Mutation.moveFile":
+		if e.complexity.Mutation.MoveFile == nil {
+			break
+		}
+
+		args, err := ec.field_Mutation_moveFile_args(ctx, rawArgs)
+		if err != nil {
+			return 0, false
+		}
+
+		return e.complexity.Mutation.MoveFile(childComplexity, args["fileId"].(string), args["newParentId"].(*string), args["conflictStrategy"].(*model.ConflictStrategy)), true
+
+	case "Mutation.moveFolder":
+		if e.complexity.Mutation.MoveFolder == nil {
+			break
+		}
+
+		args, err := ec.field_Mutation_moveFolder_args(ctx, rawArgs)
+		if err != nil {
+			return 0, false
+		}
+
+		return e.complexity.Mutation.MoveFolder(childComplexity, args["folderId"].(string), args["newParentId"].(*string), args["conflictStrategy"].(*model.ConflictStrategy)), true
+
+	case "Mutation.register":
+		if e.complexity.Mutation.Register == nil {
+			break
+		}
+
+		args, err := ec.field_Mutation_register_args(ctx, rawArgs)
+		if err != nil {
+			return 0, false
+		}
+
+		return e.complexity.Mutation.Register(childComplexity, args["username"].(string), args["email"].(string), args["password"].(string)), true
+
+	case "Mutation.removeGroupMember":
+		if e.complexity.Mutation.RemoveGroupMember == nil {
+			break
+		}
+
+		args, err := ec.field_Mutation_removeGroupMember_args(ctx, rawArgs)
+		if err != nil {
+			return 0, false
+		}
+
+		return e.complexity.Mutation.RemoveGroupMember(childComplexity, args["groupId"].(string), args["email"].(string)), true
+
+	case "Mutation.removeResourcePublicAccess":
+		if e.complexity.Mutation.RemoveResourcePublicAccess == nil {
+			break
+		}
+
+		args, err := ec.field_Mutation_removeResourcePublicAccess_args(ctx, rawArgs)
+		if err != nil {
+			return 0, false
+		}
+
+		return e.complexity.Mutation.RemoveResourcePublicAccess(childComplexity, args["resourceId"].(string)), true
+
+	case "Mutation.removeTagFromResource":
+		if e.complexity.Mutation.RemoveTagFromResource == nil {
+			break
+		}
+
+		args, err := ec.field_Mutation_removeTagFromResource_args(ctx, rawArgs)
+		if err != nil {
+			return 0, false
+		}
+
+		return e.complexity.Mutation.RemoveTagFromResource(childComplexity, args["resourceID"].(string), args["tagID"].(string)), true
+
+	case "Mutation.renameFile":
+		if e.complexity.Mutation.RenameFile == nil {
+			break
+		}
+
+		args, err := ec.field_Mutation_renameFile_args(ctx, rawArgs)
+		if err != nil {
+			return 0, false
+		}
+
+		return e.complexity.Mutation.RenameFile(childComplexity, args["id"].(string), args["newName"].(string), args["conflictStrategy"].(*model.ConflictStrategy)), true
+
+	case "Mutation.renameFolder":
+		if e.complexity.Mutation.RenameFolder == nil {
+			break
+		}
+
+		args, err := ec.field_Mutation_renameFolder_args(ctx, rawArgs)
+		if err != nil {
+			return 0, false
+		}
+
+		return e.complexity.Mutation.RenameFolder(childComplexity, args["id"].(string), args["newName"].(string), args["conflictStrategy"].(*model.ConflictStrategy)), true
+
+	case "Mutation.renameGroup":
+		if e.complexity.Mutation.RenameGroup == nil {
+			break
+		}
+
+		args, err := ec.field_Mutation_renameGroup_args(ctx, rawArgs)
+		if err != nil {
+			return 0, false
+		}
+
+		return e.complexity.Mutation.RenameGroup(childComplexity, args["id"].(string), args["name"].(string)), true
+
+	case "Mutation.renameTag":
+		if e.complexity.Mutation.RenameTag == nil {
+			break
+		}
+
+		args, err := ec.field_Mutation_renameTag_args(ctx, rawArgs)
+		if err != nil {
+			return 0, false
+		}
+
+		return e.complexity.Mutation.RenameTag(childComplexity, args["id"].(string), args["name"].(string)), true
+
+	case "Mutation.requestAccess":
+		if e.complexity.Mutation.RequestAccess == nil {
+			break
+		}
+
+		args, err := ec.field_Mutation_requestAccess_args(ctx, rawArgs)
+		if err != nil {
+			return 0, false
+		}
+
+		return e.complexity.Mutation.RequestAccess(childComplexity, args["token"].(string), args["role"].(model.Role), args["message"].(*string)), true
+
+	case "Mutation.revokeGroupPermission":
+		if e.complexity.Mutation.RevokeGroupPermission == nil {
+			break
+		}
+
+		args, err := ec.field_Mutation_revokeGroupPermission_args(ctx, rawArgs)
+		if err != nil {
+			return 0, false
+		}
+
+		return e.complexity.Mutation.RevokeGroupPermission(childComplexity, args["resourceId"].(string), args["groupId"].(string)), true
+
+	case "Mutation.revokePermission":
+		if e.complexity.Mutation.RevokePermission == nil {
+			break
+		}
+
+		args, err := ec.field_Mutation_revokePermission_args(ctx, rawArgs)
+		if err != nil {
+			return 0, false
+		}
+
+		return e.complexity.Mutation.RevokePermission(childComplexity, args["resourceId"].(string), args["email"].(string)), true
+
+	case "Mutation.saveSearch":
+		if e.complexity.Mutation.SaveSearch == nil {
+			break
+		}
+
+		args, err := ec.field_Mutation_saveSearch_args(ctx, rawArgs)
+		if err != nil {
+			return 0, false
+		}
+
+		return e.complexity.Mutation.SaveSearch(childComplexity, args["name"].(string), args["filters"].(model.SearchFilters)), true
+
+	case "Mutation.setInheritPermissions":
+		if e.complexity.Mutation.SetInheritPermissions == nil {
+			break
+		}
+
+		args, err := ec.field_Mutation_setInheritPermissions_args(ctx, rawArgs)
+		if err != nil {
+			return 0, false
+		}
+
+		return e.complexity.Mutation.SetInheritPermissions(childComplexity, args["resourceId"].(string), args["inherit"].(bool)), true
+
+	case "Mutation.setMetadata":
+		if e.complexity.Mutation.SetMetadata == nil {
+			break
+		}
+
+		args, err := ec.field_Mutation_setMetadata_args(ctx, rawArgs)
+		if err != nil {
+			return 0, false
+		}
+
+		return e.complexity.Mutation.SetMetadata(childComplexity, args["resourceId"].(string), args["values"].([]*model.MetadataValueInput)), true
+
+	case "Mutation.shareSavedSearch":
+		if e.complexity.Mutation.ShareSavedSearch == nil {
+			break
+		}
+
+		args, err := ec.field_Mutation_shareSavedSearch_args(ctx, rawArgs)
+		if err != nil {
+			return 0, false
+		}
+
+		return e.complexity.Mutation.ShareSavedSearch(childComplexity, args["id"].(string), args["email"].(string)), true
+
+	case "Mutation.starResource":
+		if e.complexity.Mutation.StarResource == nil {
+			break
+		}
+
+		args, err := ec.field_Mutation_starResource_args(ctx, rawArgs)
+		if err != nil {
+			return 0, false
+		}
+
+		return e.complexity.Mutation.StarResource(childComplexity, args["id"].(string)), true
+
+	case "Mutation.transferOwnership":
+		if e.complexity.Mutation.TransferOwnership == nil {
+			break
+		}
+
+		args, err := ec.field_Mutation_transferOwnership_args(ctx, rawArgs)
+		if err != nil {
+			return 0, false
+		}
+
+		return e.complexity.Mutation.TransferOwnership(childComplexity, args["resourceId"].(string), args["newOwnerEmail"].(string), args["keepEditorAccess"].(*bool)), true
+
+	case "Mutation.unshareSavedSearch":
+		if e.complexity.Mutation.UnshareSavedSearch == nil {
+			break
+		}
+
+		args, err := ec.field_Mutation_unshareSavedSearch_args(ctx, rawArgs)
+		if err != nil {
+			return 0, false
+		}
+
+		return e.complexity.Mutation.UnshareSavedSearch(childComplexity, args["id"].(string), args["email"].(string)), true
+
+	case "Mutation.unstarResource":
+		if e.complexity.Mutation.UnstarResource == nil {
+			break
+		}
+
+		args, err := ec.field_Mutation_unstarResource_args(ctx, rawArgs)
+		if err != nil {
+			return 0, false
+		}
+
+		return e.complexity.Mutation.UnstarResource(childComplexity, args["id"].(string)), true
+
+	case "Mutation.updateRule":
+		if e.complexity.Mutation.UpdateRule == nil {
+			break
+		}
+
+		args, err := ec.field_Mutation_updateRule_args(ctx, rawArgs)
+		if err != nil {
+			return 0, false
+		}
+
+		return e.complexity.Mutation.UpdateRule(childComplexity, args["id"].(string), args["input"].(model.RuleInput)), true
+
+	case "Mutation.updateTag":
+		if e.complexity.Mutation.UpdateTag == nil {
+			break
+		}
+
+		args, err := ec.field_Mutation_updateTag_args(ctx, rawArgs)
+		if err != nil {
+			return 0, false
+		}
+
+		return e.complexity.Mutation.UpdateTag(childComplexity, args["id"].(string), args["color"].(*string), args["description"].(*string)), true
+
+	case "Mutation.uploadFile":
+		if e.complexity.Mutation.UploadFile == nil {
+			break
+		}
+
+		args, err := ec.field_Mutation_uploadFile_args(ctx, rawArgs)
+		if err != nil {
+			return 0, false
+		}
+
+		return e.complexity.Mutation.UploadFile(childComplexity, args["file"].(graphql.Upload), args["parentId"].(*string), args["path"].(*string), args["conflictStrategy"].(*model.ConflictStrategy)), true
+
+	case "Mutation.verifyHierarchy":
+		if e.complexity.Mutation.VerifyHierarchy == nil {
+			break
+		}
+
+		args, err := ec.field_Mutation_verifyHierarchy_args(ctx, rawArgs)
+		if err != nil {
+			return 0, false
+		}
+
+		return e.complexity.Mutation.VerifyHierarchy(childComplexity, args["repair"].(*bool)), true
+
+	case "OwnershipTransfer.createdAt":
+		if e.complexity.OwnershipTransfer.CreatedAt == nil {
+			break
+		}
+
+		return e.complexity.OwnershipTransfer.CreatedAt(childComplexity), true
+
+	case "OwnershipTransfer.decidedAt":
+		if e.complexity.OwnershipTransfer.DecidedAt == nil {
+			break
+		}
+
+		return e.complexity.OwnershipTransfer.DecidedAt(childComplexity), true
+
+	case "OwnershipTransfer.fromUser":
+		if e.complexity.OwnershipTransfer.FromUser == nil {
+			break
+		}
+
+		return e.complexity.OwnershipTransfer.FromUser(childComplexity), true
+
+	case "OwnershipTransfer.id":
+		if e.complexity.OwnershipTransfer.ID == nil {
+			break
+		}
+
+		return e.complexity.OwnershipTransfer.ID(childComplexity), true
+
+	case "OwnershipTransfer.keepEditorAccess":
+		if e.complexity.OwnershipTransfer.KeepEditorAccess == nil {
+			break
+		}
+
+		return e.complexity.OwnershipTransfer.KeepEditorAccess(childComplexity), true
+
+	case "OwnershipTransfer.resource":
+		if e.complexity.OwnershipTransfer.Resource == nil {
+			break
+		}
+
+		return e.complexity.OwnershipTransfer.Resource(childComplexity), true
+
+	case "OwnershipTransfer.status":
+		if e.complexity.OwnershipTransfer.Status == nil {
+			break
+		}
+
+		return e.complexity.OwnershipTransfer.Status(childComplexity), true
+
+	case "OwnershipTransfer.toUser":
+		if e.complexity.OwnershipTransfer.ToUser == nil {
+			break
+		}
+
+		return e.complexity.OwnershipTransfer.ToUser(childComplexity), true
+
+	case "PageInfo.endCursor":
+		if e.complexity.PageInfo.EndCursor == nil {
+			break
+		}
+
+		return e.complexity.PageInfo.EndCursor(childComplexity), true
+
+	case "PageInfo.hasNextPage":
+		if e.complexity.PageInfo.HasNextPage == nil {
+			break
+		}
+
+		return e.complexity.PageInfo.HasNextPage(childComplexity), true
+
+	case "PageInfo.hasPreviousPage":
+		if e.complexity.PageInfo.HasPreviousPage == nil {
+			break
+		}
+
+		return e.complexity.PageInfo.HasPreviousPage(childComplexity), true
+
+	case "PageInfo.startCursor":
+		if e.complexity.PageInfo.StartCursor == nil {
+			break
+		}
+
+		return e.complexity.PageInfo.StartCursor(childComplexity), true
+
+	case "Permission.expiresAt":
+		if e.complexity.Permission.ExpiresAt == nil {
+			break
+		}
+
+		return e.complexity.Permission.ExpiresAt(childComplexity), true
+
+	case "Permission.group":
+		if e.complexity.Permission.Group == nil {
+			break
+		}
+
+		return e.complexity.Permission.Group(childComplexity), true
+
+	case "Permission.principalType":
+		if e.complexity.Permission.PrincipalType == nil {
+			break
+		}
+
+		return e.complexity.Permission.PrincipalType(childComplexity), true
+
+	case "Permission.role":
+		if e.complexity.Permission.Role == nil {
+			break
+		}
+
+		return e.complexity.Permission.Role(childComplexity), true
+
+	case "Permission.user":
+		if e.complexity.Permission.User == nil {
+			break
+		}
+
+		return e.complexity.Permission.User(childComplexity), true
+
+	case "Query.adminResources":
+		if e.complexity.Query.AdminResources == nil {
+			break
+		}
+
+		args, err := ec.field_Query_adminResources_args(ctx, rawArgs)
+		if err != nil {
+			return 0, false
+		}
+
+		return e.complexity.Query.AdminResources(childComplexity, args["ownerId"].(*string), args["first"].(*int), args["after"].(*string), args["sort"].(*model.ResourceSort)), true
+
+	case "Query.allResources":
+		if e.complexity.Query.AllResources == nil {
+			break
+		}
+
+		return e.complexity.Query.AllResources(childComplexity), true
+
+	case "Query.dedupSummary":
+		if e.complexity.Query.DedupSummary == nil {
+			break
+		}
+
+		return e.complexity.Query.DedupSummary(childComplexity), true
+
+	case "Query.dryRunRules":
+		if e.complexity.Query.DryRunRules == nil {
+			break
+		}
+
+		args, err := ec.field_Query_dryRunRules_args(ctx, rawArgs)
+		if err != nil {
+			return 0, false
+		}
+
+		return e.complexity.Query.DryRunRules(childComplexity, args["ruleId"].(*string), args["limit"].(*int)), true
+
+	case "Query.duplicates":
+		if e.complexity.Query.Duplicates == nil {
+			break
+		}
+
+		args, err := ec.field_Query_duplicates_args(ctx, rawArgs)
+		if err != nil {
+			return 0, false
+		}
+
+		return e.complexity.Query.Duplicates(childComplexity, args["limit"].(*int)), true
+
+	case "Query.effectivePermissions":
+		if e.complexity.Query.EffectivePermissions == nil {
+			break
+		}
+
+		args, err := ec.field_Query_effectivePermissions_args(ctx, rawArgs)
+		if err != nil {
+			return 0, false
+		}
+
+		return e.complexity.Query.EffectivePermissions(childComplexity, args["resourceId"].(string)), true
+
+	case "Query.file":
+		if e.complexity.Query.File == nil {
+			break
+		}
+
+		args, err := ec.field_Query_file_args(ctx, rawArgs)
+		if err != nil {
+			return 0, false
+		}
+
+		return e.complexity.Query.File(childComplexity, args["id"].(string)), true
+
+	case "Query.folder":
+		if e.complexity.Query.Folder == nil {
+			break
+		}
+
+		args, err := ec.field_Query_folder_args(ctx, rawArgs)
+		if err != nil {
+			return 0, false
+		}
+
+		return e.complexity.Query.Folder(childComplexity, args["id"].(string)), true
+
+	case "Query.group":
+		if e.complexity.Query.Group == nil {
+			break
+		}
+
+		args, err := ec.field_Query_group_args(ctx, rawArgs)
+		if err != nil {
+			return 0, false
+		}
+
+		return e.complexity.Query.Group(childComplexity, args["id"].(string)), true
+
+	case "Query.me":
+		if e.complexity.Query.Me == nil {
+			break
+		}
+
+		return e.complexity.Query.Me(childComplexity), true
+
+	case "Query.metadataFields":
+		if e.complexity.Query.MetadataFields == nil {
+			break
+		}
+
+		return e.complexity.Query.MetadataFields(childComplexity), true
+
+	case "Query.myGroups":
+		if e.complexity.Query.MyGroups == nil {
+			break
+		}
+
+		return e.complexity.Query.MyGroups(childComplexity), true
+
+	case "Query.myTags":
+		if e.complexity.Query.MyTags == nil {
+			break
+		}
+
+		return e.complexity.Query.MyTags(childComplexity), true
+
+	case "Query.pendingAccessRequests":
+		if e.complexity.Query.PendingAccessRequests == nil {
+			break
+		}
+
+		args, err := ec.field_Query_pendingAccessRequests_args(ctx, rawArgs)
+		if err != nil {
+			return 0, false
+		}
+
+		return e.complexity.Query.PendingAccessRequests(childComplexity, args["resourceId"].(*string)), true
+
+	case "Query.pendingOwnershipTransfers":
+		if e.complexity.Query.PendingOwnershipTransfers == nil {
+			break
+		}
+
+		return e.complexity.Query.PendingOwnershipTransfers(childComplexity), true
+
+	case "Query.recent":
+		if e.complexity.Query.Recent == nil {
+			break
+		}
+
+		args, err := ec.field_Query_recent_args(ctx, rawArgs)
+		if err != nil {
+			return 0, false
+		}
+
+		return e.complexity.Query.Recent(childComplexity, args["limit"].(*int)), true
+
+	case "Query.resolveShareLink":
+		if e.complexity.Query.ResolveShareLink == nil {
+			break
+		}
+
+		args, err := ec.field_Query_resolveShareLink_args(ctx, rawArgs)
+		if err != nil {
+			return 0, false
+		}
+
+		return e.complexity.Query.ResolveShareLink(childComplexity, args["token"].(string), args["expectedType"].(string)), true
+
+	case "Query.resourceByPath":
+		if e.complexity.Query.ResourceByPath == nil {
+			break
+		}
+
+		args, err := ec.field_Query_resourceByPath_args(ctx, rawArgs)
+		if err != nil {
+			return 0, false
+		}
+
+		return e.complexity.Query.ResourceByPath(childComplexity, args["path"].(string), args["shareToken"].(*string)), true
+
+	case "Query.resources":
+		if e.complexity.Query.Resources == nil {
+			break
+		}
+
+		args, err := ec.field_Query_resources_args(ctx, rawArgs)
+		if err != nil {
+			return 0, false
+		}
+
+		return e.complexity.Query.Resources(childComplexity, args["folderId"].(*string), args["first"].(*int), args["after"].(*string), args["sort"].(*model.ResourceSort)), true
+
+	case "Query.rules":
+		if e.complexity.Query.Rules == nil {
+			break
+		}
+
+		return e.complexity.Query.Rules(childComplexity), true
+
+	case "Query.savedSearch":
+		if e.complexity.Query.SavedSearch == nil {
+			break
+		}
+
+		args, err := ec.field_Query_savedSearch_args(ctx, rawArgs)
+		if err != nil {
+			return 0, false
+		}
+
+		return e.complexity.Query.SavedSearch(childComplexity, args["id"].(string)), true
+
+	case "Query.savedSearches":
+		if e.complexity.Query.SavedSearches == nil {
+			break
+		}
+
+		return e.complexity.Query.SavedSearches(childComplexity), true
+
+	case "Query.searchFacets":
+		if e.complexity.Query.SearchFacets == nil {
+			break
+		}
+
+		args, err := ec.field_Query_searchFacets_args(ctx, rawArgs)
+		if err != nil {
+			return 0, false
+		}
+
+		return e.complexity.Query.SearchFacets(childComplexity, args["filters"].(*model.SearchFilters), args["savedSearchId"].(*string)), true
+
+	case "Query.searchResources":
+		if e.complexity.Query.SearchResources == nil {
+			break
+		}
+
+		args, err := ec.field_Query_searchResources_args(ctx, rawArgs)
+		if err != nil {
+			return 0, false
+		}
+
+		return e.complexity.Query.SearchResources(childComplexity, args["filters"].(*model.SearchFilters), args["savedSearchId"].(*string), args["first"].(*int), args["after"].(*string), args["sort"].(*model.ResourceSort)), true
+
+	case "Query.starred":
+		if e.complexity.Query.Starred == nil {
+			break
+		}
+
+		args, err := ec.field_Query_starred_args(ctx, rawArgs)
+		if err != nil {
+			return 0, false
+		}
+
+		return e.complexity.Query.Starred(childComplexity, args["first"].(*int), args["after"].(*string), args["sort"].(*model.ResourceSort)), true
+
+	case "Query.suggestResources":
+		if e.complexity.Query.SuggestResources == nil {
+			break
+		}
+
+		args, err := ec.field_Query_suggestResources_args(ctx, rawArgs)
+		if err != nil {
+			return 0, false
+		}
+
+		return e.complexity.Query.SuggestResources(childComplexity, args["prefix"].(string), args["limit"].(*int)), true
+
+	case "RecentItem.action":
+		if e.complexity.RecentItem.Action == nil {
+			break
+		}
+
+		return e.complexity.RecentItem.Action(childComplexity), true
+
+	case "RecentItem.occurredAt":
+		if e.complexity.RecentItem.OccurredAt == nil {
+			break
+		}
+
+		return e.complexity.RecentItem.OccurredAt(childComplexity), true
+
+	case "RecentItem.resource":
+		if e.complexity.RecentItem.Resource == nil {
+			break
+		}
+
+		return e.complexity.RecentItem.Resource(childComplexity), true
+
+	case "ResourceConnection.edges":
+		if e.complexity.ResourceConnection.Edges == nil {
+			break
+		}
+
+		return e.complexity.ResourceConnection.Edges(childComplexity), true
+
+	case "ResourceConnection.pageInfo":
+		if e.complexity.ResourceConnection.PageInfo == nil {
+			break
+		}
+
+		return e.complexity.ResourceConnection.PageInfo(childComplexity), true
+
+	case "ResourceConnection.totalCount":
+		if e.complexity.ResourceConnection.TotalCount == nil {
+			break
+		}
+
+		return e.complexity.ResourceConnection.TotalCount(childComplexity), true
+
+	case "ResourceEdge.cursor":
+		if e.complexity.ResourceEdge.Cursor == nil {
+			break
+		}
+
+		return e.complexity.ResourceEdge.Cursor(childComplexity), true
+
+	case "ResourceEdge.node":
+		if e.complexity.ResourceEdge.Node == nil {
+			break
+		}
+
+		return e.complexity.ResourceEdge.Node(childComplexity), true
+
+	case "ResourceEdge.snippet":
+		if e.complexity.ResourceEdge.Snippet == nil {
+			break
+		}
+
+		return e.complexity.ResourceEdge.Snippet(childComplexity), true
+
+	case "Rule.actions":
+		if e.complexity.Rule.Actions == nil {
+			break
+		}
+
+		return e.complexity.Rule.Actions(childComplexity), true
+
+	case "Rule.conditions":
+		if e.complexity.Rule.Conditions == nil {
+			break
+		}
+
+		return e.complexity.Rule.Conditions(childComplexity), true
+
+	case "Rule.createdAt":
+		if e.complexity.Rule.CreatedAt == nil {
+			break
+		}
+
+		return e.complexity.Rule.CreatedAt(childComplexity), true
+
+	case "Rule.enabled":
+		if e.complexity.Rule.Enabled == nil {
+			break
+		}
+
+		return e.complexity.Rule.Enabled(childComplexity), true
+
+	case "Rule.id":
+		if e.complexity.Rule.ID == nil {
+			break
+		}
+
+		return e.complexity.Rule.ID(childComplexity), true
+
+	case "Rule.name":
+		if e.complexity.Rule.Name == nil {
+			break
+		}
+
+		return e.complexity.Rule.Name(childComplexity), true
+
+	case "RuleActions.addTags":
+		if e.complexity.RuleActions.AddTags == nil {
+			break
+		}
+
+		return e.complexity.RuleActions.AddTags(childComplexity), true
+
+	case "RuleActions.makePublic":
+		if e.complexity.RuleActions.MakePublic == nil {
+			break
+		}
+
+		return e.complexity.RuleActions.MakePublic(childComplexity), true
+
+	case "RuleActions.moveToFolderId":
+		if e.complexity.RuleActions.MoveToFolderID == nil {
+			break
+		}
+
+		return e.complexity.RuleActions.MoveToFolderID(childComplexity), true
+
+	case "RuleApplication.failureCount":
+		if e.complexity.RuleApplication.FailureCount == nil {
+			break
+		}
+
+		return e.complexity.RuleApplication.FailureCount(childComplexity), true
+
+	case "RuleApplication.failures":
+		if e.complexity.RuleApplication.Failures == nil {
+			break
+		}
+
+		return e.complexity.RuleApplication.Failures(childComplexity), true
+
+	case "RuleApplication.matchedCount":
+		if e.complexity.RuleApplication.MatchedCount == nil {
+			break
+		}
+
+		return e.complexity.RuleApplication.MatchedCount(childComplexity), true
+
+	case "RuleApplication.scannedCount":
+		if e.complexity.RuleApplication.ScannedCount == nil {
+			break
+		}
+
+		return e.complexity.RuleApplication.ScannedCount(childComplexity), true
+
+	case "RuleConditions.contains":
+		if e.complexity.RuleConditions.Contains == nil {
+			break
+		}
+
+		return e.complexity.RuleConditions.Contains(childComplexity), true
+
+	case "RuleConditions.folderId":
+		if e.complexity.RuleConditions.FolderID == nil {
+			break
+		}
+
+		return e.complexity.RuleConditions.FolderID(childComplexity), true
+
+	case "RuleConditions.includeSubfolders":
+		if e.complexity.RuleConditions.IncludeSubfolders == nil {
+			break
+		}
+
+		return e.complexity.RuleConditions.IncludeSubfolders(childComplexity), true
+
+	case "RuleConditions.maxSizeBytes":
+		if e.complexity.RuleConditions.MaxSizeBytes == nil {
+			break
+		}
+
+		return e.complexity.RuleConditions.MaxSizeBytes(childComplexity), true
+
+	case "RuleConditions.mimeTypes":
+		if e.complexity.RuleConditions.MimeTypes == nil {
+			break
+		}
+
+		return e.complexity.RuleConditions.MimeTypes(childComplexity), true
+
+	case "RuleConditions.minSizeBytes":
+		if e.complexity.RuleConditions.MinSizeBytes == nil {
+			break
+		}
+
+		return e.complexity.RuleConditions.MinSizeBytes(childComplexity), true
+
+	case "RuleConditions.nameGlob":
+		if e.complexity.RuleConditions.NameGlob == nil {
+			break
+		}
+
+		return e.complexity.RuleConditions.NameGlob(childComplexity), true
+
+	case "RuleConditions.nameRegex":
+		if e.complexity.RuleConditions.NameRegex == nil {
+			break
+		}
+
+		return e.complexity.RuleConditions.NameRegex(childComplexity), true
+
+	case "RuleMatch.resource":
+		if e.complexity.RuleMatch.Resource == nil {
+			break
+		}
+
+		return e.complexity.RuleMatch.Resource(childComplexity), true
+
+	case "RuleMatch.rule":
+		if e.complexity.RuleMatch.Rule == nil {
+			break
+		}
+
+		return e.complexity.RuleMatch.Rule(childComplexity), true
+
+	case "SavedMetadataFilter.fieldId":
+		if e.complexity.SavedMetadataFilter.FieldID == nil {
+			break
+		}
+
+		return e.complexity.SavedMetadataFilter.FieldID(childComplexity), true
+
+	case "SavedMetadataFilter.op":
+		if e.complexity.SavedMetadataFilter.Op == nil {
+			break
+		}
+
+		return e.complexity.SavedMetadataFilter.Op(childComplexity), true
+
+	case "SavedMetadataFilter.value":
+		if e.complexity.SavedMetadataFilter.Value == nil {
+			break
+		}
+
+		return e.complexity.SavedMetadataFilter.Value(childComplexity), true
+
+	case "SavedSearch.createdAt":
+		if e.complexity.SavedSearch.CreatedAt == nil {
+			break
 		}
 
-		return e.complexity.Mutation.Register(childComplexity, args["username"].(string), args["email"].(string), args["password"].(string)), true
+		return e.complexity.SavedSearch.CreatedAt(childComplexity), true
 
-	case "Mutation.removeResourcePublicAccess":
-		if e.complexity.Mutation.RemoveResourcePublicAccess == nil {
+	case "SavedSearch.filters":
+		if e.complexity.SavedSearch.Filters == nil {
 			break
 		}
 
-		args, err := ec.field_Mutation_removeResourcePublicAccess_args(ctx, rawArgs)
-		if err != nil {
-			return 0, false
+		return e.complexity.SavedSearch.Filters(childComplexity), true
+
+	case "SavedSearch.id":
+		if e.complexity.SavedSearch.ID == nil {
+			break
 		}
 
-		return e.complexity.Mutation.RemoveResourcePublicAccess(childComplexity, args["resourceId"].(string)), true
+		return e.complexity.SavedSearch.ID(childComplexity), true
 
-	case "Mutation.removeTagFromResource":
-		if e.complexity.Mutation.RemoveTagFromResource == nil {
+	case "SavedSearch.name":
+		if e.complexity.SavedSearch.Name == nil {
 			break
 		}
 
-		args, err := ec.field_Mutation_removeTagFromResource_args(ctx, rawArgs)
-		if err != nil {
-			return 0, false
+		return e.complexity.SavedSearch.Name(childComplexity), true
+
+	case "SavedSearch.owner":
+		if e.complexity.SavedSearch.Owner == nil {
+			break
 		}
 
-		return e.complexity.Mutation.RemoveTagFromResource(childComplexity, args["resourceID"].(string), args["tagID"].(string)), true
+		return e.complexity.SavedSearch.Owner(childComplexity), true
 
-	case "Mutation.renameFile":
-		if e.complexity.Mutation.RenameFile == nil {
+	case "SavedSearch.results":
+		if e.complexity.SavedSearch.Results == nil {
 			break
 		}
 
-		args, err := ec.field_Mutation_renameFile_args(ctx, rawArgs)
+		args, err := ec.field_SavedSearch_results_args(ctx, rawArgs)
 		if err != nil {
 			return 0, false
 		}
 
-		return e.complexity.Mutation.RenameFile(childComplexity, args["id"].(string), args["newName"].(string)), true
+		return e.complexity.SavedSearch.Results(childComplexity, args["first"].(*int), args["after"].(*string), args["sort"].(*model.ResourceSort)), true
 
-	case "Mutation.renameFolder":
-		if e.complexity.Mutation.RenameFolder == nil {
+	case "SavedSearch.sharedWith":
+		if e.complexity.SavedSearch.SharedWith == nil {
 			break
 		}
 
-		args, err := ec.field_Mutation_renameFolder_args(ctx, rawArgs)
-		if err != nil {
-			return 0, false
+		return e.complexity.SavedSearch.SharedWith(childComplexity), true
+
+	case "SavedSearchFilters.afterDate":
+		if e.complexity.SavedSearchFilters.AfterDate == nil {
+			break
 		}
 
-		return e.complexity.Mutation.RenameFolder(childComplexity, args["id"].(string), args["newName"].(string)), true
+		return e.complexity.SavedSearchFilters.AfterDate(childComplexity), true
 
-	case "Mutation.revokePermission":
-		if e.complexity.Mutation.RevokePermission == nil {
+	case "SavedSearchFilters.beforeDate":
+		if e.complexity.SavedSearchFilters.BeforeDate == nil {
 			break
 		}
 
-		args, err := ec.field_Mutation_revokePermission_args(ctx, rawArgs)
-		if err != nil {
-			return 0, false
+		return e.complexity.SavedSearchFilters.BeforeDate(childComplexity), true
+
+	case "SavedSearchFilters.folderId":
+		if e.complexity.SavedSearchFilters.FolderID == nil {
+			break
 		}
 
-		return e.complexity.Mutation.RevokePermission(childComplexity, args["resourceId"].(string), args["email"].(string)), true
+		return e.complexity.SavedSearchFilters.FolderID(childComplexity), true
 
-	case "Mutation.uploadFile":
-		if e.complexity.Mutation.UploadFile == nil {
+	case "SavedSearchFilters.maxSizeBytes":
+		if e.complexity.SavedSearchFilters.MaxSizeBytes == nil {
 			break
 		}
 
-		args, err := ec.field_Mutation_uploadFile_args(ctx, rawArgs)
-		if err != nil {
-			return 0, false
+		return e.complexity.SavedSearchFilters.MaxSizeBytes(childComplexity), true
+
+	case "SavedSearchFilters.metadata":
+		if e.complexity.SavedSearchFilters.Metadata == nil {
+			break
 		}
 
-		return e.complexity.Mutation.UploadFile(childComplexity, args["file"].(graphql.Upload), args["parentId"].(*string)), true
+		return e.complexity.SavedSearchFilters.Metadata(childComplexity), true
 
-	case "Permission.role":
-		if e.complexity.Permission.Role == nil {
+	case "SavedSearchFilters.mimeTypes":
+		if e.complexity.SavedSearchFilters.MimeTypes == nil {
 			break
 		}
 
-		return e.complexity.Permission.Role(childComplexity), true
+		return e.complexity.SavedSearchFilters.MimeTypes(childComplexity), true
 
-	case "Permission.user":
-		if e.complexity.Permission.User == nil {
+	case "SavedSearchFilters.minSizeBytes":
+		if e.complexity.SavedSearchFilters.MinSizeBytes == nil {
 			break
 		}
 
-		return e.complexity.Permission.User(childComplexity), true
+		return e.complexity.SavedSearchFilters.MinSizeBytes(childComplexity), true
 
-	case "Query.allResources":
-		if e.complexity.Query.AllResources == nil {
+	case "SavedSearchFilters.name":
+		if e.complexity.SavedSearchFilters.Name == nil {
 			break
 		}
 
-		return e.complexity.Query.AllResources(childComplexity), true
+		return e.complexity.SavedSearchFilters.Name(childComplexity), true
 
-	case "Query.file":
-		if e.complexity.Query.File == nil {
+	case "SavedSearchFilters.nameSimilarity":
+		if e.complexity.SavedSearchFilters.NameSimilarity == nil {
 			break
 		}
 
-		args, err := ec.field_Query_file_args(ctx, rawArgs)
-		if err != nil {
-			return 0, false
+		return e.complexity.SavedSearchFilters.NameSimilarity(childComplexity), true
+
+	case "SavedSearchFilters.query":
+		if e.complexity.SavedSearchFilters.Query == nil {
+			break
 		}
 
-		return e.complexity.Query.File(childComplexity, args["id"].(string)), true
+		return e.complexity.SavedSearchFilters.Query(childComplexity), true
 
-	case "Query.folder":
-		if e.complexity.Query.Folder == nil {
+	case "SavedSearchFilters.tags":
+		if e.complexity.SavedSearchFilters.Tags == nil {
 			break
 		}
 
-		args, err := ec.field_Query_folder_args(ctx, rawArgs)
-		if err != nil {
-			return 0, false
+		return e.complexity.SavedSearchFilters.Tags(childComplexity), true
+
+	case "SavedSearchFilters.types":
+		if e.complexity.SavedSearchFilters.Types == nil {
+			break
 		}
 
-		return e.complexity.Query.Folder(childComplexity, args["id"].(string)), true
+		return e.complexity.SavedSearchFilters.Types(childComplexity), true
 
-	case "Query.me":
-		if e.complexity.Query.Me == nil {
+	case "SavedSearchFilters.uploaderName":
+		if e.complexity.SavedSearchFilters.UploaderName == nil {
 			break
 		}
 
-		return e.complexity.Query.Me(childComplexity), true
+		return e.complexity.SavedSearchFilters.UploaderName(childComplexity), true
 
-	case "Query.resolveShareLink":
-		if e.complexity.Query.ResolveShareLink == nil {
+	case "SearchFacets.mimeTypes":
+		if e.complexity.SearchFacets.MimeTypes == nil {
 			break
 		}
 
-		args, err := ec.field_Query_resolveShareLink_args(ctx, rawArgs)
-		if err != nil {
-			return 0, false
+		return e.complexity.SearchFacets.MimeTypes(childComplexity), true
+
+	case "SearchFacets.months":
+		if e.complexity.SearchFacets.Months == nil {
+			break
 		}
 
-		return e.complexity.Query.ResolveShareLink(childComplexity, args["token"].(string), args["expectedType"].(string)), true
+		return e.complexity.SearchFacets.Months(childComplexity), true
 
-	case "Query.resources":
-		if e.complexity.Query.Resources == nil {
+	case "SearchFacets.owners":
+		if e.complexity.SearchFacets.Owners == nil {
 			break
 		}
 
-		args, err := ec.field_Query_resources_args(ctx, rawArgs)
-		if err != nil {
-			return 0, false
+		return e.complexity.SearchFacets.Owners(childComplexity), true
+
+	case "SearchFacets.sizes":
+		if e.complexity.SearchFacets.Sizes == nil {
+			break
 		}
 
-		return e.complexity.Query.Resources(childComplexity, args["folderId"].(*string)), true
+		return e.complexity.SearchFacets.Sizes(childComplexity), true
 
-	case "Query.searchResources":
-		if e.complexity.Query.SearchResources == nil {
+	case "SearchFacets.tags":
+		if e.complexity.SearchFacets.Tags == nil {
 			break
 		}
 
-		args, err := ec.field_Query_searchResources_args(ctx, rawArgs)
-		if err != nil {
-			return 0, false
+		return e.complexity.SearchFacets.Tags(childComplexity), true
+
+	case "SearchFacets.totalCount":
+		if e.complexity.SearchFacets.TotalCount == nil {
+			break
 		}
 
-		return e.complexity.Query.SearchResources(childComplexity, args["filters"].(model.SearchFilters), args["offset"].(*int), args["limit"].(*int)), true
+		return e.complexity.SearchFacets.TotalCount(childComplexity), true
 
 	case "StorageStats.deduplicatedSizeBytes":
 		if e.complexity.StorageStats.DeduplicatedSizeBytes == nil {
@@ -651,6 +2729,13 @@ func (e *executableSchema) Complexity(ctx context.Context, typeName, field strin
 
 		return e.complexity.StorageStats.OriginalSizeBytes(childComplexity), true
 
+	case "StorageStats.referenceCount":
+		if e.complexity.StorageStats.ReferenceCount == nil {
+			break
+		}
+
+		return e.complexity.StorageStats.ReferenceCount(childComplexity), true
+
 	case "StorageStats.savedBytes":
 		if e.complexity.StorageStats.SavedBytes == nil {
 			break
@@ -665,6 +2750,13 @@ func (e *executableSchema) Complexity(ctx context.Context, typeName, field strin
 
 		return e.complexity.StorageStats.SavedPercentage(childComplexity), true
 
+	case "Tag.color":
+		if e.complexity.Tag.Color == nil {
+			break
+		}
+
+		return e.complexity.Tag.Color(childComplexity), true
+
 	case "Tag.createdAt":
 		if e.complexity.Tag.CreatedAt == nil {
 			break
@@ -672,6 +2764,20 @@ func (e *executableSchema) Complexity(ctx context.Context, typeName, field strin
 
 		return e.complexity.Tag.CreatedAt(childComplexity), true
 
+	case "Tag.description":
+		if e.complexity.Tag.Description == nil {
+			break
+		}
+
+		return e.complexity.Tag.Description(childComplexity), true
+
+	case "Tag.groupId":
+		if e.complexity.Tag.GroupID == nil {
+			break
+		}
+
+		return e.complexity.Tag.GroupID(childComplexity), true
+
 	case "Tag.id":
 		if e.complexity.Tag.ID == nil {
 			break
@@ -693,6 +2799,20 @@ func (e *executableSchema) Complexity(ctx context.Context, typeName, field strin
 
 		return e.complexity.Tag.UpdatedAt(childComplexity), true
 
+	case "TagUsage.tag":
+		if e.complexity.TagUsage.Tag == nil {
+			break
+		}
+
+		return e.complexity.TagUsage.Tag(childComplexity), true
+
+	case "TagUsage.usageCount":
+		if e.complexity.TagUsage.UsageCount == nil {
+			break
+		}
+
+		return e.complexity.TagUsage.UsageCount(childComplexity), true
+
 	case "User.DeduplicationStorageUsed":
 		if e.complexity.User.DeduplicationStorageUsed == nil {
 			break
@@ -764,6 +2884,12 @@ func (e *executableSchema) Exec(ctx context.Context) graphql.ResponseHandler {
 	opCtx := graphql.GetOperationContext(ctx)
 	ec := executionContext{opCtx, e, 0, 0, make(chan graphql.DeferredResult)}
 	inputUnmarshalMap := graphql.BuildUnmarshalerMap(
+		ec.unmarshalInputMetadataFilter,
+		ec.unmarshalInputMetadataValueInput,
+		ec.unmarshalInputResourceSort,
+		ec.unmarshalInputRuleActionsInput,
+		ec.unmarshalInputRuleConditionsInput,
+		ec.unmarshalInputRuleInput,
 		ec.unmarshalInputSearchFilters,
 	)
 	first := true
@@ -887,33 +3013,147 @@ type StorageStats {
   deduplicatedSizeBytes: Int! # The actual space this file takes on disk
   savedBytes: Int!
   savedPercentage: Float!
+  # How many files, of any owner, share the stored content.
+  referenceCount: Int!
 }
 
 # Defines the roles a user can have on a resource.
 enum Role {
   VIEWER
   EDITOR
+  # Explicitly blocks access inherited from an ancestor's grants.
+  DENY
+}
+
+# Decides what happens when a sibling already uses the requested name.
+enum ConflictStrategy {
+  # Reject the operation.
+  FAIL
+  # Pick the first free name of the form "report (1).pdf".
+  RENAME
+  # Delete the existing sibling, which must be of the same type.
+  REPLACE
+}
+
+# Identifies who a grant applies to.
+enum PrincipalType {
+  USER
+  GROUP
 }
 
-# Shows a user's specific permission level on a resource.
+# Shows the permission level a user or a group has on a resource.
+# Exactly one of user or group is set, depending on principalType.
 type Permission {
-  user: User!
+  principalType: PrincipalType!
+  user: User
+  group: Group
   role: Role!
+  # When the grant lapses (RFC 3339), or null for permanent grants.
+  expiresAt: String
+}
+
+# A named set of users that can be granted access to resources together.
+type Group {
+  id: ID!
+  name: String!
+  owner: User!
+  members: [GroupMember!]!
+  createdAt: String!
+  updatedAt: String!
+}
+
+# A user's membership in a group. Admins can manage the group's members.
+type GroupMember {
+  user: User!
+  isAdmin: Boolean!
+  addedAt: String!
 }
 
+# A label from the caller's own vocabulary, or from a group's shared by its members.
+# Resources only ever show the tags the caller can see.
 type Tag {
   id: ID!
   name: String!
+  # Hex color such as "#1E90FF", or empty.
+  color: String!
+  description: String!
+  # The group whose members share this tag, or null for the caller's own tags.
+  groupId: ID
   createdAt: String!
   updatedAt: String!
 }
 
+# A tag together with the number of live resources carrying it.
+type TagUsage {
+  tag: Tag!
+  usageCount: Int!
+}
+
+# The type of a custom metadata field's values.
+enum MetadataFieldType {
+  STRING
+  NUMBER
+  # A calendar date, written YYYY-MM-DD.
+  DATE
+  # One of the field's options.
+  ENUM
+}
+
+# A field of a custom metadata schema. Like tags, each field belongs to the caller or to
+# one of their groups.
+type MetadataField {
+  id: ID!
+  name: String!
+  type: MetadataFieldType!
+  # Once a resource has a value from the schema, required fields must be filled in too.
+  required: Boolean!
+  # The values an ENUM field allows.
+  options: [String!]!
+  # The group whose members share this field, or null for the caller's own fields.
+  groupId: ID
+  createdAt: String!
+}
+
+# The value of a metadata field on a resource. Numbers and dates are given as text,
+# e.g. "12.5" and "2025-03-31".
+type MetadataValue {
+  field: MetadataField!
+  value: String!
+}
+
+input MetadataValueInput {
+  fieldId: ID!
+  # Null clears the value.
+  value: String
+}
+
+# Compares a metadata field in a search. CONTAINS applies to STRING fields; LT, LTE, GT
+# and GTE to NUMBER and DATE fields. EXISTS matches any value and takes none.
+enum MetadataOperator {
+  EQ
+  NE
+  CONTAINS
+  LT
+  LTE
+  GT
+  GTE
+  EXISTS
+}
+
+input MetadataFilter {
+  fieldId: ID!
+  op: MetadataOperator!
+  value: String
+}
+
 # A generic interface for any item in the vault, whether a file or folder.
 # This is the core of the new, unified schema.
 interface Resource {
   id: ID!
   name: String!
   isPublic: Boolean!
+  # When false, grants and public flags on ancestors do not apply to this resource or below it.
+  inheritPermissions: Boolean!
   owner: User!
   parent: Folder # Parent is always a folder or null if root
   createdAt: String!
@@ -922,6 +3162,19 @@ interface Resource {
   # List of users who have explicit access to this resource.
   permissions: [Permission!]
   tags: [Tag!]!
+  # Slash-separated location, e.g. "/Reports/2025/Q4.pdf". For resources shared with the
+  # caller it starts at the highest folder the caller can see.
+  path: String!
+  # The folders leading to this resource, outermost first, ending with the resource itself.
+  breadcrumbs: [Breadcrumb!]!
+  # Custom metadata values whose fields the caller can see.
+  metadata: [MetadataValue!]!
+}
+
+# One step of a resource's location.
+type Breadcrumb {
+  id: ID!
+  name: String!
 }
 
 # Represents a folder, which can contain other resources.
@@ -930,6 +3183,7 @@ type Folder implements Resource {
   id: ID!
   name: String!
   isPublic: Boolean!
+  inheritPermissions: Boolean!
   owner: User!
   parent: Folder
   createdAt: String!
@@ -942,6 +3196,14 @@ type Folder implements Resource {
   # Contains all the files and sub-folders within this folder.
   children: [Resource!]!
   tags: [Tag!]!
+  path: String!
+  breadcrumbs: [Breadcrumb!]!
+  metadata: [MetadataValue!]!
+  # Number of files and folders anywhere below this folder. Only the owner counts
+  # everything; other users count what they can read.
+  descendantCount: Int!
+  # Combined size of every file anywhere below this folder, counted like descendantCount.
+  totalSizeBytes: Int!
 }
 
 # Represents a file's metadata.
@@ -950,6 +3212,7 @@ type File implements Resource {
   id: ID!
   name: String!
   isPublic: Boolean!
+  inheritPermissions: Boolean!
   owner: User!
   parent: Folder
   createdAt: String!
@@ -963,24 +3226,377 @@ type File implements Resource {
   mimeType: String!
   storage: StorageStats!
   tags: [Tag!]!
+  path: String!
+  breadcrumbs: [Breadcrumb!]!
+  metadata: [MetadataValue!]!
 }
 
 input SearchFilters {
+  # Part of the resource name, ignoring case, accents and separators, so "q4_report"
+  # matches "Q4 Report" and "q4-report.pdf".
   name: String
+  # Also match names similar to name, tolerating typos: from 0 (anything) to 1 (the
+  # same words). Around 0.4 suits most typos. Results can then be sorted by RELEVANCE.
+  nameSimilarity: Float
   # e.g., ["file"] to only search for files
   types: [String!]
   # e.g., ["image/jpeg", "application/pdf"]
   mimeTypes: [String!]
   minSizeBytes: Int
   maxSizeBytes: Int
-  # Search for files created after this date (e.g., "2025-01-01T00:00:00Z")
+  # Search for files created after this date (e.g., "2025-01-01T00:00:00Z" or
+  # "2025-01-01"). Malformed dates are rejected.
   afterDate: String
-  # Search for files created before this date
+  # Search for files created before this date. A plain date ("2025-01-31") includes
+  # that whole day.
   beforeDate: String
   # e.g., ["Q4-Report", "urgent"]
   tags: [String!]
-  # uploader's name
+  # Part of the uploader's username. Like name, ignores case and accents.
+  uploaderName: String
+  # Only search inside this folder, at any depth.
+  folderId: ID
+  # A search in the query language, combined with the fields above, e.g.
+  #   type:pdf tag:urgent size>10MB after:2025-01-01 owner:alice "quarterly report" -draft
+  # Terms combine with AND, OR, NOT (or -) and parentheses. Words and "phrases" match
+  # names and file contents; results are ranked and edges carry highlighted snippets.
+  # Fields: type, ext, mime, tag, name, owner, size, after, before, created and
+  # modified, e.g. modified:<7d. Syntax errors report their position.
+  query: String
+  # Custom metadata conditions, all of which must hold, e.g.
+  #   [{ fieldId: "4", op: GTE, value: "2025-01-01" }]
+  metadata: [MetadataFilter!]
+}
+
+# One value of a search facet.
+type FacetBucket {
+  label: String!
+  # A query-language filter narrowing the search down to this bucket, e.g.
+  # ` + "`" + `tag:urgent` + "`" + ` or ` + "`" + `size>=1MB size<10MB` + "`" + `. Append it to the search's query.
+  filter: String!
+  count: Int!
+}
+
+# Counts of the resources matching a search, to help narrowing it down. The open-ended
+# facets keep their 20 most common values.
+type SearchFacets {
+  totalCount: Int!
+  mimeTypes: [FacetBucket!]!
+  tags: [FacetBucket!]!
+  owners: [FacetBucket!]!
+  # From the smallest files up; folders have no size.
+  sizes: [FacetBucket!]!
+  # Months of creation, newest first.
+  months: [FacetBucket!]!
+}
+
+# The filters of a saved search, as they were saved.
+type SavedSearchFilters {
+  name: String
+  nameSimilarity: Float
+  types: [String!]
+  mimeTypes: [String!]
+  minSizeBytes: Int
+  maxSizeBytes: Int
+  afterDate: String
+  beforeDate: String
+  tags: [String!]
   uploaderName: String
+  folderId: ID
+  query: String
+  metadata: [SavedMetadataFilter!]!
+}
+
+type SavedMetadataFilter {
+  fieldId: ID!
+  op: MetadataOperator!
+  value: String
+}
+
+# A named search, shown as a smart folder whose contents are computed live. Anyone it
+# is shared with can run it, read-only, and sees only what their own permissions allow.
+type SavedSearch {
+  id: ID!
+  name: String!
+  filters: SavedSearchFilters!
+  owner: User!
+  # Users the search is shared with. Only the owner sees this list.
+  sharedWith: [User!]!
+  createdAt: String!
+  # The resources currently matching the search, for the caller.
+  results(first: Int = 25, after: String, sort: ResourceSort): ResourceConnection!
+}
+
+# What a rule looks for in a file. Every condition set must hold; at least one is needed.
+input RuleConditionsInput {
+  # Exact types or whole families, e.g. ["application/pdf", "image/*"].
+  mimeTypes: [String!]
+  # A shell pattern matched against the whole name, ignoring case, e.g. "invoice-*.pdf".
+  nameGlob: String
+  # A regular expression (RE2 syntax) found anywhere in the name.
+  nameRegex: String
+  minSizeBytes: Int
+  maxSizeBytes: Int
+  # Files uploaded directly into this folder, or anywhere below it with includeSubfolders.
+  folderId: ID
+  includeSubfolders: Boolean = false
+  # Text the document contains, ignoring case. Only files with extractable text match.
+  contains: String
+}
+
+# What a rule does to the files it matches. At least one action is needed.
+input RuleActionsInput {
+  # Tags of the caller's own namespace, created as needed.
+  addTags: [String!]
+  # Moves the file into this folder, renaming it if the name is taken.
+  moveToFolderId: ID
+  makePublic: Boolean = false
+}
+
+input RuleInput {
+  name: String!
+  enabled: Boolean = true
+  conditions: RuleConditionsInput!
+  actions: RuleActionsInput!
+}
+
+type RuleConditions {
+  mimeTypes: [String!]!
+  nameGlob: String
+  nameRegex: String
+  minSizeBytes: Int
+  maxSizeBytes: Int
+  folderId: ID
+  includeSubfolders: Boolean!
+  contains: String
+}
+
+type RuleActions {
+  addTags: [String!]!
+  moveToFolderId: ID
+  makePublic: Boolean!
+}
+
+# An automation applied to the caller's uploads: files matching the conditions get the
+# actions. Rules run in the order they were created, each on the file as uploaded.
+type Rule {
+  id: ID!
+  name: String!
+  enabled: Boolean!
+  conditions: RuleConditions!
+  actions: RuleActions!
+  createdAt: String!
+}
+
+# A file a rule would apply to.
+type RuleMatch {
+  rule: Rule!
+  resource: Resource!
+}
+
+# The caller's files with identical content.
+type DuplicateGroup {
+  fileHash: String!
+  sizeBytes: Int!
+  mimeType: String!
+  # Oldest first.
+  files: [File!]!
+  # The logical storage taken by all copies but one.
+  wastedBytes: Int!
+}
+
+# How deduplication affects the caller's storage.
+type DedupSummary {
+  fileCount: Int!
+  # The size of every file, as counted towards the quota.
+  logicalBytes: Int!
+  # The size of the distinct contents among the files.
+  uniqueBytes: Int!
+  # The files' share of the disk space: each file carries an equal part of its content,
+  # split among every file that shares it, whoever owns them.
+  storedBytes: Int!
+  savedBytes: Int!
+  savedPercentage: Float!
+  duplicateGroups: Int!
+  # The files that could be removed while keeping one copy of each content.
+  duplicateFiles: Int!
+  wastedBytes: Int!
+}
+
+# The outcome of collapsing duplicates.
+type CollapseResult {
+  kept: File!
+  # The logical storage freed by the deleted copies, or that would be on a dry run.
+  freedBytes: Int!
+  # One entry per copy to remove. On a dry run, success means it would be removed.
+  removed: BulkResult!
+  # Whether nothing was actually deleted.
+  dryRun: Boolean!
+}
+
+# The outcome of applying rules to existing files.
+type RuleApplication {
+  scannedCount: Int!
+  matchedCount: Int!
+  failureCount: Int!
+  # Why rules failed on some files; at most 100 are listed.
+  failures: [String!]!
+}
+
+# Where an access request is in its lifecycle.
+enum AccessRequestStatus {
+  PENDING
+  APPROVED
+  DENIED
+}
+
+# A request for access filed by someone who opened a share link they cannot use.
+type AccessRequest {
+  id: ID!
+  resource: Resource!
+  requester: User!
+  role: Role!
+  message: String!
+  status: AccessRequestStatus!
+  createdAt: String!
+  decidedAt: String
+}
+
+# Where an ownership transfer is in its lifecycle.
+enum OwnershipTransferStatus {
+  PENDING
+  ACCEPTED
+  DECLINED
+  CANCELLED
+}
+
+# An offer to hand a resource and everything below it over to another user.
+type OwnershipTransfer {
+  id: ID!
+  resource: Resource!
+  fromUser: User!
+  toUser: User!
+  # Whether the previous owner keeps an EDITOR grant once the transfer is accepted.
+  keepEditorAccess: Boolean!
+  status: OwnershipTransferStatus!
+  createdAt: String!
+  decidedAt: String
+}
+
+# How a user came to have access to a resource.
+enum AccessSource {
+  OWNER
+  USER_GRANT
+  GROUP_GRANT
+}
+
+# Explains the access one user ends up with on a resource.
+type EffectivePermission {
+  user: User!
+  # DENY when an explicit deny blocks every grant that would otherwise apply.
+  role: Role!
+  source: AccessSource!
+  # The resource the deciding grant was made on; null for the owner.
+  grantedOnId: ID
+  grantedOnName: String
+  # True when the deciding grant was made on an ancestor rather than the resource itself.
+  inherited: Boolean!
+  # The group the deciding grant was made to, for GROUP_GRANT.
+  group: Group
+  expiresAt: String
+}
+
+# Result of comparing the resource_ancestors closure table with parent_id.
+type HierarchyReport {
+  # Closure rows implied by parent_id that were absent.
+  missingRows: Int!
+  # Closure rows parent_id does not imply, including rows with the wrong depth.
+  staleRows: Int!
+  # Resources whose chain of parents loops back to themselves.
+  cycleResourceIds: [ID!]!
+  repaired: Boolean!
+}
+
+# The outcome of one item of a bulk mutation.
+type BulkItemResult {
+  id: ID!
+  success: Boolean!
+  # Why the item failed; null on success.
+  error: String
+  # The item after the change; null on failure and for deletions.
+  resource: Resource
+}
+
+# The outcome of a bulk mutation, with one result per requested ID in request order.
+type BulkResult {
+  successCount: Int!
+  failureCount: Int!
+  results: [BulkItemResult!]!
+}
+
+# Columns resource listings can be sorted by.
+enum ResourceSortField {
+  NAME
+  SIZE
+  CREATED_AT
+  UPDATED_AT
+  TYPE
+  # Full-text search rank; only valid for searches with a query.
+  RELEVANCE
+}
+
+enum SortDirection {
+  ASC
+  DESC
+}
+
+# The order of a resource listing. Ties are broken by ID, so the order is stable
+# across pages.
+input ResourceSort {
+  field: ResourceSortField! = NAME
+  direction: SortDirection! = ASC
+  # List folders before files, each group in the order above.
+  foldersFirst: Boolean! = true
+}
+
+# Relay-style pagination details.
+type PageInfo {
+  hasNextPage: Boolean!
+  hasPreviousPage: Boolean!
+  startCursor: String
+  endCursor: String
+}
+
+type ResourceEdge {
+  # Pass as ` + "`" + `after` + "`" + ` to continue the listing after this resource.
+  cursor: String!
+  node: Resource!
+  # For full-text searches, an HTML excerpt of the matching content with the matches
+  # wrapped in <mark>. Null when only the name matched.
+  snippet: String
+}
+
+# One page of a resource listing.
+type ResourceConnection {
+  edges: [ResourceEdge!]!
+  pageInfo: PageInfo!
+  # Number of resources across all pages.
+  totalCount: Int!
+}
+
+# What the caller last did with a resource.
+enum ActivityAction {
+  UPLOADED
+  MODIFIED
+  DOWNLOADED
+  OPENED_SHARE_LINK
+}
+
+# An entry of the caller's recent activity feed.
+type RecentItem {
+  resource: Resource!
+  action: ActivityAction!
+  occurredAt: String!
 }
 
 # A new type to group resources by their owner for admin views.
@@ -996,32 +3612,173 @@ type Query {
   file(id: ID!): File
   folder(id: ID!): Folder
   resolveShareLink(token: String!, expectedType: String!): Resource
-  resources(folderId: ID): [Resource!]!
+  # The contents of a folder, or of the caller's root when folderId is omitted. At most
+  # 200 resources are returned per page.
+  resources(folderId: ID, first: Int = 50, after: String, sort: ResourceSort = {}): ResourceConnection!
+  # Searches the caller's own resources and those shared with them. Pass filters, or
+  # the ID of a saved search to run it.
   searchResources(
-    filters: SearchFilters!
-    offset: Int = 0
-    limit: Int = 25
-  ): [Resource!]!
-  allResources: [UserResources!]!
+    filters: SearchFilters
+    savedSearchId: ID
+    first: Int = 25
+    after: String
+    # Defaults to relevance with a query or a fuzzy name and to newest first otherwise.
+    sort: ResourceSort
+  ): ResourceConnection!
+  # Facet counts for a search, taking the same filters as searchResources.
+  searchFacets(filters: SearchFilters, savedSearchId: ID): SearchFacets!
+  # Autocompletion for the search box: resources whose name contains the prefix or
+  # closely matches it, best matches and most recently updated first. limit is capped
+  # at 50.
+  suggestResources(prefix: String!, limit: Int = 10): [Resource!]!
+  allResources: [UserResources!]! @deprecated(reason: "Limited to 100 resources; use adminResources.")
+  # Every resource in the vault, optionally for a single owner. Admin only.
+  adminResources(ownerId: ID, first: Int = 50, after: String, sort: ResourceSort = { field: CREATED_AT, direction: DESC, foldersFirst: false }): ResourceConnection!
+  myGroups: [Group!]!
+  group(id: ID!): Group
+  # Open access requests on the caller's resources, optionally for a single resource.
+  pendingAccessRequests(resourceId: ID): [AccessRequest!]!
+  # Open transfers the caller has offered or been offered.
+  pendingOwnershipTransfers: [OwnershipTransfer!]!
+  # Looks a resource up by its slash-separated path, e.g. "/Reports/2025/Q4.pdf". Paths
+  # start at the caller's root, or at the shared resource when shareToken is given.
+  resourceByPath(path: String!, shareToken: String): Resource
+  # The caller's starred resources that they can still read.
+  starred(first: Int = 50, after: String, sort: ResourceSort = {}): ResourceConnection!
+  # The resources the caller most recently uploaded, changed, downloaded or opened via a
+  # share link, newest first. At most 100 entries.
+  recent(limit: Int = 20): [RecentItem!]!
+  # The caller's own tags and those of their groups, by name.
+  myTags: [TagUsage!]!
+  # The caller's own metadata fields, then those of their groups.
+  metadataFields: [MetadataField!]!
+  # Everyone who can reach one of the caller's resources, and the grant that lets them.
+  effectivePermissions(resourceId: ID!): [EffectivePermission!]!
+  # The caller's saved searches and those shared with them, by name.
+  savedSearches: [SavedSearch!]!
+  savedSearch(id: ID!): SavedSearch
+  # The caller's rules, in the order they run.
+  rules: [Rule!]!
+  # The caller's files that their enabled rules, or the given rule even if disabled,
+  # would apply to. Nothing is changed. limit is capped at 500.
+  dryRunRules(ruleId: ID, limit: Int = 100): [RuleMatch!]!
+  # Contents the caller has several files of, those wasting the most bytes first. limit
+  # is capped at 200.
+  duplicates(limit: Int = 50): [DuplicateGroup!]!
+  dedupSummary: DedupSummary!
 }
 
 # The entry point for all write/change operations.
 type Mutation {
   register(username: String!, email: String!, password: String!): AuthPayload!
   login(email: String!, password: String!): AuthPayload!
-  uploadFile(file: Upload!, parentId: ID): File!
-  createFolder(name: String!, parentId: ID): Folder!
-  renameFile(id: ID!, newName: String!): File!
+  # The destination is either parentId or a folder path; missing folders along the path are created.
+  uploadFile(file: Upload!, parentId: ID, path: String, conflictStrategy: ConflictStrategy = FAIL): File!
+  createFolder(name: String!, parentId: ID, conflictStrategy: ConflictStrategy = FAIL): Folder!
+  # Creates every missing folder along the path and returns the last one.
+  mkdirP(path: String!): Folder!
+  renameFile(id: ID!, newName: String!, conflictStrategy: ConflictStrategy = FAIL): File!
   deleteFile(id: ID!): Boolean!
-  moveFile(fileId: ID!, newParentId: ID): File!
-  renameFolder(id: ID!, newName: String!): Folder!
+  moveFile(fileId: ID!, newParentId: ID, conflictStrategy: ConflictStrategy = FAIL): File!
+  renameFolder(id: ID!, newName: String!, conflictStrategy: ConflictStrategy = FAIL): Folder!
   deleteFolder(id: ID!): Boolean!
-  moveFolder(folderId: ID!, newParentId: ID): Folder!
-  grantPermission(resourceId: ID!, email: String!, role: Role!): Resource!
+  moveFolder(folderId: ID!, newParentId: ID, conflictStrategy: ConflictStrategy = FAIL): Folder!
+  # Copies a file, or a folder with everything below it the caller can read. File copies
+  # share storage with the original but count towards the caller's quota until deleted.
+  copyResource(id: ID!, destinationParentId: ID, conflictStrategy: ConflictStrategy = FAIL): Resource!
+  # expiresAt is an optional RFC 3339 timestamp after which the grant no longer applies.
+  grantPermission(resourceId: ID!, email: String!, role: Role!, expiresAt: String): Resource!
   revokePermission(resourceId: ID!, email: String!): Resource!
-  addTagToResource(resourceID: ID!, tagName: String!): Resource!
+  # Tagging requires write access to the resource. The tag is taken from the group's
+  # vocabulary when groupId is given, otherwise from the caller's, and created if missing.
+  addTagToResource(resourceID: ID!, tagName: String!, groupId: ID): Resource!
   removeTagFromResource(resourceID: ID!, tagID: ID!): Resource!
 
+  # --- Tags ---
+  # Group tags can be created by any member but only changed by the group's admins.
+  createTag(name: String!, color: String, description: String, groupId: ID): Tag!
+  # Omitted fields are left untouched; empty strings clear them.
+  updateTag(id: ID!, color: String, description: String): Tag!
+  renameTag(id: ID!, name: String!): Tag!
+  # Moves every resource tagged with a source onto the target and deletes the sources.
+  mergeTags(sourceIds: [ID!]!, targetId: ID!): Tag!
+  deleteTag(id: ID!): Boolean!
+
+  # --- Metadata ---
+  # Group fields can only be defined and deleted by the group's admins. ENUM fields
+  # need options; other types take none.
+  createMetadataField(name: String!, type: MetadataFieldType!, required: Boolean = false, options: [String!], groupId: ID): MetadataField!
+  # Also removes the field's values from every resource.
+  deleteMetadataField(id: ID!): Boolean!
+  # Sets or clears values on a resource the caller can change. Values are checked
+  # against their field's type; other fields are left untouched.
+  setMetadata(resourceId: ID!, values: [MetadataValueInput!]!): Resource!
+
+  # --- Bulk Operations ---
+  # Each mutation takes up to 500 IDs and reports per-item results; a failing item
+  # doesn't stop the others.
+  # Moves run in one transaction.
+  bulkMove(ids: [ID!]!, newParentId: ID, conflictStrategy: ConflictStrategy = FAIL): BulkResult!
+  # Deletes run in one transaction.
+  bulkDelete(ids: [ID!]!): BulkResult!
+  bulkTag(ids: [ID!]!, tagName: String!, groupId: ID): BulkResult!
+  bulkGrantPermission(resourceIds: [ID!]!, email: String!, role: Role!, expiresAt: String): BulkResult!
+
+  # --- Stars ---
+  starResource(id: ID!): Resource!
+  unstarResource(id: ID!): Boolean!
+
+  # --- Saved Searches ---
+  saveSearch(name: String!, filters: SearchFilters!): SavedSearch!
+  deleteSavedSearch(id: ID!): Boolean!
+  # Lets another user run the search, read-only. Searches filtering on metadata fields
+  # the user can't see, such as the owner's own, can't be shared with them.
+  shareSavedSearch(id: ID!, email: String!): SavedSearch!
+  unshareSavedSearch(id: ID!, email: String!): SavedSearch!
+
+  # --- Rules ---
+  createRule(input: RuleInput!): Rule!
+  updateRule(id: ID!, input: RuleInput!): Rule!
+  deleteRule(id: ID!): Boolean!
+  # Applies the enabled rules, or the given rule even if disabled, to the caller's
+  # existing files. Uploads get the enabled rules applied automatically.
+  applyRules(ruleId: ID): RuleApplication!
+
+  # --- Duplicates ---
+  # Keeps the given file and deletes the listed copies of its content, which must be
+  # the caller's. A dry run deletes nothing and shows what would happen; it lists every
+  # copy when removeIds is omitted. Copies shared with other users or made public are
+  # refused unless force is set, as their grants would be deleted with them. Copies are
+  # always deleted: there are no link resources to leave in their place.
+  collapseDuplicates(keepId: ID!, removeIds: [ID!], dryRun: Boolean = false, force: Boolean = false): CollapseResult!
+
+  # --- Groups ---
+  createGroup(name: String!): Group!
+  renameGroup(id: ID!, name: String!): Group!
+  deleteGroup(id: ID!): Boolean!
+  addGroupMember(groupId: ID!, email: String!, isAdmin: Boolean = false): Group!
+  removeGroupMember(groupId: ID!, email: String!): Group!
+  # The caller must belong to the group.
+  grantGroupPermission(resourceId: ID!, groupId: ID!, role: Role!, expiresAt: String): Resource!
+  revokeGroupPermission(resourceId: ID!, groupId: ID!): Resource!
+  setInheritPermissions(resourceId: ID!, inherit: Boolean!): Resource!
+
+  # --- Access Requests ---
+  requestAccess(token: String!, role: Role!, message: String): AccessRequest!
+  # role overrides the role that was requested.
+  approveAccessRequest(id: ID!, role: Role): AccessRequest!
+  denyAccessRequest(id: ID!): AccessRequest!
+
+  # --- Ownership Transfer ---
+  transferOwnership(resourceId: ID!, newOwnerEmail: String!, keepEditorAccess: Boolean = true): OwnershipTransfer!
+  acceptOwnershipTransfer(id: ID!): OwnershipTransfer!
+  declineOwnershipTransfer(id: ID!): OwnershipTransfer!
+  cancelOwnershipTransfer(id: ID!): OwnershipTransfer!
+
+  # --- Admin ---
+  # Checks the folder hierarchy for inconsistencies; with repair set, fixes them.
+  verifyHierarchy(repair: Boolean = false): HierarchyReport!
+
   # --- Public Sharing ---
   makeResourcePublic(resourceId: ID!): Resource!
   removeResourcePublicAccess(resourceId: ID!): Resource!
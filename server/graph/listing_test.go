@@ -0,0 +1,169 @@
+package graph
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/99designs/gqlgen/client"
+	"github.com/99designs/gqlgen/graphql/handler"
+	"github.com/99designs/gqlgen/graphql/handler/transport"
+	"github.com/bhavyajaix/BalkanID-filevault/graph/generated"
+	"github.com/bhavyajaix/BalkanID-filevault/internal/database"
+	"github.com/bhavyajaix/BalkanID-filevault/internal/database/databasetest"
+	"github.com/bhavyajaix/BalkanID-filevault/internal/folders"
+	"github.com/bhavyajaix/BalkanID-filevault/internal/loaders"
+	"github.com/bhavyajaix/BalkanID-filevault/internal/middleware"
+	"github.com/bhavyajaix/BalkanID-filevault/internal/permission"
+	"gorm.io/gorm"
+)
+
+// listingQuery asks for every field of a listing's resources that needs a lookup.
+const listingQuery = `query($folderId: ID!) {
+	resources(folderId: $folderId, first: 200) {
+		totalCount
+		edges {
+			node {
+				id
+				owner { id }
+				parent { id }
+				permissions { role }
+				tags { id }
+				metadata { value }
+				path
+				breadcrumbs { id }
+				... on Folder { descendantCount totalSizeBytes }
+				... on File { storage { savedBytes } }
+			}
+		}
+	}
+}`
+
+// statementCounter counts the SQL statements run on a database.
+type statementCounter struct {
+	count atomic.Int64
+}
+
+func (c *statementCounter) register(t *testing.T, db *gorm.DB) {
+	t.Helper()
+	count := func(*gorm.DB) { c.count.Add(1) }
+	callbacks := db.Callback()
+	for name, err := range map[string]error{
+		"query":  callbacks.Query().After("gorm:query").Register("test:count_query", count),
+		"row":    callbacks.Row().After("gorm:row").Register("test:count_row", count),
+		"raw":    callbacks.Raw().After("gorm:raw").Register("test:count_raw", count),
+		"create": callbacks.Create().After("gorm:create").Register("test:count_create", count),
+		"update": callbacks.Update().After("gorm:update").Register("test:count_update", count),
+		"delete": callbacks.Delete().After("gorm:delete").Register("test:count_delete", count),
+	} {
+		if err != nil {
+			t.Fatalf("failed to register %s callback: %v", name, err)
+		}
+	}
+}
+
+// listingFixture is a folder owned by owner and shared with viewer.
+type listingFixture struct {
+	owner, viewer uint
+	folder        uint
+}
+
+// seedListing creates a shared folder holding n children: files, and folders with a
+// file inside them.
+func seedListing(t *testing.T, db *gorm.DB, n int) listingFixture {
+	t.Helper()
+	suffix := fmt.Sprintf("%d-%d", n, time.Now().UnixNano())
+	must := func(err error) {
+		t.Helper()
+		if err != nil {
+			t.Fatalf("failed to seed listing: %v", err)
+		}
+	}
+
+	owner := database.User{Username: "owner-" + suffix, Email: "owner-" + suffix + "@example.com", PasswordHash: "x"}
+	viewer := database.User{Username: "viewer-" + suffix, Email: "viewer-" + suffix + "@example.com", PasswordHash: "x"}
+	must(db.Create(&owner).Error)
+	must(db.Create(&viewer).Error)
+
+	content := database.PhysicalFile{
+		FileHash:  fmt.Sprintf("%x", sha256.Sum256([]byte(suffix))),
+		FilePath:  "/tmp/listing-" + suffix,
+		SizeBytes: 1024,
+		MimeType:  "text/plain",
+	}
+	must(db.Create(&content).Error)
+
+	folder := database.Resource{OwnerID: owner.ID, Name: "Shared " + suffix, Type: database.Folder}
+	must(db.Create(&folder).Error)
+	must(db.Create(&database.Permission{ResourceID: folder.ID, UserID: viewer.ID, Role: database.Viewer}).Error)
+
+	for i := 0; i < n; i++ {
+		name := fmt.Sprintf("child-%03d", i)
+		if i%2 == 1 {
+			must(db.Create(&database.Resource{OwnerID: owner.ID, ParentID: &folder.ID, Name: name + ".txt", Type: database.File, PhysicalFileID: &content.ID}).Error)
+			continue
+		}
+		child := database.Resource{OwnerID: owner.ID, ParentID: &folder.ID, Name: name, Type: database.Folder}
+		must(db.Create(&child).Error)
+		must(db.Create(&database.Resource{OwnerID: owner.ID, ParentID: &child.ID, Name: "inner.txt", Type: database.File, PhysicalFileID: &content.ID}).Error)
+	}
+	return listingFixture{owner: owner.ID, viewer: viewer.ID, folder: folder.ID}
+}
+
+// asUser makes every request come from userID, as middleware.AuthMiddleware would.
+func asUser(userID uint, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := context.WithValue(r.Context(), middleware.UserContextKey, userID)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// TestListingStatementCount checks that listing a folder runs the same number of
+// statements however many resources it returns, for its owner and for a user it is
+// shared with.
+func TestListingStatementCount(t *testing.T) {
+	db := databasetest.Open(t)
+	few, many := seedListing(t, db, 10), seedListing(t, db, 100)
+
+	counter := &statementCounter{}
+	counter.register(t, db)
+
+	foldersService := folders.NewService(folders.NewRepository(db), permission.NewRepository(db))
+	srv := handler.New(generated.NewExecutableSchema(generated.Config{Resolvers: &Resolver{DB: db, FolderService: foldersService}}))
+	srv.AddTransport(transport.POST{})
+	loaderRepo := loaders.NewRepository(db)
+
+	statements := func(t *testing.T, userID, folderID uint, want int) int64 {
+		t.Helper()
+		var resp struct {
+			Resources struct {
+				TotalCount int
+				Edges      []struct {
+					Node struct{ ID string }
+				}
+			}
+		}
+		c := client.New(asUser(userID, loaders.Middleware(loaderRepo, srv)))
+		counter.count.Store(0)
+		c.MustPost(listingQuery, &resp, client.Var("folderId", fmt.Sprint(folderID)))
+		if resp.Resources.TotalCount != want || len(resp.Resources.Edges) != want {
+			t.Fatalf("listed %d of %d resources, want %d", len(resp.Resources.Edges), resp.Resources.TotalCount, want)
+		}
+		return counter.count.Load()
+	}
+
+	t.Run("owner", func(t *testing.T) {
+		if small, large := statements(t, few.owner, few.folder, 10), statements(t, many.owner, many.folder, 100); small != large {
+			t.Errorf("listing 10 resources ran %d statements, listing 100 ran %d", small, large)
+		}
+	})
+	t.Run("shared", func(t *testing.T) {
+		if small, large := statements(t, few.viewer, few.folder, 10), statements(t, many.viewer, many.folder, 100); small != large {
+			t.Errorf("listing 10 resources ran %d statements, listing 100 ran %d", small, large)
+		}
+	})
+}
@@ -14,6 +14,7 @@ type Resource interface {
 	GetID() string
 	GetName() string
 	GetIsPublic() bool
+	GetInheritPermissions() bool
 	GetOwner() *User
 	GetParent() *Folder
 	GetCreatedAt() string
@@ -21,6 +22,20 @@ type Resource interface {
 	GetShareToken() string
 	GetPermissions() []*Permission
 	GetTags() []*Tag
+	GetPath() string
+	GetBreadcrumbs() []*Breadcrumb
+	GetMetadata() []*MetadataValue
+}
+
+type AccessRequest struct {
+	ID        string              `json:"id"`
+	Resource  Resource            `json:"resource"`
+	Requester *User               `json:"requester"`
+	Role      Role                `json:"role"`
+	Message   string              `json:"message"`
+	Status    AccessRequestStatus `json:"status"`
+	CreatedAt string              `json:"createdAt"`
+	DecidedAt *string             `json:"decidedAt,omitempty"`
 }
 
 type AuthPayload struct {
@@ -28,32 +43,102 @@ type AuthPayload struct {
 	User  *User  `json:"user"`
 }
 
+type Breadcrumb struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type BulkItemResult struct {
+	ID       string   `json:"id"`
+	Success  bool     `json:"success"`
+	Error    *string  `json:"error,omitempty"`
+	Resource Resource `json:"resource,omitempty"`
+}
+
+type BulkResult struct {
+	SuccessCount int               `json:"successCount"`
+	FailureCount int               `json:"failureCount"`
+	Results      []*BulkItemResult `json:"results"`
+}
+
+type CollapseResult struct {
+	Kept       *File       `json:"kept"`
+	FreedBytes int         `json:"freedBytes"`
+	Removed    *BulkResult `json:"removed"`
+	DryRun     bool        `json:"dryRun"`
+}
+
+type DedupSummary struct {
+	FileCount       int     `json:"fileCount"`
+	LogicalBytes    int     `json:"logicalBytes"`
+	UniqueBytes     int     `json:"uniqueBytes"`
+	StoredBytes     int     `json:"storedBytes"`
+	SavedBytes      int     `json:"savedBytes"`
+	SavedPercentage float64 `json:"savedPercentage"`
+	DuplicateGroups int     `json:"duplicateGroups"`
+	DuplicateFiles  int     `json:"duplicateFiles"`
+	WastedBytes     int     `json:"wastedBytes"`
+}
+
+type DuplicateGroup struct {
+	FileHash    string  `json:"fileHash"`
+	SizeBytes   int     `json:"sizeBytes"`
+	MimeType    string  `json:"mimeType"`
+	Files       []*File `json:"files"`
+	WastedBytes int     `json:"wastedBytes"`
+}
+
+type EffectivePermission struct {
+	User          *User        `json:"user"`
+	Role          Role         `json:"role"`
+	Source        AccessSource `json:"source"`
+	GrantedOnID   *string      `json:"grantedOnId,omitempty"`
+	GrantedOnName *string      `json:"grantedOnName,omitempty"`
+	Inherited     bool         `json:"inherited"`
+	Group         *Group       `json:"group,omitempty"`
+	ExpiresAt     *string      `json:"expiresAt,omitempty"`
+}
+
+type FacetBucket struct {
+	Label  string `json:"label"`
+	Filter string `json:"filter"`
+	Count  int    `json:"count"`
+}
+
 type File struct {
-	ID          string        `json:"id"`
-	Name        string        `json:"name"`
-	IsPublic    bool          `json:"isPublic"`
-	Owner       *User         `json:"owner"`
-	Parent      *Folder       `json:"parent,omitempty"`
-	CreatedAt   string        `json:"createdAt"`
-	UpdatedAt   string        `json:"updatedAt"`
-	Permissions []*Permission `json:"permissions,omitempty"`
-	Type        string        `json:"type"`
-	ShareToken  string        `json:"shareToken"`
-	SizeBytes   int           `json:"sizeBytes"`
-	MimeType    string        `json:"mimeType"`
-	Storage     *StorageStats `json:"storage"`
-	Tags        []*Tag        `json:"tags"`
-}
-
-func (File) IsResource()                {}
-func (this File) GetID() string         { return this.ID }
-func (this File) GetName() string       { return this.Name }
-func (this File) GetIsPublic() bool     { return this.IsPublic }
-func (this File) GetOwner() *User       { return this.Owner }
-func (this File) GetParent() *Folder    { return this.Parent }
-func (this File) GetCreatedAt() string  { return this.CreatedAt }
-func (this File) GetUpdatedAt() string  { return this.UpdatedAt }
-func (this File) GetShareToken() string { return this.ShareToken }
+	ID                 string           `json:"id"`
+	Name               string           `json:"name"`
+	IsPublic           bool             `json:"isPublic"`
+	InheritPermissions bool             `json:"inheritPermissions"`
+	Owner              *User            `json:"owner"`
+	Parent             *Folder          `json:"parent,omitempty"`
+	CreatedAt          string           `json:"createdAt"`
+	UpdatedAt          string           `json:"updatedAt"`
+	Permissions        []*Permission    `json:"permissions,omitempty"`
+	Type               string           `json:"type"`
+	ShareToken         string           `json:"shareToken"`
+	SizeBytes          int              `json:"sizeBytes"`
+	MimeType           string           `json:"mimeType"`
+	Storage            *StorageStats    `json:"storage"`
+	Tags               []*Tag           `json:"tags"`
+	Path               string           `json:"path"`
+	Breadcrumbs        []*Breadcrumb    `json:"breadcrumbs"`
+	Metadata           []*MetadataValue `json:"metadata"`
+	OwnerID            uint             `json:"-"`
+	ParentID           *uint            `json:"-"`
+	PhysicalFileID     *uint            `json:"-"`
+}
+
+func (File) IsResource()                      {}
+func (this File) GetID() string               { return this.ID }
+func (this File) GetName() string             { return this.Name }
+func (this File) GetIsPublic() bool           { return this.IsPublic }
+func (this File) GetInheritPermissions() bool { return this.InheritPermissions }
+func (this File) GetOwner() *User             { return this.Owner }
+func (this File) GetParent() *Folder          { return this.Parent }
+func (this File) GetCreatedAt() string        { return this.CreatedAt }
+func (this File) GetUpdatedAt() string        { return this.UpdatedAt }
+func (this File) GetShareToken() string       { return this.ShareToken }
 func (this File) GetPermissions() []*Permission {
 	if this.Permissions == nil {
 		return nil
@@ -74,31 +159,61 @@ func (this File) GetTags() []*Tag {
 	}
 	return interfaceSlice
 }
+func (this File) GetPath() string { return this.Path }
+func (this File) GetBreadcrumbs() []*Breadcrumb {
+	if this.Breadcrumbs == nil {
+		return nil
+	}
+	interfaceSlice := make([]*Breadcrumb, 0, len(this.Breadcrumbs))
+	for _, concrete := range this.Breadcrumbs {
+		interfaceSlice = append(interfaceSlice, concrete)
+	}
+	return interfaceSlice
+}
+func (this File) GetMetadata() []*MetadataValue {
+	if this.Metadata == nil {
+		return nil
+	}
+	interfaceSlice := make([]*MetadataValue, 0, len(this.Metadata))
+	for _, concrete := range this.Metadata {
+		interfaceSlice = append(interfaceSlice, concrete)
+	}
+	return interfaceSlice
+}
 
 type Folder struct {
-	ID          string        `json:"id"`
-	Name        string        `json:"name"`
-	IsPublic    bool          `json:"isPublic"`
-	Owner       *User         `json:"owner"`
-	Parent      *Folder       `json:"parent,omitempty"`
-	CreatedAt   string        `json:"createdAt"`
-	UpdatedAt   string        `json:"updatedAt"`
-	Permissions []*Permission `json:"permissions,omitempty"`
-	Type        string        `json:"type"`
-	ShareToken  string        `json:"shareToken"`
-	Children    []Resource    `json:"children"`
-	Tags        []*Tag        `json:"tags"`
-}
-
-func (Folder) IsResource()                {}
-func (this Folder) GetID() string         { return this.ID }
-func (this Folder) GetName() string       { return this.Name }
-func (this Folder) GetIsPublic() bool     { return this.IsPublic }
-func (this Folder) GetOwner() *User       { return this.Owner }
-func (this Folder) GetParent() *Folder    { return this.Parent }
-func (this Folder) GetCreatedAt() string  { return this.CreatedAt }
-func (this Folder) GetUpdatedAt() string  { return this.UpdatedAt }
-func (this Folder) GetShareToken() string { return this.ShareToken }
+	ID                 string           `json:"id"`
+	Name               string           `json:"name"`
+	IsPublic           bool             `json:"isPublic"`
+	InheritPermissions bool             `json:"inheritPermissions"`
+	Owner              *User            `json:"owner"`
+	Parent             *Folder          `json:"parent,omitempty"`
+	CreatedAt          string           `json:"createdAt"`
+	UpdatedAt          string           `json:"updatedAt"`
+	Permissions        []*Permission    `json:"permissions,omitempty"`
+	Type               string           `json:"type"`
+	ShareToken         string           `json:"shareToken"`
+	Children           []Resource       `json:"children"`
+	Tags               []*Tag           `json:"tags"`
+	Path               string           `json:"path"`
+	Breadcrumbs        []*Breadcrumb    `json:"breadcrumbs"`
+	Metadata           []*MetadataValue `json:"metadata"`
+	DescendantCount    int              `json:"descendantCount"`
+	TotalSizeBytes     int              `json:"totalSizeBytes"`
+	OwnerID            uint             `json:"-"`
+	ParentID           *uint            `json:"-"`
+}
+
+func (Folder) IsResource()                      {}
+func (this Folder) GetID() string               { return this.ID }
+func (this Folder) GetName() string             { return this.Name }
+func (this Folder) GetIsPublic() bool           { return this.IsPublic }
+func (this Folder) GetInheritPermissions() bool { return this.InheritPermissions }
+func (this Folder) GetOwner() *User             { return this.Owner }
+func (this Folder) GetParent() *Folder          { return this.Parent }
+func (this Folder) GetCreatedAt() string        { return this.CreatedAt }
+func (this Folder) GetUpdatedAt() string        { return this.UpdatedAt }
+func (this Folder) GetShareToken() string       { return this.ShareToken }
 func (this Folder) GetPermissions() []*Permission {
 	if this.Permissions == nil {
 		return nil
@@ -119,28 +234,249 @@ func (this Folder) GetTags() []*Tag {
 	}
 	return interfaceSlice
 }
+func (this Folder) GetPath() string { return this.Path }
+func (this Folder) GetBreadcrumbs() []*Breadcrumb {
+	if this.Breadcrumbs == nil {
+		return nil
+	}
+	interfaceSlice := make([]*Breadcrumb, 0, len(this.Breadcrumbs))
+	for _, concrete := range this.Breadcrumbs {
+		interfaceSlice = append(interfaceSlice, concrete)
+	}
+	return interfaceSlice
+}
+func (this Folder) GetMetadata() []*MetadataValue {
+	if this.Metadata == nil {
+		return nil
+	}
+	interfaceSlice := make([]*MetadataValue, 0, len(this.Metadata))
+	for _, concrete := range this.Metadata {
+		interfaceSlice = append(interfaceSlice, concrete)
+	}
+	return interfaceSlice
+}
+
+type Group struct {
+	ID        string         `json:"id"`
+	Name      string         `json:"name"`
+	Owner     *User          `json:"owner"`
+	Members   []*GroupMember `json:"members"`
+	CreatedAt string         `json:"createdAt"`
+	UpdatedAt string         `json:"updatedAt"`
+}
+
+type GroupMember struct {
+	User    *User  `json:"user"`
+	IsAdmin bool   `json:"isAdmin"`
+	AddedAt string `json:"addedAt"`
+}
+
+type HierarchyReport struct {
+	MissingRows      int      `json:"missingRows"`
+	StaleRows        int      `json:"staleRows"`
+	CycleResourceIds []string `json:"cycleResourceIds"`
+	Repaired         bool     `json:"repaired"`
+}
+
+type MetadataField struct {
+	ID        string            `json:"id"`
+	Name      string            `json:"name"`
+	Type      MetadataFieldType `json:"type"`
+	Required  bool              `json:"required"`
+	Options   []string          `json:"options"`
+	GroupID   *string           `json:"groupId,omitempty"`
+	CreatedAt string            `json:"createdAt"`
+}
+
+type MetadataFilter struct {
+	FieldID string           `json:"fieldId"`
+	Op      MetadataOperator `json:"op"`
+	Value   *string          `json:"value,omitempty"`
+}
+
+type MetadataValue struct {
+	Field *MetadataField `json:"field"`
+	Value string         `json:"value"`
+}
+
+type MetadataValueInput struct {
+	FieldID string  `json:"fieldId"`
+	Value   *string `json:"value,omitempty"`
+}
 
 type Mutation struct {
 }
 
+type OwnershipTransfer struct {
+	ID               string                  `json:"id"`
+	Resource         Resource                `json:"resource"`
+	FromUser         *User                   `json:"fromUser"`
+	ToUser           *User                   `json:"toUser"`
+	KeepEditorAccess bool                    `json:"keepEditorAccess"`
+	Status           OwnershipTransferStatus `json:"status"`
+	CreatedAt        string                  `json:"createdAt"`
+	DecidedAt        *string                 `json:"decidedAt,omitempty"`
+}
+
+type PageInfo struct {
+	HasNextPage     bool    `json:"hasNextPage"`
+	HasPreviousPage bool    `json:"hasPreviousPage"`
+	StartCursor     *string `json:"startCursor,omitempty"`
+	EndCursor       *string `json:"endCursor,omitempty"`
+}
+
 type Permission struct {
-	User *User `json:"user"`
-	Role Role  `json:"role"`
+	PrincipalType PrincipalType `json:"principalType"`
+	User          *User         `json:"user,omitempty"`
+	Group         *Group        `json:"group,omitempty"`
+	Role          Role          `json:"role"`
+	ExpiresAt     *string       `json:"expiresAt,omitempty"`
 }
 
 type Query struct {
 }
 
+type RecentItem struct {
+	Resource   Resource       `json:"resource"`
+	Action     ActivityAction `json:"action"`
+	OccurredAt string         `json:"occurredAt"`
+}
+
+type ResourceConnection struct {
+	Edges      []*ResourceEdge `json:"edges"`
+	PageInfo   *PageInfo       `json:"pageInfo"`
+	TotalCount int             `json:"totalCount"`
+}
+
+type ResourceEdge struct {
+	Cursor  string   `json:"cursor"`
+	Node    Resource `json:"node"`
+	Snippet *string  `json:"snippet,omitempty"`
+}
+
+type ResourceSort struct {
+	Field        ResourceSortField `json:"field"`
+	Direction    SortDirection     `json:"direction"`
+	FoldersFirst bool              `json:"foldersFirst"`
+}
+
+type Rule struct {
+	ID         string          `json:"id"`
+	Name       string          `json:"name"`
+	Enabled    bool            `json:"enabled"`
+	Conditions *RuleConditions `json:"conditions"`
+	Actions    *RuleActions    `json:"actions"`
+	CreatedAt  string          `json:"createdAt"`
+}
+
+type RuleActions struct {
+	AddTags        []string `json:"addTags"`
+	MoveToFolderID *string  `json:"moveToFolderId,omitempty"`
+	MakePublic     bool     `json:"makePublic"`
+}
+
+type RuleActionsInput struct {
+	AddTags        []string `json:"addTags,omitempty"`
+	MoveToFolderID *string  `json:"moveToFolderId,omitempty"`
+	MakePublic     *bool    `json:"makePublic,omitempty"`
+}
+
+type RuleApplication struct {
+	ScannedCount int      `json:"scannedCount"`
+	MatchedCount int      `json:"matchedCount"`
+	FailureCount int      `json:"failureCount"`
+	Failures     []string `json:"failures"`
+}
+
+type RuleConditions struct {
+	MimeTypes         []string `json:"mimeTypes"`
+	NameGlob          *string  `json:"nameGlob,omitempty"`
+	NameRegex         *string  `json:"nameRegex,omitempty"`
+	MinSizeBytes      *int     `json:"minSizeBytes,omitempty"`
+	MaxSizeBytes      *int     `json:"maxSizeBytes,omitempty"`
+	FolderID          *string  `json:"folderId,omitempty"`
+	IncludeSubfolders bool     `json:"includeSubfolders"`
+	Contains          *string  `json:"contains,omitempty"`
+}
+
+type RuleConditionsInput struct {
+	MimeTypes         []string `json:"mimeTypes,omitempty"`
+	NameGlob          *string  `json:"nameGlob,omitempty"`
+	NameRegex         *string  `json:"nameRegex,omitempty"`
+	MinSizeBytes      *int     `json:"minSizeBytes,omitempty"`
+	MaxSizeBytes      *int     `json:"maxSizeBytes,omitempty"`
+	FolderID          *string  `json:"folderId,omitempty"`
+	IncludeSubfolders *bool    `json:"includeSubfolders,omitempty"`
+	Contains          *string  `json:"contains,omitempty"`
+}
+
+type RuleInput struct {
+	Name       string               `json:"name"`
+	Enabled    *bool                `json:"enabled,omitempty"`
+	Conditions *RuleConditionsInput `json:"conditions"`
+	Actions    *RuleActionsInput    `json:"actions"`
+}
+
+type RuleMatch struct {
+	Rule     *Rule    `json:"rule"`
+	Resource Resource `json:"resource"`
+}
+
+type SavedMetadataFilter struct {
+	FieldID string           `json:"fieldId"`
+	Op      MetadataOperator `json:"op"`
+	Value   *string          `json:"value,omitempty"`
+}
+
+type SavedSearch struct {
+	ID         string              `json:"id"`
+	Name       string              `json:"name"`
+	Filters    *SavedSearchFilters `json:"filters"`
+	Owner      *User               `json:"owner"`
+	SharedWith []*User             `json:"sharedWith"`
+	CreatedAt  string              `json:"createdAt"`
+	Results    *ResourceConnection `json:"results"`
+}
+
+type SavedSearchFilters struct {
+	Name           *string                `json:"name,omitempty"`
+	NameSimilarity *float64               `json:"nameSimilarity,omitempty"`
+	Types          []string               `json:"types,omitempty"`
+	MimeTypes      []string               `json:"mimeTypes,omitempty"`
+	MinSizeBytes   *int                   `json:"minSizeBytes,omitempty"`
+	MaxSizeBytes   *int                   `json:"maxSizeBytes,omitempty"`
+	AfterDate      *string                `json:"afterDate,omitempty"`
+	BeforeDate     *string                `json:"beforeDate,omitempty"`
+	Tags           []string               `json:"tags,omitempty"`
+	UploaderName   *string                `json:"uploaderName,omitempty"`
+	FolderID       *string                `json:"folderId,omitempty"`
+	Query          *string                `json:"query,omitempty"`
+	Metadata       []*SavedMetadataFilter `json:"metadata"`
+}
+
+type SearchFacets struct {
+	TotalCount int            `json:"totalCount"`
+	MimeTypes  []*FacetBucket `json:"mimeTypes"`
+	Tags       []*FacetBucket `json:"tags"`
+	Owners     []*FacetBucket `json:"owners"`
+	Sizes      []*FacetBucket `json:"sizes"`
+	Months     []*FacetBucket `json:"months"`
+}
+
 type SearchFilters struct {
-	Name         *string  `json:"name,omitempty"`
-	Types        []string `json:"types,omitempty"`
-	MimeTypes    []string `json:"mimeTypes,omitempty"`
-	MinSizeBytes *int     `json:"minSizeBytes,omitempty"`
-	MaxSizeBytes *int     `json:"maxSizeBytes,omitempty"`
-	AfterDate    *string  `json:"afterDate,omitempty"`
-	BeforeDate   *string  `json:"beforeDate,omitempty"`
-	Tags         []string `json:"tags,omitempty"`
-	UploaderName *string  `json:"uploaderName,omitempty"`
+	Name           *string           `json:"name,omitempty"`
+	NameSimilarity *float64          `json:"nameSimilarity,omitempty"`
+	Types          []string          `json:"types,omitempty"`
+	MimeTypes      []string          `json:"mimeTypes,omitempty"`
+	MinSizeBytes   *int              `json:"minSizeBytes,omitempty"`
+	MaxSizeBytes   *int              `json:"maxSizeBytes,omitempty"`
+	AfterDate      *string           `json:"afterDate,omitempty"`
+	BeforeDate     *string           `json:"beforeDate,omitempty"`
+	Tags           []string          `json:"tags,omitempty"`
+	UploaderName   *string           `json:"uploaderName,omitempty"`
+	FolderID       *string           `json:"folderId,omitempty"`
+	Query          *string           `json:"query,omitempty"`
+	Metadata       []*MetadataFilter `json:"metadata,omitempty"`
 }
 
 type StorageStats struct {
@@ -148,13 +484,22 @@ type StorageStats struct {
 	DeduplicatedSizeBytes int     `json:"deduplicatedSizeBytes"`
 	SavedBytes            int     `json:"savedBytes"`
 	SavedPercentage       float64 `json:"savedPercentage"`
+	ReferenceCount        int     `json:"referenceCount"`
 }
 
 type Tag struct {
-	ID        string `json:"id"`
-	Name      string `json:"name"`
-	CreatedAt string `json:"createdAt"`
-	UpdatedAt string `json:"updatedAt"`
+	ID          string  `json:"id"`
+	Name        string  `json:"name"`
+	Color       string  `json:"color"`
+	Description string  `json:"description"`
+	GroupID     *string `json:"groupId,omitempty"`
+	CreatedAt   string  `json:"createdAt"`
+	UpdatedAt   string  `json:"updatedAt"`
+}
+
+type TagUsage struct {
+	Tag        *Tag `json:"tag"`
+	UsageCount int  `json:"usageCount"`
 }
 
 type User struct {
@@ -172,21 +517,556 @@ type UserResources struct {
 	Resources     []Resource `json:"resources"`
 }
 
+type AccessRequestStatus string
+
+const (
+	AccessRequestStatusPending  AccessRequestStatus = "PENDING"
+	AccessRequestStatusApproved AccessRequestStatus = "APPROVED"
+	AccessRequestStatusDenied   AccessRequestStatus = "DENIED"
+)
+
+var AllAccessRequestStatus = []AccessRequestStatus{
+	AccessRequestStatusPending,
+	AccessRequestStatusApproved,
+	AccessRequestStatusDenied,
+}
+
+func (e AccessRequestStatus) IsValid() bool {
+	switch e {
+	case AccessRequestStatusPending, AccessRequestStatusApproved, AccessRequestStatusDenied:
+		return true
+	}
+	return false
+}
+
+func (e AccessRequestStatus) String() string {
+	return string(e)
+}
+
+func (e *AccessRequestStatus) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = AccessRequestStatus(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid AccessRequestStatus", str)
+	}
+	return nil
+}
+
+func (e AccessRequestStatus) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *AccessRequestStatus) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e AccessRequestStatus) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type AccessSource string
+
+const (
+	AccessSourceOwner      AccessSource = "OWNER"
+	AccessSourceUserGrant  AccessSource = "USER_GRANT"
+	AccessSourceGroupGrant AccessSource = "GROUP_GRANT"
+)
+
+var AllAccessSource = []AccessSource{
+	AccessSourceOwner,
+	AccessSourceUserGrant,
+	AccessSourceGroupGrant,
+}
+
+func (e AccessSource) IsValid() bool {
+	switch e {
+	case AccessSourceOwner, AccessSourceUserGrant, AccessSourceGroupGrant:
+		return true
+	}
+	return false
+}
+
+func (e AccessSource) String() string {
+	return string(e)
+}
+
+func (e *AccessSource) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = AccessSource(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid AccessSource", str)
+	}
+	return nil
+}
+
+func (e AccessSource) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *AccessSource) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e AccessSource) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type ActivityAction string
+
+const (
+	ActivityActionUploaded        ActivityAction = "UPLOADED"
+	ActivityActionModified        ActivityAction = "MODIFIED"
+	ActivityActionDownloaded      ActivityAction = "DOWNLOADED"
+	ActivityActionOpenedShareLink ActivityAction = "OPENED_SHARE_LINK"
+)
+
+var AllActivityAction = []ActivityAction{
+	ActivityActionUploaded,
+	ActivityActionModified,
+	ActivityActionDownloaded,
+	ActivityActionOpenedShareLink,
+}
+
+func (e ActivityAction) IsValid() bool {
+	switch e {
+	case ActivityActionUploaded, ActivityActionModified, ActivityActionDownloaded, ActivityActionOpenedShareLink:
+		return true
+	}
+	return false
+}
+
+func (e ActivityAction) String() string {
+	return string(e)
+}
+
+func (e *ActivityAction) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = ActivityAction(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid ActivityAction", str)
+	}
+	return nil
+}
+
+func (e ActivityAction) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *ActivityAction) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e ActivityAction) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type ConflictStrategy string
+
+const (
+	ConflictStrategyFail    ConflictStrategy = "FAIL"
+	ConflictStrategyRename  ConflictStrategy = "RENAME"
+	ConflictStrategyReplace ConflictStrategy = "REPLACE"
+)
+
+var AllConflictStrategy = []ConflictStrategy{
+	ConflictStrategyFail,
+	ConflictStrategyRename,
+	ConflictStrategyReplace,
+}
+
+func (e ConflictStrategy) IsValid() bool {
+	switch e {
+	case ConflictStrategyFail, ConflictStrategyRename, ConflictStrategyReplace:
+		return true
+	}
+	return false
+}
+
+func (e ConflictStrategy) String() string {
+	return string(e)
+}
+
+func (e *ConflictStrategy) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = ConflictStrategy(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid ConflictStrategy", str)
+	}
+	return nil
+}
+
+func (e ConflictStrategy) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *ConflictStrategy) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e ConflictStrategy) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type MetadataFieldType string
+
+const (
+	MetadataFieldTypeString MetadataFieldType = "STRING"
+	MetadataFieldTypeNumber MetadataFieldType = "NUMBER"
+	MetadataFieldTypeDate   MetadataFieldType = "DATE"
+	MetadataFieldTypeEnum   MetadataFieldType = "ENUM"
+)
+
+var AllMetadataFieldType = []MetadataFieldType{
+	MetadataFieldTypeString,
+	MetadataFieldTypeNumber,
+	MetadataFieldTypeDate,
+	MetadataFieldTypeEnum,
+}
+
+func (e MetadataFieldType) IsValid() bool {
+	switch e {
+	case MetadataFieldTypeString, MetadataFieldTypeNumber, MetadataFieldTypeDate, MetadataFieldTypeEnum:
+		return true
+	}
+	return false
+}
+
+func (e MetadataFieldType) String() string {
+	return string(e)
+}
+
+func (e *MetadataFieldType) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = MetadataFieldType(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid MetadataFieldType", str)
+	}
+	return nil
+}
+
+func (e MetadataFieldType) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *MetadataFieldType) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e MetadataFieldType) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type MetadataOperator string
+
+const (
+	MetadataOperatorEq       MetadataOperator = "EQ"
+	MetadataOperatorNe       MetadataOperator = "NE"
+	MetadataOperatorContains MetadataOperator = "CONTAINS"
+	MetadataOperatorLt       MetadataOperator = "LT"
+	MetadataOperatorLte      MetadataOperator = "LTE"
+	MetadataOperatorGt       MetadataOperator = "GT"
+	MetadataOperatorGte      MetadataOperator = "GTE"
+	MetadataOperatorExists   MetadataOperator = "EXISTS"
+)
+
+var AllMetadataOperator = []MetadataOperator{
+	MetadataOperatorEq,
+	MetadataOperatorNe,
+	MetadataOperatorContains,
+	MetadataOperatorLt,
+	MetadataOperatorLte,
+	MetadataOperatorGt,
+	MetadataOperatorGte,
+	MetadataOperatorExists,
+}
+
+func (e MetadataOperator) IsValid() bool {
+	switch e {
+	case MetadataOperatorEq, MetadataOperatorNe, MetadataOperatorContains, MetadataOperatorLt, MetadataOperatorLte, MetadataOperatorGt, MetadataOperatorGte, MetadataOperatorExists:
+		return true
+	}
+	return false
+}
+
+func (e MetadataOperator) String() string {
+	return string(e)
+}
+
+func (e *MetadataOperator) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = MetadataOperator(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid MetadataOperator", str)
+	}
+	return nil
+}
+
+func (e MetadataOperator) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *MetadataOperator) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e MetadataOperator) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type OwnershipTransferStatus string
+
+const (
+	OwnershipTransferStatusPending   OwnershipTransferStatus = "PENDING"
+	OwnershipTransferStatusAccepted  OwnershipTransferStatus = "ACCEPTED"
+	OwnershipTransferStatusDeclined  OwnershipTransferStatus = "DECLINED"
+	OwnershipTransferStatusCancelled OwnershipTransferStatus = "CANCELLED"
+)
+
+var AllOwnershipTransferStatus = []OwnershipTransferStatus{
+	OwnershipTransferStatusPending,
+	OwnershipTransferStatusAccepted,
+	OwnershipTransferStatusDeclined,
+	OwnershipTransferStatusCancelled,
+}
+
+func (e OwnershipTransferStatus) IsValid() bool {
+	switch e {
+	case OwnershipTransferStatusPending, OwnershipTransferStatusAccepted, OwnershipTransferStatusDeclined, OwnershipTransferStatusCancelled:
+		return true
+	}
+	return false
+}
+
+func (e OwnershipTransferStatus) String() string {
+	return string(e)
+}
+
+func (e *OwnershipTransferStatus) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = OwnershipTransferStatus(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid OwnershipTransferStatus", str)
+	}
+	return nil
+}
+
+func (e OwnershipTransferStatus) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *OwnershipTransferStatus) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e OwnershipTransferStatus) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type PrincipalType string
+
+const (
+	PrincipalTypeUser  PrincipalType = "USER"
+	PrincipalTypeGroup PrincipalType = "GROUP"
+)
+
+var AllPrincipalType = []PrincipalType{
+	PrincipalTypeUser,
+	PrincipalTypeGroup,
+}
+
+func (e PrincipalType) IsValid() bool {
+	switch e {
+	case PrincipalTypeUser, PrincipalTypeGroup:
+		return true
+	}
+	return false
+}
+
+func (e PrincipalType) String() string {
+	return string(e)
+}
+
+func (e *PrincipalType) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = PrincipalType(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid PrincipalType", str)
+	}
+	return nil
+}
+
+func (e PrincipalType) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *PrincipalType) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e PrincipalType) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type ResourceSortField string
+
+const (
+	ResourceSortFieldName      ResourceSortField = "NAME"
+	ResourceSortFieldSize      ResourceSortField = "SIZE"
+	ResourceSortFieldCreatedAt ResourceSortField = "CREATED_AT"
+	ResourceSortFieldUpdatedAt ResourceSortField = "UPDATED_AT"
+	ResourceSortFieldType      ResourceSortField = "TYPE"
+	ResourceSortFieldRelevance ResourceSortField = "RELEVANCE"
+)
+
+var AllResourceSortField = []ResourceSortField{
+	ResourceSortFieldName,
+	ResourceSortFieldSize,
+	ResourceSortFieldCreatedAt,
+	ResourceSortFieldUpdatedAt,
+	ResourceSortFieldType,
+	ResourceSortFieldRelevance,
+}
+
+func (e ResourceSortField) IsValid() bool {
+	switch e {
+	case ResourceSortFieldName, ResourceSortFieldSize, ResourceSortFieldCreatedAt, ResourceSortFieldUpdatedAt, ResourceSortFieldType, ResourceSortFieldRelevance:
+		return true
+	}
+	return false
+}
+
+func (e ResourceSortField) String() string {
+	return string(e)
+}
+
+func (e *ResourceSortField) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = ResourceSortField(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid ResourceSortField", str)
+	}
+	return nil
+}
+
+func (e ResourceSortField) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *ResourceSortField) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e ResourceSortField) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
 type Role string
 
 const (
 	RoleViewer Role = "VIEWER"
 	RoleEditor Role = "EDITOR"
+	RoleDeny   Role = "DENY"
 )
 
 var AllRole = []Role{
 	RoleViewer,
 	RoleEditor,
+	RoleDeny,
 }
 
 func (e Role) IsValid() bool {
 	switch e {
-	case RoleViewer, RoleEditor:
+	case RoleViewer, RoleEditor, RoleDeny:
 		return true
 	}
 	return false
@@ -226,3 +1106,58 @@ func (e Role) MarshalJSON() ([]byte, error) {
 	e.MarshalGQL(&buf)
 	return buf.Bytes(), nil
 }
+
+type SortDirection string
+
+const (
+	SortDirectionAsc  SortDirection = "ASC"
+	SortDirectionDesc SortDirection = "DESC"
+)
+
+var AllSortDirection = []SortDirection{
+	SortDirectionAsc,
+	SortDirectionDesc,
+}
+
+func (e SortDirection) IsValid() bool {
+	switch e {
+	case SortDirectionAsc, SortDirectionDesc:
+		return true
+	}
+	return false
+}
+
+func (e SortDirection) String() string {
+	return string(e)
+}
+
+func (e *SortDirection) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = SortDirection(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid SortDirection", str)
+	}
+	return nil
+}
+
+func (e SortDirection) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *SortDirection) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e SortDirection) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
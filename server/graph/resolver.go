@@ -1,9 +1,17 @@
 package graph
 
 import (
+	"github.com/bhavyajaix/BalkanID-filevault/internal/accessrequest"
+	"github.com/bhavyajaix/BalkanID-filevault/internal/activity"
+	"github.com/bhavyajaix/BalkanID-filevault/internal/dedup"
 	"github.com/bhavyajaix/BalkanID-filevault/internal/file"
 	"github.com/bhavyajaix/BalkanID-filevault/internal/folders"
+	"github.com/bhavyajaix/BalkanID-filevault/internal/group"
+	"github.com/bhavyajaix/BalkanID-filevault/internal/metadata"
+	"github.com/bhavyajaix/BalkanID-filevault/internal/ownership"
 	"github.com/bhavyajaix/BalkanID-filevault/internal/permission"
+	"github.com/bhavyajaix/BalkanID-filevault/internal/rules"
+	"github.com/bhavyajaix/BalkanID-filevault/internal/savedsearch"
 	"github.com/bhavyajaix/BalkanID-filevault/internal/search"
 	"github.com/bhavyajaix/BalkanID-filevault/internal/share"
 	"github.com/bhavyajaix/BalkanID-filevault/internal/tag"
@@ -13,12 +21,20 @@ import (
 )
 
 type Resolver struct {
-	DB                *gorm.DB
-	UserService       user.Service // Add the user service
-	FileService       file.Service
-	FolderService     folders.Service
-	PermissionService permission.Service
-	ShareService      share.Service
-	TagService        tag.TagService
-	SearchService     search.Service
+	DB                   *gorm.DB
+	UserService          user.Service // Add the user service
+	FileService          file.Service
+	FolderService        folders.Service
+	PermissionService    permission.Service
+	ShareService         share.Service
+	TagService           tag.TagService
+	SearchService        search.Service
+	GroupService         group.Service
+	AccessRequestService accessrequest.Service
+	OwnershipService     ownership.Service
+	ActivityService      activity.Service
+	SavedSearchService   savedsearch.Service
+	RuleService          rules.Service
+	MetadataService      metadata.Service
+	DedupService         dedup.Service
 }
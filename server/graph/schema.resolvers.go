@@ -8,15 +8,25 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"log"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/99designs/gqlgen/graphql"
 	"github.com/bhavyajaix/BalkanID-filevault/graph/generated"
 	"github.com/bhavyajaix/BalkanID-filevault/graph/model"
 	"github.com/bhavyajaix/BalkanID-filevault/internal/database"
+	"github.com/bhavyajaix/BalkanID-filevault/internal/dedup"
 	fileservice "github.com/bhavyajaix/BalkanID-filevault/internal/file"
+	"github.com/bhavyajaix/BalkanID-filevault/internal/folders"
+	"github.com/bhavyajaix/BalkanID-filevault/internal/loaders"
+	"github.com/bhavyajaix/BalkanID-filevault/internal/metadata"
 	"github.com/bhavyajaix/BalkanID-filevault/internal/middleware"
+	"github.com/bhavyajaix/BalkanID-filevault/internal/pagination"
+	"github.com/bhavyajaix/BalkanID-filevault/internal/permission"
+	"github.com/bhavyajaix/BalkanID-filevault/internal/rules"
+	"github.com/bhavyajaix/BalkanID-filevault/internal/savedsearch"
 	"github.com/bhavyajaix/BalkanID-filevault/internal/search"
 	"github.com/bhavyajaix/BalkanID-filevault/pkg/auth"
 	"github.com/bhavyajaix/BalkanID-filevault/pkg/utils"
@@ -28,12 +38,10 @@ func toGqlResource(dbRes *database.Resource) (model.Resource, error) {
 		return nil, errors.New("cannot convert nil resource")
 	}
 
-	owner := &model.User{
-		ID:                       fmt.Sprint(dbRes.User.ID),
-		Username:                 dbRes.User.Username,
-		Email:                    dbRes.User.Email,
-		StorageUsed:              dbRes.User.StorageUsed,
-		DeduplicationStorageUsed: dbRes.User.DeduplicationStorageUsed,
+	// The owner is left for the field resolver to load unless it was preloaded.
+	var owner *model.User
+	if dbRes.User.ID != 0 {
+		owner = toGqlOwner(&dbRes.User)
 	}
 
 	shareToken := ""
@@ -53,15 +61,19 @@ func toGqlResource(dbRes *database.Resource) (model.Resource, error) {
 			gqlChildren = append(gqlChildren, gqlChild)
 		}
 		return &model.Folder{
-			ID:         fmt.Sprint(dbRes.ID),
-			Name:       dbRes.Name,
-			IsPublic:   dbRes.IsPublic,
-			Owner:      owner,
-			CreatedAt:  dbRes.CreatedAt.String(),
-			UpdatedAt:  dbRes.UpdatedAt.String(),
-			ShareToken: shareToken,
-			Children:   gqlChildren,
-			Type:       string(dbRes.Type),
+			ID:                 fmt.Sprint(dbRes.ID),
+			Name:               dbRes.Name,
+			IsPublic:           dbRes.IsPublic,
+			InheritPermissions: dbRes.InheritPermissions,
+			Owner:              owner,
+			CreatedAt:          dbRes.CreatedAt.String(),
+			UpdatedAt:          dbRes.UpdatedAt.String(),
+			ShareToken:         shareToken,
+			Children:           gqlChildren,
+			Type:               string(dbRes.Type),
+			Permissions:        toGqlPermissions(dbRes),
+			OwnerID:            dbRes.OwnerID,
+			ParentID:           dbRes.ParentID,
 		}, nil
 
 	case database.File:
@@ -73,16 +85,21 @@ func toGqlResource(dbRes *database.Resource) (model.Resource, error) {
 		}
 
 		return &model.File{
-			ID:         fmt.Sprint(dbRes.ID),
-			Name:       dbRes.Name,
-			IsPublic:   dbRes.IsPublic,
-			Owner:      owner,
-			CreatedAt:  dbRes.CreatedAt.String(),
-			UpdatedAt:  dbRes.UpdatedAt.String(),
-			Type:       string(dbRes.Type),
-			SizeBytes:  sizeBytes,
-			MimeType:   mimeType,
-			ShareToken: shareToken,
+			ID:                 fmt.Sprint(dbRes.ID),
+			Name:               dbRes.Name,
+			IsPublic:           dbRes.IsPublic,
+			InheritPermissions: dbRes.InheritPermissions,
+			Owner:              owner,
+			CreatedAt:          dbRes.CreatedAt.String(),
+			UpdatedAt:          dbRes.UpdatedAt.String(),
+			Type:               string(dbRes.Type),
+			SizeBytes:          sizeBytes,
+			MimeType:           mimeType,
+			ShareToken:         shareToken,
+			Permissions:        toGqlPermissions(dbRes),
+			OwnerID:            dbRes.OwnerID,
+			ParentID:           dbRes.ParentID,
+			PhysicalFileID:     dbRes.PhysicalFileID,
 		}, nil
 
 	default:
@@ -111,14 +128,15 @@ func toGqlPermission(dbRes *database.Resource) (model.Resource, error) {
 	switch dbRes.Type {
 	case database.Folder:
 		folder := &model.Folder{
-			ID:         fmt.Sprint(dbRes.ID),
-			Name:       dbRes.Name,
-			IsPublic:   dbRes.IsPublic,
-			Owner:      owner,
-			CreatedAt:  dbRes.CreatedAt.String(),
-			UpdatedAt:  dbRes.UpdatedAt.String(),
-			Type:       string(dbRes.Type),
-			ShareToken: shareToken,
+			ID:                 fmt.Sprint(dbRes.ID),
+			Name:               dbRes.Name,
+			IsPublic:           dbRes.IsPublic,
+			InheritPermissions: dbRes.InheritPermissions,
+			Owner:              owner,
+			CreatedAt:          dbRes.CreatedAt.String(),
+			UpdatedAt:          dbRes.UpdatedAt.String(),
+			Type:               string(dbRes.Type),
+			ShareToken:         shareToken,
 		}
 		return folder, nil
 	case database.File:
@@ -126,16 +144,17 @@ func toGqlPermission(dbRes *database.Resource) (model.Resource, error) {
 			return nil, fmt.Errorf("physical file data not preloaded for resource ID %d", dbRes.ID)
 		}
 		file := &model.File{
-			ID:         fmt.Sprint(dbRes.ID),
-			Name:       dbRes.Name,
-			IsPublic:   dbRes.IsPublic,
-			Owner:      owner,
-			CreatedAt:  dbRes.CreatedAt.String(),
-			UpdatedAt:  dbRes.UpdatedAt.String(),
-			Type:       string(dbRes.Type),
-			ShareToken: shareToken,
-			SizeBytes:  int(dbRes.PhysicalFile.SizeBytes),
-			MimeType:   string(dbRes.PhysicalFile.MimeType),
+			ID:                 fmt.Sprint(dbRes.ID),
+			Name:               dbRes.Name,
+			IsPublic:           dbRes.IsPublic,
+			InheritPermissions: dbRes.InheritPermissions,
+			Owner:              owner,
+			CreatedAt:          dbRes.CreatedAt.String(),
+			UpdatedAt:          dbRes.UpdatedAt.String(),
+			Type:               string(dbRes.Type),
+			ShareToken:         shareToken,
+			SizeBytes:          int(dbRes.PhysicalFile.SizeBytes),
+			MimeType:           string(dbRes.PhysicalFile.MimeType),
 		}
 		return file, nil
 	default:
@@ -150,283 +169,1772 @@ func getUserIDFromContext(ctx context.Context) (uint, error) {
 	return userID, nil
 }
 
-// Register is the resolver for the register field. (Unchanged)
-func (r *mutationResolver) Register(ctx context.Context, username string, email string, password string) (*model.AuthPayload, error) {
-	dbUser, err := r.UserService.Register(username, email, password)
-	if err != nil {
-		return nil, err
+// toGqlPermissions converts the user and group grants loaded on a resource.
+// It returns nil when no grants were loaded.
+func toGqlPermissions(dbRes *database.Resource) []*model.Permission {
+	if len(dbRes.Permissions) == 0 && len(dbRes.GroupPermissions) == 0 {
+		return nil
+	}
+
+	permissions := make([]*model.Permission, 0, len(dbRes.Permissions)+len(dbRes.GroupPermissions))
+	for _, p := range dbRes.Permissions {
+		permissions = append(permissions, &model.Permission{
+			PrincipalType: model.PrincipalTypeUser,
+			User:          toGqlUserSummary(&p.User),
+			Role:          model.Role(p.Role),
+			ExpiresAt:     formatOptionalTime(p.ExpiresAt),
+		})
+	}
+	for _, p := range dbRes.GroupPermissions {
+		permissions = append(permissions, &model.Permission{
+			PrincipalType: model.PrincipalTypeGroup,
+			Group:         toGqlGroup(&p.Group),
+			Role:          model.Role(p.Role),
+			ExpiresAt:     formatOptionalTime(p.ExpiresAt),
+		})
+	}
+	return permissions
+}
+
+// parseOptionalTime parses an optional RFC 3339 argument, naming the field in the error.
+func parseOptionalTime(value *string, field string) (*time.Time, error) {
+	if value == nil {
+		return nil, nil
 	}
-	token, err := auth.GenerateToken(dbUser.ID)
+	t, err := time.Parse(time.RFC3339, *value)
 	if err != nil {
-		return nil, fmt.Errorf("could not generate token after registration: %w", err)
+		return nil, fmt.Errorf("invalid %s: expected an RFC 3339 timestamp", field)
 	}
-	gqlUser := &model.User{
+	return &t, nil
+}
+
+func formatOptionalTime(t *time.Time) *string {
+	if t == nil {
+		return nil
+	}
+	formatted := t.Format(time.RFC3339)
+	return &formatted
+}
+
+// toGqlUserSummary converts a user for display next to someone else's data, leaving
+// out their role and storage figures.
+func toGqlUserSummary(dbUser *database.User) *model.User {
+	return &model.User{
 		ID:       fmt.Sprint(dbUser.ID),
 		Username: dbUser.Username,
 		Email:    dbUser.Email,
 	}
-	return &model.AuthPayload{
-		Token: token,
-		User:  gqlUser,
-	}, nil
 }
 
-// Login is the resolver for the login field. (Unchanged)
-func (r *mutationResolver) Login(ctx context.Context, email string, password string) (*model.AuthPayload, error) {
-	dbUser, token, err := r.UserService.Login(email, password)
+func toGqlOwnershipTransfer(dbTransfer *database.OwnershipTransfer) (*model.OwnershipTransfer, error) {
+	gqlResource, err := toGqlResource(&dbTransfer.Resource)
 	if err != nil {
 		return nil, err
 	}
-	gqlUser := &model.User{
-		ID:       fmt.Sprint(dbUser.ID),
-		Username: dbUser.Username,
-		Email:    dbUser.Email,
+
+	var decidedAt *string
+	if dbTransfer.DecidedAt != nil {
+		formatted := dbTransfer.DecidedAt.String()
+		decidedAt = &formatted
 	}
-	return &model.AuthPayload{
-		Token: token,
-		User:  gqlUser,
+
+	return &model.OwnershipTransfer{
+		ID:               fmt.Sprint(dbTransfer.ID),
+		Resource:         gqlResource,
+		FromUser:         toGqlUserSummary(&dbTransfer.FromUser),
+		ToUser:           toGqlUserSummary(&dbTransfer.ToUser),
+		KeepEditorAccess: dbTransfer.KeepEditorAccess,
+		Status:           model.OwnershipTransferStatus(dbTransfer.Status),
+		CreatedAt:        dbTransfer.CreatedAt.String(),
+		DecidedAt:        decidedAt,
 	}, nil
 }
 
-// UploadFile is the resolver for the uploadFile field.
-func (r *mutationResolver) UploadFile(ctx context.Context, file graphql.Upload, parentID *string) (*model.File, error) {
-	userID, err := getUserIDFromContext(ctx)
+func toGqlAccessRequest(dbReq *database.AccessRequest) (*model.AccessRequest, error) {
+	gqlResource, err := toGqlResource(&dbReq.Resource)
 	if err != nil {
 		return nil, err
 	}
 
-	var pID *uint
-	if parentID != nil {
-		id, err := utils.StringToUint(*parentID)
-		if err != nil {
-			return nil, errors.New("invalid parentId format")
-		}
-		pID = &id
+	var decidedAt *string
+	if dbReq.DecidedAt != nil {
+		formatted := dbReq.DecidedAt.String()
+		decidedAt = &formatted
 	}
 
-	// Simply create the params struct and pass the arguments directly
-	uploadParams := fileservice.UploadParams{
-		Upload:   file, // Pass the whole file object
-		OwnerID:  userID,
-		ParentID: pID,
+	return &model.AccessRequest{
+		ID:        fmt.Sprint(dbReq.ID),
+		Resource:  gqlResource,
+		Requester: toGqlUserSummary(&dbReq.Requester),
+		Role:      model.Role(dbReq.Role),
+		Message:   dbReq.Message,
+		Status:    model.AccessRequestStatus(dbReq.Status),
+		CreatedAt: dbReq.CreatedAt.String(),
+		DecidedAt: decidedAt,
+	}, nil
+}
+
+func toGqlGroup(dbGroup *database.Group) *model.Group {
+	members := make([]*model.GroupMember, 0, len(dbGroup.Members))
+	for _, m := range dbGroup.Members {
+		members = append(members, &model.GroupMember{
+			User:    toGqlUserSummary(&m.User),
+			IsAdmin: m.IsAdmin,
+			AddedAt: m.CreatedAt.String(),
+		})
 	}
 
-	dbResource, err := r.FileService.UploadFile(uploadParams)
-	if err != nil {
-		return nil, err
+	return &model.Group{
+		ID:        fmt.Sprint(dbGroup.ID),
+		Name:      dbGroup.Name,
+		Owner:     toGqlUserSummary(&dbGroup.Owner),
+		Members:   members,
+		CreatedAt: dbGroup.CreatedAt.String(),
+		UpdatedAt: dbGroup.UpdatedAt.String(),
 	}
+}
 
-	gqlResource, err := toGqlResource(dbResource)
+func toGqlEffectivePermission(access *permission.EffectiveAccess) *model.EffectivePermission {
+	entry := &model.EffectivePermission{
+		User:   toGqlUserSummary(&access.User),
+		Role:   model.Role(access.Role),
+		Source: model.AccessSourceOwner,
+	}
+	if access.Grant == nil {
+		return entry
+	}
+
+	grantedOnID := fmt.Sprint(access.Grant.ResourceID)
+	entry.GrantedOnID = &grantedOnID
+	entry.GrantedOnName = &access.Grant.ResourceName
+	entry.Inherited = access.Grant.Depth > 0
+	entry.ExpiresAt = formatOptionalTime(access.Grant.ExpiresAt)
+	entry.Source = model.AccessSourceUserGrant
+	if access.Grant.GroupID != nil {
+		entry.Source = model.AccessSourceGroupGrant
+		entry.Group = toGqlGroup(&database.Group{Model: gorm.Model{ID: *access.Grant.GroupID}, Name: access.Grant.GroupName})
+	}
+	return entry
+}
+
+// loadBreadcrumbs resolves the trail leading to the resource with the given GraphQL ID.
+func loadBreadcrumbs(ctx context.Context, id string) ([]database.Resource, error) {
+	resID, err := utils.StringToUint(id)
 	if err != nil {
-		return nil, err
+		return nil, errors.New("invalid id format")
+	}
+	trail, _, err := loaders.For(ctx).Breadcrumbs.Load(ctx, resID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load breadcrumbs: %w", err)
 	}
+	return trail, nil
+}
 
-	gqlFile, ok := gqlResource.(*model.File)
-	if !ok {
-		return nil, errors.New("internal error: created resource was not a file")
+// maxBulkItems caps the number of IDs a single bulk mutation accepts.
+const maxBulkItems = 500
+
+// parseBulkIDs checks the size of a bulk request and parses its IDs.
+func parseBulkIDs(ids []string) ([]uint, error) {
+	if len(ids) == 0 {
+		return nil, errors.New("at least one id is required")
+	}
+	if len(ids) > maxBulkItems {
+		return nil, fmt.Errorf("at most %d ids can be processed at once", maxBulkItems)
+	}
+	parsed := make([]uint, len(ids))
+	for i, id := range ids {
+		resID, err := utils.StringToUint(id)
+		if err != nil {
+			return nil, fmt.Errorf("invalid id format: %q", id)
+		}
+		parsed[i] = resID
 	}
+	return parsed, nil
+}
 
-	return gqlFile, nil
+// toGqlBulkResult pairs each requested ID with its outcome. resources may be nil when
+// the operation has nothing to return, as for deletions.
+func toGqlBulkResult(ids []uint, resources []*database.Resource, errs []error) (*model.BulkResult, error) {
+	result := &model.BulkResult{Results: make([]*model.BulkItemResult, len(ids))}
+	for i, id := range ids {
+		item := &model.BulkItemResult{ID: fmt.Sprint(id), Success: errs[i] == nil}
+		if errs[i] != nil {
+			message := errs[i].Error()
+			item.Error = &message
+			result.FailureCount++
+		} else {
+			result.SuccessCount++
+			if resources != nil && resources[i] != nil {
+				gqlResource, err := toGqlResource(resources[i])
+				if err != nil {
+					return nil, err
+				}
+				item.Resource = gqlResource
+			}
+		}
+		result.Results[i] = item
+	}
+	return result, nil
 }
 
-// CreateFolder is the resolver for the createFolder field.
-func (r *mutationResolver) CreateFolder(ctx context.Context, name string, parentID *string) (*model.Folder, error) {
-	var pID *uint
-	if parentID != nil {
-		id, err := utils.StringToUint(*parentID)
+// toPageRequest converts Relay-style arguments into a pagination request. sort is nil
+// only when a caller bypasses the schema defaults; fallback is used then.
+func toPageRequest(first *int, after *string, sort *model.ResourceSort, fallback pagination.Sort) (pagination.Request, error) {
+	req := pagination.Request{After: after, Sort: fallback}
+	if first != nil {
+		if *first <= 0 {
+			return req, errors.New("first must be positive")
+		}
+		req.First = *first
+	}
+	if sort != nil {
+		req.Sort = pagination.Sort{
+			Field:        pagination.SortField(sort.Field.String()),
+			Descending:   sort.Direction == model.SortDirectionDesc,
+			FoldersFirst: sort.FoldersFirst,
+		}
+	}
+	return req, req.Normalize()
+}
+
+func toGqlResourceConnection(page *pagination.Page) (*model.ResourceConnection, error) {
+	conn := &model.ResourceConnection{
+		Edges: make([]*model.ResourceEdge, 0, len(page.Resources)),
+		PageInfo: &model.PageInfo{
+			HasNextPage:     page.HasNextPage,
+			HasPreviousPage: page.HasPreviousPage,
+		},
+		TotalCount: int(page.TotalCount),
+	}
+	for i := range page.Resources {
+		node, err := toGqlResource(&page.Resources[i])
 		if err != nil {
-			return nil, errors.New("invalid parentId")
+			return nil, err
 		}
-		pID = &id
+		edge := &model.ResourceEdge{Cursor: page.Cursors[i], Node: node}
+		if snippet, ok := page.Snippets[page.Resources[i].ID]; ok {
+			edge.Snippet = &snippet
+		}
+		conn.Edges = append(conn.Edges, edge)
 	}
+	if len(conn.Edges) > 0 {
+		conn.PageInfo.StartCursor = &conn.Edges[0].Cursor
+		conn.PageInfo.EndCursor = &conn.Edges[len(conn.Edges)-1].Cursor
+	}
+	return conn, nil
+}
 
-	dbFolder, err := r.FolderService.CreateFolder(ctx, name, pID)
-	if err != nil {
-		return nil, err
+// toConflictStrategy maps the optional GraphQL argument onto the service strategy,
+// failing on conflicts when it is omitted.
+func toConflictStrategy(strategy *model.ConflictStrategy) folders.ConflictStrategy {
+	if strategy == nil {
+		return folders.ConflictFail
 	}
+	return folders.ConflictStrategy(strategy.String())
+}
 
-	// We know this is a folder, so we can cast it.
-	gqlFolder, err := toGqlResource(dbFolder)
-	if err != nil {
-		return nil, err
+func toGqlBreadcrumbs(trail []database.Resource) []*model.Breadcrumb {
+	crumbs := make([]*model.Breadcrumb, 0, len(trail))
+	for _, step := range trail {
+		crumbs = append(crumbs, &model.Breadcrumb{ID: fmt.Sprint(step.ID), Name: step.Name})
 	}
-	return gqlFolder.(*model.Folder), nil
+	return crumbs
 }
 
-// RenameFile is the resolver for the renameFile field.
-func (r *mutationResolver) RenameFile(ctx context.Context, id string, newName string) (*model.File, error) {
-	userID, err := getUserIDFromContext(ctx)
-	if err != nil {
-		return nil, err
+func breadcrumbPath(trail []database.Resource) string {
+	names := make([]string, 0, len(trail))
+	for _, step := range trail {
+		names = append(names, step.Name)
 	}
+	return "/" + strings.Join(names, "/")
+}
 
-	resID, err := utils.StringToUint(id)
+// loadSubtreeStats resolves the aggregate figures for the folder with the given GraphQL ID.
+func loadSubtreeStats(ctx context.Context, id string) (*loaders.SubtreeStats, error) {
+	folderID, err := utils.StringToUint(id)
 	if err != nil {
 		return nil, errors.New("invalid id format")
 	}
+	stats, _, err := loaders.For(ctx).SubtreeStats.Load(ctx, folderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute folder stats: %w", err)
+	}
+	return stats, nil
+}
+
+// toGqlOwner converts the owner of a resource.
+func toGqlOwner(dbUser *database.User) *model.User {
+	return &model.User{
+		ID:                       fmt.Sprint(dbUser.ID),
+		Username:                 dbUser.Username,
+		Email:                    dbUser.Email,
+		StorageUsed:              dbUser.StorageUsed,
+		DeduplicationStorageUsed: dbUser.DeduplicationStorageUsed,
+	}
+}
 
-	dbResource, err := r.FileService.RenameFile(resID, userID, newName)
+// loadOwner resolves the owner of a resource, unless toGqlResource already had it.
+func loadOwner(ctx context.Context, owner *model.User, ownerID uint) (*model.User, error) {
+	if owner != nil {
+		return owner, nil
+	}
+	dbUser, found, err := loaders.For(ctx).Users.Load(ctx, ownerID)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("failed to load owner: %w", err)
 	}
+	if !found {
+		return nil, errors.New("owner not found")
+	}
+	return toGqlOwner(dbUser), nil
+}
 
-	gqlResource, err := toGqlResource(dbResource)
+// loadParent resolves the folder a resource lives in. It is null for root resources
+// and for parents the caller cannot read.
+func loadParent(ctx context.Context, parentID *uint) (*model.Folder, error) {
+	if parentID == nil {
+		return nil, nil
+	}
+	dbParent, found, err := loaders.For(ctx).Parents.Load(ctx, *parentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load parent: %w", err)
+	}
+	if !found {
+		return nil, nil
+	}
+	gqlParent, err := toGqlResource(dbParent)
 	if err != nil {
 		return nil, err
 	}
-
-	gqlFile, ok := gqlResource.(*model.File)
+	folder, ok := gqlParent.(*model.Folder)
 	if !ok {
-		return nil, errors.New("internal error: renamed resource was not a file")
+		return nil, fmt.Errorf("parent of a resource is a %s", dbParent.Type)
 	}
-
-	return gqlFile, nil
+	return folder, nil
 }
 
-// DeleteFile is the resolver for the deleteFile field.
-func (r *mutationResolver) DeleteFile(ctx context.Context, id string) (bool, error) {
+// loadPermissions resolves the explicit grants on a resource. Grants are only shown to
+// the owner; the grant mutations attach them up front.
+func loadPermissions(ctx context.Context, id string, ownerID uint, attached []*model.Permission) ([]*model.Permission, error) {
+	if attached != nil {
+		return attached, nil
+	}
 	userID, err := getUserIDFromContext(ctx)
+	if err != nil || userID != ownerID {
+		return nil, nil
+	}
+	resourceID, err := utils.StringToUint(id)
 	if err != nil {
-		return false, err
+		return nil, errors.New("invalid id format")
+	}
+	grants, _, err := loaders.For(ctx).Grants.Load(ctx, resourceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load permissions: %w", err)
 	}
+	permissions := toGqlPermissions(&database.Resource{Permissions: grants.Users, GroupPermissions: grants.Groups})
+	if permissions == nil {
+		permissions = []*model.Permission{}
+	}
+	return permissions, nil
+}
 
-	resID, err := utils.StringToUint(id)
+// loadTags resolves the tags attached to a resource.
+func loadTags(ctx context.Context, id string) ([]*model.Tag, error) {
+	resourceID, err := utils.StringToUint(id)
 	if err != nil {
-		return false, errors.New("invalid id format")
+		return nil, errors.New("invalid id format")
+	}
+	dbTags, _, err := loaders.For(ctx).Tags.Load(ctx, resourceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load tags: %w", err)
+	}
+	tags := make([]*model.Tag, 0, len(dbTags))
+	for _, tag := range dbTags {
+		tags = append(tags, toGqlTag(tag))
 	}
+	return tags, nil
+}
 
-	err = r.FileService.DeleteFile(resID, userID)
-	return err == nil, err
+func toGqlTag(dbTag *database.Tag) *model.Tag {
+	var groupID *string
+	if dbTag.GroupID != nil {
+		id := fmt.Sprint(*dbTag.GroupID)
+		groupID = &id
+	}
+	return &model.Tag{
+		ID:          fmt.Sprint(dbTag.ID),
+		Name:        dbTag.Name,
+		Color:       dbTag.Color,
+		Description: dbTag.Description,
+		GroupID:     groupID,
+		CreatedAt:   dbTag.CreatedAt.String(),
+		UpdatedAt:   dbTag.UpdatedAt.String(),
+	}
 }
 
-// MoveFile is the resolver for the moveFile field.
-func (r *mutationResolver) MoveFile(ctx context.Context, fileID string, newParentID *string) (*model.File, error) {
-	userID, err := getUserIDFromContext(ctx)
+// loadMetadata resolves the metadata values on a resource that the viewer can see.
+func loadMetadata(ctx context.Context, id string) ([]*model.MetadataValue, error) {
+	resourceID, err := utils.StringToUint(id)
 	if err != nil {
-		return nil, err
+		return nil, errors.New("invalid id format")
 	}
-
-	resID, err := utils.StringToUint(fileID)
+	values, _, err := loaders.For(ctx).Metadata.Load(ctx, resourceID)
 	if err != nil {
-		return nil, errors.New("invalid fileId format")
+		return nil, fmt.Errorf("failed to load metadata: %w", err)
 	}
-
-	var parentID *uint
-	if newParentID != nil {
-		pID, err := utils.StringToUint(*newParentID)
+	gqlValues := make([]*model.MetadataValue, 0, len(values))
+	for _, value := range values {
+		field, err := toGqlMetadataField(&value.MetadataField)
 		if err != nil {
-			return nil, errors.New("invalid newParentId format")
+			return nil, err
 		}
-		parentID = &pID
+		gqlValues = append(gqlValues, &model.MetadataValue{Field: field, Value: value.Value})
 	}
+	return gqlValues, nil
+}
 
-	dbResource, err := r.FileService.MoveFile(resID, userID, parentID)
+func toGqlMetadataField(field *database.MetadataField) (*model.MetadataField, error) {
+	options, err := metadata.Options(field)
 	if err != nil {
 		return nil, err
 	}
+	if options == nil {
+		options = []string{}
+	}
+	var groupID *string
+	if field.GroupID != nil {
+		id := fmt.Sprint(*field.GroupID)
+		groupID = &id
+	}
+	return &model.MetadataField{
+		ID:        fmt.Sprint(field.ID),
+		Name:      field.Name,
+		Type:      model.MetadataFieldType(field.Type),
+		Required:  field.Required,
+		Options:   options,
+		GroupID:   groupID,
+		CreatedAt: field.CreatedAt.Format(time.RFC3339),
+	}, nil
+}
+
+// parseGroupID parses the optional group a tag operation targets.
+func parseGroupID(groupID *string) (*uint, error) {
+	if groupID == nil {
+		return nil, nil
+	}
+	id, err := utils.StringToUint(*groupID)
+	if err != nil {
+		return nil, errors.New("invalid groupId")
+	}
+	return &id, nil
+}
 
-	gqlResource, err := toGqlResource(dbResource)
+// toGqlStorageStats reports how much of a deduplicated file's blob is attributed to
+// one of its copies: every resource sharing the blob carries an equal part of it.
+func toGqlStorageStats(physical *database.PhysicalFile) *model.StorageStats {
+	copies := int64(physical.ReferenceCount)
+	if copies < 1 {
+		copies = 1
+	}
+	original := physical.SizeBytes
+	deduplicated := original / copies
+	stats := &model.StorageStats{
+		OriginalSizeBytes:     int(original),
+		DeduplicatedSizeBytes: int(deduplicated),
+		SavedBytes:            int(original - deduplicated),
+		ReferenceCount:        physical.ReferenceCount,
+	}
+	if original > 0 {
+		stats.SavedPercentage = float64(original-deduplicated) / float64(original) * 100
+	}
+	return stats
+}
+
+// toGqlFile converts a resource known to be a file.
+func toGqlFile(dbRes *database.Resource) (*model.File, error) {
+	gqlResource, err := toGqlResource(dbRes)
 	if err != nil {
 		return nil, err
 	}
-
 	gqlFile, ok := gqlResource.(*model.File)
 	if !ok {
-		return nil, errors.New("internal error: moved resource was not a file")
+		return nil, fmt.Errorf("internal error: resource %d is not a file", dbRes.ID)
 	}
-
 	return gqlFile, nil
 }
 
-// RenameFolder is the resolver for the renameFolder field.
-func (r *mutationResolver) RenameFolder(ctx context.Context, id string, newName string) (*model.Folder, error) {
-	// 1. Convert the string ID to a uint
-	resID, err := utils.StringToUint(id)
-	if err != nil {
-		return nil, errors.New("invalid id format")
+func toGqlDuplicateGroup(group dedup.Group) (*model.DuplicateGroup, error) {
+	gqlGroup := &model.DuplicateGroup{
+		FileHash:    group.Content.FileHash,
+		SizeBytes:   int(group.Content.SizeBytes),
+		MimeType:    group.Content.MimeType,
+		Files:       make([]*model.File, 0, len(group.Files)),
+		WastedBytes: int(group.WastedBytes()),
 	}
-	// 2. Call the service to perform the business logic
-	// Note: I've corrected this to call ResourceService
-	dbResource, err := r.FolderService.RenameResource(ctx, resID, newName)
-	if err != nil {
-		return nil, err
+	for i := range group.Files {
+		gqlFile, err := toGqlFile(&group.Files[i])
+		if err != nil {
+			return nil, err
+		}
+		gqlGroup.Files = append(gqlGroup.Files, gqlFile)
 	}
+	return gqlGroup, nil
+}
 
-	// 3. Convert the updated database model to the generic GraphQL interface
-	gqlResource, err := toGqlResource(dbResource)
-	if err != nil {
-		return nil, err
+// toSavedSearchFilters maps the GraphQL search input onto the form searches are saved
+// in, which also knows how to turn itself into a search.
+func toSavedSearchFilters(filters *model.SearchFilters) (savedsearch.Filters, error) {
+	f := savedsearch.Filters{
+		Name:           filters.Name,
+		NameSimilarity: filters.NameSimilarity,
+		Types:          filters.Types,
+		MimeTypes:      filters.MimeTypes,
+		AfterDate:      filters.AfterDate,
+		BeforeDate:     filters.BeforeDate,
+		Tags:           filters.Tags,
+		UploaderName:   filters.UploaderName,
+		Query:          filters.Query,
 	}
-
-	// 4. Assert that the returned resource is specifically a Folder
-	gqlFolder, ok := gqlResource.(*model.Folder)
-	if !ok {
-		// This is an internal error; your service returned a file when we expected a folder.
-		return nil, errors.New("error: expected a folder but received a different type")
+	if filters.MinSizeBytes != nil {
+		minSize := int64(*filters.MinSizeBytes)
+		f.MinSizeBytes = &minSize
 	}
-	return gqlFolder, nil
+	if filters.MaxSizeBytes != nil {
+		maxSize := int64(*filters.MaxSizeBytes)
+		f.MaxSizeBytes = &maxSize
+	}
+	if filters.FolderID != nil {
+		folderID, err := strconv.ParseUint(*filters.FolderID, 10, 64)
+		if err != nil {
+			return f, fmt.Errorf("invalid folder ID format")
+		}
+		id := uint(folderID)
+		f.FolderID = &id
+	}
+	for _, m := range filters.Metadata {
+		fieldID, err := utils.StringToUint(m.FieldID)
+		if err != nil {
+			return f, errors.New("invalid metadata fieldId format")
+		}
+		f.Metadata = append(f.Metadata, savedsearch.MetadataFilter{FieldID: fieldID, Op: string(m.Op), Value: m.Value})
+	}
+	return f, nil
 }
 
-// DeleteFolder is the resolver for the deleteFolder field.
-func (r *mutationResolver) DeleteFolder(ctx context.Context, id string) (bool, error) {
-	resID, err := utils.StringToUint(id)
+// searchFilters picks the filters of a search: those passed in, or those of the saved
+// search, which the caller must be able to see.
+func (r *Resolver) searchFilters(ctx context.Context, filters *model.SearchFilters, savedSearchID *string) (savedsearch.Filters, error) {
+	if (filters == nil) == (savedSearchID == nil) {
+		return savedsearch.Filters{}, errors.New("pass either filters or savedSearchId")
+	}
+	if filters != nil {
+		return toSavedSearchFilters(filters)
+	}
+	id, err := strconv.ParseUint(*savedSearchID, 10, 64)
 	if err != nil {
-		return false, errors.New("invalid id format")
+		return savedsearch.Filters{}, fmt.Errorf("invalid saved search ID format")
 	}
-	err = r.FolderService.DeleteResource(ctx, resID)
-	return err == nil, err
+	return r.SavedSearchService.Filters(ctx, uint(id))
 }
 
-// MoveFolder is the resolver for the moveFolder field.
-func (r *mutationResolver) MoveFolder(ctx context.Context, folderID string, newParentID *string) (*model.Folder, error) {
-	// 1. Convert the required folderID
-	resID, err := utils.StringToUint(folderID)
+// runSearch runs a search for the caller. Best matches come first for full-text and
+// fuzzy name searches and the newest resources otherwise, unless the caller asks for another order.
+func runSearch(ctx context.Context, searchService search.Service, filters savedsearch.Filters, first *int, after *string, sort *model.ResourceSort) (*model.ResourceConnection, error) {
+	serviceFilters, err := filters.Resolve()
 	if err != nil {
-		return nil, errors.New("invalid folderId format")
+		return nil, err
 	}
 
-	// 2. Handle the optional newParentID
-	var parentID *uint
-	if newParentID != nil {
-		pID, err := utils.StringToUint(*newParentID)
-		if err != nil {
-			return nil, errors.New("invalid newParentId format")
-		}
-		parentID = &pID
+	fallback := pagination.Sort{Field: pagination.SortByCreatedAt, Descending: true}
+	if (filters.Query != nil && strings.TrimSpace(*filters.Query) != "") || filters.NameSimilarity != nil {
+		fallback = pagination.Sort{Field: pagination.SortByRelevance, Descending: true}
 	}
-
-	// 3. Call the service (Corrected to ResourceService)
-	dbResource, err := r.FolderService.MoveResource(ctx, resID, parentID)
+	req, err := toPageRequest(first, after, sort, fallback)
 	if err != nil {
 		return nil, err
 	}
 
-	// 4. Convert the result to the generic interface
-	gqlResource, err := toGqlResource(dbResource)
+	page, err := searchService.Search(ctx, serviceFilters, req)
 	if err != nil {
 		return nil, err
 	}
+	return toGqlResourceConnection(page)
+}
 
-	// 5. Assert that the interface value is specifically a *model.Folder
-	gqlFolder, ok := gqlResource.(*model.Folder)
-	if !ok {
-		return nil, errors.New("error: expected a folder but received a different type")
+func toGqlFacetBuckets(buckets []search.FacetBucket) []*model.FacetBucket {
+	result := make([]*model.FacetBucket, 0, len(buckets))
+	for _, bucket := range buckets {
+		result = append(result, &model.FacetBucket{Label: bucket.Label, Filter: bucket.Filter, Count: int(bucket.Count)})
 	}
-
-	return gqlFolder, nil
+	return result
 }
 
-// GrantPermission is the resolver for the grantPermission field.
-func (r *mutationResolver) GrantPermission(ctx context.Context, resourceID string, email string, role model.Role) (model.Resource, error) {
-	resID, err := utils.StringToUint(resourceID)
+// toGqlSavedSearch converts a saved search for the viewer. Only the owner sees who it
+// is shared with.
+func toGqlSavedSearch(saved *database.SavedSearch, viewerID uint) (*model.SavedSearch, error) {
+	filters, err := savedsearch.Decode(saved.Filters)
 	if err != nil {
-		return nil, errors.New("invalid resourceId format")
+		return nil, err
+	}
+	gqlSaved := &model.SavedSearch{
+		ID:         fmt.Sprint(saved.ID),
+		Name:       saved.Name,
+		Filters:    toGqlSavedSearchFilters(filters),
+		Owner:      toGqlUserSummary(&saved.Owner),
+		SharedWith: []*model.User{},
+		CreatedAt:  saved.CreatedAt.Format(time.RFC3339),
+	}
+	if saved.OwnerID == viewerID {
+		for i := range saved.SharedWith {
+			gqlSaved.SharedWith = append(gqlSaved.SharedWith, toGqlUserSummary(&saved.SharedWith[i]))
+		}
+	}
+	return gqlSaved, nil
+}
+
+// toRuleInput maps the GraphQL rule input onto the rules service's.
+func toRuleInput(input model.RuleInput) (rules.Input, error) {
+	in := rules.Input{Name: input.Name, Enabled: input.Enabled == nil || *input.Enabled}
+
+	if c := input.Conditions; c != nil {
+		in.Conditions = rules.Conditions{
+			MimeTypes:         c.MimeTypes,
+			NameGlob:          c.NameGlob,
+			NameRegex:         c.NameRegex,
+			IncludeSubfolders: c.IncludeSubfolders != nil && *c.IncludeSubfolders,
+			Contains:          c.Contains,
+		}
+		if c.MinSizeBytes != nil {
+			minSize := int64(*c.MinSizeBytes)
+			in.Conditions.MinSizeBytes = &minSize
+		}
+		if c.MaxSizeBytes != nil {
+			maxSize := int64(*c.MaxSizeBytes)
+			in.Conditions.MaxSizeBytes = &maxSize
+		}
+		if c.FolderID != nil {
+			folderID, err := utils.StringToUint(*c.FolderID)
+			if err != nil {
+				return in, errors.New("invalid folderId format")
+			}
+			in.Conditions.FolderID = &folderID
+		}
+	}
+
+	if a := input.Actions; a != nil {
+		in.Actions = rules.Actions{AddTags: a.AddTags, MakePublic: a.MakePublic != nil && *a.MakePublic}
+		if a.MoveToFolderID != nil {
+			folderID, err := utils.StringToUint(*a.MoveToFolderID)
+			if err != nil {
+				return in, errors.New("invalid moveToFolderId format")
+			}
+			in.Actions.MoveToFolderID = &folderID
+		}
+	}
+	return in, nil
+}
+
+func toGqlRule(rule *database.Rule) (*model.Rule, error) {
+	def, err := rules.Decode(rule)
+	if err != nil {
+		return nil, err
+	}
+	c, a := def.Conditions, def.Actions
+	conditions := &model.RuleConditions{
+		MimeTypes:         c.MimeTypes,
+		NameGlob:          c.NameGlob,
+		NameRegex:         c.NameRegex,
+		IncludeSubfolders: c.IncludeSubfolders,
+		Contains:          c.Contains,
+	}
+	if conditions.MimeTypes == nil {
+		conditions.MimeTypes = []string{}
+	}
+	if c.MinSizeBytes != nil {
+		minSize := int(*c.MinSizeBytes)
+		conditions.MinSizeBytes = &minSize
+	}
+	if c.MaxSizeBytes != nil {
+		maxSize := int(*c.MaxSizeBytes)
+		conditions.MaxSizeBytes = &maxSize
+	}
+	if c.FolderID != nil {
+		folderID := fmt.Sprint(*c.FolderID)
+		conditions.FolderID = &folderID
+	}
+
+	actions := &model.RuleActions{AddTags: a.AddTags, MakePublic: a.MakePublic}
+	if actions.AddTags == nil {
+		actions.AddTags = []string{}
+	}
+	if a.MoveToFolderID != nil {
+		folderID := fmt.Sprint(*a.MoveToFolderID)
+		actions.MoveToFolderID = &folderID
+	}
+
+	return &model.Rule{
+		ID:         fmt.Sprint(rule.ID),
+		Name:       rule.Name,
+		Enabled:    rule.Enabled,
+		Conditions: conditions,
+		Actions:    actions,
+		CreatedAt:  rule.CreatedAt.Format(time.RFC3339),
+	}, nil
+}
+
+// parseOptionalRuleID parses the rule ID of the rule queries that default to all rules.
+func parseOptionalRuleID(ruleID *string) (*uint, error) {
+	if ruleID == nil {
+		return nil, nil
+	}
+	id, err := utils.StringToUint(*ruleID)
+	if err != nil {
+		return nil, errors.New("invalid ruleId format")
+	}
+	return &id, nil
+}
+
+func toGqlSavedSearchFilters(f savedsearch.Filters) *model.SavedSearchFilters {
+	gqlFilters := &model.SavedSearchFilters{
+		Name:           f.Name,
+		NameSimilarity: f.NameSimilarity,
+		Types:          f.Types,
+		MimeTypes:      f.MimeTypes,
+		AfterDate:      f.AfterDate,
+		BeforeDate:     f.BeforeDate,
+		Tags:           f.Tags,
+		UploaderName:   f.UploaderName,
+		Query:          f.Query,
+	}
+	if f.MinSizeBytes != nil {
+		minSize := int(*f.MinSizeBytes)
+		gqlFilters.MinSizeBytes = &minSize
+	}
+	if f.MaxSizeBytes != nil {
+		maxSize := int(*f.MaxSizeBytes)
+		gqlFilters.MaxSizeBytes = &maxSize
+	}
+	if f.FolderID != nil {
+		folderID := fmt.Sprint(*f.FolderID)
+		gqlFilters.FolderID = &folderID
+	}
+	gqlFilters.Metadata = make([]*model.SavedMetadataFilter, 0, len(f.Metadata))
+	for _, m := range f.Metadata {
+		gqlFilters.Metadata = append(gqlFilters.Metadata, &model.SavedMetadataFilter{
+			FieldID: fmt.Sprint(m.FieldID),
+			Op:      model.MetadataOperator(m.Op),
+			Value:   m.Value,
+		})
+	}
+	return gqlFilters
+}
+
+// Owner is the resolver for the owner field.
+func (r *fileResolver) Owner(ctx context.Context, obj *model.File) (*model.User, error) {
+	return loadOwner(ctx, obj.Owner, obj.OwnerID)
+}
+
+// Parent is the resolver for the parent field.
+func (r *fileResolver) Parent(ctx context.Context, obj *model.File) (*model.Folder, error) {
+	return loadParent(ctx, obj.ParentID)
+}
+
+// Permissions is the resolver for the permissions field.
+func (r *fileResolver) Permissions(ctx context.Context, obj *model.File) ([]*model.Permission, error) {
+	return loadPermissions(ctx, obj.ID, obj.OwnerID, obj.Permissions)
+}
+
+// Storage is the resolver for the storage field.
+func (r *fileResolver) Storage(ctx context.Context, obj *model.File) (*model.StorageStats, error) {
+	if obj.PhysicalFileID == nil {
+		return nil, errors.New("file has no stored content")
+	}
+	physical, found, err := loaders.For(ctx).PhysicalFiles.Load(ctx, *obj.PhysicalFileID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load storage details: %w", err)
+	}
+	if !found {
+		return nil, errors.New("stored content not found")
+	}
+	return toGqlStorageStats(physical), nil
+}
+
+// Tags is the resolver for the tags field.
+func (r *fileResolver) Tags(ctx context.Context, obj *model.File) ([]*model.Tag, error) {
+	return loadTags(ctx, obj.ID)
+}
+
+// Path is the resolver for the path field.
+func (r *fileResolver) Path(ctx context.Context, obj *model.File) (string, error) {
+	trail, err := loadBreadcrumbs(ctx, obj.ID)
+	if err != nil {
+		return "", err
+	}
+	return breadcrumbPath(trail), nil
+}
+
+// Breadcrumbs is the resolver for the breadcrumbs field.
+func (r *fileResolver) Breadcrumbs(ctx context.Context, obj *model.File) ([]*model.Breadcrumb, error) {
+	trail, err := loadBreadcrumbs(ctx, obj.ID)
+	if err != nil {
+		return nil, err
+	}
+	return toGqlBreadcrumbs(trail), nil
+}
+
+// Metadata is the resolver for the metadata field.
+func (r *fileResolver) Metadata(ctx context.Context, obj *model.File) ([]*model.MetadataValue, error) {
+	return loadMetadata(ctx, obj.ID)
+}
+
+// Owner is the resolver for the owner field.
+func (r *folderResolver) Owner(ctx context.Context, obj *model.Folder) (*model.User, error) {
+	return loadOwner(ctx, obj.Owner, obj.OwnerID)
+}
+
+// Parent is the resolver for the parent field.
+func (r *folderResolver) Parent(ctx context.Context, obj *model.Folder) (*model.Folder, error) {
+	return loadParent(ctx, obj.ParentID)
+}
+
+// Permissions is the resolver for the permissions field.
+func (r *folderResolver) Permissions(ctx context.Context, obj *model.Folder) ([]*model.Permission, error) {
+	return loadPermissions(ctx, obj.ID, obj.OwnerID, obj.Permissions)
+}
+
+// Tags is the resolver for the tags field.
+func (r *folderResolver) Tags(ctx context.Context, obj *model.Folder) ([]*model.Tag, error) {
+	return loadTags(ctx, obj.ID)
+}
+
+// Path is the resolver for the path field.
+func (r *folderResolver) Path(ctx context.Context, obj *model.Folder) (string, error) {
+	trail, err := loadBreadcrumbs(ctx, obj.ID)
+	if err != nil {
+		return "", err
+	}
+	return breadcrumbPath(trail), nil
+}
+
+// Breadcrumbs is the resolver for the breadcrumbs field.
+func (r *folderResolver) Breadcrumbs(ctx context.Context, obj *model.Folder) ([]*model.Breadcrumb, error) {
+	trail, err := loadBreadcrumbs(ctx, obj.ID)
+	if err != nil {
+		return nil, err
+	}
+	return toGqlBreadcrumbs(trail), nil
+}
+
+// Metadata is the resolver for the metadata field.
+func (r *folderResolver) Metadata(ctx context.Context, obj *model.Folder) ([]*model.MetadataValue, error) {
+	return loadMetadata(ctx, obj.ID)
+}
+
+// DescendantCount is the resolver for the descendantCount field.
+func (r *folderResolver) DescendantCount(ctx context.Context, obj *model.Folder) (int, error) {
+	stats, err := loadSubtreeStats(ctx, obj.ID)
+	if err != nil {
+		return 0, err
+	}
+	return int(stats.DescendantCount), nil
+}
+
+// TotalSizeBytes is the resolver for the totalSizeBytes field.
+func (r *folderResolver) TotalSizeBytes(ctx context.Context, obj *model.Folder) (int, error) {
+	stats, err := loadSubtreeStats(ctx, obj.ID)
+	if err != nil {
+		return 0, err
+	}
+	return int(stats.TotalSizeBytes), nil
+}
+
+// Register is the resolver for the register field. (Unchanged)
+func (r *mutationResolver) Register(ctx context.Context, username string, email string, password string) (*model.AuthPayload, error) {
+	dbUser, err := r.UserService.Register(username, email, password)
+	if err != nil {
+		return nil, err
+	}
+	token, err := auth.GenerateToken(dbUser.ID)
+	if err != nil {
+		return nil, fmt.Errorf("could not generate token after registration: %w", err)
+	}
+	gqlUser := &model.User{
+		ID:       fmt.Sprint(dbUser.ID),
+		Username: dbUser.Username,
+		Email:    dbUser.Email,
+	}
+	return &model.AuthPayload{
+		Token: token,
+		User:  gqlUser,
+	}, nil
+}
+
+// Login is the resolver for the login field. (Unchanged)
+func (r *mutationResolver) Login(ctx context.Context, email string, password string) (*model.AuthPayload, error) {
+	dbUser, token, err := r.UserService.Login(email, password)
+	if err != nil {
+		return nil, err
+	}
+	gqlUser := &model.User{
+		ID:       fmt.Sprint(dbUser.ID),
+		Username: dbUser.Username,
+		Email:    dbUser.Email,
+	}
+	return &model.AuthPayload{
+		Token: token,
+		User:  gqlUser,
+	}, nil
+}
+
+// UploadFile is the resolver for the uploadFile field.
+func (r *mutationResolver) UploadFile(ctx context.Context, file graphql.Upload, parentID *string, path *string, conflictStrategy *model.ConflictStrategy) (*model.File, error) {
+	userID, err := getUserIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if parentID != nil && path != nil {
+		return nil, errors.New("provide either parentId or path, not both")
+	}
+
+	var pID *uint
+	if parentID != nil {
+		id, err := utils.StringToUint(*parentID)
+		if err != nil {
+			return nil, errors.New("invalid parentId format")
+		}
+		pID = &id
+	}
+	if path != nil {
+		folder, err := r.FolderService.MkdirP(ctx, *path)
+		if err != nil {
+			return nil, err
+		}
+		if folder != nil {
+			pID = &folder.ID
+		}
+	}
+
+	// Simply create the params struct and pass the arguments directly
+	uploadParams := fileservice.UploadParams{
+		Upload:   file, // Pass the whole file object
+		OwnerID:  userID,
+		ParentID: pID,
+		Conflict: toConflictStrategy(conflictStrategy),
+	}
+
+	dbResource, err := r.FileService.UploadFile(uploadParams)
+	if err != nil {
+		return nil, err
+	}
+
+	// The upload is committed by now, so the owner's rules can't undo it if they fail.
+	if applied, err := r.RuleService.ApplyToUpload(ctx, dbResource); err != nil {
+		log.Printf("failed to apply rules to resource %d: %v", dbResource.ID, err)
+	} else {
+		dbResource = applied
+	}
+
+	gqlResource, err := toGqlResource(dbResource)
+	if err != nil {
+		return nil, err
+	}
+
+	gqlFile, ok := gqlResource.(*model.File)
+	if !ok {
+		return nil, errors.New("internal error: created resource was not a file")
+	}
+
+	return gqlFile, nil
+}
+
+// CreateFolder is the resolver for the createFolder field.
+func (r *mutationResolver) CreateFolder(ctx context.Context, name string, parentID *string, conflictStrategy *model.ConflictStrategy) (*model.Folder, error) {
+	var pID *uint
+	if parentID != nil {
+		id, err := utils.StringToUint(*parentID)
+		if err != nil {
+			return nil, errors.New("invalid parentId")
+		}
+		pID = &id
+	}
+
+	dbFolder, err := r.FolderService.CreateFolder(ctx, name, pID, toConflictStrategy(conflictStrategy))
+	if err != nil {
+		return nil, err
+	}
+
+	// We know this is a folder, so we can cast it.
+	gqlFolder, err := toGqlResource(dbFolder)
+	if err != nil {
+		return nil, err
+	}
+	return gqlFolder.(*model.Folder), nil
+}
+
+// MkdirP is the resolver for the mkdirP field.
+func (r *mutationResolver) MkdirP(ctx context.Context, path string) (*model.Folder, error) {
+	dbFolder, err := r.FolderService.MkdirP(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	if dbFolder == nil {
+		return nil, errors.New("path must name a folder")
+	}
+
+	gqlFolder, err := toGqlResource(dbFolder)
+	if err != nil {
+		return nil, err
+	}
+	return gqlFolder.(*model.Folder), nil
+}
+
+// RenameFile is the resolver for the renameFile field.
+func (r *mutationResolver) RenameFile(ctx context.Context, id string, newName string, conflictStrategy *model.ConflictStrategy) (*model.File, error) {
+	userID, err := getUserIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	resID, err := utils.StringToUint(id)
+	if err != nil {
+		return nil, errors.New("invalid id format")
+	}
+
+	dbResource, err := r.FileService.RenameFile(resID, userID, newName, toConflictStrategy(conflictStrategy))
+	if err != nil {
+		return nil, err
+	}
+
+	gqlResource, err := toGqlResource(dbResource)
+	if err != nil {
+		return nil, err
+	}
+
+	gqlFile, ok := gqlResource.(*model.File)
+	if !ok {
+		return nil, errors.New("internal error: renamed resource was not a file")
+	}
+
+	return gqlFile, nil
+}
+
+// DeleteFile is the resolver for the deleteFile field.
+func (r *mutationResolver) DeleteFile(ctx context.Context, id string) (bool, error) {
+	userID, err := getUserIDFromContext(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	resID, err := utils.StringToUint(id)
+	if err != nil {
+		return false, errors.New("invalid id format")
+	}
+
+	err = r.FileService.DeleteFile(resID, userID)
+	return err == nil, err
+}
+
+// MoveFile is the resolver for the moveFile field.
+func (r *mutationResolver) MoveFile(ctx context.Context, fileID string, newParentID *string, conflictStrategy *model.ConflictStrategy) (*model.File, error) {
+	userID, err := getUserIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	resID, err := utils.StringToUint(fileID)
+	if err != nil {
+		return nil, errors.New("invalid fileId format")
+	}
+
+	var parentID *uint
+	if newParentID != nil {
+		pID, err := utils.StringToUint(*newParentID)
+		if err != nil {
+			return nil, errors.New("invalid newParentId format")
+		}
+		parentID = &pID
+	}
+
+	dbResource, err := r.FileService.MoveFile(resID, userID, parentID, toConflictStrategy(conflictStrategy))
+	if err != nil {
+		return nil, err
+	}
+
+	gqlResource, err := toGqlResource(dbResource)
+	if err != nil {
+		return nil, err
+	}
+
+	gqlFile, ok := gqlResource.(*model.File)
+	if !ok {
+		return nil, errors.New("internal error: moved resource was not a file")
+	}
+
+	return gqlFile, nil
+}
+
+// RenameFolder is the resolver for the renameFolder field.
+func (r *mutationResolver) RenameFolder(ctx context.Context, id string, newName string, conflictStrategy *model.ConflictStrategy) (*model.Folder, error) {
+	// 1. Convert the string ID to a uint
+	resID, err := utils.StringToUint(id)
+	if err != nil {
+		return nil, errors.New("invalid id format")
+	}
+	// 2. Call the service to perform the business logic
+	// Note: I've corrected this to call ResourceService
+	dbResource, err := r.FolderService.RenameResource(ctx, resID, newName, toConflictStrategy(conflictStrategy))
+	if err != nil {
+		return nil, err
+	}
+
+	// 3. Convert the updated database model to the generic GraphQL interface
+	gqlResource, err := toGqlResource(dbResource)
+	if err != nil {
+		return nil, err
+	}
+
+	// 4. Assert that the returned resource is specifically a Folder
+	gqlFolder, ok := gqlResource.(*model.Folder)
+	if !ok {
+		// This is an internal error; your service returned a file when we expected a folder.
+		return nil, errors.New("error: expected a folder but received a different type")
+	}
+	return gqlFolder, nil
+}
+
+// DeleteFolder is the resolver for the deleteFolder field.
+func (r *mutationResolver) DeleteFolder(ctx context.Context, id string) (bool, error) {
+	resID, err := utils.StringToUint(id)
+	if err != nil {
+		return false, errors.New("invalid id format")
+	}
+	err = r.FolderService.DeleteResource(ctx, resID)
+	return err == nil, err
+}
+
+// MoveFolder is the resolver for the moveFolder field.
+func (r *mutationResolver) MoveFolder(ctx context.Context, folderID string, newParentID *string, conflictStrategy *model.ConflictStrategy) (*model.Folder, error) {
+	// 1. Convert the required folderID
+	resID, err := utils.StringToUint(folderID)
+	if err != nil {
+		return nil, errors.New("invalid folderId format")
+	}
+
+	// 2. Handle the optional newParentID
+	var parentID *uint
+	if newParentID != nil {
+		pID, err := utils.StringToUint(*newParentID)
+		if err != nil {
+			return nil, errors.New("invalid newParentId format")
+		}
+		parentID = &pID
+	}
+
+	// 3. Call the service (Corrected to ResourceService)
+	dbResource, err := r.FolderService.MoveResource(ctx, resID, parentID, toConflictStrategy(conflictStrategy))
+	if err != nil {
+		return nil, err
+	}
+
+	// 4. Convert the result to the generic interface
+	gqlResource, err := toGqlResource(dbResource)
+	if err != nil {
+		return nil, err
+	}
+
+	// 5. Assert that the interface value is specifically a *model.Folder
+	gqlFolder, ok := gqlResource.(*model.Folder)
+	if !ok {
+		return nil, errors.New("error: expected a folder but received a different type")
+	}
+
+	return gqlFolder, nil
+}
+
+// CopyResource is the resolver for the copyResource field.
+func (r *mutationResolver) CopyResource(ctx context.Context, id string, destinationParentID *string, conflictStrategy *model.ConflictStrategy) (model.Resource, error) {
+	userID, err := getUserIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	resID, err := utils.StringToUint(id)
+	if err != nil {
+		return nil, errors.New("invalid id format")
+	}
+
+	var parentID *uint
+	if destinationParentID != nil {
+		pID, err := utils.StringToUint(*destinationParentID)
+		if err != nil {
+			return nil, errors.New("invalid destinationParentId format")
+		}
+		parentID = &pID
+	}
+
+	dbResource, err := r.FileService.CopyResource(resID, userID, parentID, toConflictStrategy(conflictStrategy))
+	if err != nil {
+		return nil, err
+	}
+	return toGqlResource(dbResource)
+}
+
+// GrantPermission is the resolver for the grantPermission field.
+func (r *mutationResolver) GrantPermission(ctx context.Context, resourceID string, email string, role model.Role, expiresAt *string) (model.Resource, error) {
+	resID, err := utils.StringToUint(resourceID)
+	if err != nil {
+		return nil, errors.New("invalid resourceId format")
+	}
+
+	expiry, err := parseOptionalTime(expiresAt, "expiresAt")
+	if err != nil {
+		return nil, err
+	}
+
+	// Convert the GraphQL Role enum to your database RoleType string
+	dbRole := database.RoleType(role.String())
+
+	updatedResource, err := r.PermissionService.GrantPermission(ctx, resID, email, dbRole, expiry)
+	if err != nil {
+		return nil, err
+	}
+
+	return toGqlResource(updatedResource)
+}
+
+// RevokePermission is the resolver for the revokePermission field.
+func (r *mutationResolver) RevokePermission(ctx context.Context, resourceID string, email string) (model.Resource, error) {
+	// 1. Decode the string ID into a uint
+	resID, err := utils.StringToUint(resourceID)
+	if err != nil {
+		return nil, errors.New("invalid resourceId format")
+	}
+
+	// 2. Call the service to handle the business logic and database operations
+	updatedResource, err := r.PermissionService.RevokePermission(ctx, resID, email)
+	if err != nil {
+		return nil, err
+	}
+
+	// 3. Encode the database model into the GraphQL model for the response
+	return toGqlResource(updatedResource)
+}
+
+// AddTagToResource is the resolver for the addTagToResource field.
+func (r *mutationResolver) AddTagToResource(ctx context.Context, resourceID string, tagName string, groupID *string) (model.Resource, error) {
+	// 1. Parse GraphQL string ID to uint
+	id, err := strconv.ParseUint(resourceID, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid resource ID format")
+	}
+
+	gID, err := parseGroupID(groupID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. Call the service to perform the business logic
+	resource, err := r.Resolver.TagService.AddTag(ctx, uint(id), tagName, gID)
+	if err != nil {
+		return nil, err // The service will return a descriptive error
+	}
+
+	// 3. Convert to GraphQL model and return the result
+	gqlResource, err := toGqlResource(resource)
+	if err != nil {
+		return nil, err
+	}
+	return gqlResource, nil
+}
+
+// RemoveTagFromResource is the resolver for the removeTagFromResource field.
+func (r *mutationResolver) RemoveTagFromResource(ctx context.Context, resourceID string, tagID string) (model.Resource, error) {
+	// 1. Parse IDs
+	resID, err := strconv.ParseUint(resourceID, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid resource ID format")
+	}
+	tID, err := strconv.ParseUint(tagID, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid tag ID format")
+	}
+
+	// 2. Call the service
+	resource, err := r.Resolver.TagService.RemoveTag(ctx, uint(resID), uint(tID))
+	if err != nil {
+		return nil, err
+	}
+
+	// 3. Convert to GraphQL model and return the result
+	gqlResource, err := toGqlResource(resource)
+	if err != nil {
+		return nil, err
+	}
+	return gqlResource, nil
+}
+
+// CreateTag is the resolver for the createTag field.
+func (r *mutationResolver) CreateTag(ctx context.Context, name string, color *string, description *string, groupID *string) (*model.Tag, error) {
+	gID, err := parseGroupID(groupID)
+	if err != nil {
+		return nil, err
+	}
+	var tagColor, tagDescription string
+	if color != nil {
+		tagColor = *color
+	}
+	if description != nil {
+		tagDescription = *description
+	}
+
+	tag, err := r.TagService.CreateTag(ctx, name, gID, tagColor, tagDescription)
+	if err != nil {
+		return nil, err
+	}
+	return toGqlTag(tag), nil
+}
+
+// UpdateTag is the resolver for the updateTag field.
+func (r *mutationResolver) UpdateTag(ctx context.Context, id string, color *string, description *string) (*model.Tag, error) {
+	tagID, err := utils.StringToUint(id)
+	if err != nil {
+		return nil, errors.New("invalid tag ID format")
+	}
+	tag, err := r.TagService.UpdateTag(ctx, tagID, color, description)
+	if err != nil {
+		return nil, err
+	}
+	return toGqlTag(tag), nil
+}
+
+// RenameTag is the resolver for the renameTag field.
+func (r *mutationResolver) RenameTag(ctx context.Context, id string, name string) (*model.Tag, error) {
+	tagID, err := utils.StringToUint(id)
+	if err != nil {
+		return nil, errors.New("invalid tag ID format")
+	}
+	tag, err := r.TagService.RenameTag(ctx, tagID, name)
+	if err != nil {
+		return nil, err
+	}
+	return toGqlTag(tag), nil
+}
+
+// MergeTags is the resolver for the mergeTags field.
+func (r *mutationResolver) MergeTags(ctx context.Context, sourceIds []string, targetID string) (*model.Tag, error) {
+	target, err := utils.StringToUint(targetID)
+	if err != nil {
+		return nil, errors.New("invalid tag ID format")
+	}
+	sources := make([]uint, 0, len(sourceIds))
+	for _, sourceID := range sourceIds {
+		id, err := utils.StringToUint(sourceID)
+		if err != nil {
+			return nil, errors.New("invalid tag ID format")
+		}
+		sources = append(sources, id)
+	}
+
+	tag, err := r.TagService.MergeTags(ctx, sources, target)
+	if err != nil {
+		return nil, err
+	}
+	return toGqlTag(tag), nil
+}
+
+// DeleteTag is the resolver for the deleteTag field.
+func (r *mutationResolver) DeleteTag(ctx context.Context, id string) (bool, error) {
+	tagID, err := utils.StringToUint(id)
+	if err != nil {
+		return false, errors.New("invalid tag ID format")
+	}
+	if err := r.TagService.DeleteTag(ctx, tagID); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// CreateMetadataField is the resolver for the createMetadataField field.
+func (r *mutationResolver) CreateMetadataField(ctx context.Context, name string, typeArg model.MetadataFieldType, required *bool, options []string, groupID *string) (*model.MetadataField, error) {
+	gID, err := parseGroupID(groupID)
+	if err != nil {
+		return nil, err
+	}
+	field, err := r.MetadataService.CreateField(ctx, metadata.FieldInput{
+		Name:     name,
+		Type:     database.MetadataFieldType(typeArg),
+		Required: required != nil && *required,
+		Options:  options,
+		GroupID:  gID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return toGqlMetadataField(field)
+}
+
+// DeleteMetadataField is the resolver for the deleteMetadataField field.
+func (r *mutationResolver) DeleteMetadataField(ctx context.Context, id string) (bool, error) {
+	fieldID, err := utils.StringToUint(id)
+	if err != nil {
+		return false, errors.New("invalid metadata field ID format")
+	}
+	if err := r.MetadataService.DeleteField(ctx, fieldID); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// SetMetadata is the resolver for the setMetadata field.
+func (r *mutationResolver) SetMetadata(ctx context.Context, resourceID string, values []*model.MetadataValueInput) (model.Resource, error) {
+	resID, err := utils.StringToUint(resourceID)
+	if err != nil {
+		return nil, errors.New("invalid resource ID format")
+	}
+	inputs := make([]metadata.ValueInput, 0, len(values))
+	for _, value := range values {
+		fieldID, err := utils.StringToUint(value.FieldID)
+		if err != nil {
+			return nil, errors.New("invalid metadata fieldId format")
+		}
+		inputs = append(inputs, metadata.ValueInput{FieldID: fieldID, Value: value.Value})
+	}
+	resource, err := r.MetadataService.SetMetadata(ctx, resID, inputs)
+	if err != nil {
+		return nil, err
+	}
+	return toGqlResource(resource)
+}
+
+// BulkMove is the resolver for the bulkMove field.
+func (r *mutationResolver) BulkMove(ctx context.Context, ids []string, newParentID *string, conflictStrategy *model.ConflictStrategy) (*model.BulkResult, error) {
+	userID, err := getUserIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	resIDs, err := parseBulkIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	var parentID *uint
+	if newParentID != nil {
+		pID, err := utils.StringToUint(*newParentID)
+		if err != nil {
+			return nil, errors.New("invalid newParentId format")
+		}
+		parentID = &pID
+	}
+
+	moved, errs, err := r.FileService.MoveResources(resIDs, userID, parentID, toConflictStrategy(conflictStrategy))
+	if err != nil {
+		return nil, err
+	}
+	return toGqlBulkResult(resIDs, moved, errs)
+}
+
+// BulkDelete is the resolver for the bulkDelete field.
+func (r *mutationResolver) BulkDelete(ctx context.Context, ids []string) (*model.BulkResult, error) {
+	userID, err := getUserIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	resIDs, err := parseBulkIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	errs, err := r.FileService.DeleteResources(resIDs, userID)
+	if err != nil {
+		return nil, err
+	}
+	return toGqlBulkResult(resIDs, nil, errs)
+}
+
+// BulkTag is the resolver for the bulkTag field.
+func (r *mutationResolver) BulkTag(ctx context.Context, ids []string, tagName string, groupID *string) (*model.BulkResult, error) {
+	resIDs, err := parseBulkIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+	gID, err := parseGroupID(groupID)
+	if err != nil {
+		return nil, err
 	}
 
-	// Convert the GraphQL Role enum to your database RoleType string
+	tagged := make([]*database.Resource, len(resIDs))
+	errs := make([]error, len(resIDs))
+	for i, resID := range resIDs {
+		tagged[i], errs[i] = r.TagService.AddTag(ctx, resID, tagName, gID)
+	}
+	return toGqlBulkResult(resIDs, tagged, errs)
+}
+
+// BulkGrantPermission is the resolver for the bulkGrantPermission field.
+func (r *mutationResolver) BulkGrantPermission(ctx context.Context, resourceIds []string, email string, role model.Role, expiresAt *string) (*model.BulkResult, error) {
+	resIDs, err := parseBulkIDs(resourceIds)
+	if err != nil {
+		return nil, err
+	}
+
+	expiry, err := parseOptionalTime(expiresAt, "expiresAt")
+	if err != nil {
+		return nil, err
+	}
 	dbRole := database.RoleType(role.String())
 
-	updatedResource, err := r.PermissionService.GrantPermission(ctx, resID, email, dbRole)
+	granted := make([]*database.Resource, len(resIDs))
+	errs := make([]error, len(resIDs))
+	for i, resID := range resIDs {
+		granted[i], errs[i] = r.PermissionService.GrantPermission(ctx, resID, email, dbRole, expiry)
+	}
+	return toGqlBulkResult(resIDs, granted, errs)
+}
+
+// StarResource is the resolver for the starResource field.
+func (r *mutationResolver) StarResource(ctx context.Context, id string) (model.Resource, error) {
+	resID, err := utils.StringToUint(id)
+	if err != nil {
+		return nil, errors.New("invalid id format")
+	}
+
+	dbResource, err := r.ActivityService.StarResource(ctx, resID)
+	if err != nil {
+		return nil, err
+	}
+	return toGqlResource(dbResource)
+}
+
+// UnstarResource is the resolver for the unstarResource field.
+func (r *mutationResolver) UnstarResource(ctx context.Context, id string) (bool, error) {
+	resID, err := utils.StringToUint(id)
+	if err != nil {
+		return false, errors.New("invalid id format")
+	}
+
+	err = r.ActivityService.UnstarResource(ctx, resID)
+	return err == nil, err
+}
+
+// SaveSearch is the resolver for the saveSearch field.
+func (r *mutationResolver) SaveSearch(ctx context.Context, name string, filters model.SearchFilters) (*model.SavedSearch, error) {
+	searchFilters, err := toSavedSearchFilters(&filters)
+	if err != nil {
+		return nil, err
+	}
+	saved, err := r.SavedSearchService.Save(ctx, name, searchFilters)
+	if err != nil {
+		return nil, err
+	}
+	return toGqlSavedSearch(saved, saved.OwnerID)
+}
+
+// DeleteSavedSearch is the resolver for the deleteSavedSearch field.
+func (r *mutationResolver) DeleteSavedSearch(ctx context.Context, id string) (bool, error) {
+	savedID, err := strconv.ParseUint(id, 10, 64)
+	if err != nil {
+		return false, fmt.Errorf("invalid saved search ID format")
+	}
+	if err := r.SavedSearchService.Delete(ctx, uint(savedID)); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// ShareSavedSearch is the resolver for the shareSavedSearch field.
+func (r *mutationResolver) ShareSavedSearch(ctx context.Context, id string, email string) (*model.SavedSearch, error) {
+	savedID, err := strconv.ParseUint(id, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid saved search ID format")
+	}
+	saved, err := r.SavedSearchService.Share(ctx, uint(savedID), email)
+	if err != nil {
+		return nil, err
+	}
+	return toGqlSavedSearch(saved, saved.OwnerID)
+}
+
+// UnshareSavedSearch is the resolver for the unshareSavedSearch field.
+func (r *mutationResolver) UnshareSavedSearch(ctx context.Context, id string, email string) (*model.SavedSearch, error) {
+	savedID, err := strconv.ParseUint(id, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid saved search ID format")
+	}
+	saved, err := r.SavedSearchService.Unshare(ctx, uint(savedID), email)
+	if err != nil {
+		return nil, err
+	}
+	return toGqlSavedSearch(saved, saved.OwnerID)
+}
+
+// CreateRule is the resolver for the createRule field.
+func (r *mutationResolver) CreateRule(ctx context.Context, input model.RuleInput) (*model.Rule, error) {
+	in, err := toRuleInput(input)
+	if err != nil {
+		return nil, err
+	}
+	rule, err := r.RuleService.Create(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	return toGqlRule(rule)
+}
+
+// UpdateRule is the resolver for the updateRule field.
+func (r *mutationResolver) UpdateRule(ctx context.Context, id string, input model.RuleInput) (*model.Rule, error) {
+	ruleID, err := utils.StringToUint(id)
+	if err != nil {
+		return nil, errors.New("invalid rule ID format")
+	}
+	in, err := toRuleInput(input)
+	if err != nil {
+		return nil, err
+	}
+	rule, err := r.RuleService.Update(ctx, ruleID, in)
+	if err != nil {
+		return nil, err
+	}
+	return toGqlRule(rule)
+}
+
+// DeleteRule is the resolver for the deleteRule field.
+func (r *mutationResolver) DeleteRule(ctx context.Context, id string) (bool, error) {
+	ruleID, err := utils.StringToUint(id)
+	if err != nil {
+		return false, errors.New("invalid rule ID format")
+	}
+	if err := r.RuleService.Delete(ctx, ruleID); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// ApplyRules is the resolver for the applyRules field.
+func (r *mutationResolver) ApplyRules(ctx context.Context, ruleID *string) (*model.RuleApplication, error) {
+	id, err := parseOptionalRuleID(ruleID)
+	if err != nil {
+		return nil, err
+	}
+	report, err := r.RuleService.Apply(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return &model.RuleApplication{
+		ScannedCount: report.Scanned,
+		MatchedCount: report.Matched,
+		FailureCount: report.FailureCount,
+		Failures:     report.Failures,
+	}, nil
+}
+
+// CollapseDuplicates is the resolver for the collapseDuplicates field.
+func (r *mutationResolver) CollapseDuplicates(ctx context.Context, keepID string, removeIds []string, dryRun *bool, force *bool) (*model.CollapseResult, error) {
+	id, err := utils.StringToUint(keepID)
+	if err != nil {
+		return nil, errors.New("invalid file ID format")
+	}
+	req := dedup.CollapseRequest{KeepID: id, DryRun: dryRun != nil && *dryRun, Force: force != nil && *force}
+	if len(removeIds) > 0 {
+		if req.RemoveIDs, err = parseBulkIDs(removeIds); err != nil {
+			return nil, err
+		}
+	}
+	result, err := r.DedupService.Collapse(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	kept, err := toGqlFile(result.Kept)
+	if err != nil {
+		return nil, err
+	}
+	removed, err := toGqlBulkResult(result.RemovedIDs, nil, result.Errors)
+	if err != nil {
+		return nil, err
+	}
+	return &model.CollapseResult{Kept: kept, FreedBytes: int(result.FreedBytes), Removed: removed, DryRun: result.DryRun}, nil
+}
+
+// CreateGroup is the resolver for the createGroup field.
+func (r *mutationResolver) CreateGroup(ctx context.Context, name string) (*model.Group, error) {
+	dbGroup, err := r.GroupService.CreateGroup(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	return toGqlGroup(dbGroup), nil
+}
+
+// RenameGroup is the resolver for the renameGroup field.
+func (r *mutationResolver) RenameGroup(ctx context.Context, id string, name string) (*model.Group, error) {
+	groupID, err := utils.StringToUint(id)
+	if err != nil {
+		return nil, errors.New("invalid id format")
+	}
+
+	dbGroup, err := r.GroupService.RenameGroup(ctx, groupID, name)
+	if err != nil {
+		return nil, err
+	}
+	return toGqlGroup(dbGroup), nil
+}
+
+// DeleteGroup is the resolver for the deleteGroup field.
+func (r *mutationResolver) DeleteGroup(ctx context.Context, id string) (bool, error) {
+	groupID, err := utils.StringToUint(id)
+	if err != nil {
+		return false, errors.New("invalid id format")
+	}
+	err = r.GroupService.DeleteGroup(ctx, groupID)
+	return err == nil, err
+}
+
+// AddGroupMember is the resolver for the addGroupMember field.
+func (r *mutationResolver) AddGroupMember(ctx context.Context, groupID string, email string, isAdmin *bool) (*model.Group, error) {
+	gID, err := utils.StringToUint(groupID)
+	if err != nil {
+		return nil, errors.New("invalid groupId format")
+	}
+
+	admin := isAdmin != nil && *isAdmin
+	dbGroup, err := r.GroupService.AddMember(ctx, gID, email, admin)
+	if err != nil {
+		return nil, err
+	}
+	return toGqlGroup(dbGroup), nil
+}
+
+// RemoveGroupMember is the resolver for the removeGroupMember field.
+func (r *mutationResolver) RemoveGroupMember(ctx context.Context, groupID string, email string) (*model.Group, error) {
+	gID, err := utils.StringToUint(groupID)
+	if err != nil {
+		return nil, errors.New("invalid groupId format")
+	}
+
+	dbGroup, err := r.GroupService.RemoveMember(ctx, gID, email)
+	if err != nil {
+		return nil, err
+	}
+	return toGqlGroup(dbGroup), nil
+}
+
+// GrantGroupPermission is the resolver for the grantGroupPermission field.
+func (r *mutationResolver) GrantGroupPermission(ctx context.Context, resourceID string, groupID string, role model.Role, expiresAt *string) (model.Resource, error) {
+	resID, err := utils.StringToUint(resourceID)
+	if err != nil {
+		return nil, errors.New("invalid resourceId format")
+	}
+	gID, err := utils.StringToUint(groupID)
+	if err != nil {
+		return nil, errors.New("invalid groupId format")
+	}
+
+	expiry, err := parseOptionalTime(expiresAt, "expiresAt")
+	if err != nil {
+		return nil, err
+	}
+
+	updatedResource, err := r.PermissionService.GrantGroupPermission(ctx, resID, gID, database.RoleType(role.String()), expiry)
 	if err != nil {
 		return nil, err
 	}
@@ -434,70 +1942,175 @@ func (r *mutationResolver) GrantPermission(ctx context.Context, resourceID strin
 	return toGqlResource(updatedResource)
 }
 
-// RevokePermission is the resolver for the revokePermission field.
-func (r *mutationResolver) RevokePermission(ctx context.Context, resourceID string, email string) (model.Resource, error) {
-	// 1. Decode the string ID into a uint
+// RevokeGroupPermission is the resolver for the revokeGroupPermission field.
+func (r *mutationResolver) RevokeGroupPermission(ctx context.Context, resourceID string, groupID string) (model.Resource, error) {
 	resID, err := utils.StringToUint(resourceID)
 	if err != nil {
 		return nil, errors.New("invalid resourceId format")
 	}
+	gID, err := utils.StringToUint(groupID)
+	if err != nil {
+		return nil, errors.New("invalid groupId format")
+	}
 
-	// 2. Call the service to handle the business logic and database operations
-	updatedResource, err := r.PermissionService.RevokePermission(ctx, resID, email)
+	updatedResource, err := r.PermissionService.RevokeGroupPermission(ctx, resID, gID)
 	if err != nil {
 		return nil, err
 	}
 
-	// 3. Encode the database model into the GraphQL model for the response
 	return toGqlResource(updatedResource)
 }
 
-// AddTagToResource is the resolver for the addTagToResource field.
-func (r *mutationResolver) AddTagToResource(ctx context.Context, resourceID string, tagName string) (model.Resource, error) {
-	// 1. Parse GraphQL string ID to uint
-	id, err := strconv.ParseUint(resourceID, 10, 64)
+// SetInheritPermissions is the resolver for the setInheritPermissions field.
+func (r *mutationResolver) SetInheritPermissions(ctx context.Context, resourceID string, inherit bool) (model.Resource, error) {
+	resID, err := utils.StringToUint(resourceID)
 	if err != nil {
-		return nil, fmt.Errorf("invalid resource ID format")
+		return nil, errors.New("invalid resourceId format")
 	}
 
-	// 2. Call the service to perform the business logic
-	resource, err := r.Resolver.TagService.AddTag(uint(id), tagName)
+	updatedResource, err := r.PermissionService.SetInheritPermissions(ctx, resID, inherit)
 	if err != nil {
-		return nil, err // The service will return a descriptive error
+		return nil, err
 	}
 
-	// 3. Convert to GraphQL model and return the result
-	gqlResource, err := toGqlResource(resource)
+	return toGqlResource(updatedResource)
+}
+
+// RequestAccess is the resolver for the requestAccess field.
+func (r *mutationResolver) RequestAccess(ctx context.Context, token string, role model.Role, message *string) (*model.AccessRequest, error) {
+	var note string
+	if message != nil {
+		note = *message
+	}
+
+	dbReq, err := r.AccessRequestService.RequestAccess(ctx, token, database.RoleType(role.String()), note)
 	if err != nil {
 		return nil, err
 	}
-	return gqlResource, nil
+	return toGqlAccessRequest(dbReq)
 }
 
-// RemoveTagFromResource is the resolver for the removeTagFromResource field.
-func (r *mutationResolver) RemoveTagFromResource(ctx context.Context, resourceID string, tagID string) (model.Resource, error) {
-	// 1. Parse IDs
-	resID, err := strconv.ParseUint(resourceID, 10, 64)
+// ApproveAccessRequest is the resolver for the approveAccessRequest field.
+func (r *mutationResolver) ApproveAccessRequest(ctx context.Context, id string, role *model.Role) (*model.AccessRequest, error) {
+	reqID, err := utils.StringToUint(id)
 	if err != nil {
-		return nil, fmt.Errorf("invalid resource ID format")
+		return nil, errors.New("invalid id format")
 	}
-	tID, err := strconv.ParseUint(tagID, 10, 64)
+
+	var dbRole *database.RoleType
+	if role != nil {
+		override := database.RoleType(role.String())
+		dbRole = &override
+	}
+
+	dbReq, err := r.AccessRequestService.Approve(ctx, reqID, dbRole)
 	if err != nil {
-		return nil, fmt.Errorf("invalid tag ID format")
+		return nil, err
 	}
+	return toGqlAccessRequest(dbReq)
+}
 
-	// 2. Call the service
-	resource, err := r.Resolver.TagService.RemoveTag(uint(resID), uint(tID))
+// DenyAccessRequest is the resolver for the denyAccessRequest field.
+func (r *mutationResolver) DenyAccessRequest(ctx context.Context, id string) (*model.AccessRequest, error) {
+	reqID, err := utils.StringToUint(id)
+	if err != nil {
+		return nil, errors.New("invalid id format")
+	}
+
+	dbReq, err := r.AccessRequestService.Deny(ctx, reqID)
 	if err != nil {
 		return nil, err
 	}
+	return toGqlAccessRequest(dbReq)
+}
 
-	// 3. Convert to GraphQL model and return the result
-	gqlResource, err := toGqlResource(resource)
+// TransferOwnership is the resolver for the transferOwnership field.
+func (r *mutationResolver) TransferOwnership(ctx context.Context, resourceID string, newOwnerEmail string, keepEditorAccess *bool) (*model.OwnershipTransfer, error) {
+	resID, err := utils.StringToUint(resourceID)
+	if err != nil {
+		return nil, errors.New("invalid resourceId format")
+	}
+
+	keep := keepEditorAccess == nil || *keepEditorAccess
+	dbTransfer, err := r.OwnershipService.TransferOwnership(ctx, resID, newOwnerEmail, keep)
 	if err != nil {
 		return nil, err
 	}
-	return gqlResource, nil
+	return toGqlOwnershipTransfer(dbTransfer)
+}
+
+// AcceptOwnershipTransfer is the resolver for the acceptOwnershipTransfer field.
+func (r *mutationResolver) AcceptOwnershipTransfer(ctx context.Context, id string) (*model.OwnershipTransfer, error) {
+	transferID, err := utils.StringToUint(id)
+	if err != nil {
+		return nil, errors.New("invalid id format")
+	}
+
+	dbTransfer, err := r.OwnershipService.AcceptTransfer(ctx, transferID)
+	if err != nil {
+		return nil, err
+	}
+	return toGqlOwnershipTransfer(dbTransfer)
+}
+
+// DeclineOwnershipTransfer is the resolver for the declineOwnershipTransfer field.
+func (r *mutationResolver) DeclineOwnershipTransfer(ctx context.Context, id string) (*model.OwnershipTransfer, error) {
+	transferID, err := utils.StringToUint(id)
+	if err != nil {
+		return nil, errors.New("invalid id format")
+	}
+
+	dbTransfer, err := r.OwnershipService.DeclineTransfer(ctx, transferID)
+	if err != nil {
+		return nil, err
+	}
+	return toGqlOwnershipTransfer(dbTransfer)
+}
+
+// CancelOwnershipTransfer is the resolver for the cancelOwnershipTransfer field.
+func (r *mutationResolver) CancelOwnershipTransfer(ctx context.Context, id string) (*model.OwnershipTransfer, error) {
+	transferID, err := utils.StringToUint(id)
+	if err != nil {
+		return nil, errors.New("invalid id format")
+	}
+
+	dbTransfer, err := r.OwnershipService.CancelTransfer(ctx, transferID)
+	if err != nil {
+		return nil, err
+	}
+	return toGqlOwnershipTransfer(dbTransfer)
+}
+
+// VerifyHierarchy is the resolver for the verifyHierarchy field.
+func (r *mutationResolver) VerifyHierarchy(ctx context.Context, repair *bool) (*model.HierarchyReport, error) {
+	userID, err := getUserIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	currentUser, err := r.UserService.GetUserByID(userID)
+	if err != nil {
+		return nil, fmt.Errorf("could not retrieve current user: %w", err)
+	}
+	if currentUser.Role != database.RoleAdmin {
+		return nil, errors.New("unauthorized: admin access required")
+	}
+
+	report, err := r.FolderService.VerifyHierarchy(repair != nil && *repair)
+	if err != nil {
+		return nil, fmt.Errorf("failed to verify hierarchy: %w", err)
+	}
+
+	cycleIDs := make([]string, 0, len(report.CycleResourceIDs))
+	for _, id := range report.CycleResourceIDs {
+		cycleIDs = append(cycleIDs, fmt.Sprint(id))
+	}
+	return &model.HierarchyReport{
+		MissingRows:      int(report.MissingRows),
+		StaleRows:        int(report.StaleRows),
+		CycleResourceIds: cycleIDs,
+		Repaired:         report.Repaired,
+	}, nil
 }
 
 // MakeResourcePublic is the resolver for the makeResourcePublic field.
@@ -590,12 +2203,48 @@ func (r *queryResolver) Me(ctx context.Context) (*model.User, error) {
 
 // File is the resolver for the file field.
 func (r *queryResolver) File(ctx context.Context, id string) (*model.File, error) {
-	panic(fmt.Errorf("not implemented: File - file"))
+	resID, err := utils.StringToUint(id)
+	if err != nil {
+		return nil, errors.New("invalid id format")
+	}
+
+	dbResource, err := r.FolderService.GetResource(ctx, resID)
+	if err != nil {
+		return nil, err
+	}
+
+	gqlResource, err := toGqlResource(dbResource)
+	if err != nil {
+		return nil, err
+	}
+	gqlFile, ok := gqlResource.(*model.File)
+	if !ok {
+		return nil, errors.New("resource is not a file")
+	}
+	return gqlFile, nil
 }
 
 // Folder is the resolver for the folder field.
 func (r *queryResolver) Folder(ctx context.Context, id string) (*model.Folder, error) {
-	panic(fmt.Errorf("not implemented: Folder - folder"))
+	resID, err := utils.StringToUint(id)
+	if err != nil {
+		return nil, errors.New("invalid id format")
+	}
+
+	dbResource, err := r.FolderService.GetResource(ctx, resID)
+	if err != nil {
+		return nil, err
+	}
+
+	gqlResource, err := toGqlResource(dbResource)
+	if err != nil {
+		return nil, err
+	}
+	gqlFolder, ok := gqlResource.(*model.Folder)
+	if !ok {
+		return nil, errors.New("resource is not a folder")
+	}
+	return gqlFolder, nil
 }
 
 // ResolveShareLink is the resolver for the resolveShareLink field.
@@ -608,7 +2257,7 @@ func (r *queryResolver) ResolveShareLink(ctx context.Context, token string, expe
 }
 
 // Resources is the resolver for the resources field.
-func (r *queryResolver) Resources(ctx context.Context, folderID *string) ([]model.Resource, error) {
+func (r *queryResolver) Resources(ctx context.Context, folderID *string, first *int, after *string, sort *model.ResourceSort) (*model.ResourceConnection, error) {
 	var pID *uint
 	if folderID != nil {
 		id, err := utils.StringToUint(*folderID)
@@ -618,80 +2267,75 @@ func (r *queryResolver) Resources(ctx context.Context, folderID *string) ([]mode
 		pID = &id
 	}
 
-	dbResources, err := r.FolderService.GetResources(ctx, pID)
+	req, err := toPageRequest(first, after, sort, pagination.Sort{Field: pagination.SortByName, FoldersFirst: true})
 	if err != nil {
 		return nil, err
 	}
-	var gqlResources []model.Resource
-	for _, dbRes := range dbResources {
-		// Use the helper to convert each resource
-		gqlRes, err := toGqlResource(&dbRes)
-		if err == nil { // Skip resources that fail to convert
-			gqlResources = append(gqlResources, gqlRes)
-		}
+
+	page, err := r.FolderService.ListResources(ctx, pID, req)
+	if err != nil {
+		return nil, err
 	}
-	return gqlResources, nil
+	return toGqlResourceConnection(page)
 }
 
 // SearchResources is the resolver for the searchResources field.
-func (r *queryResolver) SearchResources(ctx context.Context, filters model.SearchFilters, offset *int, limit *int) ([]model.Resource, error) {
-	// 1. Map the GraphQL input model to your internal service model
-	// This keeps your service layer decoupled from the GraphQL schema.
-	serviceFilters := search.SearchFilters{
-		Name:      filters.Name,
-		Types:     filters.Types,
-		MimeTypes: filters.MimeTypes,
-		Tags:      filters.Tags,
+func (r *queryResolver) SearchResources(ctx context.Context, filters *model.SearchFilters, savedSearchID *string, first *int, after *string, sort *model.ResourceSort) (*model.ResourceConnection, error) {
+	// 1. Take the filters from the request or from the saved search
+	searchFilters, err := r.searchFilters(ctx, filters, savedSearchID)
+	if err != nil {
+		return nil, err
 	}
 
-	if filters.UploaderName != nil {
-		serviceFilters.UploaderName = filters.UploaderName
-	}
+	// 2. The search service gets the current user from the context and applies the
+	// security rules
+	return runSearch(ctx, r.SearchService, searchFilters, first, after, sort)
+}
 
-	// Handle pointers for optional fields
-	if filters.MinSizeBytes != nil {
-		minSize := int64(*filters.MinSizeBytes)
-		serviceFilters.MinSizeBytes = &minSize
-	}
-	if filters.MaxSizeBytes != nil {
-		maxSize := int64(*filters.MaxSizeBytes)
-		serviceFilters.MaxSizeBytes = &maxSize
+// SearchFacets is the resolver for the searchFacets field.
+func (r *queryResolver) SearchFacets(ctx context.Context, filters *model.SearchFilters, savedSearchID *string) (*model.SearchFacets, error) {
+	searchFilters, err := r.searchFilters(ctx, filters, savedSearchID)
+	if err != nil {
+		return nil, err
 	}
-	if filters.AfterDate != nil {
-		after, _ := time.Parse(time.RFC3339, *filters.AfterDate)
-		serviceFilters.AfterDate = &after
+	serviceFilters, err := searchFilters.Resolve()
+	if err != nil {
+		return nil, err
 	}
-	if filters.BeforeDate != nil {
-		before, _ := time.Parse(time.RFC3339, *filters.BeforeDate)
-		serviceFilters.BeforeDate = &before
+	facets, err := r.SearchService.Facets(ctx, serviceFilters)
+	if err != nil {
+		return nil, err
 	}
+	return &model.SearchFacets{
+		TotalCount: int(facets.TotalCount),
+		MimeTypes:  toGqlFacetBuckets(facets.MimeTypes),
+		Tags:       toGqlFacetBuckets(facets.Tags),
+		Owners:     toGqlFacetBuckets(facets.Owners),
+		Sizes:      toGqlFacetBuckets(facets.Sizes),
+		Months:     toGqlFacetBuckets(facets.Months),
+	}, nil
+}
 
-	// Use default pagination if not provided
-	pagination := search.Pagination{Offset: 0, Limit: 25}
-	if offset != nil {
-		pagination.Offset = *offset
-	}
+// SuggestResources is the resolver for the suggestResources field.
+func (r *queryResolver) SuggestResources(ctx context.Context, prefix string, limit *int) ([]model.Resource, error) {
+	l := 10
 	if limit != nil {
-		pagination.Limit = *limit
+		l = *limit
 	}
-
-	// 2. Call the service layer
-	// The service will handle getting the current user from the context and applying security rules.
-	dbResources, err := r.SearchService.Search(ctx, serviceFilters, pagination)
+	resources, err := r.SearchService.Suggest(ctx, prefix, l)
 	if err != nil {
 		return nil, err
 	}
 
-	// 3. Convert your database models back to GraphQL models using your helper
-	gqlResources := make([]model.Resource, 0, len(dbResources))
-	for _, dbRes := range dbResources {
-		gqlRes, err := toGqlResource(&dbRes) // Use your existing helper
-		if err == nil {
-			gqlResources = append(gqlResources, gqlRes)
+	suggestions := make([]model.Resource, 0, len(resources))
+	for i := range resources {
+		gqlResource, err := toGqlResource(&resources[i])
+		if err != nil {
+			return nil, err
 		}
+		suggestions = append(suggestions, gqlResource)
 	}
-
-	return gqlResources, nil
+	return suggestions, nil
 }
 
 // AllResources is the resolver for the allResources field.
@@ -754,11 +2398,380 @@ func (r *queryResolver) AllResources(ctx context.Context) ([]*model.UserResource
 	return finalResult, nil
 }
 
+// AdminResources is the resolver for the adminResources field.
+func (r *queryResolver) AdminResources(ctx context.Context, ownerID *string, first *int, after *string, sort *model.ResourceSort) (*model.ResourceConnection, error) {
+	userID, err := getUserIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	currentUser, err := r.UserService.GetUserByID(userID)
+	if err != nil {
+		return nil, fmt.Errorf("could not retrieve current user: %w", err)
+	}
+	if currentUser.Role != database.RoleAdmin {
+		return nil, errors.New("unauthorized: admin access required")
+	}
+
+	req, err := toPageRequest(first, after, sort, pagination.Sort{Field: pagination.SortByCreatedAt, Descending: true})
+	if err != nil {
+		return nil, err
+	}
+
+	query := r.DB.Model(&database.Resource{})
+	if ownerID != nil {
+		oID, err := utils.StringToUint(*ownerID)
+		if err != nil {
+			return nil, errors.New("invalid ownerId format")
+		}
+		query = query.Where("resources.owner_id = ?", oID)
+	}
+
+	page, err := pagination.Paginate(query, req, "User", "PhysicalFile")
+	if err != nil {
+		return nil, err
+	}
+	return toGqlResourceConnection(page)
+}
+
+// MyGroups is the resolver for the myGroups field.
+func (r *queryResolver) MyGroups(ctx context.Context) ([]*model.Group, error) {
+	dbGroups, err := r.GroupService.ListGroups(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	gqlGroups := make([]*model.Group, 0, len(dbGroups))
+	for i := range dbGroups {
+		gqlGroups = append(gqlGroups, toGqlGroup(&dbGroups[i]))
+	}
+	return gqlGroups, nil
+}
+
+// Group is the resolver for the group field.
+func (r *queryResolver) Group(ctx context.Context, id string) (*model.Group, error) {
+	groupID, err := utils.StringToUint(id)
+	if err != nil {
+		return nil, errors.New("invalid id format")
+	}
+
+	dbGroup, err := r.GroupService.GetGroup(ctx, groupID)
+	if err != nil {
+		return nil, err
+	}
+	return toGqlGroup(dbGroup), nil
+}
+
+// PendingAccessRequests is the resolver for the pendingAccessRequests field.
+func (r *queryResolver) PendingAccessRequests(ctx context.Context, resourceID *string) ([]*model.AccessRequest, error) {
+	var resID *uint
+	if resourceID != nil {
+		id, err := utils.StringToUint(*resourceID)
+		if err != nil {
+			return nil, errors.New("invalid resourceId format")
+		}
+		resID = &id
+	}
+
+	dbRequests, err := r.AccessRequestService.ListPending(ctx, resID)
+	if err != nil {
+		return nil, err
+	}
+
+	gqlRequests := make([]*model.AccessRequest, 0, len(dbRequests))
+	for i := range dbRequests {
+		gqlReq, err := toGqlAccessRequest(&dbRequests[i])
+		if err != nil {
+			continue
+		}
+		gqlRequests = append(gqlRequests, gqlReq)
+	}
+	return gqlRequests, nil
+}
+
+// PendingOwnershipTransfers is the resolver for the pendingOwnershipTransfers field.
+func (r *queryResolver) PendingOwnershipTransfers(ctx context.Context) ([]*model.OwnershipTransfer, error) {
+	dbTransfers, err := r.OwnershipService.ListPending(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	gqlTransfers := make([]*model.OwnershipTransfer, 0, len(dbTransfers))
+	for i := range dbTransfers {
+		gqlTransfer, err := toGqlOwnershipTransfer(&dbTransfers[i])
+		if err != nil {
+			continue
+		}
+		gqlTransfers = append(gqlTransfers, gqlTransfer)
+	}
+	return gqlTransfers, nil
+}
+
+// ResourceByPath is the resolver for the resourceByPath field.
+func (r *queryResolver) ResourceByPath(ctx context.Context, path string, shareToken *string) (model.Resource, error) {
+	dbResource, err := r.FolderService.ResolvePath(ctx, path, shareToken)
+	if err != nil {
+		return nil, err
+	}
+	return toGqlResource(dbResource)
+}
+
+// Starred is the resolver for the starred field.
+func (r *queryResolver) Starred(ctx context.Context, first *int, after *string, sort *model.ResourceSort) (*model.ResourceConnection, error) {
+	req, err := toPageRequest(first, after, sort, pagination.Sort{Field: pagination.SortByName, FoldersFirst: true})
+	if err != nil {
+		return nil, err
+	}
+
+	page, err := r.ActivityService.Starred(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return toGqlResourceConnection(page)
+}
+
+// Recent is the resolver for the recent field.
+func (r *queryResolver) Recent(ctx context.Context, limit *int) ([]*model.RecentItem, error) {
+	n := 0
+	if limit != nil {
+		n = *limit
+		if n <= 0 {
+			return nil, errors.New("limit must be positive")
+		}
+	}
+
+	entries, err := r.ActivityService.Recent(ctx, n)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]*model.RecentItem, 0, len(entries))
+	for i := range entries {
+		gqlResource, err := toGqlResource(&entries[i].Resource)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, &model.RecentItem{
+			Resource:   gqlResource,
+			Action:     model.ActivityAction(entries[i].Action),
+			OccurredAt: entries[i].OccurredAt.Format(time.RFC3339),
+		})
+	}
+	return items, nil
+}
+
+// MyTags is the resolver for the myTags field.
+func (r *queryResolver) MyTags(ctx context.Context) ([]*model.TagUsage, error) {
+	usages, err := r.TagService.ListTags(ctx)
+	if err != nil {
+		return nil, err
+	}
+	gqlUsages := make([]*model.TagUsage, 0, len(usages))
+	for i := range usages {
+		gqlUsages = append(gqlUsages, &model.TagUsage{
+			Tag:        toGqlTag(&usages[i].Tag),
+			UsageCount: int(usages[i].UsageCount),
+		})
+	}
+	return gqlUsages, nil
+}
+
+// MetadataFields is the resolver for the metadataFields field.
+func (r *queryResolver) MetadataFields(ctx context.Context) ([]*model.MetadataField, error) {
+	fields, err := r.MetadataService.ListFields(ctx)
+	if err != nil {
+		return nil, err
+	}
+	gqlFields := make([]*model.MetadataField, 0, len(fields))
+	for i := range fields {
+		field, err := toGqlMetadataField(&fields[i])
+		if err != nil {
+			return nil, err
+		}
+		gqlFields = append(gqlFields, field)
+	}
+	return gqlFields, nil
+}
+
+// EffectivePermissions is the resolver for the effectivePermissions field.
+func (r *queryResolver) EffectivePermissions(ctx context.Context, resourceID string) ([]*model.EffectivePermission, error) {
+	resID, err := utils.StringToUint(resourceID)
+	if err != nil {
+		return nil, errors.New("invalid resourceId format")
+	}
+
+	entries, err := r.PermissionService.EffectivePermissions(ctx, resID)
+	if err != nil {
+		return nil, err
+	}
+
+	gqlEntries := make([]*model.EffectivePermission, 0, len(entries))
+	for i := range entries {
+		gqlEntries = append(gqlEntries, toGqlEffectivePermission(&entries[i]))
+	}
+	return gqlEntries, nil
+}
+
+// SavedSearches is the resolver for the savedSearches field.
+func (r *queryResolver) SavedSearches(ctx context.Context) ([]*model.SavedSearch, error) {
+	userID, err := getUserIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	searches, err := r.SavedSearchService.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*model.SavedSearch, 0, len(searches))
+	for i := range searches {
+		gqlSaved, err := toGqlSavedSearch(&searches[i], userID)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, gqlSaved)
+	}
+	return result, nil
+}
+
+// SavedSearch is the resolver for the savedSearch field.
+func (r *queryResolver) SavedSearch(ctx context.Context, id string) (*model.SavedSearch, error) {
+	userID, err := getUserIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	savedID, err := strconv.ParseUint(id, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid saved search ID format")
+	}
+	saved, err := r.SavedSearchService.Get(ctx, uint(savedID))
+	if err != nil {
+		return nil, err
+	}
+	return toGqlSavedSearch(saved, userID)
+}
+
+// Rules is the resolver for the rules field.
+func (r *queryResolver) Rules(ctx context.Context) ([]*model.Rule, error) {
+	list, err := r.RuleService.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*model.Rule, 0, len(list))
+	for i := range list {
+		rule, err := toGqlRule(&list[i])
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, rule)
+	}
+	return result, nil
+}
+
+// DryRunRules is the resolver for the dryRunRules field.
+func (r *queryResolver) DryRunRules(ctx context.Context, ruleID *string, limit *int) ([]*model.RuleMatch, error) {
+	id, err := parseOptionalRuleID(ruleID)
+	if err != nil {
+		return nil, err
+	}
+	l := rules.DefaultDryRunLimit
+	if limit != nil {
+		l = *limit
+	}
+	matches, err := r.RuleService.DryRun(ctx, id, l)
+	if err != nil {
+		return nil, err
+	}
+
+	// Matches of the same rule share its converted form.
+	gqlRules := make(map[uint]*model.Rule)
+	result := make([]*model.RuleMatch, 0, len(matches))
+	for _, match := range matches {
+		gqlRule, ok := gqlRules[match.Rule.ID]
+		if !ok {
+			if gqlRule, err = toGqlRule(match.Rule); err != nil {
+				return nil, err
+			}
+			gqlRules[match.Rule.ID] = gqlRule
+		}
+		gqlResource, err := toGqlResource(match.Resource)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, &model.RuleMatch{Rule: gqlRule, Resource: gqlResource})
+	}
+	return result, nil
+}
+
+// Duplicates is the resolver for the duplicates field.
+func (r *queryResolver) Duplicates(ctx context.Context, limit *int) ([]*model.DuplicateGroup, error) {
+	groupLimit := 0
+	if limit != nil {
+		groupLimit = *limit
+	}
+	groups, err := r.DedupService.Duplicates(ctx, groupLimit)
+	if err != nil {
+		return nil, err
+	}
+	gqlGroups := make([]*model.DuplicateGroup, 0, len(groups))
+	for _, group := range groups {
+		gqlGroup, err := toGqlDuplicateGroup(group)
+		if err != nil {
+			return nil, err
+		}
+		gqlGroups = append(gqlGroups, gqlGroup)
+	}
+	return gqlGroups, nil
+}
+
+// DedupSummary is the resolver for the dedupSummary field.
+func (r *queryResolver) DedupSummary(ctx context.Context) (*model.DedupSummary, error) {
+	summary, err := r.DedupService.Summary(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &model.DedupSummary{
+		FileCount:       int(summary.FileCount),
+		LogicalBytes:    int(summary.LogicalBytes),
+		UniqueBytes:     int(summary.UniqueBytes),
+		StoredBytes:     int(summary.StoredBytes),
+		SavedBytes:      int(summary.SavedBytes),
+		SavedPercentage: summary.SavedPercentage,
+		DuplicateGroups: int(summary.DuplicateGroups),
+		DuplicateFiles:  int(summary.DuplicateFiles),
+		WastedBytes:     int(summary.WastedBytes),
+	}, nil
+}
+
+// Results is the resolver for the results field.
+func (r *savedSearchResolver) Results(ctx context.Context, obj *model.SavedSearch, first *int, after *string, sort *model.ResourceSort) (*model.ResourceConnection, error) {
+	savedID, err := strconv.ParseUint(obj.ID, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid saved search ID format")
+	}
+	searchFilters, err := r.SavedSearchService.Filters(ctx, uint(savedID))
+	if err != nil {
+		return nil, err
+	}
+	return runSearch(ctx, r.SearchService, searchFilters, first, after, sort)
+}
+
+// File returns generated.FileResolver implementation.
+func (r *Resolver) File() generated.FileResolver { return &fileResolver{r} }
+
+// Folder returns generated.FolderResolver implementation.
+func (r *Resolver) Folder() generated.FolderResolver { return &folderResolver{r} }
+
 // Mutation returns generated.MutationResolver implementation.
 func (r *Resolver) Mutation() generated.MutationResolver { return &mutationResolver{r} }
 
 // Query returns generated.QueryResolver implementation.
 func (r *Resolver) Query() generated.QueryResolver { return &queryResolver{r} }
 
+// SavedSearch returns generated.SavedSearchResolver implementation.
+func (r *Resolver) SavedSearch() generated.SavedSearchResolver { return &savedSearchResolver{r} }
+
+type fileResolver struct{ *Resolver }
+type folderResolver struct{ *Resolver }
 type mutationResolver struct{ *Resolver }
 type queryResolver struct{ *Resolver }
+type savedSearchResolver struct{ *Resolver }
@@ -0,0 +1,97 @@
+package accessrequest
+
+import (
+	"time"
+
+	"github.com/bhavyajaix/BalkanID-filevault/internal/database"
+	"gorm.io/gorm"
+)
+
+// Repository defines the database operations for access requests.
+type Repository interface {
+	FindResourceByToken(token string) (*database.Resource, error)
+	Create(request *database.AccessRequest) error
+	Update(request *database.AccessRequest) error
+	GetByID(id uint) (*database.AccessRequest, error)
+	FindPending(resourceID, requesterID uint) (*database.AccessRequest, error)
+	CountSince(requesterID uint, resourceID *uint, since time.Time) (int64, error)
+	ListPendingForOwner(ownerID uint, resourceID *uint) ([]database.AccessRequest, error)
+}
+
+type repository struct {
+	db *gorm.DB
+}
+
+// NewRepository creates a new access request repository.
+func NewRepository(db *gorm.DB) Repository {
+	return &repository{db: db}
+}
+
+// withAssociations preloads everything needed to present a request to either party.
+func withAssociations(db *gorm.DB) *gorm.DB {
+	return db.Preload("Resource.User").Preload("Resource.PhysicalFile").Preload("Requester")
+}
+
+func (r *repository) FindResourceByToken(token string) (*database.Resource, error) {
+	var resource database.Resource
+	if err := r.db.Preload("User").Where("share_token = ?", token).First(&resource).Error; err != nil {
+		return nil, err
+	}
+	return &resource, nil
+}
+
+func (r *repository) Create(request *database.AccessRequest) error {
+	return r.db.Create(request).Error
+}
+
+func (r *repository) Update(request *database.AccessRequest) error {
+	return r.db.Omit("Resource", "Requester").Save(request).Error
+}
+
+func (r *repository) GetByID(id uint) (*database.AccessRequest, error) {
+	var request database.AccessRequest
+	if err := r.db.Scopes(withAssociations).First(&request, id).Error; err != nil {
+		return nil, err
+	}
+	return &request, nil
+}
+
+// FindPending returns the requester's open request for a resource, if any.
+func (r *repository) FindPending(resourceID, requesterID uint) (*database.AccessRequest, error) {
+	var request database.AccessRequest
+	err := r.db.Scopes(withAssociations).
+		Where("resource_id = ? AND requester_id = ? AND status = ?", resourceID, requesterID, database.AccessRequestPending).
+		First(&request).Error
+	if err != nil {
+		return nil, err
+	}
+	return &request, nil
+}
+
+// CountSince counts the requests a user has filed since the given time, optionally
+// restricted to a single resource. Used for rate limiting.
+func (r *repository) CountSince(requesterID uint, resourceID *uint, since time.Time) (int64, error) {
+	var count int64
+	query := r.db.Model(&database.AccessRequest{}).
+		Where("requester_id = ? AND created_at >= ?", requesterID, since)
+	if resourceID != nil {
+		query = query.Where("resource_id = ?", *resourceID)
+	}
+	err := query.Count(&count).Error
+	return count, err
+}
+
+// ListPendingForOwner returns the open requests on resources owned by the user,
+// oldest first, optionally restricted to a single resource.
+func (r *repository) ListPendingForOwner(ownerID uint, resourceID *uint) ([]database.AccessRequest, error) {
+	var requests []database.AccessRequest
+	owned := r.db.Model(&database.Resource{}).Select("id").Where("owner_id = ?", ownerID)
+
+	query := r.db.Scopes(withAssociations).
+		Where("status = ? AND resource_id IN (?)", database.AccessRequestPending, owned)
+	if resourceID != nil {
+		query = query.Where("resource_id = ?", *resourceID)
+	}
+	err := query.Order("created_at asc").Find(&requests).Error
+	return requests, err
+}
@@ -0,0 +1,215 @@
+package accessrequest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bhavyajaix/BalkanID-filevault/internal/database"
+	"github.com/bhavyajaix/BalkanID-filevault/internal/middleware"
+	"github.com/bhavyajaix/BalkanID-filevault/internal/permission"
+	"gorm.io/gorm"
+)
+
+const (
+	// maxRequestsPerResource caps how often one user can ask for the same resource.
+	maxRequestsPerResource = 3
+	// maxRequestsPerUser caps how many requests one user can file across all resources.
+	maxRequestsPerUser = 20
+	rateLimitWindow    = 24 * time.Hour
+	maxMessageLength   = 1000
+)
+
+func getUserIDFromContext(ctx context.Context) (uint, error) {
+	userID, ok := ctx.Value(middleware.UserContextKey).(uint)
+	if !ok {
+		return 0, errors.New("unauthorized")
+	}
+	return userID, nil
+}
+
+// Service defines the business logic for requesting and deciding on access.
+type Service interface {
+	RequestAccess(ctx context.Context, token string, role database.RoleType, message string) (*database.AccessRequest, error)
+	ListPending(ctx context.Context, resourceID *uint) ([]database.AccessRequest, error)
+	Approve(ctx context.Context, requestID uint, role *database.RoleType) (*database.AccessRequest, error)
+	Deny(ctx context.Context, requestID uint) (*database.AccessRequest, error)
+}
+
+type service struct {
+	repo     Repository
+	permRepo permission.Repository
+}
+
+// NewService creates a new access request service.
+func NewService(repo Repository, permRepo permission.Repository) Service {
+	return &service{repo: repo, permRepo: permRepo}
+}
+
+// RequestAccess files a request for the resource behind a share link. If the user
+// already has a pending request for it, that request is updated instead of creating
+// a duplicate.
+func (s *service) RequestAccess(ctx context.Context, token string, role database.RoleType, message string) (*database.AccessRequest, error) {
+	userID, err := getUserIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if role == database.Deny {
+		return nil, errors.New("access can only be requested as VIEWER or EDITOR")
+	}
+
+	message = strings.TrimSpace(message)
+	if len(message) > maxMessageLength {
+		return nil, fmt.Errorf("message cannot be longer than %d characters", maxMessageLength)
+	}
+
+	resource, err := s.repo.FindResourceByToken(token)
+	if err != nil {
+		return nil, errors.New("share link not found or invalid")
+	}
+	if resource.OwnerID == userID {
+		return nil, errors.New("you already own this resource")
+	}
+
+	// Business Rule: Don't file a request for access the user already has.
+	existing, err := s.permRepo.FindPermission(resource.ID, userID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("error checking permissions: %w", err)
+	}
+	if err == nil && (existing.Role == database.Editor || role == database.Viewer) {
+		return nil, errors.New("you already have this level of access")
+	}
+
+	// De-duplicate: refresh the open request rather than filing another one.
+	pending, err := s.repo.FindPending(resource.ID, userID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	if pending != nil {
+		pending.Role = role
+		if message != "" {
+			pending.Message = message
+		}
+		if err := s.repo.Update(pending); err != nil {
+			return nil, err
+		}
+		return s.repo.GetByID(pending.ID)
+	}
+
+	if err := s.checkRateLimit(userID, resource.ID); err != nil {
+		return nil, err
+	}
+
+	request := &database.AccessRequest{
+		ResourceID:  resource.ID,
+		RequesterID: userID,
+		Role:        role,
+		Message:     message,
+		Status:      database.AccessRequestPending,
+	}
+	if err := s.repo.Create(request); err != nil {
+		return nil, fmt.Errorf("failed to create access request: %w", err)
+	}
+	return s.repo.GetByID(request.ID)
+}
+
+// checkRateLimit enforces the per-resource and per-user request quotas.
+func (s *service) checkRateLimit(userID, resourceID uint) error {
+	since := time.Now().Add(-rateLimitWindow)
+
+	perResource, err := s.repo.CountSince(userID, &resourceID, since)
+	if err != nil {
+		return err
+	}
+	if perResource >= maxRequestsPerResource {
+		return errors.New("too many access requests for this resource, please try again later")
+	}
+
+	perUser, err := s.repo.CountSince(userID, nil, since)
+	if err != nil {
+		return err
+	}
+	if perUser >= maxRequestsPerUser {
+		return errors.New("too many access requests, please try again later")
+	}
+	return nil
+}
+
+// ListPending returns the open requests on the current user's resources.
+func (s *service) ListPending(ctx context.Context, resourceID *uint) ([]database.AccessRequest, error) {
+	userID, err := getUserIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.ListPendingForOwner(userID, resourceID)
+}
+
+// Approve grants the requester access. The owner may override the requested role.
+func (s *service) Approve(ctx context.Context, requestID uint, role *database.RoleType) (*database.AccessRequest, error) {
+	userID, request, err := s.getPendingAsOwner(ctx, requestID)
+	if err != nil {
+		return nil, err
+	}
+
+	if role != nil {
+		if *role == database.Deny {
+			return nil, errors.New("an access request can only be approved as VIEWER or EDITOR")
+		}
+		request.Role = *role
+	}
+
+	newPermission := &database.Permission{
+		ResourceID: request.ResourceID,
+		UserID:     request.RequesterID,
+		Role:       request.Role,
+		CreatedAt:  time.Now(),
+	}
+	if err := s.permRepo.CreateOrUpdate(newPermission); err != nil {
+		return nil, fmt.Errorf("failed to grant permission: %w", err)
+	}
+
+	return s.decide(request, userID, database.AccessRequestApproved)
+}
+
+// Deny closes the request without granting access.
+func (s *service) Deny(ctx context.Context, requestID uint) (*database.AccessRequest, error) {
+	userID, request, err := s.getPendingAsOwner(ctx, requestID)
+	if err != nil {
+		return nil, err
+	}
+	return s.decide(request, userID, database.AccessRequestDenied)
+}
+
+// getPendingAsOwner fetches a pending request and verifies the current user owns its resource.
+func (s *service) getPendingAsOwner(ctx context.Context, requestID uint) (uint, *database.AccessRequest, error) {
+	userID, err := getUserIDFromContext(ctx)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	request, err := s.repo.GetByID(requestID)
+	if err != nil {
+		return 0, nil, errors.New("access request not found")
+	}
+	if request.Resource.OwnerID != userID {
+		return 0, nil, errors.New("access denied: only the owner can decide on access requests")
+	}
+	if request.Status != database.AccessRequestPending {
+		return 0, nil, errors.New("access request has already been decided")
+	}
+	return userID, request, nil
+}
+
+func (s *service) decide(request *database.AccessRequest, deciderID uint, status database.AccessRequestStatus) (*database.AccessRequest, error) {
+	now := time.Now()
+	request.Status = status
+	request.DecidedByID = &deciderID
+	request.DecidedAt = &now
+	if err := s.repo.Update(request); err != nil {
+		return nil, fmt.Errorf("failed to update access request: %w", err)
+	}
+	return request, nil
+}
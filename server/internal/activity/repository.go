@@ -0,0 +1,91 @@
+package activity
+
+import (
+	"time"
+
+	"github.com/bhavyajaix/BalkanID-filevault/internal/database"
+	"github.com/bhavyajaix/BalkanID-filevault/internal/pagination"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Recorder notes that a user interacted with a resource. It is satisfied by Repository
+// and handed to the code paths that upload, change and serve files.
+type Recorder interface {
+	Record(userID, resourceID uint, action database.ActivityAction) error
+}
+
+// Repository is the interface for stars and the recent activity feed.
+type Repository interface {
+	Recorder
+	GetResource(resourceID uint) (*database.Resource, error)
+	Star(userID, resourceID uint) error
+	Unstar(userID, resourceID uint) error
+	ListStarred(userID uint, req pagination.Request) (*pagination.Page, error)
+	ListRecent(userID uint, offset, limit int) ([]database.RecentActivity, error)
+}
+
+type repository struct {
+	db *gorm.DB
+}
+
+// NewRepository creates a new activity repository.
+func NewRepository(db *gorm.DB) Repository {
+	return &repository{db: db}
+}
+
+// GetResource fetches a live resource with the associations the API returns.
+func (r *repository) GetResource(resourceID uint) (*database.Resource, error) {
+	var resource database.Resource
+	if err := r.db.Preload("User").Preload("PhysicalFile").First(&resource, resourceID).Error; err != nil {
+		return nil, err
+	}
+	return &resource, nil
+}
+
+// Star adds a resource to the user's favourites. Starring twice is a no-op.
+func (r *repository) Star(userID, resourceID uint) error {
+	star := &database.Star{UserID: userID, ResourceID: resourceID, CreatedAt: time.Now()}
+	return r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(star).Error
+}
+
+// Unstar removes a resource from the user's favourites.
+func (r *repository) Unstar(userID, resourceID uint) error {
+	return r.db.Where("user_id = ? AND resource_id = ?", userID, resourceID).Delete(&database.Star{}).Error
+}
+
+// ListStarred returns one page of the live resources the user has starred.
+func (r *repository) ListStarred(userID uint, req pagination.Request) (*pagination.Page, error) {
+	query := r.db.Model(&database.Resource{}).
+		Joins("JOIN stars ON stars.resource_id = resources.id AND stars.user_id = ?", userID)
+	return pagination.Paginate(query, req, "User", "PhysicalFile")
+}
+
+// Record upserts the user's latest interaction with a resource.
+func (r *repository) Record(userID, resourceID uint, action database.ActivityAction) error {
+	entry := &database.RecentActivity{
+		UserID:     userID,
+		ResourceID: resourceID,
+		Action:     action,
+		OccurredAt: time.Now(),
+	}
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "resource_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"action", "occurred_at"}),
+	}).Create(entry).Error
+}
+
+// ListRecent returns the user's activity on live resources, newest first.
+func (r *repository) ListRecent(userID uint, offset, limit int) ([]database.RecentActivity, error) {
+	var entries []database.RecentActivity
+	err := r.db.
+		Joins("JOIN resources ON resources.id = recent_activities.resource_id AND resources.deleted_at IS NULL").
+		Where("recent_activities.user_id = ?", userID).
+		Order("recent_activities.occurred_at DESC").
+		Offset(offset).
+		Limit(limit).
+		Preload("Resource.User").
+		Preload("Resource.PhysicalFile").
+		Find(&entries).Error
+	return entries, err
+}
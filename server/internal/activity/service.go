@@ -0,0 +1,164 @@
+package activity
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/bhavyajaix/BalkanID-filevault/internal/database"
+	"github.com/bhavyajaix/BalkanID-filevault/internal/middleware"
+	"github.com/bhavyajaix/BalkanID-filevault/internal/pagination"
+)
+
+const (
+	// DefaultRecentLimit is the size of the recent feed when the caller doesn't ask.
+	DefaultRecentLimit = 20
+	// MaxRecentLimit caps the size of the recent feed.
+	MaxRecentLimit = 100
+)
+
+// ReadChecker decides whether a user may see a resource. It is satisfied by
+// folders.Service.
+type ReadChecker interface {
+	CanRead(userID uint, resource *database.Resource) (bool, error)
+}
+
+// Service is the interface for stars and the recent activity feed.
+type Service interface {
+	StarResource(ctx context.Context, resourceID uint) (*database.Resource, error)
+	UnstarResource(ctx context.Context, resourceID uint) error
+	Starred(ctx context.Context, req pagination.Request) (*pagination.Page, error)
+	Recent(ctx context.Context, limit int) ([]database.RecentActivity, error)
+}
+
+type service struct {
+	repo   Repository
+	access ReadChecker
+}
+
+// NewService creates a new activity service.
+func NewService(repo Repository, access ReadChecker) Service {
+	return &service{repo: repo, access: access}
+}
+
+func getUserIDFromContext(ctx context.Context) (uint, error) {
+	userID, ok := ctx.Value(middleware.UserContextKey).(uint)
+	if !ok {
+		return 0, errors.New("unauthorized")
+	}
+	return userID, nil
+}
+
+// Record notes an interaction without failing the operation that caused it; the feed
+// is a convenience, so a failed write is only logged.
+func Record(recorder Recorder, userID, resourceID uint, action database.ActivityAction) {
+	if err := recorder.Record(userID, resourceID, action); err != nil {
+		log.Printf("failed to record %s of resource %d by user %d: %v", action, resourceID, userID, err)
+	}
+}
+
+// StarResource adds a resource the caller can read to their favourites.
+func (s *service) StarResource(ctx context.Context, resourceID uint) (*database.Resource, error) {
+	userID, err := getUserIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	resource, err := s.repo.GetResource(resourceID)
+	if err != nil {
+		return nil, errors.New("resource not found")
+	}
+	canRead, err := s.access.CanRead(userID, resource)
+	if err != nil {
+		return nil, fmt.Errorf("error checking permissions: %w", err)
+	}
+	if !canRead {
+		return nil, errors.New("access denied")
+	}
+
+	if err := s.repo.Star(userID, resourceID); err != nil {
+		return nil, fmt.Errorf("failed to star resource: %w", err)
+	}
+	return resource, nil
+}
+
+// UnstarResource removes a resource from the caller's favourites. It works even after
+// the caller has lost access, so stale stars can always be cleared.
+func (s *service) UnstarResource(ctx context.Context, resourceID uint) error {
+	userID, err := getUserIDFromContext(ctx)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.Unstar(userID, resourceID); err != nil {
+		return fmt.Errorf("failed to unstar resource: %w", err)
+	}
+	return nil
+}
+
+// Starred returns one page of the caller's favourites. Resources the caller can no
+// longer read are left out, so a page may hold fewer than req.First resources.
+func (s *service) Starred(ctx context.Context, req pagination.Request) (*pagination.Page, error) {
+	userID, err := getUserIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	page, err := s.repo.ListStarred(userID, req)
+	if err != nil {
+		return nil, err
+	}
+
+	readable, cursors := page.Resources[:0], page.Cursors[:0]
+	for i := range page.Resources {
+		canRead, err := s.access.CanRead(userID, &page.Resources[i])
+		if err != nil {
+			return nil, fmt.Errorf("error checking permissions: %w", err)
+		}
+		if canRead {
+			readable = append(readable, page.Resources[i])
+			cursors = append(cursors, page.Cursors[i])
+		}
+	}
+	page.Resources, page.Cursors = readable, cursors
+	return page, nil
+}
+
+// Recent returns the resources the caller most recently uploaded, changed, downloaded
+// or opened through a share link, newest first. Shared resources whose access has been
+// revoked since are skipped.
+func (s *service) Recent(ctx context.Context, limit int) ([]database.RecentActivity, error) {
+	userID, err := getUserIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if limit == 0 {
+		limit = DefaultRecentLimit
+	}
+	if limit < 0 || limit > MaxRecentLimit {
+		return nil, fmt.Errorf("limit must be between 1 and %d", MaxRecentLimit)
+	}
+
+	// Read in batches until the feed is full; revoked entries are rare, so this is
+	// almost always a single query.
+	feed := make([]database.RecentActivity, 0, limit)
+	for offset := 0; len(feed) < limit; offset += limit {
+		batch, err := s.repo.ListRecent(userID, offset, limit)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load recent activity: %w", err)
+		}
+		for i := range batch {
+			canRead, err := s.access.CanRead(userID, &batch[i].Resource)
+			if err != nil {
+				return nil, fmt.Errorf("error checking permissions: %w", err)
+			}
+			if canRead && len(feed) < limit {
+				feed = append(feed, batch[i])
+			}
+		}
+		if len(batch) < limit {
+			break
+		}
+	}
+	return feed, nil
+}
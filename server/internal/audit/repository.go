@@ -0,0 +1,32 @@
+package audit
+
+import (
+	"github.com/bhavyajaix/BalkanID-filevault/internal/database"
+	"gorm.io/gorm"
+)
+
+// Action names recorded in the audit log.
+const (
+	ActionPermissionExpired    = "permission.expired"
+	ActionOwnershipTransferred = "ownership.transferred"
+)
+
+// Repository is the interface for writing to the audit log.
+type Repository interface {
+	Record(db *gorm.DB, event *database.AuditEvent) error
+}
+
+type repository struct {
+	db *gorm.DB
+}
+
+// NewRepository creates a new audit repository.
+func NewRepository(db *gorm.DB) Repository {
+	return &repository{db: db}
+}
+
+// Record appends an event to the audit log. Pass a transaction to make the event
+// part of the change it describes.
+func (r *repository) Record(db *gorm.DB, event *database.AuditEvent) error {
+	return db.Create(event).Error
+}
@@ -16,7 +16,8 @@ func Connect() *gorm.DB {
 		log.Fatal("DATABASE_URL environment variable is not set")
 	}
 
-	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	// TranslateError surfaces unique violations as gorm.ErrDuplicatedKey.
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{TranslateError: true})
 	if err != nil {
 		log.Fatal("Failed to connect to database: ", err)
 	}
@@ -25,17 +26,11 @@ func Connect() *gorm.DB {
 	return db
 }
 
-// Migrate runs the database migrations for all the models.
+// Migrate applies any pending schema migrations, exiting if that fails or if the
+// database has been migrated by a newer release.
 func Migrate(db *gorm.DB) {
 	log.Println("Running database migrations...")
-	err := db.AutoMigrate(
-		&User{},
-		&PhysicalFile{},
-		&Resource{},
-		&Permission{},
-		&ResourceAncestor{},
-	)
-	if err != nil {
+	if err := MigrateUp(db); err != nil {
 		log.Fatal("Failed to migrate database: ", err)
 	}
 	log.Println("✅ Database migrations successful!")
@@ -0,0 +1,48 @@
+// Package databasetest connects tests to a real PostgreSQL database.
+package databasetest
+
+import (
+	"fmt"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/bhavyajaix/BalkanID-filevault/internal/database"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// sequence keeps the users created in one run apart.
+var sequence atomic.Int64
+
+// Open connects to the database named by TEST_DATABASE_URL and migrates it. The test is
+// skipped when the variable is not set. Tests share the database, so each one creates
+// the users it works with instead of expecting it to be empty.
+func Open(t *testing.T) *gorm.DB {
+	t.Helper()
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{TranslateError: true, Logger: logger.Discard})
+	if err != nil {
+		t.Fatalf("failed to connect to database: %v", err)
+	}
+	if err := database.MigrateUp(db); err != nil {
+		t.Fatalf("failed to migrate database: %v", err)
+	}
+	return db
+}
+
+// NewUser creates a user with a name no other test uses.
+func NewUser(t *testing.T, db *gorm.DB, prefix string) *database.User {
+	t.Helper()
+	name := fmt.Sprintf("%s-%d-%d", prefix, time.Now().UnixNano(), sequence.Add(1))
+	user := &database.User{Username: name, Email: name + "@example.com", PasswordHash: "x", StorageQuotaMB: 10}
+	if err := db.Create(user).Error; err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+	return user
+}
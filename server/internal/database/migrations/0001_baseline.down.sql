@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS resource_ancestors;
+DROP TABLE IF EXISTS permissions;
+DROP TABLE IF EXISTS resource_tags;
+DROP TABLE IF EXISTS resources;
+DROP TABLE IF EXISTS tags;
+DROP TABLE IF EXISTS physical_files;
+DROP TABLE IF EXISTS users;
@@ -0,0 +1,94 @@
+-- Baseline schema: users, blobs, the resource tree, tags and direct grants.
+-- Every statement is idempotent so databases created by hand before migrations
+-- existed can adopt this history.
+
+CREATE TABLE IF NOT EXISTS users (
+    id                          bigserial PRIMARY KEY,
+    created_at                  timestamptz,
+    updated_at                  timestamptz,
+    deleted_at                  timestamptz,
+    username                    varchar(255) NOT NULL,
+    email                       varchar(255) NOT NULL,
+    password_hash               text NOT NULL,
+    storage_quota_mb            bigint NOT NULL DEFAULT 10,
+    role                        varchar(50) NOT NULL DEFAULT 'user',
+    storage_used                bigint NOT NULL DEFAULT 0,
+    deduplication_storage_used  bigint NOT NULL DEFAULT 0
+);
+CREATE INDEX IF NOT EXISTS idx_users_deleted_at ON users (deleted_at);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_username ON users (username);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_email ON users (email);
+
+CREATE TABLE IF NOT EXISTS physical_files (
+    id               bigserial PRIMARY KEY,
+    created_at       timestamptz,
+    updated_at       timestamptz,
+    deleted_at       timestamptz,
+    file_hash        varchar(64) NOT NULL,
+    file_path        text NOT NULL UNIQUE,
+    size_bytes       bigint NOT NULL,
+    mime_type        varchar(255) NOT NULL,
+    reference_count  bigint NOT NULL DEFAULT 1
+);
+CREATE INDEX IF NOT EXISTS idx_physical_files_deleted_at ON physical_files (deleted_at);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_physical_files_file_hash ON physical_files (file_hash);
+CREATE INDEX IF NOT EXISTS idx_physical_files_size_bytes ON physical_files (size_bytes);
+CREATE INDEX IF NOT EXISTS idx_physical_files_mime_type ON physical_files (mime_type);
+
+CREATE TABLE IF NOT EXISTS tags (
+    id          bigserial PRIMARY KEY,
+    created_at  timestamptz,
+    updated_at  timestamptz,
+    deleted_at  timestamptz,
+    name        varchar(100) NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_tags_deleted_at ON tags (deleted_at);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_tags_name ON tags (name);
+
+CREATE TABLE IF NOT EXISTS resources (
+    id                bigserial PRIMARY KEY,
+    created_at        timestamptz,
+    updated_at        timestamptz,
+    deleted_at        timestamptz,
+    owner_id          bigint REFERENCES users (id) ON DELETE CASCADE,
+    parent_id         bigint REFERENCES resources (id) ON DELETE CASCADE,
+    name              varchar(255) NOT NULL,
+    is_public         boolean NOT NULL DEFAULT false,
+    share_token       varchar(255),
+    type              varchar(50) NOT NULL,
+    physical_file_id  bigint REFERENCES physical_files (id) ON DELETE RESTRICT
+);
+CREATE INDEX IF NOT EXISTS idx_resources_deleted_at ON resources (deleted_at);
+CREATE INDEX IF NOT EXISTS idx_resources_owner_id ON resources (owner_id);
+CREATE INDEX IF NOT EXISTS idx_resources_parent_id ON resources (parent_id);
+CREATE INDEX IF NOT EXISTS idx_resources_name ON resources (name);
+CREATE INDEX IF NOT EXISTS idx_resources_is_public ON resources (is_public);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_resources_share_token ON resources (share_token);
+CREATE INDEX IF NOT EXISTS idx_resources_type ON resources (type);
+CREATE INDEX IF NOT EXISTS idx_resources_physical_file_id ON resources (physical_file_id);
+
+CREATE TABLE IF NOT EXISTS resource_tags (
+    resource_id  bigint NOT NULL REFERENCES resources (id) ON DELETE CASCADE,
+    tag_id       bigint NOT NULL REFERENCES tags (id) ON DELETE CASCADE,
+    PRIMARY KEY (resource_id, tag_id)
+);
+CREATE INDEX IF NOT EXISTS idx_resource_tags_tag_id ON resource_tags (tag_id);
+
+CREATE TABLE IF NOT EXISTS permissions (
+    resource_id  bigint NOT NULL REFERENCES resources (id) ON DELETE CASCADE,
+    user_id      bigint NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    role         varchar(50) NOT NULL,
+    created_at   timestamptz,
+    PRIMARY KEY (resource_id, user_id)
+);
+CREATE INDEX IF NOT EXISTS idx_permissions_user_id ON permissions (user_id);
+
+-- Closure table: one row per (ancestor, descendant) pair, including a depth 0 row
+-- linking every resource to itself. Maintained by the triggers in 0002.
+CREATE TABLE IF NOT EXISTS resource_ancestors (
+    ancestor_id    bigint NOT NULL REFERENCES resources (id) ON DELETE CASCADE,
+    descendant_id  bigint NOT NULL REFERENCES resources (id) ON DELETE CASCADE,
+    depth          bigint NOT NULL,
+    PRIMARY KEY (ancestor_id, descendant_id)
+);
+CREATE INDEX IF NOT EXISTS idx_resource_ancestors_descendant_depth ON resource_ancestors (descendant_id, depth);
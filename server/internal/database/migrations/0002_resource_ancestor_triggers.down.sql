@@ -0,0 +1,4 @@
+DROP TRIGGER IF EXISTS on_resource_update ON resources;
+DROP TRIGGER IF EXISTS on_resource_insert ON resources;
+DROP FUNCTION IF EXISTS manage_resource_ancestors_on_update();
+DROP FUNCTION IF EXISTS manage_resource_ancestors_on_insert();
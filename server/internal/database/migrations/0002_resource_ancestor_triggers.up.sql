@@ -0,0 +1,59 @@
+-- Keeps resource_ancestors in sync with resources.parent_id.
+
+-- Runs whenever a new file or folder is created. Copies the ancestry of the new
+-- parent and adds a self-referencing record.
+CREATE OR REPLACE FUNCTION manage_resource_ancestors_on_insert()
+RETURNS TRIGGER AS $$
+BEGIN
+    -- First, every resource is an ancestor of itself at depth 0.
+    INSERT INTO resource_ancestors (ancestor_id, descendant_id, depth)
+    VALUES (NEW.id, NEW.id, 0);
+
+    -- If the new resource has a parent, copy all of the parent's ancestors.
+    IF NEW.parent_id IS NOT NULL THEN
+        INSERT INTO resource_ancestors (ancestor_id, descendant_id, depth)
+        SELECT p.ancestor_id, NEW.id, p.depth + 1
+        FROM resource_ancestors AS p
+        WHERE p.descendant_id = NEW.parent_id;
+    END IF;
+
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS on_resource_insert ON resources;
+CREATE TRIGGER on_resource_insert
+AFTER INSERT ON resources
+FOR EACH ROW EXECUTE FUNCTION manage_resource_ancestors_on_insert();
+
+-- Runs when a resource's parent_id changes. Prunes the old branch from the
+-- hierarchy and grafts the moved subtree onto the new parent.
+CREATE OR REPLACE FUNCTION manage_resource_ancestors_on_update()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF NEW.parent_id IS DISTINCT FROM OLD.parent_id THEN
+
+        -- Step 1: Prune the old branch. Delete all old ancestor paths for the moved subtree.
+        DELETE FROM resource_ancestors
+        WHERE descendant_id IN (SELECT descendant_id FROM resource_ancestors WHERE ancestor_id = NEW.id)
+          AND ancestor_id NOT IN (SELECT descendant_id FROM resource_ancestors WHERE ancestor_id = NEW.id);
+
+        -- Step 2: Graft the new branch, unless the subtree was moved to the root.
+        IF NEW.parent_id IS NOT NULL THEN
+            INSERT INTO resource_ancestors (ancestor_id, descendant_id, depth)
+            SELECT p.ancestor_id, c.descendant_id, p.depth + c.depth + 1
+            FROM resource_ancestors AS p, resource_ancestors AS c
+            WHERE p.descendant_id = NEW.parent_id
+              AND c.ancestor_id = NEW.id;
+        END IF;
+
+    END IF;
+
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS on_resource_update ON resources;
+CREATE TRIGGER on_resource_update
+AFTER UPDATE ON resources
+FOR EACH ROW EXECUTE FUNCTION manage_resource_ancestors_on_update();
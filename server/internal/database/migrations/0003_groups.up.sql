@@ -0,0 +1,30 @@
+-- Groups as permission principals.
+
+CREATE TABLE IF NOT EXISTS groups (
+    id          bigserial PRIMARY KEY,
+    created_at  timestamptz,
+    updated_at  timestamptz,
+    deleted_at  timestamptz,
+    name        varchar(255) NOT NULL,
+    owner_id    bigint NOT NULL REFERENCES users (id) ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS idx_groups_deleted_at ON groups (deleted_at);
+CREATE INDEX IF NOT EXISTS idx_groups_owner_id ON groups (owner_id);
+
+CREATE TABLE IF NOT EXISTS group_members (
+    group_id    bigint NOT NULL REFERENCES groups (id) ON DELETE CASCADE,
+    user_id     bigint NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    is_admin    boolean NOT NULL DEFAULT false,
+    created_at  timestamptz,
+    PRIMARY KEY (group_id, user_id)
+);
+CREATE INDEX IF NOT EXISTS idx_group_members_user_id ON group_members (user_id);
+
+CREATE TABLE IF NOT EXISTS group_permissions (
+    resource_id  bigint NOT NULL REFERENCES resources (id) ON DELETE CASCADE,
+    group_id     bigint NOT NULL REFERENCES groups (id) ON DELETE CASCADE,
+    role         varchar(50) NOT NULL,
+    created_at   timestamptz,
+    PRIMARY KEY (resource_id, group_id)
+);
+CREATE INDEX IF NOT EXISTS idx_group_permissions_group_id ON group_permissions (group_id);
@@ -0,0 +1,9 @@
+DROP TABLE IF EXISTS audit_events;
+
+DROP INDEX IF EXISTS idx_group_permissions_expires_at;
+ALTER TABLE group_permissions DROP COLUMN IF EXISTS expiry_notified_at;
+ALTER TABLE group_permissions DROP COLUMN IF EXISTS expires_at;
+
+DROP INDEX IF EXISTS idx_permissions_expires_at;
+ALTER TABLE permissions DROP COLUMN IF EXISTS expiry_notified_at;
+ALTER TABLE permissions DROP COLUMN IF EXISTS expires_at;
@@ -0,0 +1,24 @@
+-- Time-bounded grants and the audit log written by the expiry job.
+
+ALTER TABLE permissions ADD COLUMN IF NOT EXISTS expires_at timestamptz;
+ALTER TABLE permissions ADD COLUMN IF NOT EXISTS expiry_notified_at timestamptz;
+CREATE INDEX IF NOT EXISTS idx_permissions_expires_at ON permissions (expires_at);
+
+ALTER TABLE group_permissions ADD COLUMN IF NOT EXISTS expires_at timestamptz;
+ALTER TABLE group_permissions ADD COLUMN IF NOT EXISTS expiry_notified_at timestamptz;
+CREATE INDEX IF NOT EXISTS idx_group_permissions_expires_at ON group_permissions (expires_at);
+
+CREATE TABLE IF NOT EXISTS audit_events (
+    id           bigserial PRIMARY KEY,
+    created_at   timestamptz,
+    updated_at   timestamptz,
+    deleted_at   timestamptz,
+    actor_id     bigint,
+    action       varchar(100) NOT NULL,
+    resource_id  bigint,
+    details      text
+);
+CREATE INDEX IF NOT EXISTS idx_audit_events_deleted_at ON audit_events (deleted_at);
+CREATE INDEX IF NOT EXISTS idx_audit_events_actor_id ON audit_events (actor_id);
+CREATE INDEX IF NOT EXISTS idx_audit_events_action ON audit_events (action);
+CREATE INDEX IF NOT EXISTS idx_audit_events_resource_id ON audit_events (resource_id);
@@ -0,0 +1,19 @@
+-- Requests for access filed from share links.
+
+CREATE TABLE IF NOT EXISTS access_requests (
+    id             bigserial PRIMARY KEY,
+    created_at     timestamptz,
+    updated_at     timestamptz,
+    deleted_at     timestamptz,
+    resource_id    bigint NOT NULL REFERENCES resources (id) ON DELETE CASCADE,
+    requester_id   bigint NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    role           varchar(50) NOT NULL,
+    message        text,
+    status         varchar(20) NOT NULL DEFAULT 'PENDING',
+    decided_by_id  bigint,
+    decided_at     timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_access_requests_deleted_at ON access_requests (deleted_at);
+CREATE INDEX IF NOT EXISTS idx_access_requests_resource_id ON access_requests (resource_id);
+CREATE INDEX IF NOT EXISTS idx_access_requests_requester_id ON access_requests (requester_id);
+CREATE INDEX IF NOT EXISTS idx_access_requests_status ON access_requests (status);
@@ -0,0 +1,19 @@
+-- Offers to hand a subtree over to another user.
+
+CREATE TABLE IF NOT EXISTS ownership_transfers (
+    id                  bigserial PRIMARY KEY,
+    created_at          timestamptz,
+    updated_at          timestamptz,
+    deleted_at          timestamptz,
+    resource_id         bigint NOT NULL REFERENCES resources (id) ON DELETE CASCADE,
+    from_user_id        bigint NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    to_user_id          bigint NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    keep_editor_access  boolean NOT NULL DEFAULT true,
+    status              varchar(20) NOT NULL DEFAULT 'PENDING',
+    decided_at          timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_ownership_transfers_deleted_at ON ownership_transfers (deleted_at);
+CREATE INDEX IF NOT EXISTS idx_ownership_transfers_resource_id ON ownership_transfers (resource_id);
+CREATE INDEX IF NOT EXISTS idx_ownership_transfers_from_user_id ON ownership_transfers (from_user_id);
+CREATE INDEX IF NOT EXISTS idx_ownership_transfers_to_user_id ON ownership_transfers (to_user_id);
+CREATE INDEX IF NOT EXISTS idx_ownership_transfers_status ON ownership_transfers (status);
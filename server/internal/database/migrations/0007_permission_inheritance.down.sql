@@ -0,0 +1,3 @@
+DELETE FROM permissions WHERE role = 'DENY';
+DELETE FROM group_permissions WHERE role = 'DENY';
+ALTER TABLE resources DROP COLUMN IF EXISTS inherit_permissions;
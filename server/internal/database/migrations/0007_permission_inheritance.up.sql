@@ -0,0 +1,3 @@
+-- Lets a resource stop ancestor grants and public flags from applying below it.
+
+ALTER TABLE resources ADD COLUMN IF NOT EXISTS inherit_permissions boolean NOT NULL DEFAULT true;
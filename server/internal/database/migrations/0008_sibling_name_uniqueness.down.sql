@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_resources_root_owner_name;
+DROP INDEX IF EXISTS idx_resources_parent_name;
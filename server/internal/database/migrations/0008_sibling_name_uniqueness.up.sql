@@ -0,0 +1,49 @@
+-- Live resources must have distinct names among their siblings: per parent folder, or
+-- per owner at the root. Path lookups rely on these indexes.
+
+-- Existing duplicates keep the oldest row's name; later ones get their id appended, and
+-- a counter after it if a sibling already has that name too.
+DO $$
+DECLARE
+    dup record;
+    suffix text;
+    candidate text;
+    attempt int;
+BEGIN
+    FOR dup IN
+        SELECT id, parent_id, owner_id, name FROM (
+            SELECT id, parent_id, owner_id, name, ROW_NUMBER() OVER (
+                PARTITION BY COALESCE(parent_id, 0), CASE WHEN parent_id IS NULL THEN owner_id END, name
+                ORDER BY id
+            ) AS position
+            FROM resources
+            WHERE deleted_at IS NULL
+        ) ranked
+        WHERE position > 1
+        ORDER BY id
+    LOOP
+        attempt := 0;
+        LOOP
+            suffix := ' (' || dup.id || CASE WHEN attempt > 0 THEN '-' || attempt ELSE '' END || ')';
+            candidate := left(dup.name, 255 - length(suffix)) || suffix;
+            EXIT WHEN NOT EXISTS (
+                SELECT 1 FROM resources
+                WHERE deleted_at IS NULL AND name = candidate
+                  AND CASE WHEN dup.parent_id IS NULL
+                      THEN parent_id IS NULL AND owner_id = dup.owner_id
+                      ELSE parent_id = dup.parent_id
+                  END
+            );
+            attempt := attempt + 1;
+        END LOOP;
+        UPDATE resources SET name = candidate WHERE id = dup.id;
+    END LOOP;
+END $$;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_resources_parent_name
+    ON resources (parent_id, name)
+    WHERE deleted_at IS NULL AND parent_id IS NOT NULL;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_resources_root_owner_name
+    ON resources (owner_id, name)
+    WHERE deleted_at IS NULL AND parent_id IS NULL;
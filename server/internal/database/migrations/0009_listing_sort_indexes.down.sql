@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_resources_owner_created;
+DROP INDEX IF EXISTS idx_resources_root_folders_first;
+DROP INDEX IF EXISTS idx_resources_children_updated;
+DROP INDEX IF EXISTS idx_resources_children_created;
+DROP INDEX IF EXISTS idx_resources_children_folders_first;
@@ -0,0 +1,24 @@
+-- Keyset pagination of folder listings walks these indexes instead of sorting every
+-- child. The folders-first expression must match the one built by internal/pagination.
+
+CREATE INDEX IF NOT EXISTS idx_resources_children_folders_first
+    ON resources (parent_id, (CASE WHEN type = 'folder' THEN 0 ELSE 1 END), name, id)
+    WHERE deleted_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_resources_children_created
+    ON resources (parent_id, created_at, id)
+    WHERE deleted_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_resources_children_updated
+    ON resources (parent_id, updated_at, id)
+    WHERE deleted_at IS NULL;
+
+-- Root listings are per owner.
+CREATE INDEX IF NOT EXISTS idx_resources_root_folders_first
+    ON resources (owner_id, (CASE WHEN type = 'folder' THEN 0 ELSE 1 END), name, id)
+    WHERE deleted_at IS NULL AND parent_id IS NULL;
+
+-- Search results and admin listings default to newest first.
+CREATE INDEX IF NOT EXISTS idx_resources_owner_created
+    ON resources (owner_id, created_at, id)
+    WHERE deleted_at IS NULL;
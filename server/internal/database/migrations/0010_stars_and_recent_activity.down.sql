@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS recent_activities;
+DROP TABLE IF EXISTS stars;
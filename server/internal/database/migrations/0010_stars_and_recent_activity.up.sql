@@ -0,0 +1,20 @@
+-- Per-user favourites and the latest interaction of each user with each resource.
+
+CREATE TABLE IF NOT EXISTS stars (
+    user_id      bigint NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    resource_id  bigint NOT NULL REFERENCES resources (id) ON DELETE CASCADE,
+    created_at   timestamptz,
+    PRIMARY KEY (user_id, resource_id)
+);
+CREATE INDEX IF NOT EXISTS idx_stars_resource_id ON stars (resource_id);
+
+CREATE TABLE IF NOT EXISTS recent_activities (
+    user_id      bigint NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    resource_id  bigint NOT NULL REFERENCES resources (id) ON DELETE CASCADE,
+    action       varchar(30) NOT NULL,
+    occurred_at  timestamptz NOT NULL,
+    PRIMARY KEY (user_id, resource_id)
+);
+CREATE INDEX IF NOT EXISTS idx_recent_activities_resource_id ON recent_activities (resource_id);
+-- The recent feed reads a user's newest entries first.
+CREATE INDEX IF NOT EXISTS idx_recent_activities_user_occurred ON recent_activities (user_id, occurred_at DESC);
@@ -0,0 +1,19 @@
+DROP INDEX IF EXISTS idx_tags_group_name;
+DROP INDEX IF EXISTS idx_tags_owner_name;
+ALTER TABLE tags DROP CONSTRAINT IF EXISTS chk_tags_namespace;
+
+-- Fold same-named tags back into one global tag, keeping the oldest row.
+INSERT INTO resource_tags (resource_id, tag_id)
+SELECT resource_tags.resource_id, survivor.id
+FROM resource_tags
+JOIN tags ON tags.id = resource_tags.tag_id
+JOIN (SELECT MIN(id) AS id, name FROM tags GROUP BY name) survivor ON survivor.name = tags.name
+ON CONFLICT DO NOTHING;
+
+DELETE FROM tags WHERE id NOT IN (SELECT MIN(id) FROM tags GROUP BY name);
+
+ALTER TABLE tags DROP COLUMN IF EXISTS description;
+ALTER TABLE tags DROP COLUMN IF EXISTS color;
+ALTER TABLE tags DROP COLUMN IF EXISTS group_id;
+ALTER TABLE tags DROP COLUMN IF EXISTS owner_id;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_tags_name ON tags (name);
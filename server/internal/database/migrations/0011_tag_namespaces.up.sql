@@ -0,0 +1,42 @@
+-- Tags belong to a namespace: a single user's, or a group's shared by its members.
+-- Names are unique per namespace, ignoring case.
+
+ALTER TABLE tags ADD COLUMN IF NOT EXISTS owner_id bigint REFERENCES users (id) ON DELETE CASCADE;
+ALTER TABLE tags ADD COLUMN IF NOT EXISTS group_id bigint REFERENCES groups (id) ON DELETE CASCADE;
+ALTER TABLE tags ADD COLUMN IF NOT EXISTS color varchar(7) NOT NULL DEFAULT '';
+ALTER TABLE tags ADD COLUMN IF NOT EXISTS description varchar(500) NOT NULL DEFAULT '';
+DROP INDEX IF EXISTS idx_tags_name;
+
+-- Tags used to be global. Every user gets their own copy of the live tags on their live
+-- resources, one per name ignoring case, and the resources are tagged with the copy
+-- belonging to their owner instead. Links to deleted tags or resources are dropped.
+INSERT INTO tags (created_at, updated_at, name, owner_id)
+SELECT MIN(tags.created_at), MAX(tags.updated_at), MIN(tags.name), resources.owner_id
+FROM tags
+JOIN resource_tags ON resource_tags.tag_id = tags.id
+JOIN resources ON resources.id = resource_tags.resource_id AND resources.deleted_at IS NULL
+WHERE tags.owner_id IS NULL AND tags.group_id IS NULL AND tags.deleted_at IS NULL
+GROUP BY lower(tags.name), resources.owner_id;
+
+-- "Urgent" and "urgent" on one resource become a single link.
+INSERT INTO resource_tags (resource_id, tag_id)
+SELECT DISTINCT resource_tags.resource_id, owned.id
+FROM resource_tags
+JOIN tags legacy ON legacy.id = resource_tags.tag_id
+JOIN resources ON resources.id = resource_tags.resource_id AND resources.deleted_at IS NULL
+JOIN tags owned ON owned.owner_id = resources.owner_id AND lower(owned.name) = lower(legacy.name)
+WHERE legacy.owner_id IS NULL AND legacy.group_id IS NULL AND legacy.deleted_at IS NULL
+ON CONFLICT DO NOTHING;
+
+-- Removing the legacy tags removes their links along with them.
+DELETE FROM tags WHERE owner_id IS NULL AND group_id IS NULL;
+
+ALTER TABLE tags ADD CONSTRAINT chk_tags_namespace CHECK ((owner_id IS NULL) <> (group_id IS NULL));
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_tags_owner_name
+    ON tags (owner_id, lower(name))
+    WHERE deleted_at IS NULL AND owner_id IS NOT NULL;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_tags_group_name
+    ON tags (group_id, lower(name))
+    WHERE deleted_at IS NULL AND group_id IS NOT NULL;
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_resources_name_search;
+DROP TABLE IF EXISTS file_contents;
@@ -0,0 +1,20 @@
+-- Full-text search over file contents. Text is extracted by a background indexer once
+-- per physical file; the tsvector is generated from it by the database.
+
+CREATE TABLE IF NOT EXISTS file_contents (
+    physical_file_id  bigint PRIMARY KEY REFERENCES physical_files (id) ON DELETE CASCADE,
+    status            varchar(20) NOT NULL,
+    content           text NOT NULL DEFAULT '',
+    error             text NOT NULL DEFAULT '',
+    attempts          integer NOT NULL DEFAULT 0,
+    indexed_at        timestamptz NOT NULL,
+    search_vector     tsvector GENERATED ALWAYS AS (to_tsvector('english', content)) STORED
+);
+CREATE INDEX IF NOT EXISTS idx_file_contents_search_vector ON file_contents USING GIN (search_vector);
+-- The indexer retries failed extractions.
+CREATE INDEX IF NOT EXISTS idx_file_contents_failed ON file_contents (physical_file_id) WHERE status = 'FAILED';
+
+-- Resource names take part in content queries too.
+CREATE INDEX IF NOT EXISTS idx_resources_name_search
+    ON resources USING GIN (to_tsvector('english', name))
+    WHERE deleted_at IS NULL;
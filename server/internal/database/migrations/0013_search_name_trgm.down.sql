@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_users_username_trgm;
+DROP INDEX IF EXISTS idx_resources_name_trgm;
+DROP FUNCTION IF EXISTS immutable_unaccent(text);
+-- The extensions stay installed; other objects in the database may depend on them.
@@ -0,0 +1,18 @@
+-- Case- and accent-insensitive substring search on names. unaccent() is only STABLE, so
+-- it is wrapped in an IMMUTABLE function pinning the dictionary, which can be indexed.
+
+CREATE EXTENSION IF NOT EXISTS unaccent;
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE OR REPLACE FUNCTION immutable_unaccent(text)
+RETURNS text
+LANGUAGE sql IMMUTABLE PARALLEL SAFE STRICT
+AS $$ SELECT public.unaccent('public.unaccent'::regdictionary, $1) $$;
+
+CREATE INDEX IF NOT EXISTS idx_resources_name_trgm
+    ON resources USING GIN (lower(immutable_unaccent(name)) gin_trgm_ops)
+    WHERE deleted_at IS NULL;
+
+-- Uploader filters match usernames the same way.
+CREATE INDEX IF NOT EXISTS idx_users_username_trgm
+    ON users USING GIN (lower(immutable_unaccent(username)) gin_trgm_ops);
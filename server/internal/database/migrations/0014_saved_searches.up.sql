@@ -0,0 +1,24 @@
+-- Named searches, shown as smart folders, and the users they are shared with.
+
+CREATE TABLE IF NOT EXISTS saved_searches (
+    id          bigserial PRIMARY KEY,
+    created_at  timestamptz,
+    updated_at  timestamptz,
+    deleted_at  timestamptz,
+    owner_id    bigint NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    name        varchar(255) NOT NULL,
+    filters     jsonb NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_saved_searches_deleted_at ON saved_searches (deleted_at);
+CREATE INDEX IF NOT EXISTS idx_saved_searches_owner_id ON saved_searches (owner_id);
+-- Names are unique per owner, ignoring case.
+CREATE UNIQUE INDEX IF NOT EXISTS idx_saved_searches_owner_name
+    ON saved_searches (owner_id, lower(name))
+    WHERE deleted_at IS NULL;
+
+CREATE TABLE IF NOT EXISTS saved_search_shares (
+    saved_search_id  bigint NOT NULL REFERENCES saved_searches (id) ON DELETE CASCADE,
+    user_id          bigint NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    PRIMARY KEY (saved_search_id, user_id)
+);
+CREATE INDEX IF NOT EXISTS idx_saved_search_shares_user_id ON saved_search_shares (user_id);
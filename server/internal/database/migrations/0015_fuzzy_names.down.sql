@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_resources_search_name_trgm;
+DROP FUNCTION IF EXISTS search_name(text);
+
+CREATE INDEX IF NOT EXISTS idx_resources_name_trgm
+    ON resources USING GIN (lower(immutable_unaccent(name)) gin_trgm_ops)
+    WHERE deleted_at IS NULL;
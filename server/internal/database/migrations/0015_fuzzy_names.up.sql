@@ -0,0 +1,14 @@
+-- Names are compared in a normalized form that ignores case, accents and separators,
+-- so "Q4 Report", "q4_report" and "q4-report.pdf" match each other. The trigram index
+-- serves both substring and fuzzy (similarity) matches on it.
+
+CREATE OR REPLACE FUNCTION search_name(text)
+RETURNS text
+LANGUAGE sql IMMUTABLE PARALLEL SAFE STRICT
+AS $$ SELECT btrim(regexp_replace(lower(immutable_unaccent($1)), '[[:space:]_.-]+', ' ', 'g')) $$;
+
+DROP INDEX IF EXISTS idx_resources_name_trgm;
+
+CREATE INDEX IF NOT EXISTS idx_resources_search_name_trgm
+    ON resources USING GIN (search_name(name) gin_trgm_ops)
+    WHERE deleted_at IS NULL;
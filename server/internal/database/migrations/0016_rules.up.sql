@@ -0,0 +1,19 @@
+-- Per-user automation rules applied to uploads: conditions and actions are JSON.
+
+CREATE TABLE IF NOT EXISTS rules (
+    id          bigserial PRIMARY KEY,
+    created_at  timestamptz,
+    updated_at  timestamptz,
+    deleted_at  timestamptz,
+    owner_id    bigint NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    name        varchar(255) NOT NULL,
+    enabled     boolean NOT NULL DEFAULT true,
+    conditions  jsonb NOT NULL,
+    actions     jsonb NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_rules_deleted_at ON rules (deleted_at);
+CREATE INDEX IF NOT EXISTS idx_rules_owner_id ON rules (owner_id);
+-- Names are unique per owner, ignoring case.
+CREATE UNIQUE INDEX IF NOT EXISTS idx_rules_owner_name
+    ON rules (owner_id, lower(name))
+    WHERE deleted_at IS NULL;
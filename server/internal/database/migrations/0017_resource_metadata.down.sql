@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_resources_metadata;
+ALTER TABLE resources DROP COLUMN IF EXISTS metadata;
+DROP TABLE IF EXISTS metadata_fields;
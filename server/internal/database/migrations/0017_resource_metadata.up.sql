@@ -0,0 +1,35 @@
+-- Custom metadata: fields are defined per user or per group, like tags, and the values
+-- are stored on the resources as a JSON object keyed by field ID.
+
+CREATE TABLE IF NOT EXISTS metadata_fields (
+    id          bigserial PRIMARY KEY,
+    created_at  timestamptz,
+    updated_at  timestamptz,
+    deleted_at  timestamptz,
+    owner_id    bigint REFERENCES users (id) ON DELETE CASCADE,
+    group_id    bigint REFERENCES groups (id) ON DELETE CASCADE,
+    name        varchar(100) NOT NULL,
+    type        varchar(20) NOT NULL,
+    required    boolean NOT NULL DEFAULT false,
+    options     jsonb NOT NULL DEFAULT '[]',
+    CONSTRAINT chk_metadata_fields_namespace CHECK ((owner_id IS NULL) <> (group_id IS NULL)),
+    CONSTRAINT chk_metadata_fields_type CHECK (type IN ('STRING', 'NUMBER', 'DATE', 'ENUM'))
+);
+CREATE INDEX IF NOT EXISTS idx_metadata_fields_deleted_at ON metadata_fields (deleted_at);
+CREATE INDEX IF NOT EXISTS idx_metadata_fields_owner_id ON metadata_fields (owner_id);
+CREATE INDEX IF NOT EXISTS idx_metadata_fields_group_id ON metadata_fields (group_id);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_metadata_fields_owner_name
+    ON metadata_fields (owner_id, lower(name))
+    WHERE deleted_at IS NULL AND owner_id IS NOT NULL;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_metadata_fields_group_name
+    ON metadata_fields (group_id, lower(name))
+    WHERE deleted_at IS NULL AND group_id IS NOT NULL;
+
+ALTER TABLE resources ADD COLUMN IF NOT EXISTS metadata jsonb NOT NULL DEFAULT '{}';
+
+-- Serves the exact-match searches, which are written as containment (@>).
+CREATE INDEX IF NOT EXISTS idx_resources_metadata
+    ON resources USING GIN (metadata jsonb_path_ops)
+    WHERE deleted_at IS NULL;
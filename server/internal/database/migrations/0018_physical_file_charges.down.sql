@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_physical_files_charged_user_id;
+ALTER TABLE physical_files DROP COLUMN IF EXISTS charged_user_id;
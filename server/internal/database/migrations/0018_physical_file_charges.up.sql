@@ -0,0 +1,15 @@
+-- Stored content is charged to the user who uploaded it. Remembering who that was lets
+-- the charge go back to them when the content is released, whoever deletes the last
+-- file pointing to it.
+
+ALTER TABLE physical_files ADD COLUMN IF NOT EXISTS charged_user_id bigint REFERENCES users (id) ON DELETE SET NULL;
+CREATE INDEX IF NOT EXISTS idx_physical_files_charged_user_id ON physical_files (charged_user_id);
+
+-- The first file created for some content is the upload that stored it.
+UPDATE physical_files SET charged_user_id = (
+    SELECT resources.owner_id FROM resources
+    WHERE resources.physical_file_id = physical_files.id
+    ORDER BY resources.id
+    LIMIT 1
+)
+WHERE charged_user_id IS NULL;
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS idx_resources_metadata_keys;
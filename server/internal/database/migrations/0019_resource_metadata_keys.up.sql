@@ -0,0 +1,5 @@
+-- Finds the resources holding a value for some field (metadata ? key), as when a field
+-- is deleted. The jsonb_path_ops index only serves containment.
+CREATE INDEX IF NOT EXISTS idx_resources_metadata_keys
+    ON resources USING GIN (metadata)
+    WHERE deleted_at IS NULL;
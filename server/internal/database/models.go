@@ -20,8 +20,9 @@ type User struct {
 	StorageUsed              int    `gorm:"not null"`
 	DeduplicationStorageUsed int    `gorm:"not null"`
 	// "Has Many" relationships for easier preloading
-	Resources   []Resource   `gorm:"foreignKey:OwnerID"`
-	Permissions []Permission `gorm:"foreignKey:UserID"`
+	Resources   []Resource    `gorm:"foreignKey:OwnerID"`
+	Permissions []Permission  `gorm:"foreignKey:UserID"`
+	Groups      []GroupMember `gorm:"foreignKey:UserID"`
 }
 
 // PhysicalFile represents the actual file on disk for deduplication
@@ -32,6 +33,8 @@ type PhysicalFile struct {
 	SizeBytes      int64  `gorm:"not null;index"`
 	MimeType       string `gorm:"size:255;not null;index"`
 	ReferenceCount int    `gorm:"default:1;not null"`
+	// ChargedUserID is the user whose deduplicated storage the content counts towards.
+	ChargedUserID *uint `gorm:"index"`
 }
 
 // ResourceType defines if a resource is a file or folder.
@@ -42,28 +45,41 @@ const (
 	Folder ResourceType = "folder"
 )
 
+// Tag labels resources within a namespace: exactly one of OwnerID and GroupID is set.
+// Names are unique per namespace, ignoring case.
 type Tag struct {
 	gorm.Model
-	Name string `gorm:"size:100;uniqueIndex;not null"` // Tag names should be unique
+	OwnerID     *uint  `gorm:"index"`
+	GroupID     *uint  `gorm:"index"`
+	Name        string `gorm:"size:100;not null"`
+	Color       string `gorm:"size:7;not null;default:''"` // "#RRGGBB", or empty
+	Description string `gorm:"size:500;not null;default:''"`
 }
 
 // Resource unifies files and folders into a single table.
 type Resource struct {
 	gorm.Model
-	OwnerID        uint          `gorm:"index"` // Indexed for performance
-	User           User          `gorm:"foreignKey:OwnerID;constraint:OnDelete:CASCADE;"`
-	ParentID       *uint         `gorm:"index"` // Indexed for performance
-	Parent         *Resource     `gorm:"foreignKey:ParentID;constraint:OnDelete:CASCADE;"`
-	Name           string        `gorm:"size:255;not null;index"`
-	IsPublic       bool          `gorm:"default:false;not null;index"`
-	ShareToken     *string       `gorm:"size:255;uniqueIndex"`
-	Type           ResourceType  `gorm:"type:varchar(50);not null;index"` // Explicit type
-	PhysicalFileID *uint         `gorm:"index"`
-	Tags           []*Tag        `gorm:"many2many:resource_tags;"`
-	PhysicalFile   *PhysicalFile `gorm:"foreignKey:PhysicalFileID;constraint:OnDelete:RESTRICT;"`
+	OwnerID            uint          `gorm:"index"` // Indexed for performance
+	User               User          `gorm:"foreignKey:OwnerID;constraint:OnDelete:CASCADE;"`
+	ParentID           *uint         `gorm:"index"` // Indexed for performance
+	Parent             *Resource     `gorm:"foreignKey:ParentID;constraint:OnDelete:CASCADE;"`
+	Name               string        `gorm:"size:255;not null;index"`
+	IsPublic           bool          `gorm:"default:false;not null;index"`
+	InheritPermissions bool          `gorm:"default:true;not null"` // When false, ancestor grants and public flags stop here
+	ShareToken         *string       `gorm:"size:255;uniqueIndex"`
+	Type               ResourceType  `gorm:"type:varchar(50);not null;index"` // Explicit type
+	PhysicalFileID     *uint         `gorm:"index"`
+	Tags               []*Tag        `gorm:"many2many:resource_tags;"`
+	PhysicalFile       *PhysicalFile `gorm:"foreignKey:PhysicalFileID;constraint:OnDelete:RESTRICT;"`
 	// "Has Many" relationships for easier preloading
-	Permissions []Permission `gorm:"foreignKey:ResourceID"`
-	Children    []Resource   `gorm:"foreignKey:ParentID"`
+	Permissions      []Permission      `gorm:"foreignKey:ResourceID"`
+	GroupPermissions []GroupPermission `gorm:"foreignKey:ResourceID"`
+	Children         []Resource        `gorm:"foreignKey:ParentID"`
+	// Metadata holds the values of custom metadata fields, keyed by field ID. It is only
+	// written by the metadata package, so saving a resource never overwrites it.
+	Metadata string `gorm:"type:jsonb;not null;default:'{}';->"`
+	// SearchRank is only filled in by full-text searches; it is not a column.
+	SearchRank float64 `gorm:"->;-:migration"`
 }
 
 // RoleType defines the permission levels.
@@ -72,6 +88,8 @@ type RoleType string
 const (
 	Viewer RoleType = "VIEWER"
 	Editor RoleType = "EDITOR"
+	// Deny explicitly blocks access that would otherwise be inherited from an ancestor.
+	Deny RoleType = "DENY"
 )
 
 // Permission is the Access Control List (ACL) table.
@@ -82,6 +100,42 @@ type Permission struct {
 	User       User     `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE;"`
 	Role       RoleType `gorm:"type:varchar(50);not null"` // Explicit type
 	CreatedAt  time.Time
+	// ExpiresAt is nil for permanent grants. Expired grants are ignored by every
+	// authorization check and purged by a background job.
+	ExpiresAt        *time.Time `gorm:"index"`
+	ExpiryNotifiedAt *time.Time
+}
+
+// Group is a named set of users that can be granted access to resources as a whole.
+type Group struct {
+	gorm.Model
+	Name    string        `gorm:"size:255;not null"`
+	OwnerID uint          `gorm:"index;not null"`
+	Owner   User          `gorm:"foreignKey:OwnerID;constraint:OnDelete:CASCADE;"`
+	Members []GroupMember `gorm:"foreignKey:GroupID"`
+}
+
+// GroupMember links a user to a group. Group admins can manage the membership.
+type GroupMember struct {
+	GroupID   uint  `gorm:"primaryKey"`
+	UserID    uint  `gorm:"primaryKey;index"`
+	Group     Group `gorm:"foreignKey:GroupID;constraint:OnDelete:CASCADE;"`
+	User      User  `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE;"`
+	IsAdmin   bool  `gorm:"default:false;not null"`
+	CreatedAt time.Time
+}
+
+// GroupPermission is the ACL entry for grants whose principal is a group.
+type GroupPermission struct {
+	ResourceID uint     `gorm:"primaryKey"`
+	GroupID    uint     `gorm:"primaryKey;index"`
+	Resource   Resource `gorm:"foreignKey:ResourceID;constraint:OnDelete:CASCADE;"`
+	Group      Group    `gorm:"foreignKey:GroupID;constraint:OnDelete:CASCADE;"`
+	Role       RoleType `gorm:"type:varchar(50);not null"`
+	CreatedAt  time.Time
+	// ExpiresAt behaves exactly like Permission.ExpiresAt.
+	ExpiresAt        *time.Time `gorm:"index"`
+	ExpiryNotifiedAt *time.Time
 }
 
 // ResourceAncestor is the Closure Table for fast hierarchy lookups.
@@ -92,3 +146,161 @@ type ResourceAncestor struct {
 	Descendant   Resource `gorm:"foreignKey:DescendantID;constraint:OnDelete:CASCADE;"`
 	Depth        int      `gorm:"not null"`
 }
+
+// AccessRequestStatus tracks where an access request is in its lifecycle.
+type AccessRequestStatus string
+
+const (
+	AccessRequestPending  AccessRequestStatus = "PENDING"
+	AccessRequestApproved AccessRequestStatus = "APPROVED"
+	AccessRequestDenied   AccessRequestStatus = "DENIED"
+)
+
+// AccessRequest is filed by a user who opened a share link they have no access to.
+// The resource owner approves it (creating a Permission) or denies it.
+type AccessRequest struct {
+	gorm.Model
+	ResourceID  uint                `gorm:"index;not null"`
+	Resource    Resource            `gorm:"foreignKey:ResourceID;constraint:OnDelete:CASCADE;"`
+	RequesterID uint                `gorm:"index;not null"`
+	Requester   User                `gorm:"foreignKey:RequesterID;constraint:OnDelete:CASCADE;"`
+	Role        RoleType            `gorm:"type:varchar(50);not null"`
+	Message     string              `gorm:"type:text"`
+	Status      AccessRequestStatus `gorm:"type:varchar(20);not null;default:'PENDING';index"`
+	DecidedByID *uint
+	DecidedAt   *time.Time
+}
+
+// OwnershipTransferStatus tracks where an ownership transfer is in its lifecycle.
+type OwnershipTransferStatus string
+
+const (
+	TransferPending   OwnershipTransferStatus = "PENDING"
+	TransferAccepted  OwnershipTransferStatus = "ACCEPTED"
+	TransferDeclined  OwnershipTransferStatus = "DECLINED"
+	TransferCancelled OwnershipTransferStatus = "CANCELLED"
+)
+
+// OwnershipTransfer is an offer to hand a resource, and everything below it, over to
+// another user. Nothing changes hands until the recipient accepts.
+type OwnershipTransfer struct {
+	gorm.Model
+	ResourceID       uint                    `gorm:"index;not null"`
+	Resource         Resource                `gorm:"foreignKey:ResourceID;constraint:OnDelete:CASCADE;"`
+	FromUserID       uint                    `gorm:"index;not null"`
+	FromUser         User                    `gorm:"foreignKey:FromUserID;constraint:OnDelete:CASCADE;"`
+	ToUserID         uint                    `gorm:"index;not null"`
+	ToUser           User                    `gorm:"foreignKey:ToUserID;constraint:OnDelete:CASCADE;"`
+	KeepEditorAccess bool                    `gorm:"default:true;not null"`
+	Status           OwnershipTransferStatus `gorm:"type:varchar(20);not null;default:'PENDING';index"`
+	DecidedAt        *time.Time
+}
+
+// AuditEvent records a security-relevant change for later review.
+type AuditEvent struct {
+	gorm.Model
+	ActorID    *uint  `gorm:"index"` // nil for events raised by background jobs
+	Action     string `gorm:"size:100;not null;index"`
+	ResourceID *uint  `gorm:"index"`
+	Details    string `gorm:"type:text"`
+}
+
+// Star marks a resource as a favourite of a user.
+type Star struct {
+	UserID     uint     `gorm:"primaryKey"`
+	ResourceID uint     `gorm:"primaryKey;index"`
+	User       User     `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE;"`
+	Resource   Resource `gorm:"foreignKey:ResourceID;constraint:OnDelete:CASCADE;"`
+	CreatedAt  time.Time
+}
+
+// ActivityAction is what a user last did with a resource.
+type ActivityAction string
+
+const (
+	ActivityUploaded        ActivityAction = "UPLOADED"
+	ActivityModified        ActivityAction = "MODIFIED"
+	ActivityDownloaded      ActivityAction = "DOWNLOADED"
+	ActivityOpenedShareLink ActivityAction = "OPENED_SHARE_LINK"
+)
+
+// RecentActivity keeps the latest interaction of a user with a resource, so the table
+// holds at most one row per pair no matter how often a file is used.
+type RecentActivity struct {
+	UserID     uint           `gorm:"primaryKey"`
+	ResourceID uint           `gorm:"primaryKey;index"`
+	User       User           `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE;"`
+	Resource   Resource       `gorm:"foreignKey:ResourceID;constraint:OnDelete:CASCADE;"`
+	Action     ActivityAction `gorm:"type:varchar(30);not null"`
+	OccurredAt time.Time      `gorm:"not null"`
+}
+
+// ContentStatus is the outcome of extracting a blob's text for full-text search.
+type ContentStatus string
+
+const (
+	ContentIndexed     ContentStatus = "INDEXED"
+	ContentUnsupported ContentStatus = "UNSUPPORTED"
+	ContentFailed      ContentStatus = "FAILED"
+)
+
+// FileContent holds the searchable text of a physical file. Text is extracted once per
+// blob, so deduplicated uploads share it. The search_vector column is generated from
+// Content by the database.
+type FileContent struct {
+	PhysicalFileID uint          `gorm:"primaryKey"`
+	PhysicalFile   PhysicalFile  `gorm:"foreignKey:PhysicalFileID;constraint:OnDelete:CASCADE;"`
+	Status         ContentStatus `gorm:"type:varchar(20);not null"`
+	Content        string        `gorm:"type:text;not null"`
+	Error          string        `gorm:"type:text;not null"`
+	Attempts       int           `gorm:"not null"`
+	IndexedAt      time.Time     `gorm:"not null"`
+}
+
+// SavedSearch is a named set of search filters. It is shown as a smart folder whose
+// contents are computed live, under the permissions of whoever opens it. The owner can
+// share it read-only with other users.
+type SavedSearch struct {
+	gorm.Model
+	OwnerID    uint   `gorm:"not null;index"`
+	Owner      User   `gorm:"foreignKey:OwnerID;constraint:OnDelete:CASCADE;"`
+	Name       string `gorm:"size:255;not null"`
+	Filters    string `gorm:"type:jsonb;not null"` // savedsearch.Filters, as JSON
+	SharedWith []User `gorm:"many2many:saved_search_shares;"`
+}
+
+// Rule is one of a user's automations: files they upload that match its conditions
+// get its actions applied. Rules run in the order they were created.
+type Rule struct {
+	gorm.Model
+	OwnerID    uint   `gorm:"not null;index"`
+	Owner      User   `gorm:"foreignKey:OwnerID;constraint:OnDelete:CASCADE;"`
+	Name       string `gorm:"size:255;not null"`
+	Enabled    bool   `gorm:"not null"`
+	Conditions string `gorm:"type:jsonb;not null"` // rules.Conditions, as JSON
+	Actions    string `gorm:"type:jsonb;not null"` // rules.Actions, as JSON
+}
+
+// MetadataFieldType is the type of the values of a custom metadata field.
+type MetadataFieldType string
+
+const (
+	MetadataString MetadataFieldType = "STRING"
+	MetadataNumber MetadataFieldType = "NUMBER"
+	MetadataDate   MetadataFieldType = "DATE" // Stored as YYYY-MM-DD
+	MetadataEnum   MetadataFieldType = "ENUM" // One of the field's options
+)
+
+// MetadataField is a field of a custom metadata schema. Like tags, fields belong to a
+// namespace: exactly one of OwnerID and GroupID is set. Names are unique per namespace,
+// ignoring case.
+type MetadataField struct {
+	gorm.Model
+	OwnerID  *uint             `gorm:"index"`
+	GroupID  *uint             `gorm:"index"`
+	Group    *Group            `gorm:"foreignKey:GroupID;constraint:OnDelete:CASCADE;"`
+	Name     string            `gorm:"size:100;not null"`
+	Type     MetadataFieldType `gorm:"type:varchar(20);not null"`
+	Required bool              `gorm:"not null"`
+	Options  string            `gorm:"type:jsonb;not null"` // The values of an ENUM field, as a JSON array
+}
@@ -0,0 +1,113 @@
+package dedup
+
+import (
+	"github.com/bhavyajaix/BalkanID-filevault/internal/database"
+	"gorm.io/gorm"
+)
+
+// Content is a physical file that several of a user's files point to.
+type Content struct {
+	database.PhysicalFile
+	Copies int64
+}
+
+// Totals sums up the files a user owns, per copy and per distinct content.
+type Totals struct {
+	FileCount       int64
+	LogicalBytes    int64
+	UniqueBytes     int64
+	StoredBytes     int64
+	DuplicateGroups int64
+	DuplicateFiles  int64
+}
+
+// Repository is the interface for the queries behind duplicate detection.
+type Repository interface {
+	DuplicateContents(ownerID uint, limit int) ([]Content, error)
+	FilesWithContent(ownerID uint, physicalFileIDs []uint) ([]database.Resource, error)
+	Totals(ownerID uint) (*Totals, error)
+	FindFile(id uint) (*database.Resource, error)
+	SharedFileIDs(resourceIDs []uint) ([]uint, error)
+}
+
+type repository struct {
+	db *gorm.DB
+}
+
+// NewRepository creates a new dedup repository.
+func NewRepository(db *gorm.DB) Repository {
+	return &repository{db: db}
+}
+
+// ownedContents counts a user's files per physical file.
+const ownedContents = `
+SELECT physical_files.*, count(resources.id) AS copies
+FROM resources
+JOIN physical_files ON physical_files.id = resources.physical_file_id AND physical_files.deleted_at IS NULL
+WHERE resources.owner_id = ? AND resources.type = ? AND resources.deleted_at IS NULL
+GROUP BY physical_files.id`
+
+// DuplicateContents returns the contents a user has more than one file of, those
+// wasting the most bytes first.
+func (r *repository) DuplicateContents(ownerID uint, limit int) ([]Content, error) {
+	var contents []Content
+	err := r.db.Raw(`SELECT * FROM (`+ownedContents+`) AS owned
+WHERE copies > 1
+ORDER BY size_bytes * (copies - 1) DESC, id ASC
+LIMIT ?`, ownerID, database.File, limit).Scan(&contents).Error
+	return contents, err
+}
+
+// FilesWithContent returns a user's files that point to the given physical files,
+// oldest first.
+func (r *repository) FilesWithContent(ownerID uint, physicalFileIDs []uint) ([]database.Resource, error) {
+	var resources []database.Resource
+	err := r.db.Preload("PhysicalFile").Preload("User").
+		Where("owner_id = ? AND type = ? AND physical_file_id IN ?", ownerID, database.File, physicalFileIDs).
+		Order("created_at ASC, id ASC").
+		Find(&resources).Error
+	return resources, err
+}
+
+// Totals adds up a user's files. Stored bytes give each file an equal part of its
+// physical file, split among every resource that shares it, whoever owns them. The
+// parts are summed exactly and only the total is rounded, so small files shared many
+// times still count.
+func (r *repository) Totals(ownerID uint) (*Totals, error) {
+	var totals Totals
+	err := r.db.Raw(`SELECT
+	coalesce(sum(copies), 0) AS file_count,
+	coalesce(sum(size_bytes * copies), 0) AS logical_bytes,
+	coalesce(sum(size_bytes), 0) AS unique_bytes,
+	coalesce(round(sum(size_bytes::numeric * copies / GREATEST(reference_count, 1))), 0)::bigint AS stored_bytes,
+	count(*) FILTER (WHERE copies > 1) AS duplicate_groups,
+	coalesce(sum(copies - 1), 0) AS duplicate_files
+FROM (`+ownedContents+`) AS owned`, ownerID, database.File).Scan(&totals).Error
+	if err != nil {
+		return nil, err
+	}
+	return &totals, nil
+}
+
+// SharedFileIDs returns those of the given resources that are public or that someone
+// other than their owner holds a grant on.
+func (r *repository) SharedFileIDs(resourceIDs []uint) ([]uint, error) {
+	var shared []uint
+	err := r.db.Model(&database.Resource{}).
+		Where("id IN ?", resourceIDs).
+		Where(`is_public OR EXISTS (
+	SELECT 1 FROM permissions WHERE permissions.resource_id = resources.id AND permissions.user_id <> resources.owner_id
+) OR EXISTS (
+	SELECT 1 FROM group_permissions WHERE group_permissions.resource_id = resources.id
+)`).
+		Pluck("id", &shared).Error
+	return shared, err
+}
+
+func (r *repository) FindFile(id uint) (*database.Resource, error) {
+	var resource database.Resource
+	if err := r.db.Preload("PhysicalFile").Preload("User").Where("type = ?", database.File).First(&resource, id).Error; err != nil {
+		return nil, err
+	}
+	return &resource, nil
+}
@@ -0,0 +1,274 @@
+package dedup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/bhavyajaix/BalkanID-filevault/internal/database"
+	"github.com/bhavyajaix/BalkanID-filevault/internal/middleware"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultGroupLimit = 50
+	maxGroupLimit     = 200
+)
+
+// Deleter removes a user's resources. It is satisfied by file.Service.
+type Deleter interface {
+	DeleteResources(resourceIDs []uint, userID uint) ([]error, error)
+}
+
+// Group is a content with the caller's files that point to it, oldest first.
+type Group struct {
+	Content database.PhysicalFile
+	Files   []database.Resource
+}
+
+// WastedBytes is the logical storage taken by all copies but one.
+func (g Group) WastedBytes() int64 {
+	if len(g.Files) < 2 {
+		return 0
+	}
+	return g.Content.SizeBytes * int64(len(g.Files)-1)
+}
+
+// Summary describes how much deduplication saves a user, and how much more removing
+// their own duplicate files would free.
+type Summary struct {
+	Totals
+	SavedBytes      int64
+	SavedPercentage float64
+	WastedBytes     int64
+}
+
+// CollapseRequest names the file to keep and the copies of its content to delete.
+// Copies that others have access to are only deleted when Force is set. A dry run
+// deletes nothing; without RemoveIDs it considers every other copy.
+type CollapseRequest struct {
+	KeepID    uint
+	RemoveIDs []uint
+	DryRun    bool
+	Force     bool
+}
+
+// CollapseResult reports which copies a collapse removed, or would remove on a dry
+// run. Errors lines up with RemovedIDs and is nil for the copies that were deleted.
+type CollapseResult struct {
+	Kept       *database.Resource
+	RemovedIDs []uint
+	Errors     []error
+	FreedBytes int64
+	DryRun     bool
+}
+
+// Service finds files with identical content. Uploads of known content already share
+// one physical file, so duplicates cost no disk space, but every copy still counts
+// towards its owner's logical storage.
+type Service interface {
+	Duplicates(ctx context.Context, limit int) ([]Group, error)
+	Summary(ctx context.Context) (*Summary, error)
+	Collapse(ctx context.Context, req CollapseRequest) (*CollapseResult, error)
+}
+
+type service struct {
+	repo    Repository
+	deleter Deleter
+}
+
+// NewService creates a new dedup service.
+func NewService(repo Repository, deleter Deleter) Service {
+	return &service{repo: repo, deleter: deleter}
+}
+
+func getUserIDFromContext(ctx context.Context) (uint, error) {
+	userID, ok := ctx.Value(middleware.UserContextKey).(uint)
+	if !ok {
+		return 0, errors.New("unauthorized")
+	}
+	return userID, nil
+}
+
+// Duplicates lists the contents the caller owns several files of, with those files,
+// the groups wasting the most bytes first. A limit of zero picks the default.
+func (s *service) Duplicates(ctx context.Context, limit int) ([]Group, error) {
+	userID, err := getUserIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case limit < 0:
+		return nil, errors.New("limit cannot be negative")
+	case limit == 0:
+		limit = defaultGroupLimit
+	case limit > maxGroupLimit:
+		limit = maxGroupLimit
+	}
+
+	contents, err := s.repo.DuplicateContents(userID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find duplicates: %w", err)
+	}
+	if len(contents) == 0 {
+		return []Group{}, nil
+	}
+
+	ids := make([]uint, len(contents))
+	for i, content := range contents {
+		ids[i] = content.ID
+	}
+	files, err := s.repo.FilesWithContent(userID, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load duplicate files: %w", err)
+	}
+	byContent := make(map[uint][]database.Resource, len(contents))
+	for _, file := range files {
+		byContent[*file.PhysicalFileID] = append(byContent[*file.PhysicalFileID], file)
+	}
+
+	groups := make([]Group, 0, len(contents))
+	for _, content := range contents {
+		// A file deleted since the first query can leave a single copy behind.
+		if len(byContent[content.ID]) < 2 {
+			continue
+		}
+		groups = append(groups, Group{Content: content.PhysicalFile, Files: byContent[content.ID]})
+	}
+	return groups, nil
+}
+
+// Summary adds up the caller's files.
+func (s *service) Summary(ctx context.Context) (*Summary, error) {
+	userID, err := getUserIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	totals, err := s.repo.Totals(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute storage totals: %w", err)
+	}
+
+	summary := &Summary{
+		Totals:      *totals,
+		SavedBytes:  totals.LogicalBytes - totals.StoredBytes,
+		WastedBytes: totals.LogicalBytes - totals.UniqueBytes,
+	}
+	if totals.LogicalBytes > 0 {
+		summary.SavedPercentage = float64(summary.SavedBytes) / float64(totals.LogicalBytes) * 100
+	}
+	return summary, nil
+}
+
+// Collapse keeps one of the caller's files and deletes the listed copies of its
+// content, freeing the logical storage they took. Every id gets its own outcome: ids
+// that are not other copies of the kept file's content, and copies someone else has
+// access to unless the request forces it, are refused. A dry run checks the copies
+// without deleting them and, given no ids, lists all of them.
+func (s *service) Collapse(ctx context.Context, req CollapseRequest) (*CollapseResult, error) {
+	userID, err := getUserIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(req.RemoveIDs) == 0 && !req.DryRun {
+		return nil, errors.New("list the copies to remove, or ask for a dry run to see them")
+	}
+
+	keep, err := s.repo.FindFile(req.KeepID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("file not found")
+		}
+		return nil, err
+	}
+	if keep.OwnerID != userID {
+		return nil, errors.New("access denied: you can only collapse duplicates of your own files")
+	}
+	if keep.PhysicalFileID == nil || keep.PhysicalFile == nil {
+		return nil, errors.New("file has no stored content")
+	}
+
+	files, err := s.repo.FilesWithContent(userID, []uint{*keep.PhysicalFileID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load duplicate files: %w", err)
+	}
+	copies := make(map[uint]bool, len(files))
+	for _, file := range files {
+		if file.ID != keep.ID {
+			copies[file.ID] = true
+		}
+	}
+
+	result := &CollapseResult{RemovedIDs: []uint{}, Errors: []error{}, DryRun: req.DryRun}
+	if len(req.RemoveIDs) == 0 {
+		for _, file := range files {
+			if copies[file.ID] {
+				result.RemovedIDs = append(result.RemovedIDs, file.ID)
+			}
+		}
+	} else {
+		result.RemovedIDs = append(result.RemovedIDs, req.RemoveIDs...)
+	}
+	result.Errors = make([]error, len(result.RemovedIDs))
+
+	// Only copies that are still removable go on to be checked for grants and deleted.
+	var candidates []uint
+	for i, id := range result.RemovedIDs {
+		switch {
+		case id == keep.ID:
+			result.Errors[i] = errors.New("this is the file being kept")
+		case !copies[id]:
+			result.Errors[i] = errors.New("not one of your copies of this content")
+		default:
+			candidates = append(candidates, id)
+		}
+	}
+	if len(candidates) > 0 && !req.Force {
+		shared, err := s.repo.SharedFileIDs(candidates)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check sharing: %w", err)
+		}
+		isShared := make(map[uint]bool, len(shared))
+		for _, id := range shared {
+			isShared[id] = true
+		}
+		candidates = candidates[:0]
+		for i, id := range result.RemovedIDs {
+			if result.Errors[i] != nil {
+				continue
+			}
+			if isShared[id] {
+				result.Errors[i] = errors.New("copy is shared or public; force the collapse to delete it with its grants")
+				continue
+			}
+			candidates = append(candidates, id)
+		}
+	}
+
+	if len(candidates) > 0 && !req.DryRun {
+		errs, err := s.deleter.DeleteResources(candidates, userID)
+		if err != nil {
+			return nil, err
+		}
+		byID := make(map[uint]error, len(candidates))
+		for i, id := range candidates {
+			byID[id] = errs[i]
+		}
+		for i, id := range result.RemovedIDs {
+			if result.Errors[i] == nil {
+				result.Errors[i] = byID[id]
+			}
+		}
+	}
+	for _, err := range result.Errors {
+		if err == nil {
+			result.FreedBytes += keep.PhysicalFile.SizeBytes
+		}
+	}
+
+	// Reload the kept file: its content now has fewer references.
+	if result.Kept, err = s.repo.FindFile(keep.ID); err != nil {
+		return nil, fmt.Errorf("failed to reload kept file: %w", err)
+	}
+	return result, nil
+}
@@ -0,0 +1,183 @@
+package dedup
+
+import (
+	"context"
+	"sort"
+	"testing"
+
+	"github.com/bhavyajaix/BalkanID-filevault/internal/database"
+	"github.com/bhavyajaix/BalkanID-filevault/internal/middleware"
+	"gorm.io/gorm"
+)
+
+// memoryRepository holds one user's files. Only the queries Collapse uses are kept.
+type memoryRepository struct {
+	Repository
+	files  map[uint]*database.Resource
+	shared map[uint]bool
+}
+
+func (r *memoryRepository) FindFile(id uint) (*database.Resource, error) {
+	file, ok := r.files[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return file, nil
+}
+
+func (r *memoryRepository) FilesWithContent(ownerID uint, physicalFileIDs []uint) ([]database.Resource, error) {
+	var files []database.Resource
+	for _, file := range r.files {
+		if file.OwnerID != ownerID {
+			continue
+		}
+		for _, contentID := range physicalFileIDs {
+			if *file.PhysicalFileID == contentID {
+				files = append(files, *file)
+			}
+		}
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].ID < files[j].ID })
+	return files, nil
+}
+
+func (r *memoryRepository) SharedFileIDs(resourceIDs []uint) ([]uint, error) {
+	var shared []uint
+	for _, id := range resourceIDs {
+		if r.shared[id] {
+			shared = append(shared, id)
+		}
+	}
+	return shared, nil
+}
+
+// recordingDeleter deletes files from the repository and remembers which.
+type recordingDeleter struct {
+	repo    *memoryRepository
+	deleted []uint
+}
+
+func (d *recordingDeleter) DeleteResources(resourceIDs []uint, userID uint) ([]error, error) {
+	for _, id := range resourceIDs {
+		delete(d.repo.files, id)
+		d.deleted = append(d.deleted, id)
+	}
+	return make([]error, len(resourceIDs)), nil
+}
+
+func TestCollapse(t *testing.T) {
+	const owner = 1
+	ctx := context.WithValue(context.Background(), middleware.UserContextKey, uint(owner))
+
+	tests := []struct {
+		name        string
+		req         CollapseRequest
+		wantIDs     []uint
+		wantOK      []bool
+		wantDeleted []uint
+		wantErr     bool
+	}{
+		{
+			name:    "no ids without dry run",
+			req:     CollapseRequest{KeepID: 1},
+			wantErr: true,
+		},
+		{
+			name:    "dry run lists every copy",
+			req:     CollapseRequest{KeepID: 1, DryRun: true},
+			wantIDs: []uint{2, 3, 4},
+			wantOK:  []bool{true, true, false},
+		},
+		{
+			name:        "listed copies only",
+			req:         CollapseRequest{KeepID: 1, RemoveIDs: []uint{2}},
+			wantIDs:     []uint{2},
+			wantOK:      []bool{true},
+			wantDeleted: []uint{2},
+		},
+		{
+			name:        "shared copy is refused",
+			req:         CollapseRequest{KeepID: 1, RemoveIDs: []uint{4, 3}},
+			wantIDs:     []uint{4, 3},
+			wantOK:      []bool{false, true},
+			wantDeleted: []uint{3},
+		},
+		{
+			name:        "forced shared copy",
+			req:         CollapseRequest{KeepID: 1, RemoveIDs: []uint{4}, Force: true},
+			wantIDs:     []uint{4},
+			wantOK:      []bool{true},
+			wantDeleted: []uint{4},
+		},
+		{
+			name:    "kept file, other content and another user's copy",
+			req:     CollapseRequest{KeepID: 1, RemoveIDs: []uint{1, 5, 6}},
+			wantIDs: []uint{1, 5, 6},
+			wantOK:  []bool{false, false, false},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			content, other := &database.PhysicalFile{SizeBytes: 100}, &database.PhysicalFile{SizeBytes: 7}
+			content.ID, other.ID = 10, 11
+			file := func(id, ownerID uint, pf *database.PhysicalFile) *database.Resource {
+				r := &database.Resource{OwnerID: ownerID, Type: database.File, PhysicalFileID: &pf.ID, PhysicalFile: pf}
+				r.ID = id
+				return r
+			}
+			repo := &memoryRepository{
+				files: map[uint]*database.Resource{
+					1: file(1, owner, content),
+					2: file(2, owner, content),
+					3: file(3, owner, content),
+					4: file(4, owner, content),
+					5: file(5, owner, other),
+					6: file(6, owner+1, content),
+				},
+				shared: map[uint]bool{4: true},
+			}
+			deleter := &recordingDeleter{repo: repo}
+
+			result, err := NewService(repo, deleter).Collapse(ctx, tt.req)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("Collapse succeeded, want an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Collapse failed: %v", err)
+			}
+
+			if len(result.RemovedIDs) != len(tt.wantIDs) {
+				t.Fatalf("got outcomes for %v, want %v", result.RemovedIDs, tt.wantIDs)
+			}
+			var freed int64
+			for i, id := range result.RemovedIDs {
+				if id != tt.wantIDs[i] {
+					t.Errorf("outcome %d is for file %d, want %d", i, id, tt.wantIDs[i])
+				}
+				if ok := result.Errors[i] == nil; ok != tt.wantOK[i] {
+					t.Errorf("file %d: removable = %v (%v), want %v", id, ok, result.Errors[i], tt.wantOK[i])
+				}
+				if tt.wantOK[i] {
+					freed += content.SizeBytes
+				}
+			}
+			if result.FreedBytes != freed {
+				t.Errorf("freed %d bytes, want %d", result.FreedBytes, freed)
+			}
+			if len(deleter.deleted) != len(tt.wantDeleted) {
+				t.Fatalf("deleted %v, want %v", deleter.deleted, tt.wantDeleted)
+			}
+			for i, id := range deleter.deleted {
+				if id != tt.wantDeleted[i] {
+					t.Errorf("deleted %v, want %v", deleter.deleted, tt.wantDeleted)
+				}
+			}
+			if result.DryRun != tt.req.DryRun {
+				t.Errorf("result dry run = %v, want %v", result.DryRun, tt.req.DryRun)
+			}
+		})
+	}
+}
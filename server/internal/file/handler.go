@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"strconv"
 
+	"github.com/bhavyajaix/BalkanID-filevault/internal/activity"
 	"github.com/bhavyajaix/BalkanID-filevault/internal/database"
 	"github.com/bhavyajaix/BalkanID-filevault/internal/middleware"
 	"github.com/bhavyajaix/BalkanID-filevault/internal/permission"
@@ -100,7 +101,7 @@ import (
 // 	}
 // }
 
-func DownloadFileHandler(db *gorm.DB, permissionRepo permission.Repository) http.HandlerFunc {
+func DownloadFileHandler(db *gorm.DB, permissionRepo permission.Repository, recorder activity.Recorder) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		// 1. Get resourceID from URL
 		resourceIDStr := chi.URLParam(r, "resourceID")
@@ -141,29 +142,37 @@ func DownloadFileHandler(db *gorm.DB, permissionRepo permission.Repository) http
 		log.Println("Filename: ", filename)
 
 		// Check if the resource itself is public or if any of its ancestors are public.
+		// Ancestor flags stop at resources that break permission inheritance.
 		isPubliclyAccessible := resource.IsPublic
 		if !isPubliclyAccessible {
-			var publicAncestorCount int64
-			subQuery := db.Model(&database.ResourceAncestor{}).
-				Select("ancestor_id").
-				Where("descendant_id = ?", resource.ID)
-
-			if err := db.Model(&database.Resource{}).
-				Where("id IN (?)", subQuery).
-				Where("is_public = ?", true).
-				Count(&publicAncestorCount).Error; err != nil {
+			isPubliclyAccessible, err = permissionRepo.IsPubliclyAccessible(resource.ID)
+			if err != nil {
 				// This is an internal query error, not an access error
 				http.Error(w, "internal server error while checking public access", http.StatusInternalServerError)
 				return
 			}
-			if publicAncestorCount > 0 {
-				isPubliclyAccessible = true
-			}
 		}
 
 		log.Println("Public Access Check: ", isPubliclyAccessible)
 
 		if isPubliclyAccessible {
+			// Signed-in users downloading a public file still get it in their recent feed,
+			// unless an explicit deny keeps them from it.
+			if userID, ok := r.Context().Value(middleware.UserContextKey).(uint); ok {
+				if userID != resource.OwnerID {
+					denied, err := permissionRepo.IsDenied(resource.ID, userID)
+					if err != nil {
+						http.Error(w, "internal server error", http.StatusInternalServerError)
+						return
+					}
+					if denied {
+						http.Error(w, "access denied", http.StatusForbidden)
+						return
+					}
+				}
+				activity.Record(recorder, userID, resource.ID, database.ActivityDownloaded)
+			}
+
 			// 6. Stream the file
 			w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
 			w.Header().Set("Content-Type", mimeType)
@@ -205,6 +214,7 @@ func DownloadFileHandler(db *gorm.DB, permissionRepo permission.Repository) http
 		}
 
 		// If we reach here, the user has permission
+		activity.Record(recorder, userID, resource.ID, database.ActivityDownloaded)
 		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
 		w.Header().Set("Content-Type", mimeType)
 		http.ServeFile(w, r, filePath)
@@ -3,6 +3,7 @@ package file
 import (
 	"github.com/bhavyajaix/BalkanID-filevault/internal/database"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // Repository is the interface for file-related database operations.
@@ -19,6 +20,8 @@ type Repository interface {
 	DecrementReferenceCount(db *gorm.DB, physicalFileID uint) error
 	DeleteResource(db *gorm.DB, id uint) error
 	DeletePhysicalFile(db *gorm.DB, id uint) error
+	RestorePhysicalFile(db *gorm.DB, pf *database.PhysicalFile) error
+	FindReleasedPhysicalFile(db *gorm.DB, id uint) (*database.PhysicalFile, error)
 	UpdateResource(db *gorm.DB, resource *database.Resource) error
 }
 
@@ -33,9 +36,12 @@ func NewRepository(db *gorm.DB) Repository {
 
 // --- Existing Method Implementations ---
 
+// GetPhysicalFileByHash looks up the physical file holding some content, including one
+// released by a delete, and locks it until the transaction ends. The hash index covers
+// released rows too, so they have to be restored rather than created again.
 func (r *repository) GetPhysicalFileByHash(db *gorm.DB, hash string) (*database.PhysicalFile, error) {
 	var pf database.PhysicalFile
-	if err := db.Where("file_hash = ?", hash).First(&pf).Error; err != nil {
+	if err := db.Unscoped().Clauses(clause.Locking{Strength: "UPDATE"}).Where("file_hash = ?", hash).First(&pf).Error; err != nil {
 		return nil, err
 	}
 	return &pf, nil
@@ -99,6 +105,30 @@ func (r *repository) DeletePhysicalFile(db *gorm.DB, id uint) error {
 	return db.Delete(&database.PhysicalFile{}, id).Error
 }
 
+// RestorePhysicalFile brings back a physical file released by a delete, with a single
+// reference.
+func (r *repository) RestorePhysicalFile(db *gorm.DB, pf *database.PhysicalFile) error {
+	return db.Unscoped().Model(&database.PhysicalFile{}).Where("id = ?", pf.ID).Updates(map[string]interface{}{
+		"deleted_at":      nil,
+		"file_path":       pf.FilePath,
+		"size_bytes":      pf.SizeBytes,
+		"mime_type":       pf.MimeType,
+		"reference_count": 1,
+		"charged_user_id": pf.ChargedUserID,
+	}).Error
+}
+
+// FindReleasedPhysicalFile fetches a physical file that is still released, locking it
+// until the transaction ends so an upload of the same content cannot restore it
+// meanwhile.
+func (r *repository) FindReleasedPhysicalFile(db *gorm.DB, id uint) (*database.PhysicalFile, error) {
+	var pf database.PhysicalFile
+	if err := db.Unscoped().Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ? AND deleted_at IS NOT NULL", id).First(&pf).Error; err != nil {
+		return nil, err
+	}
+	return &pf, nil
+}
+
 // UpdateResource saves changes to a resource record (for rename and move).
 func (r *repository) UpdateResource(db *gorm.DB, resource *database.Resource) error {
 	return db.Save(resource).Error
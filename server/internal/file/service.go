@@ -5,12 +5,15 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"log"
 	"os"
 	"path/filepath"
 	"time"
 
 	"github.com/99designs/gqlgen/graphql"
+	"github.com/bhavyajaix/BalkanID-filevault/internal/activity"
 	"github.com/bhavyajaix/BalkanID-filevault/internal/database"
+	"github.com/bhavyajaix/BalkanID-filevault/internal/folders"
 	"github.com/bhavyajaix/BalkanID-filevault/internal/permission"
 	"github.com/bhavyajaix/BalkanID-filevault/internal/user"
 	"github.com/bhavyajaix/BalkanID-filevault/pkg/utils"
@@ -29,14 +32,18 @@ type UploadParams struct {
 	Upload   graphql.Upload
 	OwnerID  uint
 	ParentID *uint
+	Conflict folders.ConflictStrategy
 }
 
 // Service is the interface for file-related business logic.
 type Service interface {
 	UploadFile(params UploadParams) (*database.Resource, error)
 	DeleteFile(resourceID uint, userID uint) error
-	RenameFile(resourceID uint, userID uint, newName string) (*database.Resource, error)
-	MoveFile(resourceID uint, userID uint, newParentID *uint) (*database.Resource, error)
+	RenameFile(resourceID uint, userID uint, newName string, strategy folders.ConflictStrategy) (*database.Resource, error)
+	MoveFile(resourceID uint, userID uint, newParentID *uint, strategy folders.ConflictStrategy) (*database.Resource, error)
+	CopyResource(resourceID uint, userID uint, destParentID *uint, strategy folders.ConflictStrategy) (*database.Resource, error)
+	DeleteResources(resourceIDs []uint, userID uint) ([]error, error)
+	MoveResources(resourceIDs []uint, userID uint, newParentID *uint, strategy folders.ConflictStrategy) ([]*database.Resource, []error, error)
 	GetFileByID(resourceID uint, userID uint) (*FileDownload, error)
 }
 
@@ -46,14 +53,22 @@ type service struct {
 	userRepo       user.Repository
 	storagePath    string
 	permissionRepo permission.Repository
+	folderService  folders.Service
+	activity       activity.Recorder
 }
 
 // NewService creates a new file service.
-func NewService(repo Repository, userRepo user.Repository, db *gorm.DB, storagePath string, permissionRepo permission.Repository) Service {
-	return &service{repo: repo, userRepo: userRepo, db: db, storagePath: storagePath, permissionRepo: permissionRepo}
+func NewService(repo Repository, userRepo user.Repository, db *gorm.DB, storagePath string, permissionRepo permission.Repository, folderService folders.Service, recorder activity.Recorder) Service {
+	return &service{repo: repo, userRepo: userRepo, db: db, storagePath: storagePath, permissionRepo: permissionRepo, folderService: folderService, activity: recorder}
 }
 
 func (s *service) UploadFile(params UploadParams) (*database.Resource, error) {
+	// 0. Settle the name before touching storage so a conflict fails fast.
+	name, err := folders.NormalizeName(params.Upload.Filename)
+	if err != nil {
+		return nil, err
+	}
+
 	// 1. Open the uploaded file stream from the graphql.Upload object
 	src := params.Upload.File
 	// 2. Calculate the file's SHA-256 hash
@@ -64,6 +79,11 @@ func (s *service) UploadFile(params UploadParams) (*database.Resource, error) {
 	}
 	hash := fmt.Sprintf("%x", hasher.Sum(nil))
 
+	name, replaced, err := s.folderService.ResolveNameConflict(params.OwnerID, params.ParentID, name, database.File, params.Conflict, 0)
+	if err != nil {
+		return nil, err
+	}
+
 	// Begin a database transaction for atomic operations
 	tx := s.db.Begin()
 	if tx.Error != nil {
@@ -73,16 +93,20 @@ func (s *service) UploadFile(params UploadParams) (*database.Resource, error) {
 	defer tx.Rollback()
 
 	var physicalFileID uint
+	var released []uint
 
-	// 3. Check for existing physical file (deduplication)
+	// 3. Check for existing physical file (deduplication). Content released by an
+	// earlier delete is found as well, and brought back below.
 	existingPF, err := s.repo.GetPhysicalFileByHash(tx, hash)
 	if err != nil && err != gorm.ErrRecordNotFound {
 		return nil, fmt.Errorf("error checking for existing file: %w", err)
 	}
 
-	if existingPF != nil {
+	if existingPF != nil && !existingPF.DeletedAt.Valid {
 		// --- CASE A: FILE IS A DUPLICATE ---
 		// The physical file already exists, so we just increment its reference count.
+		// This includes replacing a file with the same content: the new reference is
+		// taken before the replaced file gives up its own, so the content is kept.
 		if err := s.repo.IncrementReferenceCount(tx, existingPF.ID); err != nil {
 			return nil, fmt.Errorf("failed to increment reference count: %w", err)
 		}
@@ -92,49 +116,40 @@ func (s *service) UploadFile(params UploadParams) (*database.Resource, error) {
 		physicalFileID = existingPF.ID
 	} else {
 		// --- CASE B: FILE IS UNIQUE ---
-		// Derive path from hash and ensure the nested directories exist.
 		filePath := s.getStoragePath(hash)
-		if err := os.MkdirAll(filepath.Dir(filePath), os.ModePerm); err != nil {
-			return nil, fmt.Errorf("failed to create storage directories: %w", err)
-		}
-
-		// Create the new file on the disk.
-		dst, err := os.Create(filePath)
-		if err != nil {
-			return nil, fmt.Errorf("failed to create destination file: %w", err)
+		if err := writeBlob(src, filePath); err != nil {
+			return nil, err
 		}
-		defer dst.Close()
 
-		// Rewind the source file reader to the beginning.
-		if _, err := src.Seek(0, 0); err != nil {
-			return nil, fmt.Errorf("failed to rewind file reader: %w", err)
-		}
-
-		// Copy the file content to its new permanent location on the disk.
-		if _, err := io.Copy(dst, src); err != nil {
-			return nil, fmt.Errorf("failed to save file to disk: %w", err)
-		}
-
-		// Create the physical file record in the database.
+		// Create the physical file record in the database, or restore the released one.
 		newPF := &database.PhysicalFile{
 			FileHash:       hash,
 			FilePath:       filePath,
 			SizeBytes:      params.Upload.Size,
 			MimeType:       params.Upload.ContentType,
 			ReferenceCount: 1,
+			ChargedUserID:  &params.OwnerID,
 		}
-		if err := s.repo.CreatePhysicalFile(tx, newPF); err != nil {
-			// If DB write fails, try to clean up the orphaned file on disk.
-			os.Remove(filePath)
+		if existingPF != nil {
+			newPF.ID = existingPF.ID
+			if err := s.repo.RestorePhysicalFile(tx, newPF); err != nil {
+				return nil, fmt.Errorf("failed to restore physical file record: %w", err)
+			}
+		} else if err := s.repo.CreatePhysicalFile(tx, newPF); err != nil {
 			return nil, fmt.Errorf("failed to create physical file record: %w", err)
 		}
 		if err := s.userRepo.IncrementBothStorageTypes(tx, params.OwnerID, params.Upload.Size); err != nil {
-			os.Remove(filePath) // Also clean up the file if this fails
 			return nil, fmt.Errorf("failed to update user storage: %w", err)
 		}
 		physicalFileID = newPF.ID
 	}
 
+	if replaced != nil {
+		if err := s.deleteFileTx(tx, replaced, &released); err != nil {
+			return nil, err
+		}
+	}
+
 	// 4. Create the logical resource record for the user.
 	// This acts as the user's "pointer" to the physical file.
 	token, err := utils.GenerateUUIDToken()
@@ -145,12 +160,15 @@ func (s *service) UploadFile(params UploadParams) (*database.Resource, error) {
 	newResource := &database.Resource{
 		OwnerID:        params.OwnerID,
 		ParentID:       params.ParentID,
-		Name:           params.Upload.Filename,
+		Name:           name,
 		Type:           database.File,
 		PhysicalFileID: &physicalFileID,
 		ShareToken:     &token,
 	}
 	if err := s.repo.CreateResource(tx, newResource); err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return nil, folders.ErrNameTaken
+		}
 		return nil, fmt.Errorf("failed to create resource record: %w", err)
 	}
 
@@ -169,7 +187,9 @@ func (s *service) UploadFile(params UploadParams) (*database.Resource, error) {
 	if err := tx.Commit().Error; err != nil {
 		return nil, fmt.Errorf("failed to commit transaction: %w", err)
 	}
+	s.removeReleasedBlobs(released)
 
+	activity.Record(s.activity, params.OwnerID, newResource.ID, database.ActivityUploaded)
 	return s.repo.GetResourceByID(s.db, newResource.ID) // Re-fetch to populate associations
 }
 
@@ -178,6 +198,60 @@ func (s *service) getStoragePath(hash string) string {
 	return filepath.Join(s.storagePath, hash[:2], hash[2:4], hash)
 }
 
+// writeBlob saves an upload at the path derived from its hash. The content goes to a
+// temporary file that is then renamed into place, so a blob already stored there, which
+// can only hold the same content, is never truncated. The blob is left in place if the
+// upload later fails, as another upload of the same content may be using it.
+func writeBlob(src io.ReadSeeker, filePath string) error {
+	if err := os.MkdirAll(filepath.Dir(filePath), os.ModePerm); err != nil {
+		return fmt.Errorf("failed to create storage directories: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(filePath), ".upload-*")
+	if err != nil {
+		return fmt.Errorf("failed to create destination file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	// Rewind the source file reader to the beginning.
+	if _, err := src.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("failed to rewind file reader: %w", err)
+	}
+	if _, err := io.Copy(tmp, src); err != nil {
+		return fmt.Errorf("failed to save file to disk: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to save file to disk: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), filePath); err != nil {
+		return fmt.Errorf("failed to save file to disk: %w", err)
+	}
+	return nil
+}
+
+// removeReleasedBlobs deletes from disk the content of the physical files released by a
+// committed transaction. It must only run after the commit: until then a rollback can
+// still bring the files back. Content restored since, by a savepoint rolled back or a
+// new upload, is kept.
+func (s *service) removeReleasedBlobs(released []uint) {
+	for _, id := range released {
+		err := s.db.Transaction(func(tx *gorm.DB) error {
+			pf, err := s.repo.FindReleasedPhysicalFile(tx, id)
+			if err != nil {
+				return err
+			}
+			if err := os.Remove(pf.FilePath); err != nil && !errors.Is(err, os.ErrNotExist) {
+				return err
+			}
+			return nil
+		})
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			// The database is consistent either way; the blob is only left on disk.
+			log.Printf("failed to remove content of physical file %d: %v", id, err)
+		}
+	}
+}
+
 // DeleteFile handles the logic for deleting a file resource.
 func (s *service) DeleteFile(resourceID uint, userID uint) error {
 	tx := s.db.Begin()
@@ -196,9 +270,66 @@ func (s *service) DeleteFile(resourceID uint, userID uint) error {
 	if resource.OwnerID != userID {
 		return errors.New("unauthorized: only the owner can delete this file")
 	}
+	var released []uint
+	if err := s.deleteFileTx(tx, resource, &released); err != nil {
+		return err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return err
+	}
+	s.removeReleasedBlobs(released)
+	return nil
+}
+
+// DeleteResources deletes a selection of files and folders owned by userID in a single
+// transaction. Each item runs in its own savepoint, so one failure doesn't undo the
+// others; the returned errors line up with resourceIDs and are nil for deleted items.
+func (s *service) DeleteResources(resourceIDs []uint, userID uint) ([]error, error) {
+	results := make([]error, len(resourceIDs))
+	var released []uint
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		for i, resourceID := range resourceIDs {
+			results[i] = tx.Transaction(func(itemTx *gorm.DB) error {
+				resource, err := s.repo.GetResourceByID(itemTx, resourceID)
+				if err != nil {
+					return fmt.Errorf("resource not found: %w", err)
+				}
+				if resource.OwnerID != userID {
+					return errors.New("unauthorized: only the owner can delete this resource")
+				}
+				return s.deleteResourceTx(itemTx, resource, &released)
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	s.removeReleasedBlobs(released)
+	return results, nil
+}
+
+// removeResource deletes the sibling displaced by folders.ConflictReplace.
+func (s *service) removeResource(existing *database.Resource) error {
+	var released []uint
+	if err := s.db.Transaction(func(tx *gorm.DB) error {
+		return s.deleteResourceTx(tx, existing, &released)
+	}); err != nil {
+		return err
+	}
+	s.removeReleasedBlobs(released)
+	return nil
+}
+
+// deleteFileTx removes a file resource inside tx, releasing its physical file once no
+// other resource references it. Released files are added to released; their content
+// stays on disk until removeReleasedBlobs runs after the commit.
+func (s *service) deleteFileTx(tx *gorm.DB, resource *database.Resource, released *[]uint) error {
 	if resource.PhysicalFileID == nil {
 		return errors.New("invalid resource: resource has no physical file to delete")
 	}
+	resourceID := resource.ID
 
 	// 3. Decrement the reference count of the physical file.
 	physicalFileID := *resource.PhysicalFileID
@@ -216,23 +347,41 @@ func (s *service) DeleteFile(resourceID uint, userID uint) error {
 	if err != nil && err != gorm.ErrRecordNotFound {
 		return fmt.Errorf("failed to check physical file status: %w", err)
 	}
+	if pf == nil {
+		return nil
+	}
+
+	// 6. Give the owner back the logical storage the file was charged when it was
+	// uploaded or copied, even when other resources still share the content.
+	if err := s.userRepo.DecrementStorageUsed(tx, resource.OwnerID, pf.SizeBytes); err != nil {
+		return fmt.Errorf("failed to update user storage: %w", err)
+	}
 
-	if pf != nil && pf.ReferenceCount <= 0 {
+	if pf.ReferenceCount <= 0 {
+		// Nothing points to the content any more, so it no longer takes disk space. The
+		// charge goes back to the uploader, who may not be the owner of this last file.
+		if pf.ChargedUserID != nil {
+			if err := s.userRepo.DecrementDeduplicationStorageUsed(tx, *pf.ChargedUserID, pf.SizeBytes); err != nil {
+				return fmt.Errorf("failed to update user storage: %w", err)
+			}
+		}
 		// Delete the physical file record from the database.
 		if err := s.repo.DeletePhysicalFile(tx, physicalFileID); err != nil {
 			return fmt.Errorf("failed to delete physical file record: %w", err)
 		}
-		// Delete the actual file from the disk.
-		if err := os.Remove(pf.FilePath); err != nil {
-			// Log this error but don't fail the transaction, as the DB state is consistent.
-		}
+		*released = append(*released, physicalFileID)
 	}
 
-	return tx.Commit().Error
+	return nil
 }
 
 // RenameFile handles the logic for renaming a file resource.
-func (s *service) RenameFile(resourceID uint, userID uint, newName string) (*database.Resource, error) {
+func (s *service) RenameFile(resourceID uint, userID uint, newName string, strategy folders.ConflictStrategy) (*database.Resource, error) {
+	newName, err := folders.NormalizeName(newName)
+	if err != nil {
+		return nil, err
+	}
+
 	// For simple updates, GORM can handle the transaction implicitly.
 	// For consistency, we can also wrap it explicitly.
 	resource, err := s.repo.GetResourceByID(s.db, resourceID)
@@ -245,16 +394,30 @@ func (s *service) RenameFile(resourceID uint, userID uint, newName string) (*dat
 		return nil, errors.New("unauthorized: only the owner can rename this file")
 	}
 
+	newName, replaced, err := s.folderService.ResolveNameConflict(userID, resource.ParentID, newName, database.File, strategy, resource.ID)
+	if err != nil {
+		return nil, err
+	}
+	if replaced != nil {
+		if err := s.removeResource(replaced); err != nil {
+			return nil, err
+		}
+	}
+
 	resource.Name = newName
 	if err := s.repo.UpdateResource(s.db, resource); err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return nil, folders.ErrNameTaken
+		}
 		return nil, fmt.Errorf("failed to update resource name: %w", err)
 	}
 
+	activity.Record(s.activity, userID, resource.ID, database.ActivityModified)
 	return resource, nil
 }
 
 // MoveFile handles the logic for moving a file to a different folder.
-func (s *service) MoveFile(resourceID uint, userID uint, newParentID *uint) (*database.Resource, error) {
+func (s *service) MoveFile(resourceID uint, userID uint, newParentID *uint, strategy folders.ConflictStrategy) (*database.Resource, error) {
 	resource, err := s.repo.GetResourceByID(s.db, resourceID)
 	if err != nil {
 		return nil, fmt.Errorf("resource not found: %w", err)
@@ -264,17 +427,195 @@ func (s *service) MoveFile(resourceID uint, userID uint, newParentID *uint) (*da
 	if resource.OwnerID != userID {
 		return nil, errors.New("unauthorized: only the owner can move this file")
 	}
+	if resource.Type != database.File {
+		return nil, errors.New("resource is not a file")
+	}
 
-	// Optional: Check if the new parent folder exists and if the user has write access to it.
-	// For simplicity, we are skipping that here, but in a real system, you would add that check.
-	// if newParentID != nil { ... check permissions on newParentID ... }
+	// The destination is validated (it must exist, be a folder and be writable by the
+	// user) by the same code path that moves folders.
+	if _, err := s.folderService.MoveOwnedResource(userID, resourceID, newParentID, strategy, s.removeResource); err != nil {
+		return nil, err
+	}
 
-	resource.ParentID = newParentID
-	if err := s.repo.UpdateResource(s.db, resource); err != nil {
-		return nil, fmt.Errorf("failed to update resource parent: %w", err)
+	activity.Record(s.activity, userID, resourceID, database.ActivityModified)
+	return s.repo.GetResourceByID(s.db, resourceID)
+}
+
+// MoveResources moves a selection of files and folders owned by userID under the same
+// parent in a single transaction. Each item runs in its own savepoint, so one failure
+// doesn't undo the others; results and errors line up with resourceIDs.
+func (s *service) MoveResources(resourceIDs []uint, userID uint, newParentID *uint, strategy folders.ConflictStrategy) ([]*database.Resource, []error, error) {
+	var released []uint
+	remove := func(tx *gorm.DB, existing *database.Resource) error {
+		return s.deleteResourceTx(tx, existing, &released)
 	}
+	errs, err := s.folderService.MoveOwnedResources(userID, resourceIDs, newParentID, strategy, remove)
+	if err != nil {
+		return nil, nil, err
+	}
+	s.removeReleasedBlobs(released)
 
-	return resource, nil
+	moved := make([]*database.Resource, len(resourceIDs))
+	for i, resourceID := range resourceIDs {
+		if errs[i] != nil {
+			continue
+		}
+		activity.Record(s.activity, userID, resourceID, database.ActivityModified)
+		moved[i], errs[i] = s.repo.GetResourceByID(s.db, resourceID)
+	}
+	return moved, errs, nil
+}
+
+// CopyResource duplicates a file, or a folder with everything below it that the user can
+// read, under destParentID (the user's root when nil). Copies share the original
+// physical files, so no bytes are written, but they are owned by and charged to the
+// caller's logical storage until they are deleted.
+func (s *service) CopyResource(resourceID uint, userID uint, destParentID *uint, strategy folders.ConflictStrategy) (*database.Resource, error) {
+	subtree, err := s.folderService.ReadableSubtree(userID, resourceID)
+	if err != nil {
+		return nil, err
+	}
+	root := &subtree[0]
+	if err := s.folderService.ValidateCopyTarget(userID, root, destParentID); err != nil {
+		return nil, err
+	}
+
+	name, replaced, err := s.folderService.ResolveNameConflict(userID, destParentID, root.Name, root.Type, strategy, 0)
+	if err != nil {
+		return nil, err
+	}
+	// Copying into the source's own folder finds the source itself as the sibling to
+	// replace; deleting it would leave the copy pointing at released content.
+	if replaced != nil && replaced.ID == root.ID {
+		return nil, errors.New("cannot replace a resource with a copy of itself")
+	}
+
+	var size int64
+	for _, resource := range subtree {
+		if resource.PhysicalFile != nil {
+			size += resource.PhysicalFile.SizeBytes
+		}
+	}
+
+	tx := s.db.Begin()
+	if tx.Error != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", tx.Error)
+	}
+	defer tx.Rollback()
+
+	// 1. Check the caller has room for the copies. The row lock keeps a concurrent
+	// upload from slipping past the check.
+	owner, err := s.userRepo.GetUserForUpdate(tx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("could not load user: %w", err)
+	}
+	if !user.HasQuotaFor(owner, size) {
+		return nil, errors.New("not enough storage quota to copy this resource")
+	}
+
+	// 2. Take the copies' references to their content before a replaced resource
+	// gives up its own, so content the two share is never released.
+	for _, resource := range subtree {
+		if resource.Type != database.File {
+			continue
+		}
+		if resource.PhysicalFileID == nil {
+			return nil, errors.New("invalid resource: resource has no physical file to copy")
+		}
+		if err := s.repo.IncrementReferenceCount(tx, *resource.PhysicalFileID); err != nil {
+			return nil, fmt.Errorf("failed to increment reference count: %w", err)
+		}
+	}
+
+	var released []uint
+	if replaced != nil {
+		if err := s.deleteResourceTx(tx, replaced, &released); err != nil {
+			return nil, err
+		}
+	}
+
+	// 3. Clone the subtree. Parents come before their children, so every copy's new
+	// parent already exists.
+	copies := make(map[uint]uint, len(subtree))
+	for i := range subtree {
+		source := &subtree[i]
+		parentID, copyName := destParentID, name
+		if i > 0 {
+			newParentID := copies[*source.ParentID]
+			parentID, copyName = &newParentID, source.Name
+		}
+
+		clone, err := s.cloneResource(tx, source, userID, parentID, copyName)
+		if err != nil {
+			return nil, err
+		}
+		copies[source.ID] = clone.ID
+	}
+
+	// 4. Charge the copies to the caller's logical storage only; no bytes were written.
+	if err := s.userRepo.IncrementStorageUsed(tx, userID, size); err != nil {
+		return nil, fmt.Errorf("failed to update user storage: %w", err)
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	s.removeReleasedBlobs(released)
+	return s.repo.GetResourceByID(s.db, copies[root.ID])
+}
+
+// cloneResource creates a copy of source owned by ownerID. A file copy points at the
+// same physical file, whose reference the caller has already taken; tags come along.
+func (s *service) cloneResource(tx *gorm.DB, source *database.Resource, ownerID uint, parentID *uint, name string) (*database.Resource, error) {
+	if source.Type == database.File && source.PhysicalFileID == nil {
+		return nil, errors.New("invalid resource: resource has no physical file to copy")
+	}
+
+	token, err := utils.GenerateUUIDToken()
+	if err != nil {
+		return nil, fmt.Errorf("could not generate share token: %w", err)
+	}
+	clone := &database.Resource{
+		OwnerID:        ownerID,
+		ParentID:       parentID,
+		Name:           name,
+		Type:           source.Type,
+		PhysicalFileID: source.PhysicalFileID,
+		ShareToken:     &token,
+		Tags:           source.Tags,
+	}
+	if err := s.repo.CreateResource(tx, clone); err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return nil, folders.ErrNameTaken
+		}
+		return nil, fmt.Errorf("failed to copy %q: %w", source.Name, err)
+	}
+
+	if source.Type != database.File {
+		return clone, nil
+	}
+	ownerPermission := &database.Permission{
+		ResourceID: clone.ID,
+		UserID:     ownerID,
+		Role:       database.Editor,
+		CreatedAt:  time.Now(),
+	}
+	if err := s.repo.CreatePermission(tx, ownerPermission); err != nil {
+		return nil, fmt.Errorf("failed to create owner permission: %w", err)
+	}
+	return clone, nil
+}
+
+// deleteResourceTx removes a file or folder inside tx. Files release their physical
+// file; folders are soft-deleted like a single deleteFolder.
+func (s *service) deleteResourceTx(tx *gorm.DB, resource *database.Resource, released *[]uint) error {
+	if resource.Type == database.File {
+		return s.deleteFileTx(tx, resource, released)
+	}
+	if err := s.repo.DeleteResource(tx, resource.ID); err != nil {
+		return fmt.Errorf("failed to delete %q: %w", resource.Name, err)
+	}
+	return nil
 }
 
 func (s *service) GetFileByID(resourceID uint, userID uint) (*FileDownload, error) {
@@ -288,7 +629,7 @@ func (s *service) GetFileByID(resourceID uint, userID uint) (*FileDownload, erro
 	}
 
 	// 2. Authorize the user. Check if they have direct or inherited access.
-	_, err = s.permissionRepo.FindPermission(userID, resourceID)
+	_, err = s.permissionRepo.FindPermission(resourceID, userID)
 
 	if err != nil {
 		// If the error is 'record not found', it means no permission exists. Deny access.
@@ -312,6 +653,8 @@ func (s *service) GetFileByID(resourceID uint, userID uint) (*FileDownload, erro
 		return nil, fmt.Errorf("internal server error: could not open file from storage: %w", err)
 	}
 
+	activity.Record(s.activity, userID, resource.ID, database.ActivityDownloaded)
+
 	// 5. Construct the FileDownload struct with the stream and metadata.
 	download := &FileDownload{
 		Content:  fileStream, // The os.File handle implements io.ReadCloser
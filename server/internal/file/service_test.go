@@ -0,0 +1,174 @@
+package file
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"testing"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/bhavyajaix/BalkanID-filevault/internal/activity"
+	"github.com/bhavyajaix/BalkanID-filevault/internal/database"
+	"github.com/bhavyajaix/BalkanID-filevault/internal/database/databasetest"
+	"github.com/bhavyajaix/BalkanID-filevault/internal/folders"
+	"github.com/bhavyajaix/BalkanID-filevault/internal/middleware"
+	"github.com/bhavyajaix/BalkanID-filevault/internal/permission"
+	"github.com/bhavyajaix/BalkanID-filevault/internal/user"
+	"gorm.io/gorm"
+)
+
+type fixture struct {
+	db      *gorm.DB
+	files   Service
+	folders folders.Service
+	owner   *database.User
+}
+
+func newFixture(t *testing.T) *fixture {
+	t.Helper()
+	db := databasetest.Open(t)
+	permissionRepo := permission.NewRepository(db)
+	foldersService := folders.NewService(folders.NewRepository(db), permissionRepo)
+	files := NewService(NewRepository(db), user.NewRepository(db), db, t.TempDir(), permissionRepo, foldersService, activity.NewRepository(db))
+	return &fixture{db: db, files: files, folders: foldersService, owner: databasetest.NewUser(t, db, "owner")}
+}
+
+func (f *fixture) folder(t *testing.T, name string) *database.Resource {
+	t.Helper()
+	ctx := context.WithValue(context.Background(), middleware.UserContextKey, f.owner.ID)
+	folder, err := f.folders.CreateFolder(ctx, name, nil, folders.ConflictFail)
+	if err != nil {
+		t.Fatalf("failed to create folder: %v", err)
+	}
+	return folder
+}
+
+func (f *fixture) upload(t *testing.T, parentID *uint, name string, content string, strategy folders.ConflictStrategy) (*database.Resource, error) {
+	t.Helper()
+	return f.files.UploadFile(UploadParams{
+		Upload: graphql.Upload{
+			File:        bytes.NewReader([]byte(content)),
+			Filename:    name,
+			Size:        int64(len(content)),
+			ContentType: "text/plain",
+		},
+		OwnerID:  f.owner.ID,
+		ParentID: parentID,
+		Conflict: strategy,
+	})
+}
+
+// assertReadable checks that a file still resolves to its content on disk.
+func (f *fixture) assertReadable(t *testing.T, resourceID uint, want string) {
+	t.Helper()
+	download, err := f.files.GetFileByID(resourceID, f.owner.ID)
+	if err != nil {
+		t.Fatalf("failed to open file %d: %v", resourceID, err)
+	}
+	defer download.Content.Close()
+	var got bytes.Buffer
+	if _, err := got.ReadFrom(download.Content); err != nil {
+		t.Fatalf("failed to read file %d: %v", resourceID, err)
+	}
+	if got.String() != want {
+		t.Errorf("file %d holds %q, want %q", resourceID, got.String(), want)
+	}
+}
+
+func TestCopyIntoSameFolder(t *testing.T) {
+	f := newFixture(t)
+	folder := f.folder(t, "Reports")
+	source, err := f.upload(t, &folder.ID, "q4.txt", "copy into same folder", folders.ConflictFail)
+	if err != nil {
+		t.Fatalf("failed to upload: %v", err)
+	}
+
+	if _, err := f.files.CopyResource(source.ID, f.owner.ID, &folder.ID, folders.ConflictReplace); err == nil {
+		t.Fatal("replacing a file with a copy of itself succeeded")
+	}
+	f.assertReadable(t, source.ID, "copy into same folder")
+
+	copied, err := f.files.CopyResource(source.ID, f.owner.ID, &folder.ID, folders.ConflictRename)
+	if err != nil {
+		t.Fatalf("failed to copy with RENAME: %v", err)
+	}
+	if copied.Name != "q4 (1).txt" {
+		t.Errorf("copy is named %q, want %q", copied.Name, "q4 (1).txt")
+	}
+	f.assertReadable(t, source.ID, "copy into same folder")
+	f.assertReadable(t, copied.ID, "copy into same folder")
+}
+
+func TestReplaceWithSameContent(t *testing.T) {
+	f := newFixture(t)
+	folder := f.folder(t, "Drafts")
+	first, err := f.upload(t, &folder.ID, "notes.txt", "replace with same content", folders.ConflictFail)
+	if err != nil {
+		t.Fatalf("failed to upload: %v", err)
+	}
+
+	second, err := f.upload(t, &folder.ID, "notes.txt", "replace with same content", folders.ConflictReplace)
+	if err != nil {
+		t.Fatalf("failed to replace: %v", err)
+	}
+	if *second.PhysicalFileID != *first.PhysicalFileID {
+		t.Errorf("replacement stored the content again as physical file %d, want %d", *second.PhysicalFileID, *first.PhysicalFileID)
+	}
+	f.assertReadable(t, second.ID, "replace with same content")
+}
+
+func TestUploadAfterLastReferenceDeleted(t *testing.T) {
+	f := newFixture(t)
+	content := "uploaded again after delete " + f.owner.Username
+	first, err := f.upload(t, nil, "gone.txt", content, folders.ConflictFail)
+	if err != nil {
+		t.Fatalf("failed to upload: %v", err)
+	}
+	if err := f.files.DeleteFile(first.ID, f.owner.ID); err != nil {
+		t.Fatalf("failed to delete: %v", err)
+	}
+	if _, err := os.Stat(first.PhysicalFile.FilePath); !os.IsNotExist(err) {
+		t.Errorf("content of the deleted file is still on disk: %v", err)
+	}
+
+	again, err := f.upload(t, nil, "back.txt", content, folders.ConflictFail)
+	if err != nil {
+		t.Fatalf("failed to upload the content again: %v", err)
+	}
+	f.assertReadable(t, again.ID, content)
+}
+
+func TestReleaseCreditsUploader(t *testing.T) {
+	f := newFixture(t)
+	other := databasetest.NewUser(t, f.db, "other")
+	content := "released by another user " + f.owner.Username
+	first, err := f.upload(t, nil, "shared.txt", content, folders.ConflictFail)
+	if err != nil {
+		t.Fatalf("failed to upload: %v", err)
+	}
+	second, err := f.files.UploadFile(UploadParams{
+		Upload:   graphql.Upload{File: bytes.NewReader([]byte(content)), Filename: "mine.txt", Size: int64(len(content)), ContentType: "text/plain"},
+		OwnerID:  other.ID,
+		Conflict: folders.ConflictFail,
+	})
+	if err != nil {
+		t.Fatalf("failed to upload the same content as another user: %v", err)
+	}
+
+	if err := f.files.DeleteFile(first.ID, f.owner.ID); err != nil {
+		t.Fatalf("failed to delete: %v", err)
+	}
+	if err := f.files.DeleteFile(second.ID, other.ID); err != nil {
+		t.Fatalf("failed to delete the last file: %v", err)
+	}
+
+	for _, u := range []*database.User{f.owner, other} {
+		var used int
+		if err := f.db.Model(&database.User{}).Where("id = ?", u.ID).Pluck("deduplication_storage_used", &used).Error; err != nil {
+			t.Fatalf("failed to load storage usage: %v", err)
+		}
+		if used != 0 {
+			t.Errorf("user %s still has %d bytes of stored content charged", u.Username, used)
+		}
+	}
+}
@@ -0,0 +1,147 @@
+package folders
+
+import (
+	"fmt"
+
+	"github.com/bhavyajaix/BalkanID-filevault/internal/database"
+	"gorm.io/gorm"
+)
+
+// HierarchyReport describes how far resource_ancestors has drifted from the tree that
+// resources.parent_id describes.
+type HierarchyReport struct {
+	// MissingRows are closure rows implied by parent_id that the table lacks.
+	MissingRows int64
+	// StaleRows are closure rows parent_id does not imply, including rows with the wrong depth.
+	StaleRows int64
+	// CycleResourceIDs are resources whose chain of parents loops back to themselves.
+	CycleResourceIDs []uint
+	// Repaired is true when the drift above has been fixed.
+	Repaired bool
+}
+
+// expectedClosureSQL rebuilds the closure table from parent_id. The walk stops as soon
+// as a chain of parents revisits a resource, so it terminates even on a corrupted tree;
+// those rows are flagged as cycles and left out of the expected closure.
+const expectedClosureSQL = `
+	WITH RECURSIVE walk(ancestor_id, descendant_id, depth, path, cycle) AS (
+		SELECT id, id, 0, ARRAY[id], false FROM resources
+		UNION ALL
+		SELECT r.parent_id, w.descendant_id, w.depth + 1, w.path || r.parent_id, r.parent_id = ANY(w.path)
+		FROM walk w
+		JOIN resources r ON r.id = w.ancestor_id
+		WHERE r.parent_id IS NOT NULL AND NOT w.cycle
+	),
+	expected AS (
+		SELECT ancestor_id, descendant_id, depth FROM walk WHERE NOT cycle
+	)`
+
+// VerifyHierarchy compares resource_ancestors with parent_id. With repair set, cycles in
+// parent_id are broken by moving one resource of each loop to the root, stale closure
+// rows are deleted and missing ones inserted. The report always describes the state
+// found before any repair.
+func (r *repository) VerifyHierarchy(repair bool) (*HierarchyReport, error) {
+	var report HierarchyReport
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(?)", hierarchyLockID).Error; err != nil {
+			return err
+		}
+
+		if err := inspectHierarchy(tx, &report); err != nil {
+			return err
+		}
+		if !repair || (report.MissingRows == 0 && report.StaleRows == 0 && len(report.CycleResourceIDs) == 0) {
+			return nil
+		}
+
+		if err := breakCycles(tx, report.CycleResourceIDs); err != nil {
+			return fmt.Errorf("failed to break cycles: %w", err)
+		}
+		if err := tx.Exec(expectedClosureSQL + `
+			DELETE FROM resource_ancestors ra
+			WHERE NOT EXISTS (
+				SELECT 1 FROM expected e
+				WHERE e.ancestor_id = ra.ancestor_id AND e.descendant_id = ra.descendant_id AND e.depth = ra.depth
+			)`).Error; err != nil {
+			return fmt.Errorf("failed to delete stale closure rows: %w", err)
+		}
+		if err := tx.Exec(expectedClosureSQL + `
+			INSERT INTO resource_ancestors (ancestor_id, descendant_id, depth)
+			SELECT ancestor_id, descendant_id, depth FROM expected
+			ON CONFLICT (ancestor_id, descendant_id) DO NOTHING`).Error; err != nil {
+			return fmt.Errorf("failed to insert missing closure rows: %w", err)
+		}
+
+		report.Repaired = true
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
+
+// inspectHierarchy fills in the drift counts and the resources caught in cycles.
+func inspectHierarchy(db *gorm.DB, report *HierarchyReport) error {
+	if err := db.Raw(expectedClosureSQL + `
+		SELECT COUNT(*) FROM (
+			SELECT ancestor_id, descendant_id, depth FROM expected
+			EXCEPT
+			SELECT ancestor_id, descendant_id, depth FROM resource_ancestors
+		) missing`).Scan(&report.MissingRows).Error; err != nil {
+		return fmt.Errorf("failed to count missing closure rows: %w", err)
+	}
+
+	if err := db.Raw(expectedClosureSQL + `
+		SELECT COUNT(*) FROM (
+			SELECT ancestor_id, descendant_id, depth FROM resource_ancestors
+			EXCEPT
+			SELECT ancestor_id, descendant_id, depth FROM expected
+		) stale`).Scan(&report.StaleRows).Error; err != nil {
+		return fmt.Errorf("failed to count stale closure rows: %w", err)
+	}
+
+	if err := db.Raw(expectedClosureSQL + `
+		SELECT DISTINCT descendant_id FROM walk
+		WHERE cycle AND ancestor_id = descendant_id
+		ORDER BY descendant_id`).Scan(&report.CycleResourceIDs).Error; err != nil {
+		return fmt.Errorf("failed to detect cycles: %w", err)
+	}
+	return nil
+}
+
+// breakCycles moves the lowest-numbered resource of every parent_id loop to the root.
+func breakCycles(db *gorm.DB, cycleIDs []uint) error {
+	if len(cycleIDs) == 0 {
+		return nil
+	}
+
+	var members []database.Resource
+	if err := db.Unscoped().Select("id", "parent_id").Where("id IN ?", cycleIDs).Find(&members).Error; err != nil {
+		return err
+	}
+	parents := make(map[uint]uint, len(members))
+	for _, m := range members {
+		if m.ParentID != nil {
+			parents[m.ID] = *m.ParentID
+		}
+	}
+
+	visited := make(map[uint]bool, len(members))
+	for _, start := range cycleIDs {
+		if visited[start] {
+			continue
+		}
+		lowest := start
+		for id := start; !visited[id]; id = parents[id] {
+			visited[id] = true
+			if id < lowest {
+				lowest = id
+			}
+		}
+		if err := db.Unscoped().Model(&database.Resource{}).Where("id = ?", lowest).Update("parent_id", nil).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
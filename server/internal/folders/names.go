@@ -0,0 +1,138 @@
+package folders
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/bhavyajaix/BalkanID-filevault/internal/database"
+	"golang.org/x/text/unicode/norm"
+	"gorm.io/gorm"
+)
+
+// maxNameLength matches the size of resources.name.
+const maxNameLength = 255
+
+// maxRenameAttempts bounds the search for a free " (n)" suffix.
+const maxRenameAttempts = 1000
+
+// ConflictStrategy decides what happens when a resource would take a name that a live
+// sibling already uses.
+type ConflictStrategy string
+
+const (
+	// ConflictFail rejects the operation with ErrNameTaken.
+	ConflictFail ConflictStrategy = "FAIL"
+	// ConflictRename picks the first free name of the form "report (1).pdf".
+	ConflictRename ConflictStrategy = "RENAME"
+	// ConflictReplace deletes the existing sibling, which must be of the same type and
+	// owned by the caller.
+	ConflictReplace ConflictStrategy = "REPLACE"
+)
+
+// NormalizeName trims surrounding whitespace, converts the name to Unicode NFC and
+// validates it. Two names that look the same therefore compare equal in the database.
+func NormalizeName(name string) (string, error) {
+	if !utf8.ValidString(name) {
+		return "", errors.New("name must be valid UTF-8")
+	}
+
+	name = norm.NFC.String(strings.TrimSpace(name))
+	switch {
+	case name == "":
+		return "", errors.New("name cannot be empty")
+	case name == "." || name == "..":
+		return "", fmt.Errorf("%q is not a valid name", name)
+	case utf8.RuneCountInString(name) > maxNameLength:
+		return "", fmt.Errorf("name cannot be longer than %d characters", maxNameLength)
+	}
+
+	for _, r := range name {
+		if r == '/' || r == '\\' || unicode.IsControl(r) {
+			return "", errors.New(`name cannot contain "/", "\" or control characters`)
+		}
+	}
+	return name, nil
+}
+
+// splitExt separates a file's extension from its stem. Folders have no extension.
+func splitExt(name string, kind database.ResourceType) (string, string) {
+	if kind == database.File {
+		if e := path.Ext(name); e != name {
+			return strings.TrimSuffix(name, e), e
+		}
+	}
+	return name, ""
+}
+
+// trimStem shortens a stem so that it fits beside the suffix and extension.
+func trimStem(stem string, suffix string, ext string) string {
+	room := maxNameLength - utf8.RuneCountInString(suffix+ext)
+	if runes := []rune(stem); len(runes) > room {
+		return string(runes[:room])
+	}
+	return stem
+}
+
+// withCopySuffix inserts " (n)" before a file's extension, or at the end of a folder
+// name, trimming the stem if the result would be too long.
+func withCopySuffix(name string, n int, kind database.ResourceType) string {
+	stem, ext := splitExt(name, kind)
+	suffix := fmt.Sprintf(" (%d)", n)
+	return trimStem(stem, suffix, ext) + suffix + ext
+}
+
+// copyPrefix is the start shared by every name withCopySuffix can produce for name:
+// the stem, trimmed as it is for the longest suffix.
+func copyPrefix(name string, kind database.ResourceType) string {
+	stem, ext := splitExt(name, kind)
+	return trimStem(stem, fmt.Sprintf(" (%d)", maxRenameAttempts), ext)
+}
+
+// ResolveNameConflict decides the name a resource of the given kind will take under
+// parentID (the caller's root when nil). excludeID is the resource being renamed or
+// moved, which never conflicts with itself. With ConflictReplace the sibling to delete
+// is returned; the caller removes it before writing.
+func (s *service) ResolveNameConflict(userID uint, parentID *uint, name string, kind database.ResourceType, strategy ConflictStrategy, excludeID uint) (string, *database.Resource, error) {
+	existing, err := s.repo.FindChildByName(parentID, userID, name)
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && existing.ID == excludeID) {
+		return name, nil, nil
+	}
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to check for name conflicts: %w", err)
+	}
+
+	switch strategy {
+	case ConflictRename:
+		// Every candidate starts with the same prefix, so one query finds those taken.
+		names, err := s.repo.ChildNamesWithPrefix(parentID, userID, copyPrefix(name, kind))
+		if err != nil {
+			return "", nil, fmt.Errorf("failed to check for name conflicts: %w", err)
+		}
+		taken := make(map[string]bool, len(names))
+		for _, sibling := range names {
+			taken[sibling] = true
+		}
+		for n := 1; n <= maxRenameAttempts; n++ {
+			if candidate := withCopySuffix(name, n, kind); !taken[candidate] {
+				return candidate, nil, nil
+			}
+		}
+		return "", nil, fmt.Errorf("could not find a free name for %q", name)
+
+	case ConflictReplace:
+		if existing.Type != kind {
+			return "", nil, fmt.Errorf("cannot replace %s %q with a %s", existing.Type, existing.Name, kind)
+		}
+		if existing.OwnerID != userID {
+			return "", nil, errors.New("access denied: cannot replace a resource you don't own")
+		}
+		return name, existing, nil
+
+	default:
+		return "", nil, ErrNameTaken
+	}
+}
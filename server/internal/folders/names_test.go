@@ -0,0 +1,83 @@
+package folders
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/bhavyajaix/BalkanID-filevault/internal/database"
+)
+
+func TestNormalizeName(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{name: "plain", input: "report.pdf", want: "report.pdf"},
+		{name: "surrounding whitespace", input: "  report.pdf \t", want: "report.pdf"},
+		{name: "decomposed accent", input: "cafe\u0301.txt", want: "caf\u00e9.txt"},
+		{name: "inner spaces kept", input: "Q4  report", want: "Q4  report"},
+		{name: "longest", input: strings.Repeat("é", maxNameLength), want: strings.Repeat("é", maxNameLength)},
+		{name: "empty", input: "", wantErr: true},
+		{name: "only whitespace", input: "   ", wantErr: true},
+		{name: "dot", input: ".", wantErr: true},
+		{name: "dot dot", input: " .. ", wantErr: true},
+		{name: "too long", input: strings.Repeat("a", maxNameLength+1), wantErr: true},
+		{name: "slash", input: "a/b", wantErr: true},
+		{name: "backslash", input: `a\b`, wantErr: true},
+		{name: "control character", input: "a\x00b", wantErr: true},
+		{name: "invalid UTF-8", input: "a\xffb", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NormalizeName(tt.input)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("NormalizeName(%q) = %q, want an error", tt.input, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NormalizeName(%q) failed: %v", tt.input, err)
+			}
+			if got != tt.want {
+				t.Errorf("NormalizeName(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithCopySuffix(t *testing.T) {
+	long := strings.Repeat("x", maxNameLength)
+	tests := []struct {
+		name  string
+		input string
+		n     int
+		kind  database.ResourceType
+		want  string
+	}{
+		{name: "file", input: "report.pdf", n: 1, kind: database.File, want: "report (1).pdf"},
+		{name: "last extension only", input: "archive.tar.gz", n: 2, kind: database.File, want: "archive.tar (2).gz"},
+		{name: "file without extension", input: "README", n: 3, kind: database.File, want: "README (3)"},
+		{name: "dotfile", input: ".env", n: 1, kind: database.File, want: ".env (1)"},
+		{name: "folder with a dot", input: "v1.2", n: 1, kind: database.Folder, want: "v1.2 (1)"},
+		{name: "long folder is trimmed", input: long, n: 12, kind: database.Folder, want: long[:maxNameLength-5] + " (12)"},
+		{name: "long file keeps its extension", input: long[:maxNameLength-4] + ".pdf", n: 1, kind: database.File, want: long[:maxNameLength-8] + " (1).pdf"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := withCopySuffix(tt.input, tt.n, tt.kind)
+			if got != tt.want {
+				t.Errorf("withCopySuffix(%q, %d) = %q, want %q", tt.input, tt.n, got, tt.want)
+			}
+			if utf8.RuneCountInString(got) > maxNameLength {
+				t.Errorf("withCopySuffix(%q, %d) is %d characters long", tt.input, tt.n, utf8.RuneCountInString(got))
+			}
+			if !strings.HasPrefix(got, copyPrefix(tt.input, tt.kind)) {
+				t.Errorf("%q does not start with the prefix %q used to find taken names", got, copyPrefix(tt.input, tt.kind))
+			}
+		})
+	}
+}
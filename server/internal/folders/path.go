@@ -0,0 +1,141 @@
+package folders
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/bhavyajaix/BalkanID-filevault/internal/database"
+	"gorm.io/gorm"
+)
+
+// splitPath breaks a slash-separated path such as "/Reports/2025/Q4.pdf" into its
+// normalized names. Empty segments from repeated or trailing slashes are ignored; "."
+// and ".." are rejected because resources have no notion of a working directory.
+func splitPath(path string) ([]string, error) {
+	var names []string
+	for _, segment := range strings.Split(path, "/") {
+		switch segment {
+		case "":
+			continue
+		case ".", "..":
+			return nil, fmt.Errorf("invalid path %q: relative segments are not supported", path)
+		}
+		name, err := NormalizeName(segment)
+		if err != nil {
+			return nil, fmt.Errorf("invalid path %q: %w", path, err)
+		}
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+// ResolvePath finds a resource by its path. Without a share token the path starts at
+// the caller's own root. With one it is relative to the shared resource, and "/" names
+// the shared resource itself. The result is returned as GetResource would.
+func (s *service) ResolvePath(ctx context.Context, path string, shareToken *string) (*database.Resource, error) {
+	userID, err := getUserIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	names, err := splitPath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var current *database.Resource
+	if shareToken != nil {
+		current, err = s.repo.GetByShareToken(*shareToken)
+		if err != nil {
+			return nil, errors.New("share link not found or invalid")
+		}
+		canRead, err := s.CanRead(userID, current)
+		if err != nil {
+			return nil, fmt.Errorf("error checking permissions: %w", err)
+		}
+		if !canRead {
+			return nil, errors.New("access denied")
+		}
+	} else if len(names) == 0 {
+		return nil, errors.New("path must name a file or folder")
+	}
+
+	// A resource the caller can't read is reported like a missing one, so names below a
+	// carve-out can't be probed.
+	notFound := fmt.Errorf("no such file or folder: %q", path)
+	for _, name := range names {
+		var parentID *uint
+		if current != nil {
+			if current.Type != database.Folder {
+				return nil, fmt.Errorf("%q is not a folder", current.Name)
+			}
+			parentID = &current.ID
+		}
+
+		current, err = s.repo.FindChildByName(parentID, userID, name)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, notFound
+			}
+			return nil, fmt.Errorf("failed to resolve path: %w", err)
+		}
+		canRead, err := s.CanRead(userID, current)
+		if err != nil {
+			return nil, fmt.Errorf("error checking permissions: %w", err)
+		}
+		if !canRead {
+			return nil, notFound
+		}
+	}
+
+	return s.GetResource(ctx, current.ID)
+}
+
+// MkdirP makes sure every folder along a path exists in the caller's tree, creating the
+// missing ones, and returns the last. An empty path or "/" returns nil for the root.
+func (s *service) MkdirP(ctx context.Context, path string) (*database.Resource, error) {
+	userID, err := getUserIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	names, err := splitPath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var current *database.Resource
+	for _, name := range names {
+		var parentID *uint
+		if current != nil {
+			parentID = &current.ID
+		}
+
+		next, err := s.repo.FindChildByName(parentID, userID, name)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			next, err = s.CreateFolder(ctx, name, parentID, ConflictFail)
+			if errors.Is(err, ErrNameTaken) {
+				// Created concurrently by another request; use theirs.
+				next, err = s.repo.FindChildByName(parentID, userID, name)
+			}
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to create %q: %w", name, err)
+		}
+
+		if next.Type != database.Folder {
+			return nil, fmt.Errorf("%q already exists and is not a folder", name)
+		}
+		canWrite, err := s.CanWrite(userID, next)
+		if err != nil {
+			return nil, fmt.Errorf("error checking permissions: %w", err)
+		}
+		if !canWrite {
+			return nil, fmt.Errorf("access denied to folder %q", name)
+		}
+		current = next
+	}
+	return current, nil
+}
@@ -1,17 +1,41 @@
 package folders
 
 import (
+	"errors"
+	"strings"
+
 	"github.com/bhavyajaix/BalkanID-filevault/internal/database"
+	"github.com/bhavyajaix/BalkanID-filevault/internal/pagination"
+	"github.com/bhavyajaix/BalkanID-filevault/internal/tag"
 	"gorm.io/gorm"
 )
 
+// hierarchyLockID is the key of the advisory lock that serializes changes to the
+// resource tree, so concurrent moves can't combine into a cycle.
+const hierarchyLockID = 724_113_002
+
+// ErrMoveIntoDescendant is returned when a folder would be moved into itself or
+// somewhere below it.
+var ErrMoveIntoDescendant = errors.New("cannot move a folder into itself or one of its descendants")
+
 type Repository interface {
 	Create(resource *database.Resource) error
 	GetByID(id uint) (*database.Resource, error)
+	GetWithFile(id uint) (*database.Resource, error)
+	FindChildByName(parentID *uint, ownerID uint, name string) (*database.Resource, error)
+	ChildNamesWithPrefix(parentID *uint, ownerID uint, prefix string) ([]string, error)
+	GetByShareToken(token string) (*database.Resource, error)
 	GetChildren(parentID uint) ([]database.Resource, error)
-	GetRoot(ownerID uint) ([]database.Resource, error)
+	GetReadableChildren(parentID uint, readable func(db *gorm.DB) *gorm.DB) ([]database.Resource, error)
+	GetSubtree(rootID uint, viewerID uint) ([]database.Resource, error)
+	ListChildren(parentID *uint, ownerID uint, readable func(db *gorm.DB) *gorm.DB, req pagination.Request) (*pagination.Page, error)
 	Update(resource *database.Resource) error
 	Delete(id uint) error
+	IsAncestor(ancestorID, descendantID uint) (bool, error)
+	Move(resourceID uint, newParentID *uint, name string) error
+	VerifyHierarchy(repair bool) (*HierarchyReport, error)
+	Transaction(fn func(tx *gorm.DB) error) error
+	WithDB(db *gorm.DB) Repository
 }
 
 type repository struct {
@@ -22,6 +46,17 @@ func NewRepository(db *gorm.DB) Repository {
 	return &repository{db: db}
 }
 
+// Transaction runs fn in a transaction on the repository's database.
+func (r *repository) Transaction(fn func(tx *gorm.DB) error) error {
+	return r.db.Transaction(fn)
+}
+
+// WithDB returns a copy of the repository that runs its queries on db, typically a
+// transaction owned by the caller.
+func (r *repository) WithDB(db *gorm.DB) Repository {
+	return &repository{db: db}
+}
+
 func (r *repository) Create(resource *database.Resource) error {
 	return r.db.Create(resource).Error
 }
@@ -35,6 +70,57 @@ func (r *repository) GetByID(id uint) (*database.Resource, error) {
 	return &resource, nil
 }
 
+// GetWithFile fetches a resource together with its owner and, for files, the physical file.
+func (r *repository) GetWithFile(id uint) (*database.Resource, error) {
+	var resource database.Resource
+	if err := r.db.Preload("User").Preload("PhysicalFile").First(&resource, id).Error; err != nil {
+		return nil, err
+	}
+	return &resource, nil
+}
+
+// FindChildByName looks up a live resource by name within a folder, or within the
+// owner's root when parentID is nil. Both lookups are served by the sibling-name indexes.
+func (r *repository) FindChildByName(parentID *uint, ownerID uint, name string) (*database.Resource, error) {
+	var resource database.Resource
+	query := r.db.Where("name = ?", name)
+	if parentID == nil {
+		query = query.Where("parent_id IS NULL AND owner_id = ?", ownerID)
+	} else {
+		query = query.Where("parent_id = ?", *parentID)
+	}
+	if err := query.Take(&resource).Error; err != nil {
+		return nil, err
+	}
+	return &resource, nil
+}
+
+// likeEscaper escapes the wildcards of a LIKE pattern.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// ChildNamesWithPrefix returns the names of the live resources in a folder, or in the
+// owner's root when parentID is nil, that start with prefix.
+func (r *repository) ChildNamesWithPrefix(parentID *uint, ownerID uint, prefix string) ([]string, error) {
+	var names []string
+	query := r.db.Model(&database.Resource{}).Where("name LIKE ?", likeEscaper.Replace(prefix)+"%")
+	if parentID == nil {
+		query = query.Where("parent_id IS NULL AND owner_id = ?", ownerID)
+	} else {
+		query = query.Where("parent_id = ?", *parentID)
+	}
+	err := query.Pluck("name", &names).Error
+	return names, err
+}
+
+// GetByShareToken fetches the resource behind a share link.
+func (r *repository) GetByShareToken(token string) (*database.Resource, error) {
+	var resource database.Resource
+	if err := r.db.Where("share_token = ?", token).Take(&resource).Error; err != nil {
+		return nil, err
+	}
+	return &resource, nil
+}
+
 // GetChildren finds all direct children of a parent resource.
 func (r *repository) GetChildren(parentID uint) ([]database.Resource, error) {
 	var children []database.Resource
@@ -43,13 +129,47 @@ func (r *repository) GetChildren(parentID uint) ([]database.Resource, error) {
 	return children, err
 }
 
-// GetRoot finds all resources with no parent for a specific user.
-func (r *repository) GetRoot(ownerID uint) ([]database.Resource, error) {
+// GetReadableChildren is GetChildren restricted to the children readable lets through,
+// or all of them when it is nil.
+func (r *repository) GetReadableChildren(parentID uint, readable func(db *gorm.DB) *gorm.DB) ([]database.Resource, error) {
+	var children []database.Resource
+	query := r.db.Preload("User").Preload("PhysicalFile").Where("resources.parent_id = ?", parentID)
+	if readable != nil {
+		query = query.Scopes(readable)
+	}
+	err := query.Find(&children).Error
+	return children, err
+}
+
+// GetSubtree returns the live resources at and below rootID, shallowest first, so every
+// parent precedes its children. Files come with their physical file and the tags the
+// viewer can see.
+func (r *repository) GetSubtree(rootID uint, viewerID uint) ([]database.Resource, error) {
 	var resources []database.Resource
-	err := r.db.Preload("User").Preload("PhysicalFile").Where("parent_id IS NULL AND owner_id = ?", ownerID).Find(&resources).Error
+	err := r.db.Preload("PhysicalFile").Preload("Tags", tag.VisibleTo(viewerID)).
+		Joins("JOIN resource_ancestors ON resource_ancestors.descendant_id = resources.id").
+		Where("resource_ancestors.ancestor_id = ?", rootID).
+		Order("resource_ancestors.depth ASC, resources.id ASC").
+		Find(&resources).Error
 	return resources, err
 }
 
+// ListChildren returns one page of a folder's direct children, or of the owner's root
+// when parentID is nil. When readable is set, only the children it lets through are
+// listed and counted.
+func (r *repository) ListChildren(parentID *uint, ownerID uint, readable func(db *gorm.DB) *gorm.DB, req pagination.Request) (*pagination.Page, error) {
+	query := r.db.Model(&database.Resource{})
+	if parentID == nil {
+		query = query.Where("resources.parent_id IS NULL AND resources.owner_id = ?", ownerID)
+	} else {
+		query = query.Where("resources.parent_id = ?", *parentID)
+	}
+	if readable != nil {
+		query = query.Scopes(readable)
+	}
+	return pagination.Paginate(query, req, "User", "PhysicalFile")
+}
+
 func (r *repository) Update(resource *database.Resource) error {
 	return r.db.Save(resource).Error
 }
@@ -58,3 +178,45 @@ func (r *repository) Update(resource *database.Resource) error {
 func (r *repository) Delete(id uint) error {
 	return r.db.Delete(&database.Resource{}, id).Error
 }
+
+// IsAncestor reports whether ancestorID is descendantID itself or one of its ancestors.
+func (r *repository) IsAncestor(ancestorID, descendantID uint) (bool, error) {
+	return isAncestor(r.db, ancestorID, descendantID)
+}
+
+// lockHierarchy takes the hierarchy lock until tx ends. Taking it again in the same
+// transaction is a no-op.
+func lockHierarchy(tx *gorm.DB) error {
+	return tx.Exec("SELECT pg_advisory_xact_lock(?)", hierarchyLockID).Error
+}
+
+func isAncestor(db *gorm.DB, ancestorID, descendantID uint) (bool, error) {
+	var count int64
+	err := db.Model(&database.ResourceAncestor{}).
+		Where("ancestor_id = ? AND descendant_id = ?", ancestorID, descendantID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// Move re-parents a resource, giving it the name it will have in its new folder. The
+// closure table is updated by the database trigger.
+// Moves are serialized and the cycle check is repeated under the lock, so a move that
+// was validated against a stale tree can't slip a loop into the hierarchy.
+func (r *repository) Move(resourceID uint, newParentID *uint, name string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := lockHierarchy(tx); err != nil {
+			return err
+		}
+		if newParentID != nil {
+			cycle, err := isAncestor(tx, resourceID, *newParentID)
+			if err != nil {
+				return err
+			}
+			if cycle {
+				return ErrMoveIntoDescendant
+			}
+		}
+		return tx.Model(&database.Resource{}).Where("id = ?", resourceID).
+			Updates(map[string]interface{}{"parent_id": newParentID, "name": name}).Error
+	})
+}
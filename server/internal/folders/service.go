@@ -7,23 +7,61 @@ import (
 
 	"github.com/bhavyajaix/BalkanID-filevault/internal/database"
 	"github.com/bhavyajaix/BalkanID-filevault/internal/middleware"
+	"github.com/bhavyajaix/BalkanID-filevault/internal/pagination"
 	"github.com/bhavyajaix/BalkanID-filevault/pkg/utils"
+	"gorm.io/gorm"
 )
 
 type Service interface {
-	CreateFolder(ctx context.Context, name string, parentID *uint) (*database.Resource, error)
-	GetResources(ctx context.Context, folderID *uint) ([]database.Resource, error)
-	RenameResource(ctx context.Context, resourceID uint, newName string) (*database.Resource, error)
-	MoveResource(ctx context.Context, resourceID uint, newParentID *uint) (*database.Resource, error)
+	CreateFolder(ctx context.Context, name string, parentID *uint, strategy ConflictStrategy) (*database.Resource, error)
+	ListResources(ctx context.Context, folderID *uint, req pagination.Request) (*pagination.Page, error)
+	RenameResource(ctx context.Context, resourceID uint, newName string, strategy ConflictStrategy) (*database.Resource, error)
+	MoveResource(ctx context.Context, resourceID uint, newParentID *uint, strategy ConflictStrategy) (*database.Resource, error)
+	MoveOwnedResource(userID uint, resourceID uint, newParentID *uint, strategy ConflictStrategy, remove RemoveFunc) (*database.Resource, error)
+	MoveOwnedResources(userID uint, resourceIDs []uint, newParentID *uint, strategy ConflictStrategy, remove RemoveTxFunc) ([]error, error)
+	ResolveNameConflict(userID uint, parentID *uint, name string, kind database.ResourceType, strategy ConflictStrategy, excludeID uint) (string, *database.Resource, error)
 	DeleteResource(ctx context.Context, resourceID uint) error
+	VerifyHierarchy(repair bool) (*HierarchyReport, error)
+	GetResource(ctx context.Context, resourceID uint) (*database.Resource, error)
+	ReadableSubtree(userID uint, rootID uint) ([]database.Resource, error)
+	ValidateCopyTarget(userID uint, resource *database.Resource, parentID *uint) error
+	CanRead(userID uint, resource *database.Resource) (bool, error)
+	CanWrite(userID uint, resource *database.Resource) (bool, error)
+	ResolvePath(ctx context.Context, path string, shareToken *string) (*database.Resource, error)
+	MkdirP(ctx context.Context, path string) (*database.Resource, error)
+}
+
+// ErrNameTaken is returned when a live sibling already uses the requested name.
+var ErrNameTaken = errors.New("a file or folder with this name already exists here")
+
+// ErrCopyIntoDescendant is returned when a folder would be copied into itself or
+// somewhere below it.
+var ErrCopyIntoDescendant = errors.New("cannot copy a folder into itself or one of its descendants")
+
+// RemoveFunc deletes the sibling displaced by ConflictReplace. Folders are removed by
+// this package; files are removed by the file service so their blobs are released.
+type RemoveFunc func(existing *database.Resource) error
+
+// RemoveTxFunc is a RemoveFunc that runs inside the caller's transaction.
+type RemoveTxFunc func(tx *gorm.DB, existing *database.Resource) error
+
+// AccessChecker resolves the grant a user holds on a resource. It is satisfied by
+// permission.Repository, which can't be imported here without a cycle. ReadableBy
+// returns a scope restricting a resources query to the rows a user can read.
+type AccessChecker interface {
+	FindPermission(resourceID, userID uint) (*database.Permission, error)
+	IsPubliclyAccessible(resourceID uint) (bool, error)
+	IsDenied(resourceID, userID uint) (bool, error)
+	ReadableBy(userID uint) func(db *gorm.DB) *gorm.DB
 }
 
 type service struct {
-	repo Repository
+	repo   Repository
+	access AccessChecker
 }
 
-func NewService(repo Repository) Service {
-	return &service{repo: repo}
+func NewService(repo Repository, access AccessChecker) Service {
+	return &service{repo: repo, access: access}
 }
 
 // Helper to get user ID from context
@@ -35,18 +73,34 @@ func getUserIDFromContext(ctx context.Context) (uint, error) {
 	return userID, nil
 }
 
-func (s *service) CreateFolder(ctx context.Context, name string, parentID *uint) (*database.Resource, error) {
+func (s *service) CreateFolder(ctx context.Context, name string, parentID *uint, strategy ConflictStrategy) (*database.Resource, error) {
 	userID, err := getUserIDFromContext(ctx)
 	if err != nil {
 		return nil, err
 	}
 
+	name, err = NormalizeName(name)
+	if err != nil {
+		return nil, err
+	}
+
 	if parentID != nil {
 		parent, err := s.repo.GetByID(*parentID)
 		if err != nil || parent.OwnerID != userID || parent.Type != database.Folder {
 			return nil, errors.New("access denied or invalid parent folder")
 		}
 	}
+
+	name, replaced, err := s.ResolveNameConflict(userID, parentID, name, database.Folder, strategy, 0)
+	if err != nil {
+		return nil, err
+	}
+	if replaced != nil {
+		if err := s.removeFolder(replaced); err != nil {
+			return nil, err
+		}
+	}
+
 	token, err := utils.GenerateUUIDToken()
 	if err != nil {
 		return nil, fmt.Errorf("could not generate share token: %w", err)
@@ -59,36 +113,58 @@ func (s *service) CreateFolder(ctx context.Context, name string, parentID *uint)
 		ShareToken: &token,
 	}
 
-	err = s.repo.Create(folder)
-	return folder, err
+	if err := s.repo.Create(folder); err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return nil, ErrNameTaken
+		}
+		return nil, err
+	}
+	return folder, nil
 }
 
-func (s *service) GetResources(ctx context.Context, folderID *uint) ([]database.Resource, error) {
+// ListResources returns one page of a folder's contents, or of the caller's root when
+// folderID is nil. In a folder shared with the caller, children carved out with a deny
+// or an inheritance break are left out of the page and of its total count.
+func (s *service) ListResources(ctx context.Context, folderID *uint, req pagination.Request) (*pagination.Page, error) {
 	userID, err := getUserIDFromContext(ctx)
 	if err != nil {
 		return nil, err
 	}
 
 	if folderID == nil {
-		return s.repo.GetRoot(userID)
+		return s.repo.ListChildren(nil, userID, nil, req)
 	}
 
-	_, err = s.repo.GetByID(*folderID)
-	if err != nil {
+	folder, err := s.repo.GetByID(*folderID)
+	if err != nil || folder.Type != database.Folder {
 		return nil, errors.New("folder not found")
 	}
+	canRead, err := s.CanRead(userID, folder)
+	if err != nil {
+		return nil, fmt.Errorf("error checking permissions: %w", err)
+	}
+	if !canRead {
+		return nil, errors.New("access denied")
+	}
 
-	// ...
-
-	return s.repo.GetChildren(*folderID)
+	var readable func(db *gorm.DB) *gorm.DB
+	if folder.OwnerID != userID {
+		readable = s.access.ReadableBy(userID)
+	}
+	return s.repo.ListChildren(folderID, userID, readable, req)
 }
 
-func (s *service) RenameResource(ctx context.Context, resourceID uint, newName string) (*database.Resource, error) {
+func (s *service) RenameResource(ctx context.Context, resourceID uint, newName string, strategy ConflictStrategy) (*database.Resource, error) {
 	userID, err := getUserIDFromContext(ctx)
 	if err != nil {
 		return nil, err
 	}
 
+	newName, err = NormalizeName(newName)
+	if err != nil {
+		return nil, err
+	}
+
 	resource, err := s.repo.GetByID(resourceID)
 	if err != nil {
 		return nil, errors.New("resource not found")
@@ -97,17 +173,38 @@ func (s *service) RenameResource(ctx context.Context, resourceID uint, newName s
 		return nil, errors.New("access denied")
 	}
 
+	newName, replaced, err := s.ResolveNameConflict(userID, resource.ParentID, newName, resource.Type, strategy, resource.ID)
+	if err != nil {
+		return nil, err
+	}
+	if replaced != nil {
+		if err := s.removeFolder(replaced); err != nil {
+			return nil, err
+		}
+	}
+
 	resource.Name = newName
-	err = s.repo.Update(resource)
-	return resource, err
+	if err := s.repo.Update(resource); err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return nil, ErrNameTaken
+		}
+		return nil, err
+	}
+	return resource, nil
 }
 
-func (s *service) MoveResource(ctx context.Context, resourceID uint, newParentID *uint) (*database.Resource, error) {
+func (s *service) MoveResource(ctx context.Context, resourceID uint, newParentID *uint, strategy ConflictStrategy) (*database.Resource, error) {
 	userID, err := getUserIDFromContext(ctx)
 	if err != nil {
 		return nil, err
 	}
+	return s.MoveOwnedResource(userID, resourceID, newParentID, strategy, s.removeFolder)
+}
 
+// MoveOwnedResource moves a resource owned by userID under a new parent, or to the root
+// when newParentID is nil. It backs both folder and file moves; remove deletes the
+// sibling displaced when strategy is ConflictReplace.
+func (s *service) MoveOwnedResource(userID uint, resourceID uint, newParentID *uint, strategy ConflictStrategy, remove RemoveFunc) (*database.Resource, error) {
 	resource, err := s.repo.GetByID(resourceID)
 	if err != nil {
 		return nil, errors.New("resource not found")
@@ -117,25 +214,107 @@ func (s *service) MoveResource(ctx context.Context, resourceID uint, newParentID
 	}
 
 	// If moving to a new parent folder, check its validity and permissions.
+	// If newParentID is nil, we are moving to the root, so no parent checks are needed.
 	if newParentID != nil {
-		newParent, err := s.repo.GetByID(*newParentID) // Dereference the pointer here
-		if err != nil {
-			return nil, errors.New("destination folder not found")
+		if err := s.validateMoveTarget(userID, resource, *newParentID); err != nil {
+			return nil, err
+		}
+	}
+
+	name, replaced, err := s.ResolveNameConflict(userID, newParentID, resource.Name, resource.Type, strategy, resource.ID)
+	if err != nil {
+		return nil, err
+	}
+	if replaced != nil {
+		if err := remove(replaced); err != nil {
+			return nil, err
 		}
-		if newParent.OwnerID != userID {
-			return nil, errors.New("access denied to the destination folder")
+	}
+
+	if err := s.repo.Move(resourceID, newParentID, name); err != nil {
+		if errors.Is(err, ErrMoveIntoDescendant) {
+			return nil, err
 		}
-		if newParent.Type != database.Folder {
-			return nil, errors.New("can only move resources into a folder")
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return nil, ErrNameTaken
 		}
+		return nil, fmt.Errorf("failed to move resource: %w", err)
 	}
-	// If newParentID is nil, we are moving to the root, so no parent checks are needed.
+	return s.repo.GetByID(resourceID)
+}
 
-	resource.ParentID = newParentID // Assign the pointer directly
-	if err := s.repo.Update(resource); err != nil {
-		return nil, err
+// MoveOwnedResources moves a selection of resources owned by userID under the same
+// parent in a single transaction that holds the hierarchy lock throughout, so the whole
+// batch sees one tree. Each item runs in its own savepoint: one failure doesn't undo the
+// others, and the returned errors line up with resourceIDs.
+func (s *service) MoveOwnedResources(userID uint, resourceIDs []uint, newParentID *uint, strategy ConflictStrategy, remove RemoveTxFunc) ([]error, error) {
+	results := make([]error, len(resourceIDs))
+	err := s.repo.Transaction(func(tx *gorm.DB) error {
+		if err := lockHierarchy(tx); err != nil {
+			return err
+		}
+		for i, resourceID := range resourceIDs {
+			results[i] = tx.Transaction(func(itemTx *gorm.DB) error {
+				scoped := &service{repo: s.repo.WithDB(itemTx), access: s.access}
+				removeInTx := func(existing *database.Resource) error {
+					return remove(itemTx, existing)
+				}
+				_, err := scoped.MoveOwnedResource(userID, resourceID, newParentID, strategy, removeInTx)
+				return err
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
 	}
-	return resource, nil
+	return results, nil
+}
+
+// removeFolder deletes a folder displaced by ConflictReplace.
+func (s *service) removeFolder(existing *database.Resource) error {
+	if existing.Type != database.Folder {
+		return fmt.Errorf("cannot replace %s %q here", existing.Type, existing.Name)
+	}
+	if err := s.repo.Delete(existing.ID); err != nil {
+		return fmt.Errorf("failed to replace %q: %w", existing.Name, err)
+	}
+	return nil
+}
+
+// validateMoveTarget checks that newParentID is a folder the user can write to and that
+// it doesn't sit inside the resource being moved.
+func (s *service) validateMoveTarget(userID uint, resource *database.Resource, newParentID uint) error {
+	if newParentID == resource.ID {
+		return ErrMoveIntoDescendant
+	}
+
+	newParent, err := s.repo.GetByID(newParentID)
+	if err != nil {
+		return errors.New("destination folder not found")
+	}
+	if newParent.Type != database.Folder {
+		return errors.New("the destination must be a folder")
+	}
+	canWrite, err := s.CanWrite(userID, newParent)
+	if err != nil {
+		return fmt.Errorf("error checking permissions: %w", err)
+	}
+	if !canWrite {
+		return errors.New("access denied to the destination folder")
+	}
+
+	// The closure table lists every folder below the resource, so one lookup rules out cycles.
+	if resource.Type == database.Folder {
+		inside, err := s.repo.IsAncestor(resource.ID, newParent.ID)
+		if err != nil {
+			return fmt.Errorf("failed to check destination folder: %w", err)
+		}
+		if inside {
+			return ErrMoveIntoDescendant
+		}
+	}
+	return nil
 }
 
 func (s *service) DeleteResource(ctx context.Context, resourceID uint) error {
@@ -154,3 +333,130 @@ func (s *service) DeleteResource(ctx context.Context, resourceID uint) error {
 
 	return s.repo.Delete(resourceID)
 }
+
+// VerifyHierarchy checks resource_ancestors against parent_id and optionally repairs it.
+// Callers are responsible for restricting this to administrators.
+func (s *service) VerifyHierarchy(repair bool) (*HierarchyReport, error) {
+	return s.repo.VerifyHierarchy(repair)
+}
+
+// GetResource fetches a single resource the user may read: one they own, one they hold
+// a grant on (directly, through a group or inherited), or one that is public. Folders
+// are returned with their direct children.
+func (s *service) GetResource(ctx context.Context, resourceID uint) (*database.Resource, error) {
+	userID, err := getUserIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	resource, err := s.repo.GetWithFile(resourceID)
+	if err != nil {
+		return nil, errors.New("resource not found")
+	}
+
+	canRead, err := s.CanRead(userID, resource)
+	if err != nil {
+		return nil, fmt.Errorf("error checking permissions: %w", err)
+	}
+	if !canRead {
+		return nil, errors.New("access denied")
+	}
+
+	if resource.Type == database.Folder {
+		// A non-owner doesn't see children carved out with a deny or an inheritance break.
+		var readable func(db *gorm.DB) *gorm.DB
+		if resource.OwnerID != userID {
+			readable = s.access.ReadableBy(userID)
+		}
+		resource.Children, err = s.repo.GetReadableChildren(resource.ID, readable)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load folder contents: %w", err)
+		}
+	}
+	return resource, nil
+}
+
+// ReadableSubtree returns the resources at and below rootID that the user can read,
+// shallowest first. A branch the user can't read is left out along with everything
+// below it.
+func (s *service) ReadableSubtree(userID uint, rootID uint) ([]database.Resource, error) {
+	subtree, err := s.repo.GetSubtree(rootID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load resources: %w", err)
+	}
+	if len(subtree) == 0 {
+		return nil, errors.New("resource not found")
+	}
+
+	included := make(map[uint]bool, len(subtree))
+	readable := make([]database.Resource, 0, len(subtree))
+	for i := range subtree {
+		resource := &subtree[i]
+		if resource.ID != rootID && (resource.ParentID == nil || !included[*resource.ParentID]) {
+			continue
+		}
+		canRead, err := s.CanRead(userID, resource)
+		if err != nil {
+			return nil, fmt.Errorf("error checking permissions: %w", err)
+		}
+		if !canRead {
+			if resource.ID == rootID {
+				return nil, errors.New("access denied")
+			}
+			continue
+		}
+		included[resource.ID] = true
+		readable = append(readable, *resource)
+	}
+	return readable, nil
+}
+
+// ValidateCopyTarget checks that parentID, when set, is a folder the user can write to
+// and that it doesn't sit inside the resource being copied.
+func (s *service) ValidateCopyTarget(userID uint, resource *database.Resource, parentID *uint) error {
+	if parentID == nil {
+		return nil
+	}
+	if err := s.validateMoveTarget(userID, resource, *parentID); err != nil {
+		if errors.Is(err, ErrMoveIntoDescendant) {
+			return ErrCopyIntoDescendant
+		}
+		return err
+	}
+	return nil
+}
+
+// CanWrite reports whether the user owns a resource or holds an EDITOR grant on it.
+func (s *service) CanWrite(userID uint, resource *database.Resource) (bool, error) {
+	if resource.OwnerID == userID {
+		return true, nil
+	}
+
+	grant, err := s.access.FindPermission(resource.ID, userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return grant.Role == database.Editor, nil
+}
+
+// CanRead reports whether the user owns, has been granted, or can publicly see a resource.
+// An explicit deny overrides public access.
+func (s *service) CanRead(userID uint, resource *database.Resource) (bool, error) {
+	if resource.OwnerID == userID {
+		return true, nil
+	}
+
+	if _, err := s.access.FindPermission(resource.ID, userID); err == nil {
+		return true, nil
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, err
+	}
+
+	if denied, err := s.access.IsDenied(resource.ID, userID); err != nil || denied {
+		return false, err
+	}
+	return s.access.IsPubliclyAccessible(resource.ID)
+}
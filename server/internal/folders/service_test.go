@@ -0,0 +1,59 @@
+package folders
+
+import (
+	"testing"
+
+	"github.com/bhavyajaix/BalkanID-filevault/internal/database"
+	"gorm.io/gorm"
+)
+
+// stubAccess answers access checks with fixed results.
+type stubAccess struct {
+	AccessChecker
+	granted, denied, public bool
+}
+
+func (a *stubAccess) FindPermission(resourceID, userID uint) (*database.Permission, error) {
+	if !a.granted {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &database.Permission{ResourceID: resourceID, UserID: userID, Role: database.Viewer}, nil
+}
+
+func (a *stubAccess) IsDenied(resourceID, userID uint) (bool, error) {
+	return a.denied, nil
+}
+
+func (a *stubAccess) IsPubliclyAccessible(resourceID uint) (bool, error) {
+	return a.public, nil
+}
+
+func TestCanRead(t *testing.T) {
+	const owner, other = 1, 2
+	resource := &database.Resource{OwnerID: owner, Name: "report.pdf", Type: database.File}
+
+	tests := []struct {
+		name   string
+		userID uint
+		access stubAccess
+		want   bool
+	}{
+		{name: "owner", userID: owner, access: stubAccess{denied: true}, want: true},
+		{name: "granted", userID: other, access: stubAccess{granted: true}, want: true},
+		{name: "public", userID: other, access: stubAccess{public: true}, want: true},
+		{name: "denied public", userID: other, access: stubAccess{denied: true, public: true}, want: false},
+		{name: "nothing", userID: other, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &service{access: &tt.access}
+			got, err := s.CanRead(tt.userID, resource)
+			if err != nil {
+				t.Fatalf("CanRead failed: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("CanRead = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
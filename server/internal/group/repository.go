@@ -0,0 +1,103 @@
+package group
+
+import (
+	"github.com/bhavyajaix/BalkanID-filevault/internal/database"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Repository defines the database operations for groups and their members.
+type Repository interface {
+	Create(group *database.Group) error
+	GetByID(id uint) (*database.Group, error)
+	ListForUser(userID uint) ([]database.Group, error)
+	Update(group *database.Group) error
+	Delete(id uint) error
+	FindMember(groupID, userID uint) (*database.GroupMember, error)
+	AddOrUpdateMember(member *database.GroupMember) error
+	RemoveMember(groupID, userID uint) error
+}
+
+type repository struct {
+	db *gorm.DB
+}
+
+// NewRepository creates a new group repository.
+func NewRepository(db *gorm.DB) Repository {
+	return &repository{db: db}
+}
+
+// Create saves the group together with any members already attached to it.
+func (r *repository) Create(group *database.Group) error {
+	return r.db.Create(group).Error
+}
+
+// GetByID fetches a group with its owner and members preloaded.
+func (r *repository) GetByID(id uint) (*database.Group, error) {
+	var group database.Group
+	if err := r.db.Preload("Owner").Preload("Members.User").First(&group, id).Error; err != nil {
+		return nil, err
+	}
+	return &group, nil
+}
+
+// ListForUser returns every group the user is a member of.
+func (r *repository) ListForUser(userID uint) ([]database.Group, error) {
+	var groups []database.Group
+	memberships := r.db.Model(&database.GroupMember{}).Select("group_id").Where("user_id = ?", userID)
+	err := r.db.Preload("Owner").Preload("Members.User").
+		Where("id IN (?)", memberships).
+		Order("name asc").
+		Find(&groups).Error
+	return groups, err
+}
+
+func (r *repository) Update(group *database.Group) error {
+	return r.db.Save(group).Error
+}
+
+// Delete soft-deletes the group. Memberships, group grants, group tags and group
+// metadata fields are removed explicitly, since the DB cascade never fires for a soft
+// delete. The values of those fields are stripped from every resource.
+func (r *repository) Delete(id uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("group_id = ?", id).Delete(&database.GroupPermission{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("group_id = ?", id).Delete(&database.GroupMember{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Where("group_id = ?", id).Delete(&database.Tag{}).Error; err != nil {
+			return err
+		}
+		fieldKeys := tx.Unscoped().Model(&database.MetadataField{}).Select("id::text").Where("group_id = ?", id)
+		if err := tx.Exec("UPDATE resources SET metadata = metadata - ARRAY(?) WHERE EXISTS (SELECT 1 FROM jsonb_object_keys(metadata) AS key WHERE key IN (?))", fieldKeys, fieldKeys).Error; err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Where("group_id = ?", id).Delete(&database.MetadataField{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&database.Group{}, id).Error
+	})
+}
+
+func (r *repository) FindMember(groupID, userID uint) (*database.GroupMember, error) {
+	var member database.GroupMember
+	if err := r.db.Where("group_id = ? AND user_id = ?", groupID, userID).First(&member).Error; err != nil {
+		return nil, err
+	}
+	return &member, nil
+}
+
+// AddOrUpdateMember performs an "upsert" on the membership, updating the admin flag
+// if the user is already a member.
+func (r *repository) AddOrUpdateMember(member *database.GroupMember) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "group_id"}, {Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"is_admin"}),
+	}).Create(member).Error
+}
+
+func (r *repository) RemoveMember(groupID, userID uint) error {
+	return r.db.Where("group_id = ? AND user_id = ?", groupID, userID).Delete(&database.GroupMember{}).Error
+}
@@ -0,0 +1,204 @@
+package group
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/bhavyajaix/BalkanID-filevault/internal/database"
+	"github.com/bhavyajaix/BalkanID-filevault/internal/middleware"
+	"github.com/bhavyajaix/BalkanID-filevault/internal/user"
+	"gorm.io/gorm"
+)
+
+func getUserIDFromContext(ctx context.Context) (uint, error) {
+	userID, ok := ctx.Value(middleware.UserContextKey).(uint)
+	if !ok {
+		return 0, errors.New("unauthorized")
+	}
+	return userID, nil
+}
+
+// Service defines the business logic for managing groups and their membership.
+type Service interface {
+	CreateGroup(ctx context.Context, name string) (*database.Group, error)
+	GetGroup(ctx context.Context, groupID uint) (*database.Group, error)
+	ListGroups(ctx context.Context) ([]database.Group, error)
+	RenameGroup(ctx context.Context, groupID uint, newName string) (*database.Group, error)
+	DeleteGroup(ctx context.Context, groupID uint) error
+	AddMember(ctx context.Context, groupID uint, email string, isAdmin bool) (*database.Group, error)
+	RemoveMember(ctx context.Context, groupID uint, email string) (*database.Group, error)
+}
+
+type service struct {
+	repo     Repository
+	userRepo user.Repository
+}
+
+// NewService creates a new group service.
+func NewService(repo Repository, userRepo user.Repository) Service {
+	return &service{repo: repo, userRepo: userRepo}
+}
+
+// CreateGroup creates a group owned by the current user, who also becomes its first admin.
+func (s *service) CreateGroup(ctx context.Context, name string) (*database.Group, error) {
+	userID, err := getUserIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, errors.New("group name cannot be empty")
+	}
+
+	group := &database.Group{
+		Name:    name,
+		OwnerID: userID,
+		Members: []database.GroupMember{{UserID: userID, IsAdmin: true, CreatedAt: time.Now()}},
+	}
+	if err := s.repo.Create(group); err != nil {
+		return nil, err
+	}
+	return s.repo.GetByID(group.ID)
+}
+
+// GetGroup returns a group if the current user is one of its members.
+func (s *service) GetGroup(ctx context.Context, groupID uint) (*database.Group, error) {
+	userID, err := getUserIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.repo.FindMember(groupID, userID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("group not found")
+		}
+		return nil, err
+	}
+	return s.repo.GetByID(groupID)
+}
+
+// ListGroups returns every group the current user belongs to.
+func (s *service) ListGroups(ctx context.Context) ([]database.Group, error) {
+	userID, err := getUserIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.ListForUser(userID)
+}
+
+func (s *service) RenameGroup(ctx context.Context, groupID uint, newName string) (*database.Group, error) {
+	group, err := s.getGroupAsAdmin(ctx, groupID)
+	if err != nil {
+		return nil, err
+	}
+
+	newName = strings.TrimSpace(newName)
+	if newName == "" {
+		return nil, errors.New("group name cannot be empty")
+	}
+
+	group.Name = newName
+	if err := s.repo.Update(group); err != nil {
+		return nil, err
+	}
+	return group, nil
+}
+
+// DeleteGroup removes the group and every grant made to it. Only the owner can do this.
+func (s *service) DeleteGroup(ctx context.Context, groupID uint) error {
+	userID, err := getUserIDFromContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	group, err := s.repo.GetByID(groupID)
+	if err != nil {
+		return errors.New("group not found")
+	}
+	if group.OwnerID != userID {
+		return errors.New("access denied: only the owner can delete a group")
+	}
+	return s.repo.Delete(groupID)
+}
+
+// AddMember adds a user to the group, or updates their admin flag if they are already a member.
+func (s *service) AddMember(ctx context.Context, groupID uint, email string, isAdmin bool) (*database.Group, error) {
+	group, err := s.getGroupAsAdmin(ctx, groupID)
+	if err != nil {
+		return nil, err
+	}
+
+	targetUser, err := s.userRepo.GetUserByEmail(email)
+	if err != nil {
+		return nil, errors.New("user with the specified email not found")
+	}
+	if targetUser.ID == group.OwnerID && !isAdmin {
+		return nil, errors.New("the group owner is always an admin")
+	}
+
+	member := &database.GroupMember{
+		GroupID:   groupID,
+		UserID:    targetUser.ID,
+		IsAdmin:   isAdmin,
+		CreatedAt: time.Now(),
+	}
+	if err := s.repo.AddOrUpdateMember(member); err != nil {
+		return nil, err
+	}
+	return s.repo.GetByID(groupID)
+}
+
+// RemoveMember removes a user from the group. Admins can remove anyone but the owner,
+// and any member can remove themselves.
+func (s *service) RemoveMember(ctx context.Context, groupID uint, email string) (*database.Group, error) {
+	userID, err := getUserIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	targetUser, err := s.userRepo.GetUserByEmail(email)
+	if err != nil {
+		return nil, errors.New("user with the specified email not found")
+	}
+
+	group, err := s.repo.GetByID(groupID)
+	if err != nil {
+		return nil, errors.New("group not found")
+	}
+	if targetUser.ID == group.OwnerID {
+		return nil, errors.New("cannot remove the owner from their group")
+	}
+	if targetUser.ID != userID {
+		if _, err := s.getGroupAsAdmin(ctx, groupID); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.repo.RemoveMember(groupID, targetUser.ID); err != nil {
+		return nil, err
+	}
+	return s.repo.GetByID(groupID)
+}
+
+// getGroupAsAdmin fetches the group and verifies the current user is one of its admins.
+func (s *service) getGroupAsAdmin(ctx context.Context, groupID uint) (*database.Group, error) {
+	userID, err := getUserIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	member, err := s.repo.FindMember(groupID, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("group not found")
+		}
+		return nil, err
+	}
+	if !member.IsAdmin {
+		return nil, errors.New("access denied: only group admins can manage the group")
+	}
+	return s.repo.GetByID(groupID)
+}
@@ -0,0 +1,236 @@
+package indexer
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/net/html"
+)
+
+// maxTextBytes caps the text kept per file. PostgreSQL refuses tsvectors over 1MB, and
+// the start of a document is what matters most for search anyway.
+const maxTextBytes = 512 << 10
+
+// ErrUnsupported is returned for content no extractor understands.
+var ErrUnsupported = errors.New("unsupported content type")
+
+type format int
+
+const (
+	formatUnknown format = iota
+	formatText
+	formatHTML
+	formatPDF
+	formatDOCX
+)
+
+const docxMimeType = "application/vnd.openxmlformats-officedocument.wordprocessingml.document"
+
+// textMimeTypes are served by the plain text extractor: plain text, Markdown and CSV.
+var textMimeTypes = map[string]bool{
+	"text/plain":                true,
+	"text/markdown":             true,
+	"text/x-markdown":           true,
+	"text/csv":                  true,
+	"application/csv":           true,
+	"text/tab-separated-values": true,
+}
+
+// Extract returns the searchable text of a document. The declared MIME type comes from
+// the uploading client, so it is only trusted when the content agrees; otherwise the
+// format is sniffed from the data.
+func Extract(data []byte, mimeType string) (string, error) {
+	var text string
+	var err error
+	switch detectFormat(data, mimeType) {
+	case formatText:
+		text = string(data)
+	case formatHTML:
+		text, err = extractHTML(data)
+	case formatPDF:
+		text, err = extractPDF(data)
+	case formatDOCX:
+		text, err = extractDOCX(data)
+	default:
+		return "", ErrUnsupported
+	}
+	if err != nil {
+		return "", err
+	}
+	return clean(text), nil
+}
+
+func detectFormat(data []byte, mimeType string) format {
+	declared, _, _ := mime.ParseMediaType(mimeType)
+
+	switch {
+	case bytes.HasPrefix(data, []byte("%PDF-")):
+		return formatPDF
+	case bytes.HasPrefix(data, []byte("PK\x03\x04")):
+		if declared == docxMimeType || isDOCX(data) {
+			return formatDOCX
+		}
+		return formatUnknown
+	case declared == "text/html" || declared == "application/xhtml+xml":
+		return formatHTML
+	}
+
+	if !utf8.Valid(data) {
+		return formatUnknown
+	}
+	if textMimeTypes[declared] {
+		return formatText
+	}
+	sniffed, _, _ := mime.ParseMediaType(http.DetectContentType(data))
+	switch sniffed {
+	case "text/html":
+		return formatHTML
+	case "text/plain":
+		return formatText
+	}
+	return formatUnknown
+}
+
+// clean makes text safe to store and index: valid UTF-8 without control characters,
+// truncated to maxTextBytes on a rune boundary.
+func clean(text string) string {
+	text = strings.ToValidUTF8(text, " ")
+	text = strings.Map(func(r rune) rune {
+		if r == '\n' || r == '\t' {
+			return r
+		}
+		if unicode.IsControl(r) {
+			return ' '
+		}
+		return r
+	}, text)
+
+	if len(text) > maxTextBytes {
+		cut := maxTextBytes
+		for cut > 0 && !utf8.RuneStart(text[cut]) {
+			cut--
+		}
+		text = text[:cut]
+	}
+	return strings.TrimSpace(text)
+}
+
+// extractHTML returns the visible text of an HTML document.
+func extractHTML(data []byte) (string, error) {
+	var sb strings.Builder
+	tokenizer := html.NewTokenizer(bytes.NewReader(data))
+	skip := 0
+	for {
+		switch tokenizer.Next() {
+		case html.ErrorToken:
+			if err := tokenizer.Err(); err != io.EOF {
+				return "", err
+			}
+			return sb.String(), nil
+		case html.StartTagToken:
+			if name, _ := tokenizer.TagName(); isHiddenElement(string(name)) {
+				skip++
+			}
+		case html.EndTagToken:
+			name, _ := tokenizer.TagName()
+			if isHiddenElement(string(name)) && skip > 0 {
+				skip--
+			}
+			sb.WriteByte('\n')
+		case html.TextToken:
+			if skip == 0 {
+				sb.Write(tokenizer.Text())
+				sb.WriteByte(' ')
+			}
+		}
+	}
+}
+
+func isHiddenElement(name string) bool {
+	return name == "script" || name == "style" || name == "noscript" || name == "template"
+}
+
+func isDOCX(data []byte) bool {
+	reader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return false
+	}
+	for _, f := range reader.File {
+		if f.Name == "word/document.xml" {
+			return true
+		}
+	}
+	return false
+}
+
+// extractDOCX returns the text of a Word document's main body.
+func extractDOCX(data []byte) (string, error) {
+	reader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return "", err
+	}
+
+	for _, f := range reader.File {
+		if f.Name != "word/document.xml" {
+			continue
+		}
+		body, err := f.Open()
+		if err != nil {
+			return "", err
+		}
+		defer body.Close()
+		return extractWordXML(io.LimitReader(body, 64<<20))
+	}
+	return "", errors.New("document body not found")
+}
+
+// extractWordXML collects the runs of text (w:t) in a WordprocessingML body, breaking
+// lines at paragraphs.
+func extractWordXML(r io.Reader) (string, error) {
+	var sb strings.Builder
+	decoder := xml.NewDecoder(r)
+	inText := false
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return sb.String(), nil
+		}
+		if err != nil {
+			return "", err
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "t":
+				inText = true
+			case "tab":
+				sb.WriteByte('\t')
+			case "br", "cr":
+				sb.WriteByte('\n')
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "t":
+				inText = false
+			case "p":
+				sb.WriteByte('\n')
+			}
+		case xml.CharData:
+			if inText {
+				sb.Write(t)
+			}
+		}
+		if sb.Len() > maxTextBytes {
+			return sb.String(), nil
+		}
+	}
+}
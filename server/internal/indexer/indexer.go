@@ -0,0 +1,128 @@
+package indexer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/bhavyajaix/BalkanID-filevault/internal/database"
+)
+
+const (
+	// batchSize is how many files are indexed per run.
+	batchSize = 20
+	// maxFileBytes is the largest file the indexer reads; bigger ones are skipped.
+	maxFileBytes = 32 << 20
+)
+
+// ErrTooLarge is returned for files bigger than the indexer reads.
+var ErrTooLarge = errors.New("file too large to index")
+
+// ExtractFile returns the searchable text of a stored file.
+func ExtractFile(pf *database.PhysicalFile) (string, error) {
+	if pf.SizeBytes > maxFileBytes {
+		return "", ErrTooLarge
+	}
+	data, err := os.ReadFile(pf.FilePath)
+	if err != nil {
+		return "", err
+	}
+	return Extract(data, pf.MimeType)
+}
+
+// Indexer extracts the text of uploaded files in the background. Blobs are
+// deduplicated, so each physical file is indexed once, however many resources use it.
+type Indexer struct {
+	repo     Repository
+	interval time.Duration
+}
+
+// NewIndexer creates an indexer that looks for new files every interval.
+func NewIndexer(repo Repository, interval time.Duration) *Indexer {
+	return &Indexer{repo: repo, interval: interval}
+}
+
+// Run blocks until the context is cancelled, indexing pending files on every tick.
+func (ix *Indexer) Run(ctx context.Context) {
+	ticker := time.NewTicker(ix.interval)
+	defer ticker.Stop()
+
+	for {
+		if err := ix.RunOnce(ctx); err != nil {
+			log.Printf("content indexer: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce indexes the files that are waiting, until none are left or the context is
+// cancelled. Each file is tried at most once per run; files that fail are retried on a
+// later run.
+func (ix *Indexer) RunOnce(ctx context.Context) error {
+	var lastID uint
+	for ctx.Err() == nil {
+		files, err := ix.repo.FindPending(lastID, batchSize)
+		if err != nil {
+			return fmt.Errorf("failed to find pending files: %w", err)
+		}
+		if len(files) == 0 {
+			return nil
+		}
+
+		for i := range files {
+			if err := ix.repo.SaveContent(ix.index(&files[i])); err != nil {
+				log.Printf("content indexer: could not save content of file %d: %v", files[i].ID, err)
+				ix.recordFailure(files[i].ID, err)
+			}
+		}
+		lastID = files[len(files)-1].ID
+	}
+	return nil
+}
+
+// recordFailure marks a file whose content could not be saved as failed, without the
+// text that may have been rejected, so it counts towards its attempts.
+func (ix *Indexer) recordFailure(physicalFileID uint, cause error) {
+	failed := &database.FileContent{
+		PhysicalFileID: physicalFileID,
+		Status:         database.ContentFailed,
+		Error:          cause.Error(),
+		Attempts:       1,
+		IndexedAt:      time.Now(),
+	}
+	if err := ix.repo.SaveContent(failed); err != nil {
+		log.Printf("content indexer: could not record failure of file %d: %v", physicalFileID, err)
+	}
+}
+
+// index extracts the text of a physical file. Failures are recorded rather than
+// returned, so one bad file doesn't hold up the rest.
+func (ix *Indexer) index(pf *database.PhysicalFile) *database.FileContent {
+	content := &database.FileContent{
+		PhysicalFileID: pf.ID,
+		Attempts:       1,
+		IndexedAt:      time.Now(),
+	}
+	var err error
+	content.Content, err = ExtractFile(pf)
+	switch {
+	case errors.Is(err, ErrUnsupported), errors.Is(err, ErrTooLarge):
+		content.Status = database.ContentUnsupported
+		content.Error = err.Error()
+	case err != nil:
+		content.Status = database.ContentFailed
+		content.Error = err.Error()
+		log.Printf("content indexer: could not index file %d: %v", pf.ID, err)
+	default:
+		content.Status = database.ContentIndexed
+	}
+	return content
+}
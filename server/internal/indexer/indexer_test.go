@@ -0,0 +1,99 @@
+package indexer
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/bhavyajaix/BalkanID-filevault/internal/database"
+)
+
+var errRejected = errors.New("rejected")
+
+// memoryRepository keeps the indexing state in memory. Saving the outcome of indexing
+// the files in rejected fails; only the failure recorded afterwards is kept.
+type memoryRepository struct {
+	files    []database.PhysicalFile
+	contents map[uint]*database.FileContent
+	rejected map[uint]bool
+	saves    map[uint]int
+}
+
+func (r *memoryRepository) FindPending(afterID uint, limit int) ([]database.PhysicalFile, error) {
+	var pending []database.PhysicalFile
+	for _, file := range r.files {
+		content := r.contents[file.ID]
+		if file.ID > afterID && (content == nil || (content.Status == database.ContentFailed && content.Attempts < maxAttempts)) {
+			pending = append(pending, file)
+		}
+		if len(pending) == limit {
+			break
+		}
+	}
+	return pending, nil
+}
+
+func (r *memoryRepository) SaveContent(content *database.FileContent) error {
+	r.saves[content.PhysicalFileID]++
+	if r.rejected[content.PhysicalFileID] && content.Error != errRejected.Error() {
+		return errRejected
+	}
+	attempts := 1
+	if previous := r.contents[content.PhysicalFileID]; previous != nil {
+		attempts = previous.Attempts + 1
+	}
+	saved := *content
+	saved.Attempts = attempts
+	r.contents[content.PhysicalFileID] = &saved
+	return nil
+}
+
+func TestRunOnce(t *testing.T) {
+	dir := t.TempDir()
+	repo := &memoryRepository{
+		files: []database.PhysicalFile{
+			{FilePath: dir + "/missing", MimeType: "text/plain"},
+			{FilePath: dir + "/rejected", MimeType: "text/plain"},
+			{FilePath: dir + "/also-missing", MimeType: "text/plain"},
+		},
+		contents: map[uint]*database.FileContent{},
+		rejected: map[uint]bool{2: true},
+		saves:    map[uint]int{},
+	}
+	for i := range repo.files {
+		repo.files[i].ID = uint(i + 1)
+	}
+	ix := NewIndexer(repo, 0)
+
+	if err := ix.RunOnce(context.Background()); err != nil {
+		t.Fatalf("RunOnce failed: %v", err)
+	}
+	for _, file := range repo.files {
+		content := repo.contents[file.ID]
+		if content == nil {
+			t.Errorf("file %d: no outcome recorded", file.ID)
+			continue
+		}
+		if content.Status != database.ContentFailed || content.Attempts != 1 {
+			t.Errorf("file %d: recorded %s after %d attempts, want FAILED after 1", file.ID, content.Status, content.Attempts)
+		}
+	}
+	if repo.saves[1] != 1 || repo.saves[2] != 2 || repo.saves[3] != 1 {
+		t.Errorf("files were saved %v times, want each once per run and the rejected one's failure once more", repo.saves)
+	}
+	if repo.contents[2].Error != errRejected.Error() {
+		t.Errorf("rejected file recorded %q, want %q", repo.contents[2].Error, errRejected.Error())
+	}
+
+	// Every run tries the failing files once more, until they run out of attempts.
+	for run := 2; run <= maxAttempts+1; run++ {
+		if err := ix.RunOnce(context.Background()); err != nil {
+			t.Fatalf("run %d failed: %v", run, err)
+		}
+	}
+	for _, file := range repo.files {
+		if attempts := repo.contents[file.ID].Attempts; attempts != maxAttempts {
+			t.Errorf("file %d: tried %d times, want %d", file.ID, attempts, maxAttempts)
+		}
+	}
+}
@@ -0,0 +1,288 @@
+package indexer
+
+import (
+	"bytes"
+	"compress/zlib"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+)
+
+// maxStreamBytes caps the decompressed size of a single PDF stream.
+const maxStreamBytes = 16 << 20
+
+// extractPDF pulls the text shown by the content streams of a PDF. It understands
+// uncompressed and Flate-compressed streams and fonts with single-byte or UTF-16
+// encodings, which covers documents exported by common office software; text drawn with
+// embedded CID fonts is skipped.
+func extractPDF(data []byte) (string, error) {
+	var sb strings.Builder
+	for _, stream := range pdfStreams(data) {
+		if !bytes.Contains(stream, []byte("BT")) {
+			continue
+		}
+		pdfContentText(stream, &sb)
+		if sb.Len() > maxTextBytes {
+			break
+		}
+	}
+	if sb.Len() == 0 {
+		return "", errors.New("no extractable text found")
+	}
+	return sb.String(), nil
+}
+
+// pdfStreams returns the decoded data of every stream that is either uncompressed or
+// Flate-compressed. Streams using other filters, such as images, are skipped.
+func pdfStreams(data []byte) [][]byte {
+	var streams [][]byte
+	pos := 0
+	for {
+		start := bytes.Index(data[pos:], []byte("stream"))
+		if start < 0 {
+			return streams
+		}
+		start += pos
+		pos = start + len("stream")
+
+		// "endstream" also contains the keyword; only a stream start is followed by EOL.
+		if start >= 3 && string(data[start-3:start]) == "end" {
+			continue
+		}
+		body := pos
+		if body < len(data) && data[body] == '\r' {
+			body++
+		}
+		if body >= len(data) || data[body] != '\n' {
+			continue
+		}
+		body++
+
+		end := bytes.Index(data[body:], []byte("endstream"))
+		if end < 0 {
+			return streams
+		}
+		raw := bytes.TrimRight(data[body:body+end], "\r\n")
+		pos = body + end + len("endstream")
+
+		dict := streamDictionary(data[:start])
+		switch {
+		case bytes.Contains(dict, []byte("/Subtype/Image")), bytes.Contains(dict, []byte("/Subtype /Image")):
+			continue
+		case bytes.Contains(dict, []byte("/FlateDecode")):
+			if bytes.Count(dict, []byte("Decode")) > 1 {
+				continue
+			}
+			decoded, err := inflate(raw)
+			if err != nil {
+				continue
+			}
+			streams = append(streams, decoded)
+		case bytes.Contains(dict, []byte("/Filter")):
+			continue
+		default:
+			streams = append(streams, raw)
+		}
+	}
+}
+
+// streamDictionary returns the dictionary of the object a stream belongs to: the text
+// between the last "obj" keyword and the stream.
+func streamDictionary(before []byte) []byte {
+	if i := bytes.LastIndex(before, []byte("obj")); i >= 0 {
+		return before[i:]
+	}
+	return before
+}
+
+func inflate(raw []byte) ([]byte, error) {
+	reader, err := zlib.NewReader(bytes.NewReader(raw))
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	decoded, err := io.ReadAll(io.LimitReader(reader, maxStreamBytes))
+	if err != nil && len(decoded) == 0 {
+		return nil, err
+	}
+	// A truncated stream still yields the text decoded so far.
+	return decoded, nil
+}
+
+// pdfContentText interprets the text operators of a content stream and writes the
+// strings they show to sb.
+func pdfContentText(stream []byte, sb *strings.Builder) {
+	var operands []string
+	inArray := false
+	for i := 0; i < len(stream); {
+		c := stream[i]
+		switch {
+		case c == '(':
+			s, next := pdfLiteralString(stream, i)
+			operands = append(operands, s)
+			i = next
+		case c == '<' && i+1 < len(stream) && stream[i+1] != '<':
+			s, next := pdfHexString(stream, i)
+			operands = append(operands, s)
+			i = next
+		case c == '[':
+			inArray = true
+			operands = operands[:0]
+			i++
+		case c == ']':
+			inArray = false
+			i++
+		case c == '%':
+			for i < len(stream) && stream[i] != '\n' && stream[i] != '\r' {
+				i++
+			}
+		case isPDFWhitespace(c) || c == '<' || c == '>':
+			i++
+		default:
+			start := i
+			for i < len(stream) && !isPDFWhitespace(stream[i]) && !isPDFDelimiter(stream[i]) {
+				i++
+			}
+			if i == start {
+				i++
+				continue
+			}
+			token := string(stream[start:i])
+			if inArray {
+				// Large negative adjustments inside TJ arrays stand for word gaps.
+				if n, err := strconv.ParseFloat(token, 64); err == nil && n < -200 {
+					operands = append(operands, " ")
+				}
+				continue
+			}
+			if _, err := strconv.ParseFloat(token, 64); err == nil || token[0] == '/' {
+				continue
+			}
+
+			switch token {
+			case "Tj", "TJ":
+				sb.WriteString(strings.Join(operands, ""))
+			case "'", "\"":
+				sb.WriteByte('\n')
+				sb.WriteString(strings.Join(operands, ""))
+			case "Td", "TD", "Tm":
+				sb.WriteByte(' ')
+			case "T*", "ET":
+				sb.WriteByte('\n')
+			}
+			operands = operands[:0]
+		}
+	}
+}
+
+func isPDFWhitespace(c byte) bool {
+	return c == ' ' || c == '\n' || c == '\r' || c == '\t' || c == '\f' || c == 0
+}
+
+func isPDFDelimiter(c byte) bool {
+	return strings.IndexByte("()<>[]{}/%", c) >= 0
+}
+
+// pdfLiteralString decodes the (...) string starting at stream[i] and returns the index
+// just past it.
+func pdfLiteralString(stream []byte, i int) (string, int) {
+	var buf []byte
+	depth := 0
+	for i++; i < len(stream); i++ {
+		c := stream[i]
+		switch c {
+		case '\\':
+			i++
+			if i >= len(stream) {
+				break
+			}
+			switch e := stream[i]; e {
+			case 'n':
+				buf = append(buf, '\n')
+			case 'r':
+				buf = append(buf, '\r')
+			case 't':
+				buf = append(buf, '\t')
+			case 'b', 'f':
+			case '\r', '\n':
+				// A line continuation.
+			default:
+				if e >= '0' && e <= '7' {
+					n := 0
+					for j := 0; j < 3 && i < len(stream) && stream[i] >= '0' && stream[i] <= '7'; j++ {
+						n = n*8 + int(stream[i]-'0')
+						i++
+					}
+					i--
+					buf = append(buf, byte(n))
+				} else {
+					buf = append(buf, e)
+				}
+			}
+		case '(':
+			depth++
+			buf = append(buf, c)
+		case ')':
+			if depth == 0 {
+				return decodePDFText(buf), i + 1
+			}
+			depth--
+			buf = append(buf, c)
+		default:
+			buf = append(buf, c)
+		}
+	}
+	return decodePDFText(buf), i
+}
+
+// pdfHexString decodes the <...> string starting at stream[i] and returns the index
+// just past it.
+func pdfHexString(stream []byte, i int) (string, int) {
+	var buf []byte
+	var digits []byte
+	for i++; i < len(stream) && stream[i] != '>'; i++ {
+		if isHexDigit(stream[i]) {
+			digits = append(digits, stream[i])
+		}
+	}
+	if len(digits)%2 == 1 {
+		digits = append(digits, '0')
+	}
+	for j := 0; j < len(digits); j += 2 {
+		n, _ := strconv.ParseUint(string(digits[j:j+2]), 16, 8)
+		buf = append(buf, byte(n))
+	}
+	return decodePDFText(buf), i + 1
+}
+
+func isHexDigit(c byte) bool {
+	return (c >= '0' && c <= '9') || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F')
+}
+
+// decodePDFText converts a PDF string to UTF-8. Strings with a UTF-16 byte order mark
+// are decoded as such; others are read as single-byte text. Strings that are mostly
+// unprintable, as with two-byte CID font codes, are dropped.
+func decodePDFText(b []byte) string {
+	if len(b) >= 2 && b[0] == 0xFE && b[1] == 0xFF {
+		units := make([]uint16, 0, len(b)/2)
+		for j := 2; j+1 < len(b); j += 2 {
+			units = append(units, uint16(b[j])<<8|uint16(b[j+1]))
+		}
+		return string(utf16.Decode(units))
+	}
+
+	printable := 0
+	runes := make([]rune, len(b))
+	for j, c := range b {
+		runes[j] = rune(c)
+		if c >= 0x20 && c != 0x7F {
+			printable++
+		}
+	}
+	if len(b) > 0 && printable*2 < len(b) {
+		return ""
+	}
+	return string(runes)
+}
@@ -0,0 +1,52 @@
+package indexer
+
+import (
+	"github.com/bhavyajaix/BalkanID-filevault/internal/database"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// maxAttempts is how many times a failing file is tried before the indexer gives up.
+const maxAttempts = 3
+
+type Repository interface {
+	FindPending(afterID uint, limit int) ([]database.PhysicalFile, error)
+	SaveContent(content *database.FileContent) error
+}
+
+type repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) Repository {
+	return &repository{db: db}
+}
+
+// FindPending returns physical files after afterID that have never been indexed, or
+// whose extraction failed and may be retried, oldest first.
+func (r *repository) FindPending(afterID uint, limit int) ([]database.PhysicalFile, error) {
+	var files []database.PhysicalFile
+	err := r.db.
+		Joins("LEFT JOIN file_contents ON file_contents.physical_file_id = physical_files.id").
+		Where("physical_files.id > ?", afterID).
+		Where("file_contents.physical_file_id IS NULL OR (file_contents.status = ? AND file_contents.attempts < ?)",
+			database.ContentFailed, maxAttempts).
+		Order("physical_files.id ASC").
+		Limit(limit).
+		Find(&files).Error
+	return files, err
+}
+
+// SaveContent stores the outcome of an extraction, counting the attempt.
+func (r *repository) SaveContent(content *database.FileContent) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "physical_file_id"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"status":     content.Status,
+			"content":    content.Content,
+			"error":      content.Error,
+			"attempts":   gorm.Expr("file_contents.attempts + 1"),
+			"indexed_at": content.IndexedAt,
+		}),
+	}).Omit("PhysicalFile").Create(content).Error
+}
@@ -0,0 +1,112 @@
+package loaders
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const (
+	// batchWait is how long a loader collects keys before it fetches them. gqlgen
+	// resolves the fields of a list's items concurrently, so siblings arrive well
+	// within this window.
+	batchWait = 2 * time.Millisecond
+	// maxBatch caps the keys fetched by a single query.
+	maxBatch = 500
+)
+
+// FetchFunc loads the values for a batch of keys. Keys without a value are left out
+// of the map.
+type FetchFunc[K comparable, V any] func(keys []K) (map[K]V, error)
+
+// Loader collects the keys requested by concurrently running resolvers, fetches them
+// in one call and caches the results for the rest of the request.
+type Loader[K comparable, V any] struct {
+	fetch FetchFunc[K, V]
+
+	mu      sync.Mutex
+	results map[K]*result[V]
+	pending *batch[K]
+}
+
+type result[V any] struct {
+	done  chan struct{}
+	value V
+	found bool
+	err   error
+}
+
+type batch[K comparable] struct {
+	keys []K
+	full chan struct{}
+}
+
+// NewLoader creates a loader backed by fetch.
+func NewLoader[K comparable, V any](fetch FetchFunc[K, V]) *Loader[K, V] {
+	return &Loader[K, V]{fetch: fetch, results: make(map[K]*result[V])}
+}
+
+// Load returns the value for key, waiting for the batch it joins to be fetched. found
+// is false when the fetch returned no value for the key.
+func (l *Loader[K, V]) Load(ctx context.Context, key K) (value V, found bool, err error) {
+	l.mu.Lock()
+	res, ok := l.results[key]
+	if !ok {
+		res = &result[V]{done: make(chan struct{})}
+		l.results[key] = res
+		l.enqueue(key)
+	}
+	l.mu.Unlock()
+
+	select {
+	case <-res.done:
+		return res.value, res.found, res.err
+	case <-ctx.Done():
+		return value, false, ctx.Err()
+	}
+}
+
+// enqueue adds key to the pending batch, starting a new one if needed. l.mu must be held.
+func (l *Loader[K, V]) enqueue(key K) {
+	if l.pending == nil {
+		l.pending = &batch[K]{full: make(chan struct{})}
+		go l.dispatch(l.pending)
+	}
+	l.pending.keys = append(l.pending.keys, key)
+	if len(l.pending.keys) >= maxBatch {
+		close(l.pending.full)
+		l.pending = nil
+	}
+}
+
+// dispatch waits for the batch to fill up or the window to pass, then fetches it.
+func (l *Loader[K, V]) dispatch(b *batch[K]) {
+	timer := time.NewTimer(batchWait)
+	select {
+	case <-timer.C:
+	case <-b.full:
+		timer.Stop()
+	}
+
+	l.mu.Lock()
+	if l.pending == b {
+		l.pending = nil
+	}
+	l.mu.Unlock()
+
+	values, err := l.fetch(b.keys)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, key := range b.keys {
+		res := l.results[key]
+		if err != nil {
+			// Failures aren't cached, so a later resolver can try again.
+			res.err = err
+			delete(l.results, key)
+		} else {
+			res.value, res.found = values[key]
+		}
+		close(res.done)
+	}
+}
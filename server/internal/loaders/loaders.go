@@ -0,0 +1,190 @@
+package loaders
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/bhavyajaix/BalkanID-filevault/internal/database"
+	"github.com/bhavyajaix/BalkanID-filevault/internal/middleware"
+)
+
+type loadersCtxKey string
+
+const loadersContextKey = loadersCtxKey("loaders")
+
+// Grants holds the explicit grants made on a single resource.
+type Grants struct {
+	Users  []database.Permission
+	Groups []database.GroupPermission
+}
+
+// Loaders batches the association lookups made by the GraphQL field resolvers, so a
+// listing costs a fixed number of queries however many resources it returns. A new
+// set is created for every request, which keeps the caches from going stale.
+type Loaders struct {
+	Users         *Loader[uint, *database.User]
+	Parents       *Loader[uint, *database.Resource]
+	PhysicalFiles *Loader[uint, *database.PhysicalFile]
+	Tags          *Loader[uint, []*database.Tag]
+	Metadata      *Loader[uint, []*MetadataValue]
+	Grants        *Loader[uint, *Grants]
+	Breadcrumbs   *Loader[uint, []database.Resource]
+	SubtreeStats  *Loader[uint, *SubtreeStats]
+}
+
+// New creates the loaders for a request made by userID, or 0 for anonymous callers.
+func New(repo Repository, userID uint) *Loaders {
+	return &Loaders{
+		Users: NewLoader(func(ids []uint) (map[uint]*database.User, error) {
+			users, err := repo.UsersByID(ids)
+			if err != nil {
+				return nil, err
+			}
+			byID := make(map[uint]*database.User, len(users))
+			for i := range users {
+				byID[users[i].ID] = &users[i]
+			}
+			return byID, nil
+		}),
+
+		// Parents only holds the folders the caller can read, so a shared resource
+		// doesn't reveal the folder it lives in.
+		Parents: NewLoader(func(ids []uint) (map[uint]*database.Resource, error) {
+			resources, err := repo.ReadableResourcesByID(userID, ids)
+			if err != nil {
+				return nil, err
+			}
+			byID := make(map[uint]*database.Resource, len(resources))
+			for i := range resources {
+				byID[resources[i].ID] = &resources[i]
+			}
+			return byID, nil
+		}),
+
+		PhysicalFiles: NewLoader(func(ids []uint) (map[uint]*database.PhysicalFile, error) {
+			files, err := repo.PhysicalFilesByID(ids)
+			if err != nil {
+				return nil, err
+			}
+			byID := make(map[uint]*database.PhysicalFile, len(files))
+			for i := range files {
+				byID[files[i].ID] = &files[i]
+			}
+			return byID, nil
+		}),
+
+		Tags: NewLoader(func(resourceIDs []uint) (map[uint][]*database.Tag, error) {
+			tags, err := repo.TagsByResource(userID, resourceIDs)
+			if err != nil {
+				return nil, err
+			}
+			byResource := make(map[uint][]*database.Tag, len(resourceIDs))
+			for i := range tags {
+				byResource[tags[i].ResourceID] = append(byResource[tags[i].ResourceID], &tags[i].Tag)
+			}
+			return byResource, nil
+		}),
+
+		Metadata: NewLoader(func(resourceIDs []uint) (map[uint][]*MetadataValue, error) {
+			values, err := repo.MetadataByResource(userID, resourceIDs)
+			if err != nil {
+				return nil, err
+			}
+			byResource := make(map[uint][]*MetadataValue, len(resourceIDs))
+			for i := range values {
+				byResource[values[i].ResourceID] = append(byResource[values[i].ResourceID], &values[i])
+			}
+			return byResource, nil
+		}),
+
+		Grants: NewLoader(func(resourceIDs []uint) (map[uint]*Grants, error) {
+			userGrants, groupGrants, err := repo.GrantsByResource(resourceIDs)
+			if err != nil {
+				return nil, err
+			}
+			byResource := make(map[uint]*Grants, len(resourceIDs))
+			for _, id := range resourceIDs {
+				byResource[id] = &Grants{}
+			}
+			for _, grant := range userGrants {
+				byResource[grant.ResourceID].Users = append(byResource[grant.ResourceID].Users, grant)
+			}
+			for _, grant := range groupGrants {
+				byResource[grant.ResourceID].Groups = append(byResource[grant.ResourceID].Groups, grant)
+			}
+			return byResource, nil
+		}),
+
+		// Breadcrumbs holds the trail leading to each resource, outermost first and
+		// ending with the resource itself. A trail stops below the first folder the
+		// caller can't read, so a shared resource doesn't reveal the names of the
+		// owner's private folders.
+		Breadcrumbs: NewLoader(func(resourceIDs []uint) (map[uint][]database.Resource, error) {
+			ancestors, err := repo.AncestorsOf(resourceIDs)
+			if err != nil {
+				return nil, err
+			}
+			trails := make(map[uint][]database.Resource, len(resourceIDs))
+			folderIDs := make([]uint, 0, len(ancestors))
+			for _, ancestor := range ancestors {
+				trails[ancestor.DescendantID] = append(trails[ancestor.DescendantID], ancestor.Resource)
+				if ancestor.Depth > 0 {
+					folderIDs = append(folderIDs, ancestor.ID)
+				}
+			}
+			readable := make(map[uint]bool, len(folderIDs))
+			if len(folderIDs) > 0 {
+				readableIDs, err := repo.ReadableIDs(userID, folderIDs)
+				if err != nil {
+					return nil, err
+				}
+				for _, id := range readableIDs {
+					readable[id] = true
+				}
+			}
+
+			// Walk upwards from each resource's parent; the resource itself is always
+			// included.
+			for id, trail := range trails {
+				start := len(trail) - 1
+				for start > 0 && readable[trail[start-1].ID] {
+					start--
+				}
+				trails[id] = trail[start:]
+			}
+			return trails, nil
+		}),
+
+		// SubtreeStats holds the size of the tree below each folder. Folders with
+		// nothing the caller can count below them get zeroes.
+		SubtreeStats: NewLoader(func(folderIDs []uint) (map[uint]*SubtreeStats, error) {
+			stats, err := repo.SubtreeStatsByFolder(userID, folderIDs)
+			if err != nil {
+				return nil, err
+			}
+			byFolder := make(map[uint]*SubtreeStats, len(folderIDs))
+			for _, id := range folderIDs {
+				byFolder[id] = &SubtreeStats{FolderID: id}
+			}
+			for i := range stats {
+				byFolder[stats[i].FolderID] = &stats[i]
+			}
+			return byFolder, nil
+		}),
+	}
+}
+
+// Middleware attaches a fresh set of loaders to every request. It must run after
+// middleware.AuthMiddleware, so it knows who the caller is.
+func Middleware(repo Repository, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userID, _ := r.Context().Value(middleware.UserContextKey).(uint)
+		ctx := context.WithValue(r.Context(), loadersContextKey, New(repo, userID))
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// For returns the loaders attached to the request by Middleware.
+func For(ctx context.Context) *Loaders {
+	return ctx.Value(loadersContextKey).(*Loaders)
+}
@@ -0,0 +1,175 @@
+package loaders
+
+import (
+	"time"
+
+	"github.com/bhavyajaix/BalkanID-filevault/internal/database"
+	"github.com/bhavyajaix/BalkanID-filevault/internal/metadata"
+	"github.com/bhavyajaix/BalkanID-filevault/internal/permission"
+	"github.com/bhavyajaix/BalkanID-filevault/internal/tag"
+	"gorm.io/gorm"
+)
+
+// Repository fetches the associations of many resources at once, one query per call.
+type Repository interface {
+	UsersByID(ids []uint) ([]database.User, error)
+	ReadableResourcesByID(viewerID uint, ids []uint) ([]database.Resource, error)
+	ReadableIDs(viewerID uint, ids []uint) ([]uint, error)
+	AncestorsOf(resourceIDs []uint) ([]Ancestor, error)
+	SubtreeStatsByFolder(viewerID uint, folderIDs []uint) ([]SubtreeStats, error)
+	PhysicalFilesByID(ids []uint) ([]database.PhysicalFile, error)
+	TagsByResource(viewerID uint, resourceIDs []uint) ([]ResourceTag, error)
+	MetadataByResource(viewerID uint, resourceIDs []uint) ([]MetadataValue, error)
+	GrantsByResource(resourceIDs []uint) ([]database.Permission, []database.GroupPermission, error)
+}
+
+// ResourceTag is a tag together with the resource it is attached to.
+type ResourceTag struct {
+	database.Tag
+	ResourceID uint
+}
+
+// Ancestor is a folder leading to a resource, or the resource itself at depth 0.
+type Ancestor struct {
+	database.Resource
+	DescendantID uint
+	Depth        int
+}
+
+// SubtreeStats summarizes the live resources below a folder.
+type SubtreeStats struct {
+	FolderID        uint
+	DescendantCount int64
+	TotalSizeBytes  int64
+}
+
+// MetadataValue is the value of a metadata field on a resource, as text.
+type MetadataValue struct {
+	database.MetadataField
+	ResourceID uint
+	Value      string
+}
+
+type repository struct {
+	db *gorm.DB
+}
+
+// NewRepository creates a new loader repository.
+func NewRepository(db *gorm.DB) Repository {
+	return &repository{db: db}
+}
+
+func (r *repository) UsersByID(ids []uint) ([]database.User, error) {
+	var users []database.User
+	err := r.db.Where("id IN ?", ids).Find(&users).Error
+	return users, err
+}
+
+// ReadableResourcesByID fetches the live resources the viewer can read, without their
+// associations.
+func (r *repository) ReadableResourcesByID(viewerID uint, ids []uint) ([]database.Resource, error) {
+	var resources []database.Resource
+	err := r.db.Where("resources.id IN ?", ids).
+		Scopes(permission.Readable(r.db, viewerID)).
+		Find(&resources).Error
+	return resources, err
+}
+
+// ReadableIDs returns the IDs of the live resources the viewer can read.
+func (r *repository) ReadableIDs(viewerID uint, ids []uint) ([]uint, error) {
+	var readable []uint
+	err := r.db.Model(&database.Resource{}).
+		Where("resources.id IN ?", ids).
+		Scopes(permission.Readable(r.db, viewerID)).
+		Pluck("resources.id", &readable).Error
+	return readable, err
+}
+
+// AncestorsOf returns the live resources leading to each of the given resources,
+// including the resources themselves, outermost first.
+func (r *repository) AncestorsOf(resourceIDs []uint) ([]Ancestor, error) {
+	var ancestors []Ancestor
+	err := r.db.Model(&database.Resource{}).
+		Select("resources.id, resources.name, resources.owner_id, resources.parent_id, resources.type, ra.descendant_id, ra.depth").
+		Joins("JOIN resource_ancestors ra ON ra.ancestor_id = resources.id").
+		Where("ra.descendant_id IN ?", resourceIDs).
+		Order("ra.descendant_id ASC, ra.depth DESC").
+		Scan(&ancestors).Error
+	return ancestors, err
+}
+
+// SubtreeStatsByFolder counts the live resources below each folder and sums the size of
+// their files, in one aggregate over the closure table. The owner of a folder counts
+// everything below it; anyone else only the resources they can read. Folders with
+// nothing to count are left out.
+func (r *repository) SubtreeStatsByFolder(viewerID uint, folderIDs []uint) ([]SubtreeStats, error) {
+	var stats []SubtreeStats
+	err := r.db.Model(&database.ResourceAncestor{}).
+		Select("resource_ancestors.ancestor_id AS folder_id, COUNT(*) AS descendant_count, COALESCE(SUM(physical_files.size_bytes), 0) AS total_size_bytes").
+		Joins("JOIN resources folder ON folder.id = resource_ancestors.ancestor_id").
+		Joins("JOIN resources ON resources.id = resource_ancestors.descendant_id AND resources.deleted_at IS NULL").
+		Joins("LEFT JOIN physical_files ON physical_files.id = resources.physical_file_id").
+		Where("resource_ancestors.ancestor_id IN ? AND resource_ancestors.depth > 0", folderIDs).
+		Where("(folder.owner_id = ? OR ?)", viewerID, permission.ReadableExpr(r.db, viewerID)).
+		Group("resource_ancestors.ancestor_id").
+		Scan(&stats).Error
+	return stats, err
+}
+
+func (r *repository) PhysicalFilesByID(ids []uint) ([]database.PhysicalFile, error) {
+	var files []database.PhysicalFile
+	err := r.db.Where("id IN ?", ids).Find(&files).Error
+	return files, err
+}
+
+// TagsByResource returns the tags the viewer can see on the given resources, ordered by
+// name. Tags from other users' vocabularies are left out.
+func (r *repository) TagsByResource(viewerID uint, resourceIDs []uint) ([]ResourceTag, error) {
+	var tags []ResourceTag
+	err := r.db.Table("tags").
+		Select("tags.*, resource_tags.resource_id").
+		Joins("JOIN resource_tags ON resource_tags.tag_id = tags.id").
+		Where("resource_tags.resource_id IN ? AND tags.deleted_at IS NULL", resourceIDs).
+		Scopes(tag.VisibleTo(viewerID)).
+		Order("lower(tags.name) ASC").
+		Scan(&tags).Error
+	return tags, err
+}
+
+// MetadataByResource returns the metadata values on the given resources whose fields
+// the viewer can see, ordered like the viewer's list of fields.
+func (r *repository) MetadataByResource(viewerID uint, resourceIDs []uint) ([]MetadataValue, error) {
+	var values []MetadataValue
+	err := r.db.Table("resources").
+		Select("metadata_fields.*, resources.id AS resource_id, entries.value #>> '{}' AS value").
+		Joins("CROSS JOIN LATERAL jsonb_each(resources.metadata) AS entries").
+		Joins("JOIN metadata_fields ON metadata_fields.id::text = entries.key").
+		Where("resources.id IN ? AND metadata_fields.deleted_at IS NULL", resourceIDs).
+		Scopes(metadata.VisibleTo(viewerID)).
+		Order("metadata_fields.group_id ASC NULLS FIRST, lower(metadata_fields.name) ASC").
+		Scan(&values).Error
+	return values, err
+}
+
+// GrantsByResource returns the explicit, unexpired user and group grants made on the
+// given resources, oldest first.
+func (r *repository) GrantsByResource(resourceIDs []uint) ([]database.Permission, []database.GroupPermission, error) {
+	now := time.Now()
+
+	var userGrants []database.Permission
+	if err := r.db.Preload("User").
+		Where("resource_id IN ? AND (expires_at IS NULL OR expires_at > ?)", resourceIDs, now).
+		Order("created_at asc").
+		Find(&userGrants).Error; err != nil {
+		return nil, nil, err
+	}
+
+	var groupGrants []database.GroupPermission
+	if err := r.db.Preload("Group").
+		Where("resource_id IN ? AND (expires_at IS NULL OR expires_at > ?)", resourceIDs, now).
+		Order("created_at asc").
+		Find(&groupGrants).Error; err != nil {
+		return nil, nil, err
+	}
+	return userGrants, groupGrants, nil
+}
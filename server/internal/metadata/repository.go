@@ -0,0 +1,116 @@
+package metadata
+
+import (
+	"github.com/bhavyajaix/BalkanID-filevault/internal/database"
+	"gorm.io/gorm"
+)
+
+// Repository is the interface for metadata fields and the values stored on resources.
+type Repository interface {
+	CreateField(field *database.MetadataField) error
+	FindField(id uint) (*database.MetadataField, error)
+	ListVisibleFields(userID uint) ([]database.MetadataField, error)
+	FieldsInNamespace(ownerID *uint, groupID *uint) ([]database.MetadataField, error)
+	DeleteField(field *database.MetadataField) error
+	FindResource(id uint) (*database.Resource, error)
+	UpdateValues(resourceID uint, update func(metadata string) (string, error)) error
+}
+
+type repository struct {
+	db *gorm.DB
+}
+
+// NewRepository creates a new metadata repository.
+func NewRepository(db *gorm.DB) Repository {
+	return &repository{db: db}
+}
+
+// VisibleTo scopes a metadata field query to the namespaces a user can see: their own
+// fields and the fields of the groups they belong to.
+func VisibleTo(userID uint) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("(metadata_fields.owner_id = ? OR metadata_fields.group_id IN (SELECT group_id FROM group_members WHERE user_id = ?))", userID, userID)
+	}
+}
+
+func (r *repository) CreateField(field *database.MetadataField) error {
+	return r.db.Omit("Group").Create(field).Error
+}
+
+func (r *repository) FindField(id uint) (*database.MetadataField, error) {
+	var field database.MetadataField
+	if err := r.db.First(&field, id).Error; err != nil {
+		return nil, err
+	}
+	return &field, nil
+}
+
+// ListVisibleFields returns the user's own fields, then those of their groups, each by
+// name.
+func (r *repository) ListVisibleFields(userID uint) ([]database.MetadataField, error) {
+	var fields []database.MetadataField
+	err := r.db.Model(&database.MetadataField{}).
+		Scopes(VisibleTo(userID)).
+		Order("metadata_fields.group_id ASC NULLS FIRST, lower(metadata_fields.name) ASC").
+		Find(&fields).Error
+	return fields, err
+}
+
+// FieldsInNamespace returns the fields of a user's namespace, or of a group's when
+// groupID is set.
+func (r *repository) FieldsInNamespace(ownerID *uint, groupID *uint) ([]database.MetadataField, error) {
+	var fields []database.MetadataField
+	query := r.db.Model(&database.MetadataField{})
+	if groupID != nil {
+		query = query.Where("group_id = ?", *groupID)
+	} else {
+		query = query.Where("owner_id = ?", *ownerID)
+	}
+	err := query.Order("lower(name) ASC").Find(&fields).Error
+	return fields, err
+}
+
+// DeleteField removes a field for good, along with its values on every resource.
+func (r *repository) DeleteField(field *database.MetadataField) error {
+	// GORM takes every ? for a placeholder until it runs out of values, so the key is
+	// bound once, up front, and the ? operator after it is left alone. Written as an
+	// uncorrelated subquery, the key is still a constant the key index can look up.
+	const removeKey = `WITH field AS (SELECT CAST(? AS text) AS key)
+UPDATE resources SET metadata = metadata - (SELECT key FROM field)
+WHERE deleted_at IS NULL AND metadata ? (SELECT key FROM field)`
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec(removeKey, Key(field.ID)).Error; err != nil {
+			return err
+		}
+		return tx.Unscoped().Delete(field).Error
+	})
+}
+
+func (r *repository) FindResource(id uint) (*database.Resource, error) {
+	var resource database.Resource
+	if err := r.db.Preload("PhysicalFile").Preload("User").First(&resource, id).Error; err != nil {
+		return nil, err
+	}
+	return &resource, nil
+}
+
+// UpdateValues replaces the metadata of a resource with what update makes of it. The
+// row is locked in between, so concurrent updates of different fields don't undo each
+// other. Resource.Metadata is read-only to GORM, so this is the only way it is written.
+func (r *repository) UpdateValues(resourceID uint, update func(metadata string) (string, error)) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var metadata string
+		result := tx.Raw("SELECT metadata FROM resources WHERE id = ? AND deleted_at IS NULL FOR UPDATE", resourceID).Scan(&metadata)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		updated, err := update(metadata)
+		if err != nil {
+			return err
+		}
+		return tx.Exec("UPDATE resources SET metadata = CAST(? AS jsonb), updated_at = now() WHERE id = ?", updated, resourceID).Error
+	})
+}
@@ -0,0 +1,356 @@
+package metadata
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/bhavyajaix/BalkanID-filevault/internal/database"
+	"github.com/bhavyajaix/BalkanID-filevault/internal/group"
+	"github.com/bhavyajaix/BalkanID-filevault/internal/middleware"
+	"gorm.io/gorm"
+)
+
+const (
+	maxNameLength   = 100
+	maxOptionLength = 100
+	maxOptions      = 100
+)
+
+// ErrFieldNameTaken is returned when the namespace already has a field with the name.
+var ErrFieldNameTaken = errors.New("a metadata field with this name already exists")
+
+// errFieldNotFound is also returned for fields in namespaces the caller can't see, so
+// other users' schemas can't be probed.
+var errFieldNotFound = errors.New("metadata field not found")
+
+var errGroupNotFound = errors.New("group not found")
+
+// WriteChecker decides whether a user may change a resource. It is satisfied by
+// folders.Service.
+type WriteChecker interface {
+	CanWrite(userID uint, resource *database.Resource) (bool, error)
+}
+
+// FieldInput defines a new metadata field, in the caller's namespace or in the group's
+// when GroupID is set. Options are the allowed values of an ENUM field.
+type FieldInput struct {
+	Name     string
+	Type     database.MetadataFieldType
+	Required bool
+	Options  []string
+	GroupID  *uint
+}
+
+// ValueInput sets the value of a field on a resource, or clears it when Value is nil.
+type ValueInput struct {
+	FieldID uint
+	Value   *string
+}
+
+// Service manages metadata schemas and the values stored on resources. Like tags,
+// every field belongs to a user or to a group; group fields can be filled in by all
+// members but only defined and deleted by the group's admins.
+type Service interface {
+	CreateField(ctx context.Context, input FieldInput) (*database.MetadataField, error)
+	DeleteField(ctx context.Context, id uint) error
+	ListFields(ctx context.Context) ([]database.MetadataField, error)
+	SetMetadata(ctx context.Context, resourceID uint, values []ValueInput) (*database.Resource, error)
+}
+
+type service struct {
+	repo   Repository
+	groups group.Repository
+	access WriteChecker
+}
+
+// NewService creates a new metadata service.
+func NewService(repo Repository, groups group.Repository, access WriteChecker) Service {
+	return &service{repo: repo, groups: groups, access: access}
+}
+
+func getUserIDFromContext(ctx context.Context) (uint, error) {
+	userID, ok := ctx.Value(middleware.UserContextKey).(uint)
+	if !ok {
+		return 0, errors.New("unauthorized")
+	}
+	return userID, nil
+}
+
+// CreateField adds a field to the caller's schema, or to the group's when GroupID is set.
+func (s *service) CreateField(ctx context.Context, input FieldInput) (*database.MetadataField, error) {
+	userID, err := getUserIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	name := strings.TrimSpace(input.Name)
+	switch {
+	case name == "":
+		return nil, errors.New("field name cannot be empty")
+	case utf8.RuneCountInString(name) > maxNameLength:
+		return nil, fmt.Errorf("field name cannot be longer than %d characters", maxNameLength)
+	}
+	options, err := normalizeOptions(input.Type, input.Options)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkNamespace(userID, input.GroupID, true); err != nil {
+		return nil, err
+	}
+
+	encoded, err := json.Marshal(options)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode options: %w", err)
+	}
+	field := &database.MetadataField{
+		Name:     name,
+		Type:     input.Type,
+		Required: input.Required,
+		Options:  string(encoded),
+	}
+	if input.GroupID != nil {
+		field.GroupID = input.GroupID
+	} else {
+		field.OwnerID = &userID
+	}
+	if err := s.repo.CreateField(field); err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return nil, ErrFieldNameTaken
+		}
+		return nil, fmt.Errorf("failed to create metadata field: %w", err)
+	}
+	return field, nil
+}
+
+// DeleteField removes a field from its schema, with its values on every resource.
+func (s *service) DeleteField(ctx context.Context, id uint) error {
+	userID, err := getUserIDFromContext(ctx)
+	if err != nil {
+		return err
+	}
+	field, err := s.visibleField(userID, id, true)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.DeleteField(field); err != nil {
+		return fmt.Errorf("failed to delete metadata field: %w", err)
+	}
+	return nil
+}
+
+// ListFields returns the fields of the caller's schema and of their groups' schemas.
+func (s *service) ListFields(ctx context.Context) ([]database.MetadataField, error) {
+	userID, err := getUserIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.ListVisibleFields(userID)
+}
+
+// SetMetadata sets or clears field values on a resource the caller can change. Once a
+// resource has a value from a schema, the schema's required fields must be filled in
+// too.
+func (s *service) SetMetadata(ctx context.Context, resourceID uint, inputs []ValueInput) (*database.Resource, error) {
+	userID, err := getUserIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	resource, err := s.repo.FindResource(resourceID)
+	if err != nil {
+		return nil, errors.New("resource not found")
+	}
+	canWrite, err := s.access.CanWrite(userID, resource)
+	if err != nil {
+		return nil, fmt.Errorf("error checking permissions: %w", err)
+	}
+	if !canWrite {
+		return nil, errors.New("access denied")
+	}
+
+	// Check the fields and parse the values before taking the lock.
+	type change struct {
+		key   string
+		value json.RawMessage // nil removes the value
+	}
+	changes := make([]change, 0, len(inputs))
+	seen := make(map[uint]bool, len(inputs))
+	namespaces := make(map[string]*database.MetadataField)
+	for _, input := range inputs {
+		if seen[input.FieldID] {
+			return nil, fmt.Errorf("metadata field %d is given more than once", input.FieldID)
+		}
+		seen[input.FieldID] = true
+
+		field, err := s.visibleField(userID, input.FieldID, false)
+		if err != nil {
+			return nil, err
+		}
+		namespaces[namespaceKey(field)] = field
+
+		if input.Value == nil {
+			changes = append(changes, change{key: Key(field.ID)})
+			continue
+		}
+		value, err := ParseValue(field, *input.Value)
+		if err != nil {
+			return nil, err
+		}
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode metadata: %w", err)
+		}
+		changes = append(changes, change{key: Key(field.ID), value: encoded})
+	}
+
+	// Apply them to the metadata as it is now, not as it was when the resource was
+	// loaded, so values set meanwhile by someone else are kept.
+	err = s.repo.UpdateValues(resource.ID, func(metadata string) (string, error) {
+		current := *resource
+		current.Metadata = metadata
+		values, err := Values(&current)
+		if err != nil {
+			return "", err
+		}
+		for _, c := range changes {
+			if c.value == nil {
+				delete(values, c.key)
+			} else {
+				values[c.key] = c.value
+			}
+		}
+		for _, field := range namespaces {
+			if err := s.checkRequired(field, values); err != nil {
+				return "", err
+			}
+		}
+		encoded, err := json.Marshal(values)
+		if err != nil {
+			return "", fmt.Errorf("failed to encode metadata: %w", err)
+		}
+		return string(encoded), nil
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errors.New("resource not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.FindResource(resource.ID)
+}
+
+// checkRequired makes sure that if the resource has any value from the schema the
+// field belongs to, it has values for all of the schema's required fields.
+func (s *service) checkRequired(field *database.MetadataField, values map[string]json.RawMessage) error {
+	fields, err := s.repo.FieldsInNamespace(field.OwnerID, field.GroupID)
+	if err != nil {
+		return fmt.Errorf("failed to load metadata schema: %w", err)
+	}
+	used := false
+	var missing []string
+	for _, f := range fields {
+		if _, ok := values[Key(f.ID)]; ok {
+			used = true
+		} else if f.Required {
+			missing = append(missing, f.Name)
+		}
+	}
+	if used && len(missing) > 0 {
+		return fmt.Errorf("missing required metadata: %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// visibleField returns a field from one of the caller's namespaces. Managing a group's
+// schema also requires being one of its admins.
+func (s *service) visibleField(userID uint, id uint, manage bool) (*database.MetadataField, error) {
+	field, err := s.repo.FindField(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errFieldNotFound
+		}
+		return nil, err
+	}
+	if field.GroupID == nil {
+		if field.OwnerID == nil || *field.OwnerID != userID {
+			return nil, errFieldNotFound
+		}
+		return field, nil
+	}
+	if err := s.checkNamespace(userID, field.GroupID, manage); err != nil {
+		if errors.Is(err, errGroupNotFound) {
+			return nil, errFieldNotFound
+		}
+		return nil, err
+	}
+	return field, nil
+}
+
+// checkNamespace verifies the caller may use the group's schema, or manage it. The
+// caller's own schema is always theirs.
+func (s *service) checkNamespace(userID uint, groupID *uint, manage bool) error {
+	if groupID == nil {
+		return nil
+	}
+
+	member, err := s.groups.FindMember(*groupID, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errGroupNotFound
+		}
+		return err
+	}
+	if manage && !member.IsAdmin {
+		return errors.New("access denied: only group admins can manage the group's metadata fields")
+	}
+	return nil
+}
+
+func namespaceKey(field *database.MetadataField) string {
+	if field.GroupID != nil {
+		return "group:" + Key(*field.GroupID)
+	}
+	return "user:" + Key(*field.OwnerID)
+}
+
+// normalizeOptions checks the options of a field of the given type: ENUM fields need
+// distinct, non-empty options and other types take none.
+func normalizeOptions(fieldType database.MetadataFieldType, options []string) ([]string, error) {
+	switch fieldType {
+	case database.MetadataString, database.MetadataNumber, database.MetadataDate:
+		if len(options) > 0 {
+			return nil, errors.New("only ENUM fields take options")
+		}
+		return []string{}, nil
+	case database.MetadataEnum:
+	default:
+		return nil, fmt.Errorf("invalid field type %q", fieldType)
+	}
+
+	if len(options) == 0 {
+		return nil, errors.New("ENUM fields need at least one option")
+	}
+	if len(options) > maxOptions {
+		return nil, fmt.Errorf("ENUM fields cannot have more than %d options", maxOptions)
+	}
+	seen := make(map[string]bool, len(options))
+	normalized := make([]string, 0, len(options))
+	for _, option := range options {
+		option = strings.TrimSpace(option)
+		switch {
+		case option == "":
+			return nil, errors.New("options cannot be empty")
+		case utf8.RuneCountInString(option) > maxOptionLength:
+			return nil, fmt.Errorf("options cannot be longer than %d characters", maxOptionLength)
+		case seen[strings.ToLower(option)]:
+			return nil, fmt.Errorf("option %q is given more than once", option)
+		}
+		seen[strings.ToLower(option)] = true
+		normalized = append(normalized, option)
+	}
+	return normalized, nil
+}
@@ -0,0 +1,104 @@
+package metadata
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/bhavyajaix/BalkanID-filevault/internal/database"
+)
+
+// maxStringLength caps STRING values.
+const maxStringLength = 1000
+
+// DateLayout is how DATE values are written and stored. Stored dates compare correctly
+// as strings.
+const DateLayout = "2006-01-02"
+
+// ValueError reports a value that doesn't fit its field.
+type ValueError struct {
+	Field  string
+	Value  string
+	Reason string
+}
+
+func (e *ValueError) Error() string {
+	return fmt.Sprintf("invalid value %q for %s: %s", e.Value, e.Field, e.Reason)
+}
+
+// ParseValue checks a value written by a user against a field and returns it in its
+// stored form: a float64 for numbers, a YYYY-MM-DD string for dates and the trimmed
+// string otherwise. Enum values take the case of the matching option.
+func ParseValue(field *database.MetadataField, raw string) (interface{}, error) {
+	value := strings.TrimSpace(raw)
+	invalid := func(reason string) error {
+		return &ValueError{Field: field.Name, Value: raw, Reason: reason}
+	}
+
+	switch field.Type {
+	case database.MetadataNumber:
+		n, err := strconv.ParseFloat(value, 64)
+		if err != nil || math.IsInf(n, 0) || math.IsNaN(n) {
+			return nil, invalid("expected a number")
+		}
+		return n, nil
+	case database.MetadataDate:
+		date, err := time.Parse(DateLayout, value)
+		if err != nil {
+			return nil, invalid("expected a YYYY-MM-DD date")
+		}
+		return date.Format(DateLayout), nil
+	case database.MetadataEnum:
+		options, err := Options(field)
+		if err != nil {
+			return nil, err
+		}
+		for _, option := range options {
+			if strings.EqualFold(option, value) {
+				return option, nil
+			}
+		}
+		return nil, invalid("expected one of " + strings.Join(options, ", "))
+	default:
+		if value == "" {
+			return nil, invalid("cannot be empty")
+		}
+		if utf8.RuneCountInString(value) > maxStringLength {
+			return nil, invalid(fmt.Sprintf("cannot be longer than %d characters", maxStringLength))
+		}
+		return value, nil
+	}
+}
+
+// Options returns the values an ENUM field allows.
+func Options(field *database.MetadataField) ([]string, error) {
+	var options []string
+	if field.Options == "" {
+		return options, nil
+	}
+	if err := json.Unmarshal([]byte(field.Options), &options); err != nil {
+		return nil, fmt.Errorf("failed to decode options of field %d: %w", field.ID, err)
+	}
+	return options, nil
+}
+
+// Values decodes the metadata stored on a resource, keyed by field ID.
+func Values(resource *database.Resource) (map[string]json.RawMessage, error) {
+	values := map[string]json.RawMessage{}
+	if resource.Metadata == "" {
+		return values, nil
+	}
+	if err := json.Unmarshal([]byte(resource.Metadata), &values); err != nil {
+		return nil, fmt.Errorf("failed to decode metadata of resource %d: %w", resource.ID, err)
+	}
+	return values, nil
+}
+
+// Key is the key of a field's value in a resource's metadata.
+func Key(fieldID uint) string {
+	return strconv.FormatUint(uint64(fieldID), 10)
+}
@@ -0,0 +1,60 @@
+package metadata
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/bhavyajaix/BalkanID-filevault/internal/database"
+)
+
+func TestParseValue(t *testing.T) {
+	str := &database.MetadataField{Name: "Client", Type: database.MetadataString}
+	number := &database.MetadataField{Name: "Amount", Type: database.MetadataNumber}
+	date := &database.MetadataField{Name: "Due", Type: database.MetadataDate}
+	enum := &database.MetadataField{Name: "Status", Type: database.MetadataEnum, Options: `["Draft","Final"]`}
+
+	tests := []struct {
+		name    string
+		field   *database.MetadataField
+		raw     string
+		want    interface{}
+		wantErr bool
+	}{
+		{name: "string is trimmed", field: str, raw: "  Acme  ", want: "Acme"},
+		{name: "empty string", field: str, raw: "   ", wantErr: true},
+		{name: "longest string", field: str, raw: strings.Repeat("é", maxStringLength), want: strings.Repeat("é", maxStringLength)},
+		{name: "too long string", field: str, raw: strings.Repeat("a", maxStringLength+1), wantErr: true},
+		{name: "integer", field: number, raw: "42", want: 42.0},
+		{name: "decimal", field: number, raw: " -1.5 ", want: -1.5},
+		{name: "exponent", field: number, raw: "1e3", want: 1000.0},
+		{name: "not a number", field: number, raw: "forty", wantErr: true},
+		{name: "NaN", field: number, raw: "NaN", wantErr: true},
+		{name: "infinity", field: number, raw: "+Inf", wantErr: true},
+		{name: "overflow", field: number, raw: "1e400", wantErr: true},
+		{name: "date", field: date, raw: "2025-03-01", want: "2025-03-01"},
+		{name: "timestamp", field: date, raw: "2025-03-01T10:00:00Z", wantErr: true},
+		{name: "impossible date", field: date, raw: "2025-02-30", wantErr: true},
+		{name: "enum takes the option's case", field: enum, raw: "final", want: "Final"},
+		{name: "unknown option", field: enum, raw: "Archived", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseValue(tt.field, tt.raw)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("ParseValue(%q) = %v, want an error", tt.raw, got)
+				}
+				if _, ok := err.(*ValueError); !ok {
+					t.Errorf("ParseValue(%q) returned %T, want a *ValueError", tt.raw, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseValue(%q) failed: %v", tt.raw, err)
+			}
+			if got != tt.want {
+				t.Errorf("ParseValue(%q) = %#v, want %#v", tt.raw, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,110 @@
+package ownership
+
+import (
+	"github.com/bhavyajaix/BalkanID-filevault/internal/database"
+	"gorm.io/gorm"
+)
+
+// Repository defines the database operations for ownership transfers.
+type Repository interface {
+	Create(transfer *database.OwnershipTransfer) error
+	Update(db *gorm.DB, transfer *database.OwnershipTransfer) error
+	GetByID(id uint) (*database.OwnershipTransfer, error)
+	FindPendingForResource(resourceID uint) (*database.OwnershipTransfer, error)
+	ListPendingForUser(userID uint) ([]database.OwnershipTransfer, error)
+
+	SubtreeUsage(db *gorm.DB, rootID, ownerID uint) (int64, error)
+	ReassignSubtree(db *gorm.DB, rootID, fromUserID, toUserID uint) error
+	MoveToOwnerRoot(db *gorm.DB, resourceID, ownerID uint, name string) error
+	DeleteUserGrantsInSubtree(db *gorm.DB, rootID, userID uint) error
+}
+
+type repository struct {
+	db *gorm.DB
+}
+
+// NewRepository creates a new ownership transfer repository.
+func NewRepository(db *gorm.DB) Repository {
+	return &repository{db: db}
+}
+
+// withAssociations preloads everything needed to present a transfer to either party.
+func withAssociations(db *gorm.DB) *gorm.DB {
+	return db.Preload("Resource.User").Preload("Resource.PhysicalFile").Preload("FromUser").Preload("ToUser")
+}
+
+// subtree selects the IDs of a resource and all of its descendants from the closure table.
+func subtree(db *gorm.DB, rootID uint) *gorm.DB {
+	return db.Model(&database.ResourceAncestor{}).Select("descendant_id").Where("ancestor_id = ?", rootID)
+}
+
+func (r *repository) Create(transfer *database.OwnershipTransfer) error {
+	return r.db.Create(transfer).Error
+}
+
+func (r *repository) Update(db *gorm.DB, transfer *database.OwnershipTransfer) error {
+	return db.Omit("Resource", "FromUser", "ToUser").Save(transfer).Error
+}
+
+func (r *repository) GetByID(id uint) (*database.OwnershipTransfer, error) {
+	var transfer database.OwnershipTransfer
+	if err := r.db.Scopes(withAssociations).First(&transfer, id).Error; err != nil {
+		return nil, err
+	}
+	return &transfer, nil
+}
+
+func (r *repository) FindPendingForResource(resourceID uint) (*database.OwnershipTransfer, error) {
+	var transfer database.OwnershipTransfer
+	err := r.db.Where("resource_id = ? AND status = ?", resourceID, database.TransferPending).First(&transfer).Error
+	if err != nil {
+		return nil, err
+	}
+	return &transfer, nil
+}
+
+// ListPendingForUser returns the open transfers the user has offered or been offered.
+func (r *repository) ListPendingForUser(userID uint) ([]database.OwnershipTransfer, error) {
+	var transfers []database.OwnershipTransfer
+	err := r.db.Scopes(withAssociations).
+		Where("status = ? AND (from_user_id = ? OR to_user_id = ?)", database.TransferPending, userID, userID).
+		Order("created_at asc").
+		Find(&transfers).Error
+	return transfers, err
+}
+
+// SubtreeUsage sums the logical size of the files in a subtree that belong to the owner.
+func (r *repository) SubtreeUsage(db *gorm.DB, rootID, ownerID uint) (int64, error) {
+	var total int64
+	err := db.Model(&database.Resource{}).
+		Joins("JOIN physical_files ON physical_files.id = resources.physical_file_id").
+		Where("resources.id IN (?) AND resources.owner_id = ?", subtree(db, rootID), ownerID).
+		Select("COALESCE(SUM(physical_files.size_bytes), 0)").
+		Scan(&total).Error
+	return total, err
+}
+
+// ReassignSubtree hands every resource in the subtree owned by fromUserID to toUserID.
+// Resources other users created inside the subtree keep their owner.
+func (r *repository) ReassignSubtree(db *gorm.DB, rootID, fromUserID, toUserID uint) error {
+	return db.Model(&database.Resource{}).
+		Where("id IN (?) AND owner_id = ?", subtree(db, rootID), fromUserID).
+		UpdateColumn("owner_id", toUserID).Error
+}
+
+// MoveToOwnerRoot hands a resource to ownerID and moves it to their root under name, in
+// one update so it never sits in a namespace it hasn't been checked against. The update
+// trigger keeps resource_ancestors in sync.
+func (r *repository) MoveToOwnerRoot(db *gorm.DB, resourceID, ownerID uint, name string) error {
+	return db.Model(&database.Resource{}).Where("id = ?", resourceID).Updates(map[string]interface{}{
+		"owner_id":  ownerID,
+		"parent_id": nil,
+		"name":      name,
+	}).Error
+}
+
+// DeleteUserGrantsInSubtree removes every explicit grant the user holds inside the subtree.
+func (r *repository) DeleteUserGrantsInSubtree(db *gorm.DB, rootID, userID uint) error {
+	return db.Where("resource_id IN (?) AND user_id = ?", subtree(db, rootID), userID).
+		Delete(&database.Permission{}).Error
+}
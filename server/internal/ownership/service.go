@@ -0,0 +1,267 @@
+package ownership
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/bhavyajaix/BalkanID-filevault/internal/audit"
+	"github.com/bhavyajaix/BalkanID-filevault/internal/database"
+	"github.com/bhavyajaix/BalkanID-filevault/internal/folders"
+	"github.com/bhavyajaix/BalkanID-filevault/internal/middleware"
+	"github.com/bhavyajaix/BalkanID-filevault/internal/permission"
+	"github.com/bhavyajaix/BalkanID-filevault/internal/user"
+	"gorm.io/gorm"
+)
+
+func getUserIDFromContext(ctx context.Context) (uint, error) {
+	userID, ok := ctx.Value(middleware.UserContextKey).(uint)
+	if !ok {
+		return 0, errors.New("unauthorized")
+	}
+	return userID, nil
+}
+
+// Service defines the business logic for handing resources over to another user.
+type Service interface {
+	TransferOwnership(ctx context.Context, resourceID uint, newOwnerEmail string, keepEditorAccess bool) (*database.OwnershipTransfer, error)
+	AcceptTransfer(ctx context.Context, transferID uint) (*database.OwnershipTransfer, error)
+	DeclineTransfer(ctx context.Context, transferID uint) (*database.OwnershipTransfer, error)
+	CancelTransfer(ctx context.Context, transferID uint) (*database.OwnershipTransfer, error)
+	ListPending(ctx context.Context) ([]database.OwnershipTransfer, error)
+}
+
+type service struct {
+	repo         Repository
+	resourceRepo folders.Repository
+	userRepo     user.Repository
+	permRepo     permission.Repository
+	auditRepo    audit.Repository
+	db           *gorm.DB
+}
+
+// NewService creates a new ownership transfer service.
+func NewService(repo Repository, resourceRepo folders.Repository, userRepo user.Repository, permRepo permission.Repository, auditRepo audit.Repository, db *gorm.DB) Service {
+	return &service{
+		repo:         repo,
+		resourceRepo: resourceRepo,
+		userRepo:     userRepo,
+		permRepo:     permRepo,
+		auditRepo:    auditRepo,
+		db:           db,
+	}
+}
+
+// TransferOwnership offers a resource to another user. Ownership only changes once
+// the recipient accepts.
+func (s *service) TransferOwnership(ctx context.Context, resourceID uint, newOwnerEmail string, keepEditorAccess bool) (*database.OwnershipTransfer, error) {
+	userID, err := getUserIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	resource, err := s.resourceRepo.GetByID(resourceID)
+	if err != nil {
+		return nil, errors.New("resource not found")
+	}
+	if resource.OwnerID != userID {
+		return nil, errors.New("access denied: only the owner can transfer ownership")
+	}
+
+	recipient, err := s.userRepo.GetUserByEmail(newOwnerEmail)
+	if err != nil {
+		return nil, errors.New("user with the specified email not found")
+	}
+	if recipient.ID == userID {
+		return nil, errors.New("cannot transfer a resource to yourself")
+	}
+
+	if _, err := s.repo.FindPendingForResource(resourceID); err == nil {
+		return nil, errors.New("a transfer is already pending for this resource")
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	transfer := &database.OwnershipTransfer{
+		ResourceID:       resourceID,
+		FromUserID:       userID,
+		ToUserID:         recipient.ID,
+		KeepEditorAccess: keepEditorAccess,
+		Status:           database.TransferPending,
+	}
+	if err := s.repo.Create(transfer); err != nil {
+		return nil, fmt.Errorf("failed to create ownership transfer: %w", err)
+	}
+	return s.repo.GetByID(transfer.ID)
+}
+
+// AcceptTransfer hands the resource and every descendant the sender owns to the
+// recipient, moving their logical storage usage along with them.
+func (s *service) AcceptTransfer(ctx context.Context, transferID uint) (*database.OwnershipTransfer, error) {
+	userID, err := getUserIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	transfer, err := s.getPending(transferID)
+	if err != nil {
+		return nil, err
+	}
+	if transfer.ToUserID != userID {
+		return nil, errors.New("ownership transfer not found")
+	}
+	if transfer.Resource.OwnerID != transfer.FromUserID {
+		return nil, errors.New("the resource is no longer owned by the sender")
+	}
+
+	tx := s.db.Begin()
+	if tx.Error != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", tx.Error)
+	}
+	defer tx.Rollback()
+
+	rootID := transfer.ResourceID
+
+	// 1. Check the recipient has room for the subtree. The row lock keeps a concurrent
+	// upload from slipping past the check.
+	recipient, err := s.userRepo.GetUserForUpdate(tx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("could not load recipient: %w", err)
+	}
+	usage, err := s.repo.SubtreeUsage(tx, rootID, transfer.FromUserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute storage usage: %w", err)
+	}
+	if !user.HasQuotaFor(recipient, usage) {
+		return nil, errors.New("not enough storage quota to accept this transfer")
+	}
+
+	// 2. The old parent folder still belongs to the sender, so the resource moves to the
+	// recipient's root, renamed if they already have something by that name there.
+	names := folders.NewService(s.resourceRepo.WithDB(tx), s.permRepo.WithDB(tx))
+	name, _, err := names.ResolveNameConflict(userID, nil, transfer.Resource.Name, transfer.Resource.Type, folders.ConflictRename, rootID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.MoveToOwnerRoot(tx, rootID, userID, name); err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return nil, folders.ErrNameTaken
+		}
+		return nil, fmt.Errorf("failed to move resource to the recipient's root: %w", err)
+	}
+
+	// 3. Reassign the rest of the subtree and move the logical storage usage with it.
+	if err := s.repo.ReassignSubtree(tx, rootID, transfer.FromUserID, userID); err != nil {
+		return nil, fmt.Errorf("failed to reassign resources: %w", err)
+	}
+	if err := s.userRepo.DecrementStorageUsed(tx, transfer.FromUserID, usage); err != nil {
+		return nil, fmt.Errorf("failed to update sender storage: %w", err)
+	}
+	if err := s.userRepo.IncrementStorageUsed(tx, userID, usage); err != nil {
+		return nil, fmt.Errorf("failed to update recipient storage: %w", err)
+	}
+
+	// 4. The previous owner keeps editing rights unless the transfer said otherwise.
+	if transfer.KeepEditorAccess {
+		grant := &database.Permission{
+			ResourceID: rootID,
+			UserID:     transfer.FromUserID,
+			Role:       database.Editor,
+			CreatedAt:  time.Now(),
+		}
+		if err := s.permRepo.WithDB(tx).CreateOrUpdate(grant); err != nil {
+			return nil, fmt.Errorf("failed to grant the previous owner access: %w", err)
+		}
+	} else {
+		if err := s.repo.DeleteUserGrantsInSubtree(tx, rootID, transfer.FromUserID); err != nil {
+			return nil, fmt.Errorf("failed to remove the previous owner's access: %w", err)
+		}
+	}
+
+	now := time.Now()
+	transfer.Status = database.TransferAccepted
+	transfer.DecidedAt = &now
+	if err := s.repo.Update(tx, transfer); err != nil {
+		return nil, fmt.Errorf("failed to update ownership transfer: %w", err)
+	}
+
+	event := &database.AuditEvent{
+		ActorID:    &userID,
+		Action:     audit.ActionOwnershipTransferred,
+		ResourceID: &rootID,
+		Details:    fmt.Sprintf("ownership transferred from user %d to user %d (%d bytes)", transfer.FromUserID, userID, usage),
+	}
+	if err := s.auditRepo.Record(tx, event); err != nil {
+		return nil, fmt.Errorf("failed to record audit event: %w", err)
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return s.repo.GetByID(transferID)
+}
+
+// DeclineTransfer lets the recipient turn the offer down.
+func (s *service) DeclineTransfer(ctx context.Context, transferID uint) (*database.OwnershipTransfer, error) {
+	userID, err := getUserIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	transfer, err := s.getPending(transferID)
+	if err != nil {
+		return nil, err
+	}
+	if transfer.ToUserID != userID {
+		return nil, errors.New("ownership transfer not found")
+	}
+	return s.close(transfer, database.TransferDeclined)
+}
+
+// CancelTransfer lets the sender withdraw the offer before it is accepted.
+func (s *service) CancelTransfer(ctx context.Context, transferID uint) (*database.OwnershipTransfer, error) {
+	userID, err := getUserIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	transfer, err := s.getPending(transferID)
+	if err != nil {
+		return nil, err
+	}
+	if transfer.FromUserID != userID {
+		return nil, errors.New("ownership transfer not found")
+	}
+	return s.close(transfer, database.TransferCancelled)
+}
+
+// ListPending returns the open transfers the current user has offered or been offered.
+func (s *service) ListPending(ctx context.Context) ([]database.OwnershipTransfer, error) {
+	userID, err := getUserIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.ListPendingForUser(userID)
+}
+
+func (s *service) getPending(transferID uint) (*database.OwnershipTransfer, error) {
+	transfer, err := s.repo.GetByID(transferID)
+	if err != nil {
+		return nil, errors.New("ownership transfer not found")
+	}
+	if transfer.Status != database.TransferPending {
+		return nil, errors.New("ownership transfer has already been decided")
+	}
+	return transfer, nil
+}
+
+func (s *service) close(transfer *database.OwnershipTransfer, status database.OwnershipTransferStatus) (*database.OwnershipTransfer, error) {
+	now := time.Now()
+	transfer.Status = status
+	transfer.DecidedAt = &now
+	if err := s.repo.Update(s.db, transfer); err != nil {
+		return nil, fmt.Errorf("failed to update ownership transfer: %w", err)
+	}
+	return transfer, nil
+}
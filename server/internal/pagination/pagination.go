@@ -0,0 +1,317 @@
+package pagination
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bhavyajaix/BalkanID-filevault/internal/database"
+	"gorm.io/gorm"
+)
+
+const (
+	// DefaultPageSize is used when a listing doesn't ask for a page size.
+	DefaultPageSize = 50
+	// MaxPageSize caps how many resources a single page can hold.
+	MaxPageSize = 200
+)
+
+// ErrInvalidCursor is returned for cursors that are malformed or were issued for a
+// different sort order.
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// SortField is a column resource listings can be ordered by.
+type SortField string
+
+const (
+	SortByName      SortField = "NAME"
+	SortBySize      SortField = "SIZE"
+	SortByCreatedAt SortField = "CREATED_AT"
+	SortByUpdatedAt SortField = "UPDATED_AT"
+	SortByType      SortField = "TYPE"
+	// SortByRelevance orders full-text search results by their rank; the query must
+	// select a search_rank column.
+	SortByRelevance SortField = "RELEVANCE"
+)
+
+// Sort describes the order of a listing. Ties are always broken by resource ID, so the
+// order is total and a cursor identifies a single position.
+type Sort struct {
+	Field        SortField
+	Descending   bool
+	FoldersFirst bool
+}
+
+// Request asks for up to First resources following the After cursor.
+type Request struct {
+	First int
+	After *string
+	Sort  Sort
+}
+
+// Page is one slice of a listing. Cursors[i] is the cursor of Resources[i].
+type Page struct {
+	Resources       []database.Resource
+	Cursors         []string
+	TotalCount      int64
+	HasNextPage     bool
+	HasPreviousPage bool
+	// Snippets holds highlighted excerpts of matching content by resource ID. Only
+	// full-text searches fill it in.
+	Snippets map[uint]string
+}
+
+// sortKey is one expression of the ORDER BY clause.
+type sortKey struct {
+	expr       string
+	descending bool
+}
+
+// cursor is the position of a resource in a listing: its value for every sort key.
+type cursor struct {
+	Sort   string   `json:"s"`
+	Values []string `json:"v"`
+}
+
+// sizeJoin brings in file sizes under an alias of its own, so it can't clash with a
+// physical_files join the caller already made for filtering.
+const sizeJoin = "LEFT JOIN physical_files AS sort_files ON sort_files.id = resources.physical_file_id"
+
+// keys returns the ORDER BY expressions for s, ending with the ID tie-breaker.
+func (s Sort) keys() ([]sortKey, error) {
+	var keys []sortKey
+	if s.FoldersFirst {
+		keys = append(keys, sortKey{expr: "CASE WHEN resources.type = 'folder' THEN 0 ELSE 1 END"})
+	}
+
+	switch s.Field {
+	case SortByName:
+		keys = append(keys, sortKey{"resources.name", s.Descending})
+	case SortBySize:
+		keys = append(keys, sortKey{"COALESCE(sort_files.size_bytes, 0)", s.Descending})
+	case SortByCreatedAt:
+		keys = append(keys, sortKey{"resources.created_at", s.Descending})
+	case SortByUpdatedAt:
+		keys = append(keys, sortKey{"resources.updated_at", s.Descending})
+	case SortByType:
+		keys = append(keys, sortKey{"resources.type", s.Descending}, sortKey{"resources.name", s.Descending})
+	case SortByRelevance:
+		keys = append(keys, sortKey{"resources.search_rank", s.Descending})
+	default:
+		return nil, fmt.Errorf("unsupported sort field %q", s.Field)
+	}
+	return append(keys, sortKey{"resources.id", s.Descending}), nil
+}
+
+// signature identifies the sort order a cursor was issued for.
+func (s Sort) signature() string {
+	return fmt.Sprintf("%s:%t:%t", s.Field, s.Descending, s.FoldersFirst)
+}
+
+// values returns the resource's value for every sort key, as stored in a cursor.
+func (s Sort) values(resource *database.Resource) []string {
+	var values []string
+	if s.FoldersFirst {
+		values = append(values, strconv.Itoa(folderRank(resource)))
+	}
+
+	switch s.Field {
+	case SortByName:
+		values = append(values, resource.Name)
+	case SortBySize:
+		var size int64
+		if resource.PhysicalFile != nil {
+			size = resource.PhysicalFile.SizeBytes
+		}
+		values = append(values, strconv.FormatInt(size, 10))
+	case SortByCreatedAt:
+		values = append(values, resource.CreatedAt.Format(time.RFC3339Nano))
+	case SortByUpdatedAt:
+		values = append(values, resource.UpdatedAt.Format(time.RFC3339Nano))
+	case SortByType:
+		values = append(values, string(resource.Type), resource.Name)
+	case SortByRelevance:
+		values = append(values, strconv.FormatFloat(resource.SearchRank, 'g', -1, 64))
+	}
+	return append(values, strconv.FormatUint(uint64(resource.ID), 10))
+}
+
+// args converts cursor values back into typed query arguments.
+func (s Sort) args(values []string) ([]interface{}, error) {
+	keys, err := s.keys()
+	if err != nil {
+		return nil, err
+	}
+	if len(values) != len(keys) {
+		return nil, ErrInvalidCursor
+	}
+
+	args := make([]interface{}, 0, len(values))
+	if s.FoldersFirst {
+		rank, err := strconv.Atoi(values[0])
+		if err != nil {
+			return nil, ErrInvalidCursor
+		}
+		args = append(args, rank)
+		values = values[1:]
+	}
+
+	switch s.Field {
+	case SortBySize:
+		size, err := strconv.ParseInt(values[0], 10, 64)
+		if err != nil {
+			return nil, ErrInvalidCursor
+		}
+		args = append(args, size)
+	case SortByCreatedAt, SortByUpdatedAt:
+		at, err := time.Parse(time.RFC3339Nano, values[0])
+		if err != nil {
+			return nil, ErrInvalidCursor
+		}
+		args = append(args, at)
+	case SortByRelevance:
+		rank, err := strconv.ParseFloat(values[0], 64)
+		if err != nil {
+			return nil, ErrInvalidCursor
+		}
+		args = append(args, rank)
+	case SortByType:
+		args = append(args, values[0], values[1])
+		values = values[1:]
+	default:
+		args = append(args, values[0])
+	}
+
+	id, err := strconv.ParseUint(values[1], 10, 64)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	return append(args, uint(id)), nil
+}
+
+func folderRank(resource *database.Resource) int {
+	if resource.Type == database.Folder {
+		return 0
+	}
+	return 1
+}
+
+func encodeCursor(s Sort, resource *database.Resource) string {
+	raw, _ := json.Marshal(cursor{Sort: s.signature(), Values: s.values(resource)})
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func decodeCursor(s Sort, encoded string) ([]interface{}, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var c cursor
+	if err := json.Unmarshal(raw, &c); err != nil {
+		return nil, ErrInvalidCursor
+	}
+	if c.Sort != s.signature() {
+		return nil, fmt.Errorf("%w: it was issued for a different sort order", ErrInvalidCursor)
+	}
+	return s.args(c.Values)
+}
+
+// seekCondition builds the keyset predicate selecting rows strictly after the cursor:
+// (k1 > v1) OR (k1 = v1 AND k2 > v2) OR ..., with < for descending keys.
+func seekCondition(keys []sortKey, args []interface{}) (string, []interface{}) {
+	var clauses []string
+	var vars []interface{}
+	for i, key := range keys {
+		var parts []string
+		for j := 0; j < i; j++ {
+			parts = append(parts, keys[j].expr+" = ?")
+			vars = append(vars, args[j])
+		}
+		op := ">"
+		if key.descending {
+			op = "<"
+		}
+		parts = append(parts, key.expr+" "+op+" ?")
+		vars = append(vars, args[i])
+		clauses = append(clauses, "("+strings.Join(parts, " AND ")+")")
+	}
+	return strings.Join(clauses, " OR "), vars
+}
+
+// Normalize fills in the default page size and rejects sizes outside 1..MaxPageSize.
+func (r *Request) Normalize() error {
+	if r.First == 0 {
+		r.First = DefaultPageSize
+	}
+	if r.First < 0 || r.First > MaxPageSize {
+		return fmt.Errorf("first must be between 1 and %d", MaxPageSize)
+	}
+	return nil
+}
+
+// Paginate counts the resources matched by query and fetches the page described by req
+// using keyset pagination, so deep pages cost the same as the first one. query must
+// select from resources; the named associations are preloaded for the page only.
+func Paginate(query *gorm.DB, req Request, preloads ...string) (*Page, error) {
+	if err := req.Normalize(); err != nil {
+		return nil, err
+	}
+	keys, err := req.Sort.keys()
+	if err != nil {
+		return nil, err
+	}
+
+	base := query.Session(&gorm.Session{})
+	page := &Page{}
+	if err := base.Session(&gorm.Session{NewDB: true}).
+		Table("(?) AS matches", base.Select("resources.id")).
+		Count(&page.TotalCount).Error; err != nil {
+		return nil, fmt.Errorf("failed to count resources: %w", err)
+	}
+
+	fetch := base.Select("resources.*")
+	if req.Sort.Field == SortBySize {
+		fetch = fetch.Joins(sizeJoin)
+	}
+	if req.After != nil {
+		args, err := decodeCursor(req.Sort, *req.After)
+		if err != nil {
+			return nil, err
+		}
+		condition, vars := seekCondition(keys, args)
+		fetch = fetch.Where(condition, vars...)
+		page.HasPreviousPage = true
+	}
+	for _, key := range keys {
+		direction := " ASC"
+		if key.descending {
+			direction = " DESC"
+		}
+		fetch = fetch.Order(key.expr + direction)
+	}
+	for _, association := range preloads {
+		fetch = fetch.Preload(association)
+	}
+	if req.Sort.Field == SortBySize {
+		fetch = fetch.Preload("PhysicalFile")
+	}
+
+	// One extra row tells us whether there is another page.
+	if err := fetch.Limit(req.First + 1).Find(&page.Resources).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch resources: %w", err)
+	}
+	if len(page.Resources) > req.First {
+		page.Resources = page.Resources[:req.First]
+		page.HasNextPage = true
+	}
+
+	page.Cursors = make([]string, len(page.Resources))
+	for i := range page.Resources {
+		page.Cursors[i] = encodeCursor(req.Sort, &page.Resources[i])
+	}
+	return page, nil
+}
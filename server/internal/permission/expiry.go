@@ -0,0 +1,175 @@
+package permission
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/bhavyajaix/BalkanID-filevault/internal/audit"
+	"github.com/bhavyajaix/BalkanID-filevault/internal/database"
+	"gorm.io/gorm"
+)
+
+// ExpiringGrant describes a time-bounded grant that is about to lapse.
+type ExpiringGrant struct {
+	ResourceID    uint
+	ResourceName  string
+	OwnerEmail    string
+	PrincipalType string // "user" or "group"
+	PrincipalName string // the user's email or the group's name
+	Role          database.RoleType
+	ExpiresAt     time.Time
+}
+
+// Notifier tells a resource owner that one of their grants is about to lapse.
+type Notifier interface {
+	NotifyExpiringGrant(ctx context.Context, grant ExpiringGrant) error
+}
+
+// LogNotifier is the default Notifier. It only writes the notice to the server log.
+type LogNotifier struct{}
+
+func (LogNotifier) NotifyExpiringGrant(ctx context.Context, grant ExpiringGrant) error {
+	log.Printf("notice for %s: %s access for %s %q on %q expires at %s",
+		grant.OwnerEmail, grant.Role, grant.PrincipalType, grant.PrincipalName, grant.ResourceName,
+		grant.ExpiresAt.Format(time.RFC3339))
+	return nil
+}
+
+// ExpiryWorker periodically warns owners about grants that are about to lapse and
+// deletes the ones that already have.
+type ExpiryWorker struct {
+	db        *gorm.DB
+	repo      Repository
+	auditRepo audit.Repository
+	notifier  Notifier
+	interval  time.Duration
+	notice    time.Duration
+}
+
+// NewExpiryWorker creates a worker that runs every interval and notifies owners
+// notice ahead of a grant's expiry.
+func NewExpiryWorker(db *gorm.DB, repo Repository, auditRepo audit.Repository, notifier Notifier, interval, notice time.Duration) *ExpiryWorker {
+	return &ExpiryWorker{
+		db:        db,
+		repo:      repo,
+		auditRepo: auditRepo,
+		notifier:  notifier,
+		interval:  interval,
+		notice:    notice,
+	}
+}
+
+// Run blocks until the context is cancelled, processing grants on every tick.
+func (w *ExpiryWorker) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		if err := w.RunOnce(ctx); err != nil {
+			log.Printf("permission expiry: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce sends any pending expiry notices and purges expired grants.
+func (w *ExpiryWorker) RunOnce(ctx context.Context) error {
+	if err := w.notifyExpiring(ctx); err != nil {
+		return fmt.Errorf("failed to send expiry notices: %w", err)
+	}
+	if err := w.purgeExpired(); err != nil {
+		return fmt.Errorf("failed to purge expired grants: %w", err)
+	}
+	return nil
+}
+
+func (w *ExpiryWorker) notifyExpiring(ctx context.Context) error {
+	userGrants, groupGrants, err := w.repo.FindExpiringBefore(time.Now().Add(w.notice))
+	if err != nil {
+		return err
+	}
+
+	for i := range userGrants {
+		p := &userGrants[i]
+		grant := ExpiringGrant{
+			ResourceID:    p.ResourceID,
+			ResourceName:  p.Resource.Name,
+			OwnerEmail:    p.Resource.User.Email,
+			PrincipalType: "user",
+			PrincipalName: p.User.Email,
+			Role:          p.Role,
+			ExpiresAt:     *p.ExpiresAt,
+		}
+		if err := w.notifier.NotifyExpiringGrant(ctx, grant); err != nil {
+			// Leave it unmarked so the notice is retried on the next run.
+			log.Printf("permission expiry: could not notify owner of resource %d: %v", p.ResourceID, err)
+			continue
+		}
+		if err := w.repo.MarkExpiryNotified(p); err != nil {
+			return err
+		}
+	}
+
+	for i := range groupGrants {
+		p := &groupGrants[i]
+		grant := ExpiringGrant{
+			ResourceID:    p.ResourceID,
+			ResourceName:  p.Resource.Name,
+			OwnerEmail:    p.Resource.User.Email,
+			PrincipalType: "group",
+			PrincipalName: p.Group.Name,
+			Role:          p.Role,
+			ExpiresAt:     *p.ExpiresAt,
+		}
+		if err := w.notifier.NotifyExpiringGrant(ctx, grant); err != nil {
+			log.Printf("permission expiry: could not notify owner of resource %d: %v", p.ResourceID, err)
+			continue
+		}
+		if err := w.repo.MarkGroupExpiryNotified(p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// purgeExpired deletes lapsed grants and records an audit event for each of them,
+// in a single transaction.
+func (w *ExpiryWorker) purgeExpired() error {
+	return w.db.Transaction(func(tx *gorm.DB) error {
+		userGrants, groupGrants, err := w.repo.DeleteExpired(tx)
+		if err != nil {
+			return err
+		}
+
+		for _, p := range userGrants {
+			resourceID := p.ResourceID
+			event := &database.AuditEvent{
+				Action:     audit.ActionPermissionExpired,
+				ResourceID: &resourceID,
+				Details:    fmt.Sprintf("%s grant for user %d expired at %s", p.Role, p.UserID, p.ExpiresAt.Format(time.RFC3339)),
+			}
+			if err := w.auditRepo.Record(tx, event); err != nil {
+				return err
+			}
+		}
+		for _, p := range groupGrants {
+			resourceID := p.ResourceID
+			event := &database.AuditEvent{
+				Action:     audit.ActionPermissionExpired,
+				ResourceID: &resourceID,
+				Details:    fmt.Sprintf("%s grant for group %d expired at %s", p.Role, p.GroupID, p.ExpiresAt.Format(time.RFC3339)),
+			}
+			if err := w.auditRepo.Record(tx, event); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
@@ -1,6 +1,8 @@
 package permission
 
 import (
+	"time"
+
 	"github.com/bhavyajaix/BalkanID-filevault/internal/database"
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
@@ -10,6 +12,18 @@ type Repository interface {
 	CreateOrUpdate(permission *database.Permission) error
 	Delete(resourceID, userID uint) error
 	FindPermission(resourceID, userID uint) (*database.Permission, error)
+	IsPubliclyAccessible(resourceID uint) (bool, error)
+	IsDenied(resourceID, userID uint) (bool, error)
+	ListChainGrants(resourceID uint) ([]ChainGrant, error)
+	CreateOrUpdateGroup(permission *database.GroupPermission) error
+	DeleteGroup(resourceID, groupID uint) error
+	ListByResource(resourceID uint) ([]database.Permission, []database.GroupPermission, error)
+	FindExpiringBefore(deadline time.Time) ([]database.Permission, []database.GroupPermission, error)
+	MarkExpiryNotified(permission *database.Permission) error
+	MarkGroupExpiryNotified(permission *database.GroupPermission) error
+	DeleteExpired(db *gorm.DB) ([]database.Permission, []database.GroupPermission, error)
+	ReadableBy(userID uint) func(db *gorm.DB) *gorm.DB
+	WithDB(db *gorm.DB) Repository
 }
 
 type repository struct {
@@ -20,13 +34,24 @@ func NewRepository(db *gorm.DB) Repository {
 	return &repository{db: db}
 }
 
+// WithDB returns a copy of the repository that runs its queries on db, typically a
+// transaction owned by the caller.
+func (r *repository) WithDB(db *gorm.DB) Repository {
+	return &repository{db: db}
+}
+
+// notExpired scopes a grant query to grants that are permanent or have not lapsed yet.
+func notExpired(db *gorm.DB) *gorm.DB {
+	return db.Where("expires_at IS NULL OR expires_at > ?", time.Now())
+}
+
 // CreateOrUpdate performs an "upsert". If a permission for that user/resource
-// already exists, it updates the role; otherwise, it creates a new record.
+// already exists, it updates the role and expiry; otherwise, it creates a new record.
 func (r *repository) CreateOrUpdate(permission *database.Permission) error {
-	// On a conflict of the primary key (ResourceID, UserID), update the "role" column.
+	// On a conflict of the primary key (ResourceID, UserID), update the "role" and expiry columns.
 	return r.db.Clauses(clause.OnConflict{
 		Columns:   []clause.Column{{Name: "resource_id"}, {Name: "user_id"}},
-		DoUpdates: clause.AssignmentColumns([]string{"role"}),
+		DoUpdates: clause.AssignmentColumns([]string{"role", "expires_at", "expiry_notified_at"}),
 	}).Create(permission).Error
 }
 
@@ -34,17 +59,323 @@ func (r *repository) Delete(resourceID, userID uint) error {
 	return r.db.Where("resource_id = ? AND user_id = ?", resourceID, userID).Delete(&database.Permission{}).Error
 }
 
+// inheritedChainSQL selects the ancestors of @resource (the resource itself included, at
+// depth 0) whose grants and public flags still apply to it: every ancestor up to and
+// including the nearest one that has permission inheritance switched off.
+const inheritedChainSQL = `
+	SELECT ra.ancestor_id, ra.depth
+	FROM resource_ancestors ra
+	WHERE ra.descendant_id = @resource
+	  AND ra.depth <= COALESCE((
+		SELECT MIN(cut.depth)
+		FROM resource_ancestors cut
+		JOIN resources cr ON cr.id = cut.ancestor_id
+		WHERE cut.descendant_id = @resource AND cr.inherit_permissions = false
+	  ), ra.depth)`
+
+// userGrantsSQL collects the unexpired grants that reach @user on the inherited chain,
+// whether made to the user directly or to one of their groups.
+const userGrantsSQL = `
+	WITH chain AS (` + inheritedChainSQL + `),
+	grants AS (
+		SELECT p.resource_id, p.role, p.created_at, chain.depth
+		FROM permissions p
+		JOIN chain ON chain.ancestor_id = p.resource_id
+		WHERE p.user_id = @user AND (p.expires_at IS NULL OR p.expires_at > @now)
+		UNION ALL
+		SELECT gp.resource_id, gp.role, gp.created_at, chain.depth
+		FROM group_permissions gp
+		JOIN chain ON chain.ancestor_id = gp.resource_id
+		JOIN group_members gm ON gm.group_id = gp.group_id
+		WHERE gm.user_id = @user AND (gp.expires_at IS NULL OR gp.expires_at > @now)
+	)`
+
+// userReachSQL expands the unexpired grants of @user, made directly or through their
+// groups, to every resource they reach: reach holds one row per grant and resource, at
+// the depth the grant was made above it.
+const userReachSQL = `
+	WITH user_grants AS (
+		SELECT p.resource_id, p.role
+		FROM permissions p
+		WHERE p.user_id = @user AND (p.expires_at IS NULL OR p.expires_at > @now)
+		UNION ALL
+		SELECT gp.resource_id, gp.role
+		FROM group_permissions gp
+		JOIN group_members gm ON gm.group_id = gp.group_id
+		WHERE gm.user_id = @user AND (gp.expires_at IS NULL OR gp.expires_at > @now)
+	),
+	reach AS (
+		SELECT ra.descendant_id, ra.depth, g.role
+		FROM user_grants g
+		JOIN resource_ancestors ra ON ra.ancestor_id = g.resource_id
+		WHERE ra.depth <= COALESCE((
+			SELECT MIN(cut.depth)
+			FROM resource_ancestors cut
+			JOIN resources cr ON cr.id = cut.ancestor_id
+			WHERE cut.descendant_id = ra.descendant_id AND cr.inherit_permissions = false
+		), ra.depth)
+	)`
+
+// sharedWithSQL selects the resources @user can read through grants rather than
+// ownership. It applies the rules of FindPermission to every resource at once: an allow
+// on the inherited chain counts unless a deny at the same level or further up
+// overrides it.
+const sharedWithSQL = userReachSQL + `
+	SELECT DISTINCT a.descendant_id
+	FROM reach a
+	WHERE a.role <> @deny
+	  AND NOT EXISTS (
+		SELECT 1 FROM reach d
+		WHERE d.descendant_id = a.descendant_id AND d.role = @deny AND d.depth <= a.depth
+	  )`
+
+// deniedToSQL selects the resources a deny keeps from @user, like IsDenied: those with
+// a deny on the inherited chain that no allow placed below it lifts.
+const deniedToSQL = userReachSQL + `
+	SELECT DISTINCT d.descendant_id
+	FROM reach d
+	WHERE d.role = @deny
+	  AND NOT EXISTS (
+		SELECT 1 FROM reach a
+		WHERE a.descendant_id = d.descendant_id AND a.role <> @deny AND a.depth < d.depth
+	  )`
+
+// SharedWith returns a subquery selecting the IDs of the resources shared with a user,
+// directly or through their groups, for use as in Where("resources.id IN (?)", ...).
+// Public resources are not included.
+func SharedWith(db *gorm.DB, userID uint) *gorm.DB {
+	return db.Raw(sharedWithSQL, map[string]interface{}{
+		"user": userID,
+		"now":  time.Now(),
+		"deny": database.Deny,
+	})
+}
+
+// DeniedTo returns a subquery selecting the IDs of the resources a deny keeps from a
+// user, for use as in Where("resources.id NOT IN (?)", ...).
+func DeniedTo(db *gorm.DB, userID uint) *gorm.DB {
+	return db.Raw(deniedToSQL, map[string]interface{}{
+		"user": userID,
+		"now":  time.Now(),
+		"deny": database.Deny,
+	})
+}
+
+// publiclyReachableSQL holds for the resources row when it is public, itself or through
+// an ancestor whose public flag still reaches it, like IsPubliclyAccessible.
+const publiclyReachableSQL = `EXISTS (
+	SELECT 1
+	FROM resource_ancestors pa
+	JOIN resources pub ON pub.id = pa.ancestor_id AND pub.is_public = true AND pub.deleted_at IS NULL
+	WHERE pa.descendant_id = resources.id
+	  AND pa.depth <= COALESCE((
+		SELECT MIN(cut.depth)
+		FROM resource_ancestors cut
+		JOIN resources cr ON cr.id = cut.ancestor_id
+		WHERE cut.descendant_id = resources.id AND cr.inherit_permissions = false
+	  ), pa.depth))`
+
+// ReadableExpr holds for the resources rows a user can read: their own, those shared
+// with them and public ones they are not denied. It decides in SQL what
+// folders.Service.CanRead decides for a single resource, and can be combined with other
+// conditions.
+func ReadableExpr(db *gorm.DB, userID uint) clause.Expr {
+	return gorm.Expr("(resources.owner_id = ? OR resources.id IN (?) OR ("+publiclyReachableSQL+" AND resources.id NOT IN (?)))",
+		userID, SharedWith(db, userID), DeniedTo(db, userID))
+}
+
+// Readable returns a scope restricting a query on resources to the rows a user can read.
+func Readable(db *gorm.DB, userID uint) func(query *gorm.DB) *gorm.DB {
+	return func(query *gorm.DB) *gorm.DB {
+		return query.Where(ReadableExpr(db, userID))
+	}
+}
+
+// ReadableBy is Readable on the repository's database.
+func (r *repository) ReadableBy(userID uint) func(db *gorm.DB) *gorm.DB {
+	return Readable(r.db, userID)
+}
+
+// FindPermission resolves the effective permission a user has on a resource. Grants made
+// to the user directly and grants made to any group they belong to are both considered,
+// on the resource itself or any ancestor up to the nearest one that breaks inheritance.
+// An explicit deny overrides every allow made at the same level or further up the tree,
+// so a deny on a folder can only be lifted by a grant placed below it. Expired grants are
+// ignored. When several allows remain, the most permissive role wins.
 func (r *repository) FindPermission(resourceID, userID uint) (*database.Permission, error) {
 	var permission database.Permission
 
-	subQuery := r.db.Model(&database.ResourceAncestor{}).
-		Select("ancestor_id").
-		Where("descendant_id = ?", resourceID)
+	result := r.db.Raw(userGrantsSQL+`
+		SELECT g.resource_id, g.role, g.created_at
+		FROM grants g
+		WHERE g.role <> @deny
+		  AND NOT EXISTS (SELECT 1 FROM grants d WHERE d.role = @deny AND d.depth <= g.depth)
+		ORDER BY g.role = @editor DESC, g.depth ASC
+		LIMIT 1`,
+		map[string]interface{}{
+			"resource": resourceID,
+			"user":     userID,
+			"now":      time.Now(),
+			"deny":     database.Deny,
+			"editor":   database.Editor,
+		}).Scan(&permission)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	permission.UserID = userID
+
+	return &permission, nil
+}
+
+// IsPubliclyAccessible reports whether a resource is public, either itself or through an
+// ancestor whose public flag still reaches it.
+func (r *repository) IsPubliclyAccessible(resourceID uint) (bool, error) {
+	var count int64
+	err := r.db.Raw(`
+		WITH chain AS (`+inheritedChainSQL+`)
+		SELECT COUNT(*) FROM chain JOIN resources res ON res.id = chain.ancestor_id
+		WHERE res.is_public = true AND res.deleted_at IS NULL`,
+		map[string]interface{}{"resource": resourceID}).Scan(&count).Error
+	return count > 0, err
+}
+
+// IsDenied reports whether an explicit deny keeps a user from a resource: a deny on the
+// inherited chain, made to them or one of their groups, that no allow placed below it
+// lifts. A denied user can't read the resource even when it is public.
+func (r *repository) IsDenied(resourceID, userID uint) (bool, error) {
+	var count int64
+	err := r.db.Raw(userGrantsSQL+`
+		SELECT COUNT(*)
+		FROM grants d
+		WHERE d.role = @deny
+		  AND NOT EXISTS (SELECT 1 FROM grants a WHERE a.role <> @deny AND a.depth < d.depth)`,
+		map[string]interface{}{
+			"resource": resourceID,
+			"user":     userID,
+			"now":      time.Now(),
+			"deny":     database.Deny,
+		}).Scan(&count).Error
+	return count > 0, err
+}
+
+// ChainGrant is an unexpired grant found on the inherited chain of a resource, expanded
+// to the user it reaches. GroupID and GroupName are set when the grant was made to a group.
+type ChainGrant struct {
+	UserID       uint
+	Username     string
+	Email        string
+	GroupID      *uint
+	GroupName    string
+	ResourceID   uint
+	ResourceName string
+	Depth        int
+	Role         database.RoleType
+	ExpiresAt    *time.Time
+}
+
+// ListChainGrants returns every unexpired grant that applies to a resource through its
+// inherited chain, with group grants expanded to one row per member.
+func (r *repository) ListChainGrants(resourceID uint) ([]ChainGrant, error) {
+	var grants []ChainGrant
+	err := r.db.Raw(`
+		WITH chain AS (`+inheritedChainSQL+`)
+		SELECT p.user_id, u.username, u.email, NULL AS group_id, '' AS group_name,
+			p.resource_id, res.name AS resource_name, chain.depth, p.role, p.expires_at
+		FROM permissions p
+		JOIN chain ON chain.ancestor_id = p.resource_id
+		JOIN resources res ON res.id = p.resource_id
+		JOIN users u ON u.id = p.user_id
+		WHERE p.expires_at IS NULL OR p.expires_at > @now
+		UNION ALL
+		SELECT gm.user_id, u.username, u.email, gp.group_id, g.name AS group_name,
+			gp.resource_id, res.name AS resource_name, chain.depth, gp.role, gp.expires_at
+		FROM group_permissions gp
+		JOIN chain ON chain.ancestor_id = gp.resource_id
+		JOIN resources res ON res.id = gp.resource_id
+		JOIN group_members gm ON gm.group_id = gp.group_id
+		JOIN users u ON u.id = gm.user_id
+		JOIN groups g ON g.id = gp.group_id AND g.deleted_at IS NULL
+		WHERE gp.expires_at IS NULL OR gp.expires_at > @now
+		ORDER BY depth ASC`,
+		map[string]interface{}{"resource": resourceID, "now": time.Now()}).Scan(&grants).Error
+	return grants, err
+}
+
+// CreateOrUpdateGroup performs an "upsert" of a grant made to a group.
+func (r *repository) CreateOrUpdateGroup(permission *database.GroupPermission) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "resource_id"}, {Name: "group_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"role", "expires_at", "expiry_notified_at"}),
+	}).Create(permission).Error
+}
+
+func (r *repository) DeleteGroup(resourceID, groupID uint) error {
+	return r.db.Where("resource_id = ? AND group_id = ?", resourceID, groupID).Delete(&database.GroupPermission{}).Error
+}
+
+// ListByResource returns the explicit, unexpired user and group grants made on a resource.
+func (r *repository) ListByResource(resourceID uint) ([]database.Permission, []database.GroupPermission, error) {
+	var userGrants []database.Permission
+	if err := r.db.Preload("User").Scopes(notExpired).Where("resource_id = ?", resourceID).Order("created_at asc").Find(&userGrants).Error; err != nil {
+		return nil, nil, err
+	}
+
+	var groupGrants []database.GroupPermission
+	if err := r.db.Preload("Group").Scopes(notExpired).Where("resource_id = ?", resourceID).Order("created_at asc").Find(&groupGrants).Error; err != nil {
+		return nil, nil, err
+	}
+	return userGrants, groupGrants, nil
+}
+
+// FindExpiringBefore returns the grants that are still active but lapse before the
+// deadline, and whose owner has not been notified yet. The resource and its owner
+// are preloaded so a notification can be sent.
+func (r *repository) FindExpiringBefore(deadline time.Time) ([]database.Permission, []database.GroupPermission, error) {
+	var userGrants []database.Permission
+	if err := r.db.Preload("Resource.User").Preload("User").
+		Scopes(notExpired).
+		Where("expires_at <= ? AND expiry_notified_at IS NULL", deadline).
+		Find(&userGrants).Error; err != nil {
+		return nil, nil, err
+	}
 
-	// Check permission on resource itself OR any ancestor
-	err := r.db.Where("(resource_id = ? OR resource_id IN (?)) AND user_id = ?", resourceID, subQuery, userID).
-		First(&permission).Error
+	var groupGrants []database.GroupPermission
+	if err := r.db.Preload("Resource.User").Preload("Group").
+		Scopes(notExpired).
+		Where("expires_at <= ? AND expiry_notified_at IS NULL", deadline).
+		Find(&groupGrants).Error; err != nil {
+		return nil, nil, err
+	}
+	return userGrants, groupGrants, nil
+}
+
+func (r *repository) MarkExpiryNotified(permission *database.Permission) error {
+	return r.db.Model(&database.Permission{}).
+		Where("resource_id = ? AND user_id = ?", permission.ResourceID, permission.UserID).
+		UpdateColumn("expiry_notified_at", time.Now()).Error
+}
 
-	return &permission, err
+func (r *repository) MarkGroupExpiryNotified(permission *database.GroupPermission) error {
+	return r.db.Model(&database.GroupPermission{}).
+		Where("resource_id = ? AND group_id = ?", permission.ResourceID, permission.GroupID).
+		UpdateColumn("expiry_notified_at", time.Now()).Error
 }
 
+// DeleteExpired removes every lapsed grant and returns the deleted rows.
+func (r *repository) DeleteExpired(db *gorm.DB) ([]database.Permission, []database.GroupPermission, error) {
+	now := time.Now()
+
+	var userGrants []database.Permission
+	if err := db.Clauses(clause.Returning{}).Where("expires_at <= ?", now).Delete(&userGrants).Error; err != nil {
+		return nil, nil, err
+	}
+
+	var groupGrants []database.GroupPermission
+	if err := db.Clauses(clause.Returning{}).Where("expires_at <= ?", now).Delete(&groupGrants).Error; err != nil {
+		return nil, nil, err
+	}
+	return userGrants, groupGrants, nil
+}
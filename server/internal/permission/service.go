@@ -8,8 +8,10 @@ import (
 
 	"github.com/bhavyajaix/BalkanID-filevault/internal/database"
 	"github.com/bhavyajaix/BalkanID-filevault/internal/folders"
+	"github.com/bhavyajaix/BalkanID-filevault/internal/group"
 	"github.com/bhavyajaix/BalkanID-filevault/internal/middleware"
 	"github.com/bhavyajaix/BalkanID-filevault/internal/user"
+	"gorm.io/gorm"
 )
 
 func getUserIDFromContext(ctx context.Context) (uint, error) {
@@ -21,26 +23,46 @@ func getUserIDFromContext(ctx context.Context) (uint, error) {
 }
 
 type Service interface {
-	GrantPermission(ctx context.Context, resourceID uint, targetEmail string, role database.RoleType) (*database.Resource, error)
+	GrantPermission(ctx context.Context, resourceID uint, targetEmail string, role database.RoleType, expiresAt *time.Time) (*database.Resource, error)
 	RevokePermission(ctx context.Context, resourceID uint, targetEmail string) (*database.Resource, error)
+	GrantGroupPermission(ctx context.Context, resourceID uint, groupID uint, role database.RoleType, expiresAt *time.Time) (*database.Resource, error)
+	RevokeGroupPermission(ctx context.Context, resourceID uint, groupID uint) (*database.Resource, error)
+	SetInheritPermissions(ctx context.Context, resourceID uint, inherit bool) (*database.Resource, error)
+	EffectivePermissions(ctx context.Context, resourceID uint) ([]EffectiveAccess, error)
+}
+
+// EffectiveAccess explains how a user reaches a resource: the role they end up with and
+// the grant responsible for it. Grant is nil for the owner. Users blocked by an explicit
+// deny are reported with the Deny role and the deny entry as their grant.
+type EffectiveAccess struct {
+	User    database.User
+	Role    database.RoleType
+	IsOwner bool
+	Grant   *ChainGrant
 }
 
 type service struct {
 	permRepo     Repository
 	resourceRepo folders.Repository
 	userRepo     user.Repository
+	groupRepo    group.Repository
 }
 
-func NewService(permRepo Repository, resourceRepo folders.Repository, userRepo user.Repository) Service {
-	return &service{permRepo: permRepo, resourceRepo: resourceRepo, userRepo: userRepo}
+func NewService(permRepo Repository, resourceRepo folders.Repository, userRepo user.Repository, groupRepo group.Repository) Service {
+	return &service{permRepo: permRepo, resourceRepo: resourceRepo, userRepo: userRepo, groupRepo: groupRepo}
 }
 
-func (s *service) GrantPermission(ctx context.Context, resourceID uint, targetEmail string, role database.RoleType) (*database.Resource, error) {
+// GrantPermission shares a resource with a user. A nil expiresAt makes the grant permanent.
+// Granting the Deny role records an explicit deny that blocks inherited access instead.
+func (s *service) GrantPermission(ctx context.Context, resourceID uint, targetEmail string, role database.RoleType, expiresAt *time.Time) (*database.Resource, error) {
 	// 1. Get the current user (the one granting permission).
 	ownerID, err := getUserIDFromContext(ctx) // Assuming you create this helper
 	if err != nil {
 		return nil, err
 	}
+	if err := validateExpiry(expiresAt); err != nil {
+		return nil, err
+	}
 
 	// 2. Security Check: Verify the current user owns the resource.
 	resourceToShare, err := s.resourceRepo.GetByID(resourceID)
@@ -68,13 +90,14 @@ func (s *service) GrantPermission(ctx context.Context, resourceID uint, targetEm
 		UserID:     targetUser.ID,
 		Role:       role,
 		CreatedAt:  time.Now(),
+		ExpiresAt:  expiresAt,
 	}
 	if err := s.permRepo.CreateOrUpdate(newPermission); err != nil {
 		return nil, err
 	}
 
-	// Return the updated resource (you'll need to Preload permissions)
-	return s.resourceRepo.GetByID(resourceID)
+	// Return the updated resource with its grants attached
+	return s.resourceWithGrants(resourceID)
 }
 
 func (s *service) RevokePermission(ctx context.Context, resourceID uint, targetEmail string) (*database.Resource, error) {
@@ -105,5 +128,188 @@ func (s *service) RevokePermission(ctx context.Context, resourceID uint, targetE
 	}
 
 	// 5. Return the updated resource. When fetched, its permissions list will be updated.
-	return s.resourceRepo.GetByID(resourceID)
+	return s.resourceWithGrants(resourceID)
+}
+
+// GrantGroupPermission shares a resource with every member of a group the owner
+// belongs to.
+func (s *service) GrantGroupPermission(ctx context.Context, resourceID uint, groupID uint, role database.RoleType, expiresAt *time.Time) (*database.Resource, error) {
+	ownerID, err := getUserIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateExpiry(expiresAt); err != nil {
+		return nil, err
+	}
+
+	resourceToShare, err := s.resourceRepo.GetByID(resourceID)
+	if err != nil {
+		return nil, errors.New("resource not found")
+	}
+	if resourceToShare.OwnerID != ownerID {
+		return nil, errors.New("access denied: only the owner can grant permissions")
+	}
+
+	// Only groups the owner belongs to can be granted access; others are reported as
+	// missing so group IDs can't be probed.
+	if _, err := s.groupRepo.FindMember(groupID, ownerID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("group not found")
+		}
+		return nil, fmt.Errorf("failed to check group membership: %w", err)
+	}
+
+	newPermission := &database.GroupPermission{
+		ResourceID: resourceID,
+		GroupID:    groupID,
+		Role:       role,
+		CreatedAt:  time.Now(),
+		ExpiresAt:  expiresAt,
+	}
+	if err := s.permRepo.CreateOrUpdateGroup(newPermission); err != nil {
+		return nil, err
+	}
+
+	return s.resourceWithGrants(resourceID)
+}
+
+// RevokeGroupPermission removes a grant previously made to a group.
+func (s *service) RevokeGroupPermission(ctx context.Context, resourceID uint, groupID uint) (*database.Resource, error) {
+	ownerID, err := getUserIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	resource, err := s.resourceRepo.GetByID(resourceID)
+	if err != nil {
+		return nil, errors.New("resource not found")
+	}
+	if resource.OwnerID != ownerID {
+		return nil, errors.New("access denied: only the owner can revoke permissions")
+	}
+
+	if err := s.permRepo.DeleteGroup(resourceID, groupID); err != nil {
+		return nil, fmt.Errorf("failed to revoke permission: %w", err)
+	}
+
+	return s.resourceWithGrants(resourceID)
+}
+
+// SetInheritPermissions switches whether grants and public flags on ancestors apply to
+// a resource and everything below it.
+func (s *service) SetInheritPermissions(ctx context.Context, resourceID uint, inherit bool) (*database.Resource, error) {
+	ownerID, err := getUserIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	resource, err := s.resourceRepo.GetByID(resourceID)
+	if err != nil {
+		return nil, errors.New("resource not found")
+	}
+	if resource.OwnerID != ownerID {
+		return nil, errors.New("access denied: only the owner can change permission inheritance")
+	}
+
+	resource.InheritPermissions = inherit
+	if err := s.resourceRepo.Update(resource); err != nil {
+		return nil, fmt.Errorf("failed to update permission inheritance: %w", err)
+	}
+
+	return s.resourceWithGrants(resourceID)
+}
+
+// EffectivePermissions lists everyone who can reach a resource, together with the grant
+// or ancestor that gives them access. The owner comes first, followed by the other users
+// in the order their deciding grant sits from the resource upwards.
+func (s *service) EffectivePermissions(ctx context.Context, resourceID uint) ([]EffectiveAccess, error) {
+	ownerID, err := getUserIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	resource, err := s.resourceRepo.GetByID(resourceID)
+	if err != nil {
+		return nil, errors.New("resource not found")
+	}
+	if resource.OwnerID != ownerID {
+		return nil, errors.New("access denied: only the owner can view effective permissions")
+	}
+
+	grants, err := s.permRepo.ListChainGrants(resourceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load permissions: %w", err)
+	}
+
+	// Group the grants per user, keeping the nearest-first order they arrive in.
+	var order []uint
+	byUser := make(map[uint][]*ChainGrant)
+	for i := range grants {
+		grant := &grants[i]
+		if grant.UserID == resource.OwnerID {
+			continue
+		}
+		if _, seen := byUser[grant.UserID]; !seen {
+			order = append(order, grant.UserID)
+		}
+		byUser[grant.UserID] = append(byUser[grant.UserID], grant)
+	}
+
+	entries := []EffectiveAccess{{User: resource.User, Role: database.Editor, IsOwner: true}}
+	for _, userID := range order {
+		userGrants := byUser[userID]
+
+		// The nearest deny cuts off every allow at its own depth and above.
+		var deny *ChainGrant
+		for _, grant := range userGrants {
+			if grant.Role == database.Deny {
+				deny = grant
+				break
+			}
+		}
+
+		var best *ChainGrant
+		for _, grant := range userGrants {
+			if deny != nil && grant.Depth >= deny.Depth {
+				break
+			}
+			if best == nil || (grant.Role == database.Editor && best.Role == database.Viewer) {
+				best = grant
+			}
+		}
+		if best == nil {
+			best = deny
+		}
+
+		entries = append(entries, EffectiveAccess{
+			User:  database.User{Model: gorm.Model{ID: userID}, Username: best.Username, Email: best.Email},
+			Role:  best.Role,
+			Grant: best,
+		})
+	}
+	return entries, nil
+}
+
+// resourceWithGrants fetches a resource with its user and group grants attached.
+func (s *service) resourceWithGrants(resourceID uint) (*database.Resource, error) {
+	resource, err := s.resourceRepo.GetByID(resourceID)
+	if err != nil {
+		return nil, err
+	}
+
+	userGrants, groupGrants, err := s.permRepo.ListByResource(resourceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load permissions: %w", err)
+	}
+	resource.Permissions = userGrants
+	resource.GroupPermissions = groupGrants
+	return resource, nil
+}
+
+// validateExpiry rejects expiry times that are already in the past.
+func validateExpiry(expiresAt *time.Time) error {
+	if expiresAt != nil && !expiresAt.After(time.Now()) {
+		return errors.New("expiresAt must be in the future")
+	}
+	return nil
 }
@@ -0,0 +1,108 @@
+package rules
+
+import (
+	"errors"
+
+	"github.com/bhavyajaix/BalkanID-filevault/internal/database"
+	"gorm.io/gorm"
+)
+
+// Repository is the interface for rules and the resources they are applied to.
+type Repository interface {
+	Create(rule *database.Rule) error
+	Update(rule *database.Rule) error
+	FindByID(id uint) (*database.Rule, error)
+	ListByOwner(ownerID uint) ([]database.Rule, error)
+	Delete(id uint) error
+	FindResource(id uint) (*database.Resource, error)
+	ListOwnedFiles(ownerID uint, afterID uint, limit int) ([]database.Resource, error)
+	IsInFolder(resourceID uint, folderID uint) (bool, error)
+	IndexedContent(physicalFileID uint) (string, bool, error)
+	MakePublic(resourceID uint) error
+}
+
+type repository struct {
+	db *gorm.DB
+}
+
+// NewRepository creates a new rule repository.
+func NewRepository(db *gorm.DB) Repository {
+	return &repository{db: db}
+}
+
+func (r *repository) Create(rule *database.Rule) error {
+	return r.db.Omit("Owner").Create(rule).Error
+}
+
+// Update saves a rule's name, state and definition.
+func (r *repository) Update(rule *database.Rule) error {
+	return r.db.Model(rule).Select("Name", "Enabled", "Conditions", "Actions").Updates(rule).Error
+}
+
+func (r *repository) FindByID(id uint) (*database.Rule, error) {
+	var rule database.Rule
+	if err := r.db.First(&rule, id).Error; err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+// ListByOwner returns a user's rules in the order they run.
+func (r *repository) ListByOwner(ownerID uint) ([]database.Rule, error) {
+	var rules []database.Rule
+	err := r.db.Where("owner_id = ?", ownerID).Order("id ASC").Find(&rules).Error
+	return rules, err
+}
+
+// Delete removes a rule for good, freeing its name.
+func (r *repository) Delete(id uint) error {
+	return r.db.Unscoped().Delete(&database.Rule{}, id).Error
+}
+
+func (r *repository) FindResource(id uint) (*database.Resource, error) {
+	var resource database.Resource
+	if err := r.db.Preload("PhysicalFile").Preload("User").First(&resource, id).Error; err != nil {
+		return nil, err
+	}
+	return &resource, nil
+}
+
+// ListOwnedFiles returns a page of a user's files, in ID order after afterID.
+func (r *repository) ListOwnedFiles(ownerID uint, afterID uint, limit int) ([]database.Resource, error) {
+	var resources []database.Resource
+	err := r.db.Preload("PhysicalFile").Preload("User").
+		Where("owner_id = ? AND type = ? AND id > ?", ownerID, database.File, afterID).
+		Order("id ASC").
+		Limit(limit).
+		Find(&resources).Error
+	return resources, err
+}
+
+// IsInFolder reports whether a resource is below a folder, at any depth.
+func (r *repository) IsInFolder(resourceID uint, folderID uint) (bool, error) {
+	var count int64
+	err := r.db.Table("resource_ancestors").
+		Where("ancestor_id = ? AND descendant_id = ? AND depth > 0", folderID, resourceID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// IndexedContent returns the text the content indexer extracted from a physical file,
+// and false when it hasn't indexed the file successfully.
+func (r *repository) IndexedContent(physicalFileID uint) (string, bool, error) {
+	var content database.FileContent
+	err := r.db.Select("content").
+		Where("physical_file_id = ? AND status = ?", physicalFileID, database.ContentIndexed).
+		Take(&content).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	return content.Content, true, nil
+}
+
+func (r *repository) MakePublic(resourceID uint) error {
+	return r.db.Model(&database.Resource{}).Where("id = ?", resourceID).Update("is_public", true).Error
+}
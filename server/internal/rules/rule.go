@@ -0,0 +1,221 @@
+package rules
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/bhavyajaix/BalkanID-filevault/internal/database"
+)
+
+const (
+	maxPatternLength  = 500
+	maxContainsLength = 1000
+)
+
+// Conditions select the files a rule applies to. Every condition that is set must
+// hold, and a rule needs at least one.
+type Conditions struct {
+	// MimeTypes match the file's type exactly, or a whole family with "image/*".
+	MimeTypes []string `json:"mimeTypes,omitempty"`
+	// NameGlob is a shell pattern such as "*.pdf" or "invoice-*", matched against the
+	// whole name ignoring case.
+	NameGlob *string `json:"nameGlob,omitempty"`
+	// NameRegex is a regular expression, in RE2 syntax, found anywhere in the name.
+	NameRegex    *string `json:"nameRegex,omitempty"`
+	MinSizeBytes *int64  `json:"minSizeBytes,omitempty"`
+	MaxSizeBytes *int64  `json:"maxSizeBytes,omitempty"`
+	// FolderID matches files directly inside the folder, or at any depth with
+	// IncludeSubfolders.
+	FolderID          *uint `json:"folderId,omitempty"`
+	IncludeSubfolders bool  `json:"includeSubfolders,omitempty"`
+	// Contains matches files whose text contains the string, ignoring case. The text is
+	// extracted as for content search, so only documents with text can match.
+	Contains *string `json:"contains,omitempty"`
+}
+
+// Actions are applied to the files a rule matches. A rule needs at least one.
+type Actions struct {
+	// AddTags names tags of the owner's own namespace, created as needed.
+	AddTags []string `json:"addTags,omitempty"`
+	// MoveToFolderID moves the file into a folder, renaming it if the name is taken.
+	MoveToFolderID *uint `json:"moveToFolderId,omitempty"`
+	MakePublic     bool  `json:"makePublic,omitempty"`
+}
+
+// Definition is what a rule does, as stored on database.Rule.
+type Definition struct {
+	Conditions Conditions
+	Actions    Actions
+}
+
+// Decode reads the conditions and actions stored on a rule.
+func Decode(rule *database.Rule) (Definition, error) {
+	var def Definition
+	if err := json.Unmarshal([]byte(rule.Conditions), &def.Conditions); err != nil {
+		return def, fmt.Errorf("failed to decode conditions of rule %d: %w", rule.ID, err)
+	}
+	if err := json.Unmarshal([]byte(rule.Actions), &def.Actions); err != nil {
+		return def, fmt.Errorf("failed to decode actions of rule %d: %w", rule.ID, err)
+	}
+	return def, nil
+}
+
+// normalize trims the definition's strings, lower-cases MIME types and drops duplicate
+// tags, then checks it is valid.
+func (d *Definition) normalize() error {
+	c := &d.Conditions
+	mimeTypes := c.MimeTypes
+	c.MimeTypes = nil
+	for _, mimeType := range mimeTypes {
+		mimeType = strings.ToLower(strings.TrimSpace(mimeType))
+		if mimeType == "" || !strings.Contains(mimeType, "/") {
+			return fmt.Errorf("invalid MIME type %q", mimeType)
+		}
+		c.MimeTypes = append(c.MimeTypes, mimeType)
+	}
+	c.NameGlob = trimmed(c.NameGlob)
+	c.Contains = trimmed(c.Contains)
+	if c.NameRegex != nil && *c.NameRegex == "" {
+		c.NameRegex = nil
+	}
+	if c.FolderID == nil {
+		c.IncludeSubfolders = false
+	}
+
+	a := &d.Actions
+	seen := make(map[string]bool, len(a.AddTags))
+	tags := a.AddTags
+	a.AddTags = nil
+	for _, name := range tags {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			return errors.New("tag names cannot be empty")
+		}
+		if !seen[strings.ToLower(name)] {
+			seen[strings.ToLower(name)] = true
+			a.AddTags = append(a.AddTags, name)
+		}
+	}
+
+	if len(c.MimeTypes) == 0 && c.NameGlob == nil && c.NameRegex == nil && c.MinSizeBytes == nil &&
+		c.MaxSizeBytes == nil && c.FolderID == nil && c.Contains == nil {
+		return errors.New("a rule needs at least one condition")
+	}
+	if len(a.AddTags) == 0 && a.MoveToFolderID == nil && !a.MakePublic {
+		return errors.New("a rule needs at least one action")
+	}
+	if c.MinSizeBytes != nil && *c.MinSizeBytes < 0 {
+		return errors.New("minimum size cannot be negative")
+	}
+	if c.MinSizeBytes != nil && c.MaxSizeBytes != nil && *c.MinSizeBytes > *c.MaxSizeBytes {
+		return errors.New("minimum size cannot be greater than maximum size")
+	}
+	if c.Contains != nil && len(*c.Contains) > maxContainsLength {
+		return fmt.Errorf("contains cannot be longer than %d characters", maxContainsLength)
+	}
+	_, err := compile(c)
+	return err
+}
+
+func trimmed(s *string) *string {
+	if s == nil {
+		return nil
+	}
+	t := strings.TrimSpace(*s)
+	if t == "" {
+		return nil
+	}
+	return &t
+}
+
+// matcher checks files against a rule's conditions.
+type matcher struct {
+	*Conditions
+	nameRegex *regexp.Regexp
+}
+
+func compile(c *Conditions) (*matcher, error) {
+	m := &matcher{Conditions: c}
+	if c.NameGlob != nil {
+		if len(*c.NameGlob) > maxPatternLength {
+			return nil, fmt.Errorf("name glob cannot be longer than %d characters", maxPatternLength)
+		}
+		if _, err := path.Match(strings.ToLower(*c.NameGlob), ""); err != nil {
+			return nil, fmt.Errorf("invalid name glob %q: %w", *c.NameGlob, err)
+		}
+	}
+	if c.NameRegex != nil {
+		if len(*c.NameRegex) > maxPatternLength {
+			return nil, fmt.Errorf("name regex cannot be longer than %d characters", maxPatternLength)
+		}
+		re, err := regexp.Compile(*c.NameRegex)
+		if err != nil {
+			return nil, fmt.Errorf("invalid name regex: %w", err)
+		}
+		m.nameRegex = re
+	}
+	return m, nil
+}
+
+// match reports whether the file meets every condition. The checks that need the
+// database or the file's text run last, and only when the cheap ones have passed.
+func (m *matcher) match(f *target) (bool, error) {
+	resource := f.resource
+	var mimeType string
+	var size int64
+	if resource.PhysicalFile != nil {
+		mimeType = strings.ToLower(resource.PhysicalFile.MimeType)
+		size = resource.PhysicalFile.SizeBytes
+	}
+
+	if len(m.MimeTypes) > 0 && !matchesMimeType(m.MimeTypes, mimeType) {
+		return false, nil
+	}
+	if m.NameGlob != nil {
+		if ok, _ := path.Match(strings.ToLower(*m.NameGlob), strings.ToLower(resource.Name)); !ok {
+			return false, nil
+		}
+	}
+	if m.nameRegex != nil && !m.nameRegex.MatchString(resource.Name) {
+		return false, nil
+	}
+	if m.MinSizeBytes != nil && size < *m.MinSizeBytes {
+		return false, nil
+	}
+	if m.MaxSizeBytes != nil && size > *m.MaxSizeBytes {
+		return false, nil
+	}
+	if m.FolderID != nil {
+		inside, err := f.inFolder(*m.FolderID, m.IncludeSubfolders)
+		if err != nil || !inside {
+			return false, err
+		}
+	}
+	if m.Contains != nil {
+		text, err := f.text()
+		if err != nil {
+			return false, err
+		}
+		if !strings.Contains(strings.ToLower(text), strings.ToLower(*m.Contains)) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func matchesMimeType(patterns []string, mimeType string) bool {
+	for _, pattern := range patterns {
+		if family, ok := strings.CutSuffix(pattern, "/*"); ok {
+			if strings.HasPrefix(mimeType, family+"/") {
+				return true
+			}
+		} else if pattern == mimeType {
+			return true
+		}
+	}
+	return false
+}
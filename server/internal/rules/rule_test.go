@@ -0,0 +1,126 @@
+package rules
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/bhavyajaix/BalkanID-filevault/internal/database"
+)
+
+func TestNormalize(t *testing.T) {
+	str := func(s string) *string { return &s }
+	size := func(n int64) *int64 { return &n }
+	folder := uint(3)
+
+	tests := []struct {
+		name    string
+		def     Definition
+		want    Definition
+		wantErr bool
+	}{
+		{
+			name: "cleans up",
+			def: Definition{
+				Conditions: Conditions{MimeTypes: []string{" Image/* ", "APPLICATION/PDF"}, NameGlob: str("  *.pdf "), NameRegex: str(""), Contains: str("   "), IncludeSubfolders: true},
+				Actions:    Actions{AddTags: []string{" Work ", "work", "Invoices"}},
+			},
+			want: Definition{
+				Conditions: Conditions{MimeTypes: []string{"image/*", "application/pdf"}, NameGlob: str("*.pdf")},
+				Actions:    Actions{AddTags: []string{"Work", "Invoices"}},
+			},
+		},
+		{
+			name: "keeps subfolders with a folder",
+			def:  Definition{Conditions: Conditions{FolderID: &folder, IncludeSubfolders: true}, Actions: Actions{MakePublic: true}},
+			want: Definition{Conditions: Conditions{FolderID: &folder, IncludeSubfolders: true}, Actions: Actions{MakePublic: true}},
+		},
+		{name: "no condition", def: Definition{Conditions: Conditions{NameGlob: str(" ")}, Actions: Actions{MakePublic: true}}, wantErr: true},
+		{name: "no action", def: Definition{Conditions: Conditions{NameGlob: str("*")}}, wantErr: true},
+		{name: "invalid MIME type", def: Definition{Conditions: Conditions{MimeTypes: []string{"pdf"}}, Actions: Actions{MakePublic: true}}, wantErr: true},
+		{name: "empty tag", def: Definition{Conditions: Conditions{NameGlob: str("*")}, Actions: Actions{AddTags: []string{" "}}}, wantErr: true},
+		{name: "negative size", def: Definition{Conditions: Conditions{MinSizeBytes: size(-1)}, Actions: Actions{MakePublic: true}}, wantErr: true},
+		{name: "sizes reversed", def: Definition{Conditions: Conditions{MinSizeBytes: size(10), MaxSizeBytes: size(5)}, Actions: Actions{MakePublic: true}}, wantErr: true},
+		{name: "invalid glob", def: Definition{Conditions: Conditions{NameGlob: str("[")}, Actions: Actions{MakePublic: true}}, wantErr: true},
+		{name: "invalid regex", def: Definition{Conditions: Conditions{NameRegex: str("(")}, Actions: Actions{MakePublic: true}}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.def.normalize()
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("normalize succeeded, want an error: %+v", tt.def)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("normalize failed: %v", err)
+			}
+			if !reflect.DeepEqual(tt.def, tt.want) {
+				t.Errorf("normalize made\n%+v\nwant\n%+v", tt.def, tt.want)
+			}
+		})
+	}
+}
+
+// folderRepository answers the folder lookups of a match: the file is inside the
+// folders listed, at any depth.
+type folderRepository struct {
+	Repository
+	ancestors map[uint]bool
+}
+
+func (r *folderRepository) IsInFolder(resourceID uint, folderID uint) (bool, error) {
+	return r.ancestors[folderID], nil
+}
+
+func TestMatch(t *testing.T) {
+	str := func(s string) *string { return &s }
+	size := func(n int64) *int64 { return &n }
+	id := func(n uint) *uint { return &n }
+
+	parent := uint(5)
+	file := &database.Resource{
+		Name:         "Invoice-2025.PDF",
+		Type:         database.File,
+		ParentID:     &parent,
+		PhysicalFile: &database.PhysicalFile{MimeType: "Application/PDF", SizeBytes: 2048},
+	}
+	s := &service{repo: &folderRepository{ancestors: map[uint]bool{5: true, 2: true}}}
+
+	tests := []struct {
+		name       string
+		conditions Conditions
+		want       bool
+	}{
+		{name: "exact MIME type", conditions: Conditions{MimeTypes: []string{"application/pdf"}}, want: true},
+		{name: "MIME family", conditions: Conditions{MimeTypes: []string{"image/*", "application/*"}}, want: true},
+		{name: "other MIME type", conditions: Conditions{MimeTypes: []string{"image/*"}}, want: false},
+		{name: "glob ignores case", conditions: Conditions{NameGlob: str("invoice-*.pdf")}, want: true},
+		{name: "glob matches the whole name", conditions: Conditions{NameGlob: str("invoice")}, want: false},
+		{name: "regex anywhere", conditions: Conditions{NameRegex: str(`\d{4}`)}, want: true},
+		{name: "regex is case-sensitive", conditions: Conditions{NameRegex: str("invoice")}, want: false},
+		{name: "size in range", conditions: Conditions{MinSizeBytes: size(2048), MaxSizeBytes: size(2048)}, want: true},
+		{name: "too small", conditions: Conditions{MinSizeBytes: size(2049)}, want: false},
+		{name: "too large", conditions: Conditions{MaxSizeBytes: size(2047)}, want: false},
+		{name: "parent folder", conditions: Conditions{FolderID: id(5)}, want: true},
+		{name: "ancestor without subfolders", conditions: Conditions{FolderID: id(2)}, want: false},
+		{name: "ancestor with subfolders", conditions: Conditions{FolderID: id(2), IncludeSubfolders: true}, want: true},
+		{name: "other folder", conditions: Conditions{FolderID: id(9), IncludeSubfolders: true}, want: false},
+		{name: "every condition must hold", conditions: Conditions{NameGlob: str("*.pdf"), MaxSizeBytes: size(10)}, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := compile(&tt.conditions)
+			if err != nil {
+				t.Fatalf("compile failed: %v", err)
+			}
+			got, err := m.match(s.newTarget(file))
+			if err != nil {
+				t.Fatalf("match failed: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("match = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
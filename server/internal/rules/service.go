@@ -0,0 +1,499 @@
+package rules
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/bhavyajaix/BalkanID-filevault/internal/database"
+	"github.com/bhavyajaix/BalkanID-filevault/internal/folders"
+	"github.com/bhavyajaix/BalkanID-filevault/internal/indexer"
+	"github.com/bhavyajaix/BalkanID-filevault/internal/middleware"
+	"gorm.io/gorm"
+)
+
+const (
+	// DefaultDryRunLimit is how many matches a dry run lists when the caller doesn't ask.
+	DefaultDryRunLimit = 100
+	// MaxDryRunLimit caps the matches listed by a dry run.
+	MaxDryRunLimit = 500
+	// scanBatchSize is how many files are loaded at a time when scanning a library.
+	scanBatchSize = 200
+	// maxReportedFailures caps the failures listed by a backfill; the count is exact.
+	maxReportedFailures = 100
+)
+
+// ErrNameTaken is returned when the owner already has a rule of that name.
+var ErrNameTaken = errors.New("a rule with this name already exists")
+
+// errNotFound hides other users' rules, so IDs can't be probed.
+var errNotFound = errors.New("rule not found")
+
+// Tagger adds tags to resources. It is satisfied by tag.TagService.
+type Tagger interface {
+	AddTag(ctx context.Context, resourceID uint, tagName string, groupID *uint) (*database.Resource, error)
+}
+
+// Mover moves files between folders. It is satisfied by file.Service.
+type Mover interface {
+	MoveFile(resourceID uint, userID uint, newParentID *uint, strategy folders.ConflictStrategy) (*database.Resource, error)
+}
+
+// AccessChecker decides what a user may do with a resource. It is satisfied by
+// folders.Service.
+type AccessChecker interface {
+	CanRead(userID uint, resource *database.Resource) (bool, error)
+	CanWrite(userID uint, resource *database.Resource) (bool, error)
+}
+
+// Input is a rule as the user writes it.
+type Input struct {
+	Name       string
+	Enabled    bool
+	Conditions Conditions
+	Actions    Actions
+}
+
+// Match is a file a rule applies to.
+type Match struct {
+	Rule     *database.Rule
+	Resource *database.Resource
+}
+
+// Report sums up a backfill.
+type Report struct {
+	Scanned      int
+	Matched      int
+	FailureCount int
+	Failures     []string
+}
+
+// Service is the interface for automation rules.
+type Service interface {
+	Create(ctx context.Context, input Input) (*database.Rule, error)
+	Update(ctx context.Context, id uint, input Input) (*database.Rule, error)
+	Delete(ctx context.Context, id uint) error
+	List(ctx context.Context) ([]database.Rule, error)
+	ApplyToUpload(ctx context.Context, resource *database.Resource) (*database.Resource, error)
+	DryRun(ctx context.Context, ruleID *uint, limit int) ([]Match, error)
+	Apply(ctx context.Context, ruleID *uint) (*Report, error)
+}
+
+type service struct {
+	repo   Repository
+	access AccessChecker
+	tags   Tagger
+	mover  Mover
+}
+
+// NewService creates a new rule service.
+func NewService(repo Repository, access AccessChecker, tags Tagger, mover Mover) Service {
+	return &service{repo: repo, access: access, tags: tags, mover: mover}
+}
+
+func getUserIDFromContext(ctx context.Context) (uint, error) {
+	userID, ok := ctx.Value(middleware.UserContextKey).(uint)
+	if !ok {
+		return 0, errors.New("unauthorized")
+	}
+	return userID, nil
+}
+
+// Create adds a rule to the end of the caller's rules.
+func (s *service) Create(ctx context.Context, input Input) (*database.Rule, error) {
+	userID, err := getUserIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rule := &database.Rule{OwnerID: userID}
+	if err := s.fill(userID, rule, input); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Create(rule); err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return nil, ErrNameTaken
+		}
+		return nil, fmt.Errorf("failed to create rule: %w", err)
+	}
+	return rule, nil
+}
+
+// Update replaces one of the caller's rules. It keeps its place in the order.
+func (s *service) Update(ctx context.Context, id uint, input Input) (*database.Rule, error) {
+	rule, err := s.getOwned(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.fill(rule.OwnerID, rule, input); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Update(rule); err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return nil, ErrNameTaken
+		}
+		return nil, fmt.Errorf("failed to update rule: %w", err)
+	}
+	return rule, nil
+}
+
+func (s *service) Delete(ctx context.Context, id uint) error {
+	rule, err := s.getOwned(ctx, id)
+	if err != nil {
+		return err
+	}
+	return s.repo.Delete(rule.ID)
+}
+
+// List returns the caller's rules in the order they run.
+func (s *service) List(ctx context.Context) ([]database.Rule, error) {
+	userID, err := getUserIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.ListByOwner(userID)
+}
+
+// ApplyToUpload runs the owner's enabled rules on a file they just uploaded and returns
+// it as it ends up. The upload has already been committed, so failing rules are only
+// logged and never fail it.
+func (s *service) ApplyToUpload(ctx context.Context, resource *database.Resource) (*database.Resource, error) {
+	compiled, err := s.enabledRules(resource.OwnerID, nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(compiled) == 0 {
+		return resource, nil
+	}
+
+	f := s.newTarget(resource)
+	for _, rule := range compiled {
+		matched, err := rule.matcher.match(f)
+		if err != nil {
+			log.Printf("rules: could not check rule %d on resource %d: %v", rule.ID, resource.ID, err)
+			continue
+		}
+		if !matched {
+			continue
+		}
+		if err := s.apply(ctx, rule, resource); err != nil {
+			log.Printf("rules: could not apply rule %d to resource %d: %v", rule.ID, resource.ID, err)
+		}
+	}
+	return s.repo.FindResource(resource.ID)
+}
+
+// DryRun lists the caller's files the enabled rules, or the given rule, would apply to,
+// without changing anything. Disabled rules can be tried out by naming them.
+func (s *service) DryRun(ctx context.Context, ruleID *uint, limit int) ([]Match, error) {
+	userID, err := getUserIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if limit <= 0 {
+		limit = DefaultDryRunLimit
+	}
+	if limit > MaxDryRunLimit {
+		limit = MaxDryRunLimit
+	}
+	compiled, err := s.selectRules(ctx, userID, ruleID)
+	if err != nil {
+		return nil, err
+	}
+
+	matches := []Match{}
+	_, err = s.scan(userID, compiled, func(rule *compiledRule, resource *database.Resource) bool {
+		matches = append(matches, Match{Rule: rule.Rule, Resource: resource})
+		return len(matches) < limit
+	})
+	if err != nil {
+		return nil, err
+	}
+	return matches, nil
+}
+
+// Apply runs the enabled rules, or the given rule, on every file the caller already
+// has. Rules only add tags, move and publish, so running them again is harmless.
+func (s *service) Apply(ctx context.Context, ruleID *uint) (*Report, error) {
+	userID, err := getUserIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	compiled, err := s.selectRules(ctx, userID, ruleID)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &Report{Failures: []string{}}
+	report.Scanned, err = s.scan(userID, compiled, func(rule *compiledRule, resource *database.Resource) bool {
+		report.Matched++
+		if err := s.apply(ctx, rule, resource); err != nil {
+			report.FailureCount++
+			if len(report.Failures) < maxReportedFailures {
+				report.Failures = append(report.Failures, fmt.Sprintf("rule %q on %q: %v", rule.Name, resource.Name, err))
+			}
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// compiledRule is a rule ready to be checked against files.
+type compiledRule struct {
+	*database.Rule
+	def     Definition
+	matcher *matcher
+}
+
+// selectRules returns the rule the caller named, which must be theirs, or all their
+// enabled rules.
+func (s *service) selectRules(ctx context.Context, userID uint, ruleID *uint) ([]*compiledRule, error) {
+	if ruleID == nil {
+		return s.enabledRules(userID, nil)
+	}
+	rule, err := s.getOwned(ctx, *ruleID)
+	if err != nil {
+		return nil, err
+	}
+	return s.enabledRules(userID, rule)
+}
+
+// enabledRules compiles the user's enabled rules, or only the given one whether it is
+// enabled or not.
+func (s *service) enabledRules(userID uint, only *database.Rule) ([]*compiledRule, error) {
+	var rules []database.Rule
+	if only != nil {
+		rules = []database.Rule{*only}
+	} else {
+		all, err := s.repo.ListByOwner(userID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load rules: %w", err)
+		}
+		for _, rule := range all {
+			if rule.Enabled {
+				rules = append(rules, rule)
+			}
+		}
+	}
+
+	compiled := make([]*compiledRule, 0, len(rules))
+	for i := range rules {
+		def, err := Decode(&rules[i])
+		if err != nil {
+			return nil, err
+		}
+		c := &compiledRule{Rule: &rules[i], def: def}
+		if c.matcher, err = compile(&c.def.Conditions); err != nil {
+			return nil, fmt.Errorf("rule %d: %w", rules[i].ID, err)
+		}
+		compiled = append(compiled, c)
+	}
+	return compiled, nil
+}
+
+// scan checks every file the user owns against the rules, calling found for each match
+// until it returns false. It returns the number of files checked.
+func (s *service) scan(userID uint, compiled []*compiledRule, found func(*compiledRule, *database.Resource) bool) (int, error) {
+	scanned := 0
+	if len(compiled) == 0 {
+		return scanned, nil
+	}
+	var afterID uint
+	for {
+		resources, err := s.repo.ListOwnedFiles(userID, afterID, scanBatchSize)
+		if err != nil {
+			return scanned, fmt.Errorf("failed to list files: %w", err)
+		}
+		for i := range resources {
+			resource := &resources[i]
+			afterID = resource.ID
+			scanned++
+
+			f := s.newTarget(resource)
+			for _, rule := range compiled {
+				matched, err := rule.matcher.match(f)
+				if err != nil {
+					return scanned, fmt.Errorf("failed to check rule %q on %q: %w", rule.Name, resource.Name, err)
+				}
+				if matched && !found(rule, resource) {
+					return scanned, nil
+				}
+			}
+		}
+		if len(resources) < scanBatchSize {
+			return scanned, nil
+		}
+	}
+}
+
+// apply carries out a rule's actions on a file, as its owner, and updates the resource
+// to match.
+func (s *service) apply(ctx context.Context, rule *compiledRule, resource *database.Resource) error {
+	actions := rule.def.Actions
+	for _, name := range actions.AddTags {
+		if _, err := s.tags.AddTag(ctx, resource.ID, name, nil); err != nil {
+			return fmt.Errorf("failed to add tag %q: %w", name, err)
+		}
+	}
+	if actions.MakePublic && !resource.IsPublic {
+		if err := s.repo.MakePublic(resource.ID); err != nil {
+			return fmt.Errorf("failed to make public: %w", err)
+		}
+		resource.IsPublic = true
+	}
+	if target := actions.MoveToFolderID; target != nil && (resource.ParentID == nil || *resource.ParentID != *target) {
+		moved, err := s.mover.MoveFile(resource.ID, rule.OwnerID, target, folders.ConflictRename)
+		if err != nil {
+			return fmt.Errorf("failed to move: %w", err)
+		}
+		// The rules after this one see the file where it is now, under its new name.
+		resource.ParentID, resource.Name = moved.ParentID, moved.Name
+	}
+	return nil
+}
+
+// fill validates the input and copies it onto the rule. The folders it names must be
+// ones the owner can read, or write to for moves.
+func (s *service) fill(userID uint, rule *database.Rule, input Input) error {
+	name := strings.TrimSpace(input.Name)
+	if name == "" {
+		return errors.New("name cannot be empty")
+	}
+	if len(name) > 255 {
+		return errors.New("name cannot be longer than 255 characters")
+	}
+
+	def := Definition{Conditions: input.Conditions, Actions: input.Actions}
+	if err := def.normalize(); err != nil {
+		return err
+	}
+	if id := def.Conditions.FolderID; id != nil {
+		if err := s.checkFolder(userID, *id, false); err != nil {
+			return err
+		}
+	}
+	if id := def.Actions.MoveToFolderID; id != nil {
+		if err := s.checkFolder(userID, *id, true); err != nil {
+			return err
+		}
+	}
+
+	conditions, err := json.Marshal(def.Conditions)
+	if err != nil {
+		return fmt.Errorf("failed to encode conditions: %w", err)
+	}
+	actions, err := json.Marshal(def.Actions)
+	if err != nil {
+		return fmt.Errorf("failed to encode actions: %w", err)
+	}
+	rule.Name = name
+	rule.Enabled = input.Enabled
+	rule.Conditions = string(conditions)
+	rule.Actions = string(actions)
+	return nil
+}
+
+func (s *service) checkFolder(userID uint, folderID uint, write bool) error {
+	folder, err := s.repo.FindResource(folderID)
+	if err != nil {
+		return errors.New("folder not found")
+	}
+	if folder.Type != database.Folder {
+		return fmt.Errorf("resource %d is not a folder", folderID)
+	}
+	allowed, err := s.access.CanRead(userID, folder)
+	if err == nil && allowed && write {
+		allowed, err = s.access.CanWrite(userID, folder)
+	}
+	if err != nil {
+		return fmt.Errorf("error checking permissions: %w", err)
+	}
+	if !allowed {
+		return errors.New("access denied")
+	}
+	return nil
+}
+
+func (s *service) getOwned(ctx context.Context, id uint) (*database.Rule, error) {
+	userID, err := getUserIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	rule, err := s.repo.FindByID(id)
+	if err != nil || rule.OwnerID != userID {
+		return nil, errNotFound
+	}
+	return rule, nil
+}
+
+// target is a file being checked against rules. Its location and text are looked up
+// the first time a rule needs them.
+type target struct {
+	repo     Repository
+	resource *database.Resource
+	folders  map[folderKey]bool
+	content  *string
+}
+
+// folderKey is a folder looked up for the file while it was in some parent, 0 being
+// the root. A rule can move the file before the next one is checked.
+type folderKey struct {
+	folderID, parentID uint
+}
+
+func (s *service) newTarget(resource *database.Resource) *target {
+	return &target{repo: s.repo, resource: resource, folders: map[folderKey]bool{}}
+}
+
+func (t *target) inFolder(folderID uint, includeSubfolders bool) (bool, error) {
+	if t.resource.ParentID != nil && *t.resource.ParentID == folderID {
+		return true, nil
+	}
+	if !includeSubfolders {
+		return false, nil
+	}
+	key := folderKey{folderID: folderID}
+	if t.resource.ParentID != nil {
+		key.parentID = *t.resource.ParentID
+	}
+	if inside, ok := t.folders[key]; ok {
+		return inside, nil
+	}
+	inside, err := t.repo.IsInFolder(t.resource.ID, folderID)
+	if err != nil {
+		return false, err
+	}
+	t.folders[key] = inside
+	return inside, nil
+}
+
+// text returns the file's text, preferably as indexed for search. Files the indexer
+// hasn't reached yet, such as new uploads, are read from storage. Files without text
+// have none, which is not an error.
+func (t *target) text() (string, error) {
+	if t.content != nil {
+		return *t.content, nil
+	}
+	pf := t.resource.PhysicalFile
+	if pf == nil {
+		return "", nil
+	}
+	text, ok, err := t.repo.IndexedContent(pf.ID)
+	if err != nil {
+		return "", err
+	}
+	if !ok {
+		if text, err = indexer.ExtractFile(pf); err != nil {
+			text = ""
+		}
+	}
+	t.content = &text
+	return text, nil
+}
@@ -0,0 +1,114 @@
+package rules
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/bhavyajaix/BalkanID-filevault/internal/database"
+	"github.com/bhavyajaix/BalkanID-filevault/internal/folders"
+	"gorm.io/gorm"
+)
+
+// memoryRepository holds one user's rules, a file and the folders it can be moved
+// between. Only the queries ApplyToUpload uses are kept.
+type memoryRepository struct {
+	Repository
+	rules   []database.Rule
+	file    database.Resource
+	parents map[uint]uint // folder ID to parent ID, 0 being the root
+}
+
+func (r *memoryRepository) ListByOwner(ownerID uint) ([]database.Rule, error) {
+	return r.rules, nil
+}
+
+func (r *memoryRepository) FindResource(id uint) (*database.Resource, error) {
+	if id != r.file.ID {
+		return nil, gorm.ErrRecordNotFound
+	}
+	file := r.file
+	return &file, nil
+}
+
+func (r *memoryRepository) IsInFolder(resourceID uint, folderID uint) (bool, error) {
+	for parent := r.file.ParentID; parent != nil; {
+		if *parent == folderID {
+			return true, nil
+		}
+		next, ok := r.parents[*parent]
+		if !ok || next == 0 {
+			return false, nil
+		}
+		parent = &next
+	}
+	return false, nil
+}
+
+func (r *memoryRepository) MakePublic(resourceID uint) error {
+	r.file.IsPublic = true
+	return nil
+}
+
+// MoveFile makes memoryRepository a Mover as well.
+func (r *memoryRepository) MoveFile(resourceID uint, userID uint, newParentID *uint, strategy folders.ConflictStrategy) (*database.Resource, error) {
+	parentID := *newParentID
+	r.file.ParentID = &parentID
+	return r.FindResource(resourceID)
+}
+
+func newRule(t *testing.T, id uint, conditions Conditions, actions Actions) database.Rule {
+	t.Helper()
+	c, err := json.Marshal(conditions)
+	if err != nil {
+		t.Fatalf("failed to encode conditions: %v", err)
+	}
+	a, err := json.Marshal(actions)
+	if err != nil {
+		t.Fatalf("failed to encode actions: %v", err)
+	}
+	rule := database.Rule{OwnerID: 1, Name: "rule", Enabled: true, Conditions: string(c), Actions: string(a)}
+	rule.ID = id
+	return rule
+}
+
+// TestApplyToUploadAfterMove checks that the rules after one that moves a file see it
+// in its new folder.
+func TestApplyToUploadAfterMove(t *testing.T) {
+	const archive, inbox, done = 30, 31, 40
+	ptr := func(id uint) *uint { return &id }
+	glob := "*.pdf"
+
+	repo := &memoryRepository{
+		parents: map[uint]uint{archive: 0, inbox: archive, done: 0},
+		file: database.Resource{
+			OwnerID:      1,
+			Name:         "report.pdf",
+			Type:         database.File,
+			PhysicalFile: &database.PhysicalFile{MimeType: "application/pdf", SizeBytes: 10},
+		},
+	}
+	repo.file.ID = 7
+	repo.rules = []database.Rule{
+		// Checked before the move, while the file is not in the archive.
+		newRule(t, 1, Conditions{FolderID: ptr(archive), IncludeSubfolders: true}, Actions{MakePublic: true}),
+		newRule(t, 2, Conditions{NameGlob: &glob}, Actions{MoveToFolderID: ptr(inbox)}),
+		newRule(t, 3, Conditions{FolderID: ptr(archive), IncludeSubfolders: true}, Actions{MakePublic: true}),
+		newRule(t, 4, Conditions{FolderID: ptr(inbox)}, Actions{MoveToFolderID: ptr(done)}),
+	}
+
+	upload := repo.file
+	s := NewService(repo, nil, nil, repo)
+	result, err := s.ApplyToUpload(context.Background(), &upload)
+	if err != nil {
+		t.Fatalf("ApplyToUpload failed: %v", err)
+	}
+	if result.ParentID == nil {
+		t.Errorf("file ended up in the root, want folder %d", done)
+	} else if *result.ParentID != done {
+		t.Errorf("file ended up in folder %d, want %d", *result.ParentID, done)
+	}
+	if !result.IsPublic {
+		t.Error("file was not made public by the rule matching its new folder")
+	}
+}
@@ -0,0 +1,87 @@
+package savedsearch
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/bhavyajaix/BalkanID-filevault/internal/search"
+)
+
+// Filters is the stored form of a search: the searchResources filters as the client
+// sent them. Dates and the query are kept as text, so relative dates such as
+// modified:<90d are evaluated afresh every time the search runs.
+type Filters struct {
+	Name           *string          `json:"name,omitempty"`
+	NameSimilarity *float64         `json:"nameSimilarity,omitempty"`
+	Types          []string         `json:"types,omitempty"`
+	MimeTypes      []string         `json:"mimeTypes,omitempty"`
+	MinSizeBytes   *int64           `json:"minSizeBytes,omitempty"`
+	MaxSizeBytes   *int64           `json:"maxSizeBytes,omitempty"`
+	AfterDate      *string          `json:"afterDate,omitempty"`
+	BeforeDate     *string          `json:"beforeDate,omitempty"`
+	Tags           []string         `json:"tags,omitempty"`
+	UploaderName   *string          `json:"uploaderName,omitempty"`
+	FolderID       *uint            `json:"folderId,omitempty"`
+	Query          *string          `json:"query,omitempty"`
+	Metadata       []MetadataFilter `json:"metadata,omitempty"`
+}
+
+// MetadataFilter is the stored form of a search.MetadataFilter.
+type MetadataFilter struct {
+	FieldID uint    `json:"fieldId"`
+	Op      string  `json:"op"`
+	Value   *string `json:"value,omitempty"`
+}
+
+// Resolve turns the filters into a search, parsing dates and the query. Errors are
+// *search.FilterError or *search.ParseError values.
+func (f Filters) Resolve() (search.SearchFilters, error) {
+	filters := search.SearchFilters{
+		Name:           f.Name,
+		NameSimilarity: f.NameSimilarity,
+		Types:          f.Types,
+		MimeTypes:      f.MimeTypes,
+		MinSizeBytes:   f.MinSizeBytes,
+		MaxSizeBytes:   f.MaxSizeBytes,
+		Tags:           f.Tags,
+		UploaderName:   f.UploaderName,
+		FolderID:       f.FolderID,
+		Query:          f.Query,
+	}
+	for _, m := range f.Metadata {
+		filters.Metadata = append(filters.Metadata, search.MetadataFilter{FieldID: m.FieldID, Op: search.MetadataOp(m.Op), Value: m.Value})
+	}
+	if f.AfterDate != nil {
+		after, _, err := search.ParseFilterDate("afterDate", *f.AfterDate)
+		if err != nil {
+			return filters, err
+		}
+		filters.AfterDate = &after
+	}
+	if f.BeforeDate != nil {
+		before, dateOnly, err := search.ParseFilterDate("beforeDate", *f.BeforeDate)
+		if err != nil {
+			return filters, err
+		}
+		if dateOnly {
+			// A bare date includes the whole day, as in the query language.
+			before = before.AddDate(0, 0, 1)
+		}
+		filters.BeforeDate = &before
+	}
+	if f.Query != nil {
+		if _, err := search.ParseQuery(*f.Query); err != nil {
+			return filters, err
+		}
+	}
+	return filters, nil
+}
+
+// Decode reads the filters stored on a saved search.
+func Decode(stored string) (Filters, error) {
+	var f Filters
+	if err := json.Unmarshal([]byte(stored), &f); err != nil {
+		return f, fmt.Errorf("failed to decode saved filters: %w", err)
+	}
+	return f, nil
+}
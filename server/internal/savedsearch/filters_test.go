@@ -0,0 +1,68 @@
+package savedsearch
+
+import (
+	"testing"
+	"time"
+)
+
+func TestResolveDates(t *testing.T) {
+	date := func(s string) *time.Time {
+		at, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			t.Fatalf("bad test date %q: %v", s, err)
+		}
+		return &at
+	}
+	str := func(s string) *string { return &s }
+
+	tests := []struct {
+		name       string
+		filters    Filters
+		wantAfter  *time.Time
+		wantBefore *time.Time
+		wantErr    bool
+	}{
+		{
+			name:       "bare dates cover the whole day",
+			filters:    Filters{AfterDate: str("2025-01-01"), BeforeDate: str("2025-01-31")},
+			wantAfter:  date("2025-01-01T00:00:00Z"),
+			wantBefore: date("2025-02-01T00:00:00Z"),
+		},
+		{
+			name:       "timestamps are exact",
+			filters:    Filters{AfterDate: str("2025-01-01T08:00:00Z"), BeforeDate: str("2025-01-31T17:30:00+02:00")},
+			wantAfter:  date("2025-01-01T08:00:00Z"),
+			wantBefore: date("2025-01-31T15:30:00Z"),
+		},
+		{name: "no dates", filters: Filters{}},
+		{name: "invalid after", filters: Filters{AfterDate: str("yesterday")}, wantErr: true},
+		{name: "invalid before", filters: Filters{BeforeDate: str("2025-13-01")}, wantErr: true},
+		{name: "invalid query", filters: Filters{Query: str("(unclosed")}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.filters.Resolve()
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("Resolve succeeded, want an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Resolve failed: %v", err)
+			}
+			for _, check := range []struct {
+				name      string
+				got, want *time.Time
+			}{{"after", got.AfterDate, tt.wantAfter}, {"before", got.BeforeDate, tt.wantBefore}} {
+				switch {
+				case check.got == nil && check.want == nil:
+				case check.got == nil || check.want == nil:
+					t.Errorf("%s date = %v, want %v", check.name, check.got, check.want)
+				case !check.got.Equal(*check.want):
+					t.Errorf("%s date = %v, want %v", check.name, *check.got, *check.want)
+				}
+			}
+		})
+	}
+}
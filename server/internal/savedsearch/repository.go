@@ -0,0 +1,117 @@
+package savedsearch
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/bhavyajaix/BalkanID-filevault/internal/database"
+	"github.com/bhavyajaix/BalkanID-filevault/internal/metadata"
+	"gorm.io/gorm"
+)
+
+// Repository is the interface for saved searches and their shares.
+type Repository interface {
+	Create(saved *database.SavedSearch) error
+	FindByID(id uint) (*database.SavedSearch, error)
+	LoadShares(saved *database.SavedSearch) error
+	IsSharedWith(savedID uint, userID uint) (bool, error)
+	ListVisible(userID uint) ([]database.SavedSearch, error)
+	HiddenFields(fieldIDs []uint, userID uint) ([]uint, error)
+	Delete(id uint) error
+	Share(saved *database.SavedSearch, user *database.User) error
+	Unshare(saved *database.SavedSearch, user *database.User) error
+}
+
+type repository struct {
+	db *gorm.DB
+}
+
+// NewRepository creates a new saved search repository.
+func NewRepository(db *gorm.DB) Repository {
+	return &repository{db: db}
+}
+
+func (r *repository) Create(saved *database.SavedSearch) error {
+	return r.db.Omit("SharedWith").Create(saved).Error
+}
+
+// FindByID fetches a saved search with its owner. The users it is shared with are
+// only loaded for the owner, by LoadShares.
+func (r *repository) FindByID(id uint) (*database.SavedSearch, error) {
+	var saved database.SavedSearch
+	if err := r.db.Preload("Owner").First(&saved, id).Error; err != nil {
+		return nil, err
+	}
+	return &saved, nil
+}
+
+// LoadShares fills in the users a saved search is shared with.
+func (r *repository) LoadShares(saved *database.SavedSearch) error {
+	saved.SharedWith = nil
+	return r.db.Model(saved).Association("SharedWith").Find(&saved.SharedWith)
+}
+
+func (r *repository) IsSharedWith(savedID uint, userID uint) (bool, error) {
+	var count int64
+	err := r.db.Table("saved_search_shares").Where("saved_search_id = ? AND user_id = ?", savedID, userID).Count(&count).Error
+	return count > 0, err
+}
+
+// ListVisible returns the user's own saved searches and those shared with them, by name.
+// Only the user's own come with the users they are shared with.
+func (r *repository) ListVisible(userID uint) ([]database.SavedSearch, error) {
+	var owned, shared []database.SavedSearch
+	if err := r.db.Preload("Owner").Preload("SharedWith").Where("owner_id = ?", userID).Find(&owned).Error; err != nil {
+		return nil, err
+	}
+	if err := r.db.Preload("Owner").
+		Where("id IN (SELECT saved_search_id FROM saved_search_shares WHERE user_id = ?)", userID).
+		Find(&shared).Error; err != nil {
+		return nil, err
+	}
+
+	searches := append(owned, shared...)
+	sort.SliceStable(searches, func(i, j int) bool {
+		a, b := strings.ToLower(searches[i].Name), strings.ToLower(searches[j].Name)
+		if a != b {
+			return a < b
+		}
+		return searches[i].ID < searches[j].ID
+	})
+	return searches, nil
+}
+
+// HiddenFields returns those of the metadata fields that the user can't see.
+func (r *repository) HiddenFields(fieldIDs []uint, userID uint) ([]uint, error) {
+	var visible []uint
+	if err := r.db.Model(&database.MetadataField{}).
+		Scopes(metadata.VisibleTo(userID)).
+		Where("metadata_fields.id IN ?", fieldIDs).
+		Pluck("metadata_fields.id", &visible).Error; err != nil {
+		return nil, err
+	}
+	isVisible := make(map[uint]bool, len(visible))
+	for _, id := range visible {
+		isVisible[id] = true
+	}
+	var hidden []uint
+	for _, id := range fieldIDs {
+		if !isVisible[id] {
+			hidden = append(hidden, id)
+		}
+	}
+	return hidden, nil
+}
+
+// Delete removes a saved search for good; the shares go with it.
+func (r *repository) Delete(id uint) error {
+	return r.db.Unscoped().Delete(&database.SavedSearch{}, id).Error
+}
+
+func (r *repository) Share(saved *database.SavedSearch, user *database.User) error {
+	return r.db.Model(saved).Association("SharedWith").Append(user)
+}
+
+func (r *repository) Unshare(saved *database.SavedSearch, user *database.User) error {
+	return r.db.Model(saved).Association("SharedWith").Delete(user)
+}
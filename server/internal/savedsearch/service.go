@@ -0,0 +1,219 @@
+package savedsearch
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/bhavyajaix/BalkanID-filevault/internal/database"
+	"github.com/bhavyajaix/BalkanID-filevault/internal/middleware"
+	"github.com/bhavyajaix/BalkanID-filevault/internal/user"
+	"gorm.io/gorm"
+)
+
+// ErrNameTaken is returned when the owner already has a saved search of that name.
+var ErrNameTaken = errors.New("a saved search with this name already exists")
+
+// errNotFound hides saved searches the caller may not see, so IDs can't be probed.
+var errNotFound = errors.New("saved search not found")
+
+// Service is the interface for saved searches.
+type Service interface {
+	Save(ctx context.Context, name string, filters Filters) (*database.SavedSearch, error)
+	Get(ctx context.Context, id uint) (*database.SavedSearch, error)
+	List(ctx context.Context) ([]database.SavedSearch, error)
+	Delete(ctx context.Context, id uint) error
+	Share(ctx context.Context, id uint, email string) (*database.SavedSearch, error)
+	Unshare(ctx context.Context, id uint, email string) (*database.SavedSearch, error)
+	Filters(ctx context.Context, id uint) (Filters, error)
+}
+
+type service struct {
+	repo     Repository
+	userRepo user.Repository
+}
+
+// NewService creates a new saved search service.
+func NewService(repo Repository, userRepo user.Repository) Service {
+	return &service{repo: repo, userRepo: userRepo}
+}
+
+func getUserIDFromContext(ctx context.Context) (uint, error) {
+	userID, ok := ctx.Value(middleware.UserContextKey).(uint)
+	if !ok {
+		return 0, errors.New("unauthorized")
+	}
+	return userID, nil
+}
+
+// Save stores the filters under a name in the caller's saved searches. The filters are
+// validated now, so a saved search can't fail to parse later.
+func (s *service) Save(ctx context.Context, name string, filters Filters) (*database.SavedSearch, error) {
+	userID, err := getUserIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, errors.New("name cannot be empty")
+	}
+	if len(name) > 255 {
+		return nil, errors.New("name cannot be longer than 255 characters")
+	}
+	if _, err := filters.Resolve(); err != nil {
+		return nil, err
+	}
+	encoded, err := json.Marshal(filters)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode filters: %w", err)
+	}
+
+	saved := &database.SavedSearch{OwnerID: userID, Name: name, Filters: string(encoded)}
+	if err := s.repo.Create(saved); err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return nil, ErrNameTaken
+		}
+		return nil, fmt.Errorf("failed to save search: %w", err)
+	}
+	return s.repo.FindByID(saved.ID)
+}
+
+// Get returns a saved search the caller owns or that is shared with them.
+func (s *service) Get(ctx context.Context, id uint) (*database.SavedSearch, error) {
+	userID, err := getUserIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	saved, err := s.repo.FindByID(id)
+	if err != nil {
+		return nil, errNotFound
+	}
+	if saved.OwnerID == userID {
+		return s.withShares(saved)
+	}
+	if shared, err := s.repo.IsSharedWith(saved.ID, userID); err != nil || !shared {
+		return nil, errNotFound
+	}
+	return saved, nil
+}
+
+func (s *service) List(ctx context.Context) ([]database.SavedSearch, error) {
+	userID, err := getUserIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.ListVisible(userID)
+}
+
+// Delete removes one of the caller's saved searches, unsharing it from everyone.
+func (s *service) Delete(ctx context.Context, id uint) error {
+	saved, err := s.getOwned(ctx, id)
+	if err != nil {
+		return err
+	}
+	return s.repo.Delete(saved.ID)
+}
+
+// Share lets another user see and run one of the caller's saved searches, read-only.
+func (s *service) Share(ctx context.Context, id uint, email string) (*database.SavedSearch, error) {
+	saved, err := s.getOwned(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	target, err := s.userRepo.GetUserByEmail(email)
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+	if target.ID == saved.OwnerID {
+		return nil, errors.New("cannot share a saved search with its owner")
+	}
+	if err := s.checkFieldsVisible(saved, target); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Share(saved, target); err != nil {
+		return nil, fmt.Errorf("failed to share saved search: %w", err)
+	}
+	return s.withShares(saved)
+}
+
+// Unshare takes a user's access to one of the caller's saved searches away.
+func (s *service) Unshare(ctx context.Context, id uint, email string) (*database.SavedSearch, error) {
+	saved, err := s.getOwned(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	target, err := s.userRepo.GetUserByEmail(email)
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+	if err := s.repo.Unshare(saved, target); err != nil {
+		return nil, fmt.Errorf("failed to unshare saved search: %w", err)
+	}
+	return s.withShares(saved)
+}
+
+// Filters returns the filters of a saved search the caller can see. They are run
+// through search.Service like any other search, so they are evaluated under the
+// caller's own permissions, not the owner's, and sharing a search never reveals the
+// owner's files.
+func (s *service) Filters(ctx context.Context, id uint) (Filters, error) {
+	saved, err := s.Get(ctx, id)
+	if err != nil {
+		return Filters{}, err
+	}
+	return Decode(saved.Filters)
+}
+
+func (s *service) getOwned(ctx context.Context, id uint) (*database.SavedSearch, error) {
+	userID, err := getUserIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	saved, err := s.repo.FindByID(id)
+	if err != nil {
+		return nil, errNotFound
+	}
+	if saved.OwnerID != userID {
+		if shared, err := s.repo.IsSharedWith(saved.ID, userID); err == nil && shared {
+			return nil, errors.New("access denied: saved search is shared read-only")
+		}
+		return nil, errNotFound
+	}
+	return saved, nil
+}
+
+// withShares loads the users a saved search is shared with, for its owner.
+func (s *service) withShares(saved *database.SavedSearch) (*database.SavedSearch, error) {
+	if err := s.repo.LoadShares(saved); err != nil {
+		return nil, fmt.Errorf("failed to load shares: %w", err)
+	}
+	return saved, nil
+}
+
+// checkFieldsVisible refuses to share a search filtering on metadata fields the
+// recipient can't see, such as the owner's personal fields: running it would fail for
+// them, and its filters would reveal the fields' values.
+func (s *service) checkFieldsVisible(saved *database.SavedSearch, target *database.User) error {
+	filters, err := Decode(saved.Filters)
+	if err != nil {
+		return err
+	}
+	if len(filters.Metadata) == 0 {
+		return nil
+	}
+	ids := make([]uint, len(filters.Metadata))
+	for i, m := range filters.Metadata {
+		ids[i] = m.FieldID
+	}
+	hidden, err := s.repo.HiddenFields(ids, target.ID)
+	if err != nil {
+		return fmt.Errorf("failed to check metadata fields: %w", err)
+	}
+	if len(hidden) > 0 {
+		return fmt.Errorf("cannot share a saved search filtering on metadata fields %s cannot see; use fields of a group you both belong to", target.Username)
+	}
+	return nil
+}
@@ -0,0 +1,135 @@
+package savedsearch
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/bhavyajaix/BalkanID-filevault/internal/database"
+	"github.com/bhavyajaix/BalkanID-filevault/internal/middleware"
+	"github.com/bhavyajaix/BalkanID-filevault/internal/user"
+	"gorm.io/gorm"
+)
+
+// memoryRepository holds saved searches and their shares. visibleFields lists the
+// metadata fields each user can see.
+type memoryRepository struct {
+	Repository
+	searches      map[uint]*database.SavedSearch
+	shares        map[uint][]database.User
+	visibleFields map[uint][]uint
+}
+
+func (r *memoryRepository) FindByID(id uint) (*database.SavedSearch, error) {
+	saved, ok := r.searches[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	found := *saved
+	return &found, nil
+}
+
+func (r *memoryRepository) LoadShares(saved *database.SavedSearch) error {
+	saved.SharedWith = append([]database.User{}, r.shares[saved.ID]...)
+	return nil
+}
+
+func (r *memoryRepository) IsSharedWith(savedID uint, userID uint) (bool, error) {
+	for _, u := range r.shares[savedID] {
+		if u.ID == userID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *memoryRepository) Share(saved *database.SavedSearch, u *database.User) error {
+	r.shares[saved.ID] = append(r.shares[saved.ID], *u)
+	return nil
+}
+
+func (r *memoryRepository) HiddenFields(fieldIDs []uint, userID uint) ([]uint, error) {
+	var hidden []uint
+	for _, id := range fieldIDs {
+		visible := false
+		for _, v := range r.visibleFields[userID] {
+			visible = visible || v == id
+		}
+		if !visible {
+			hidden = append(hidden, id)
+		}
+	}
+	return hidden, nil
+}
+
+type memoryUsers struct {
+	user.Repository
+	users []database.User
+}
+
+func (r *memoryUsers) GetUserByEmail(email string) (*database.User, error) {
+	for i := range r.users {
+		if r.users[i].Email == email {
+			return &r.users[i], nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func TestSharing(t *testing.T) {
+	const owner, viewer, stranger = 1, 2, 3
+	as := func(userID uint) context.Context {
+		return context.WithValue(context.Background(), middleware.UserContextKey, userID)
+	}
+	newUser := func(id uint, name string) database.User {
+		u := database.User{Username: name, Email: name + "@example.com"}
+		u.ID = id
+		return u
+	}
+	users := &memoryUsers{users: []database.User{newUser(owner, "owner"), newUser(viewer, "viewer"), newUser(stranger, "stranger")}}
+
+	// Field 10 is the owner's own, 20 belongs to a group the viewer is in too.
+	withField := func(id, fieldID uint) *database.SavedSearch {
+		filters, err := json.Marshal(Filters{Metadata: []MetadataFilter{{FieldID: fieldID, Op: "EXISTS"}}})
+		if err != nil {
+			t.Fatalf("failed to encode filters: %v", err)
+		}
+		saved := &database.SavedSearch{OwnerID: owner, Name: "search", Filters: string(filters)}
+		saved.ID = id
+		return saved
+	}
+	repo := &memoryRepository{
+		searches:      map[uint]*database.SavedSearch{1: withField(1, 20), 2: withField(2, 10)},
+		shares:        map[uint][]database.User{},
+		visibleFields: map[uint][]uint{owner: {10, 20}, viewer: {20}},
+	}
+	s := NewService(repo, users)
+
+	if _, err := s.Share(as(owner), 1, "viewer@example.com"); err != nil {
+		t.Fatalf("failed to share a search on a group field: %v", err)
+	}
+	if _, err := s.Share(as(owner), 2, "viewer@example.com"); err == nil {
+		t.Error("shared a search filtering on the owner's own field")
+	}
+
+	mine, err := s.Get(as(owner), 1)
+	if err != nil {
+		t.Fatalf("owner failed to get the search: %v", err)
+	}
+	if len(mine.SharedWith) != 1 || mine.SharedWith[0].ID != viewer {
+		t.Errorf("owner sees the search shared with %v, want the viewer", mine.SharedWith)
+	}
+	theirs, err := s.Get(as(viewer), 1)
+	if err != nil {
+		t.Fatalf("viewer failed to get the shared search: %v", err)
+	}
+	if len(theirs.SharedWith) != 0 {
+		t.Errorf("viewer sees who the search is shared with: %v", theirs.SharedWith)
+	}
+	if _, err := s.Get(as(stranger), 1); err == nil {
+		t.Error("a user the search isn't shared with can get it")
+	}
+	if _, err := s.Share(as(viewer), 1, "stranger@example.com"); err == nil {
+		t.Error("a user the search is shared with can share it on")
+	}
+}
@@ -0,0 +1,120 @@
+package search
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/bhavyajaix/BalkanID-filevault/internal/tag"
+	"gorm.io/gorm"
+)
+
+// compiledQuery is a parsed query translated into a WHERE condition over resources
+// joined with physical_files and file_contents.
+type compiledQuery struct {
+	where string
+	args  []interface{}
+	// tsquery ORs together the query's positive text terms, for ranking and
+	// highlighting. It is empty when the query has none.
+	tsquery     string
+	tsqueryArgs []interface{}
+}
+
+type compiler struct {
+	db       *gorm.DB
+	viewerID uint
+	out      compiledQuery
+}
+
+func compileQuery(db *gorm.DB, expr Expr, viewerID uint) compiledQuery {
+	c := &compiler{db: db, viewerID: viewerID}
+	c.out.where = c.compile(expr, false)
+	return c.out
+}
+
+// compile returns the condition for expr, appending its arguments. negated tells
+// whether expr sits under an odd number of NOTs, whose text terms don't count for
+// ranking.
+func (c *compiler) compile(expr Expr, negated bool) string {
+	switch e := expr.(type) {
+	case AndExpr:
+		return "(" + c.compile(e.Left, negated) + " AND " + c.compile(e.Right, negated) + ")"
+	case OrExpr:
+		return "(" + c.compile(e.Left, negated) + " OR " + c.compile(e.Right, negated) + ")"
+	case NotExpr:
+		// Columns of folders and unindexed files are NULL; NOT must still match them.
+		return "(" + c.compile(e.Operand, !negated) + ") IS NOT TRUE"
+	case TextExpr:
+		return c.text(e, negated)
+	case FieldExpr:
+		return c.field(e)
+	}
+	panic(fmt.Sprintf("search: unexpected expression %T", expr))
+}
+
+func (c *compiler) text(e TextExpr, negated bool) string {
+	tsquery := "plainto_tsquery('english', ?)"
+	if e.Phrase {
+		tsquery = "phraseto_tsquery('english', ?)"
+	}
+	if !negated {
+		if c.out.tsquery != "" {
+			c.out.tsquery += " || "
+		}
+		c.out.tsquery += tsquery
+		c.out.tsqueryArgs = append(c.out.tsqueryArgs, e.Text)
+	}
+	c.out.args = append(c.out.args, e.Text, e.Text)
+	return "(file_contents.search_vector @@ " + tsquery + " OR to_tsvector('english', resources.name) @@ " + tsquery + ")"
+}
+
+func (c *compiler) field(e FieldExpr) string {
+	switch e.Field {
+	case FieldType:
+		c.out.args = append(c.out.args, e.Value)
+		return "resources.type = ?"
+	case FieldMimeType:
+		value := strings.ToLower(e.Value.(string))
+		if strings.HasSuffix(value, "/*") {
+			c.out.args = append(c.out.args, escapeLike(strings.TrimSuffix(value, "*"))+"%")
+			return "lower(physical_files.mime_type) LIKE ?"
+		}
+		c.out.args = append(c.out.args, value)
+		return "lower(physical_files.mime_type) = ?"
+	case FieldName:
+		c.out.args = append(c.out.args, e.Value)
+		return nameContains
+	case FieldExt:
+		c.out.args = append(c.out.args, "%."+escapeLike(e.Value.(string)))
+		return "resources.name ILIKE ?"
+	case FieldOwner:
+		if strings.EqualFold(e.Value.(string), "me") {
+			c.out.args = append(c.out.args, c.viewerID)
+			return "resources.owner_id = ?"
+		}
+		c.out.args = append(c.out.args, e.Value, e.Value)
+		return "resources.owner_id IN (SELECT id FROM users WHERE lower(username) = lower(?) OR lower(email) = lower(?))"
+	case FieldTag:
+		tagged := c.db.Table("resource_tags").
+			Select("resource_tags.resource_id").
+			Joins("JOIN tags ON tags.id = resource_tags.tag_id").
+			Where("tags.deleted_at IS NULL AND lower(tags.name) = lower(?)", e.Value).
+			Scopes(tag.VisibleTo(c.viewerID))
+		c.out.args = append(c.out.args, tagged)
+		return "resources.id IN (?)"
+	case FieldSize:
+		c.out.args = append(c.out.args, e.Value)
+		return "physical_files.size_bytes " + string(e.Op) + " ?"
+	case FieldCreated:
+		c.out.args = append(c.out.args, e.Value)
+		return "resources.created_at " + string(e.Op) + " ?"
+	case FieldModified:
+		c.out.args = append(c.out.args, e.Value)
+		return "resources.updated_at " + string(e.Op) + " ?"
+	}
+	panic(fmt.Sprintf("search: unexpected field %q", e.Field))
+}
+
+// escapeLike escapes the LIKE wildcards in s, so it matches literally.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
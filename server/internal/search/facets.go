@@ -0,0 +1,166 @@
+package search
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bhavyajaix/BalkanID-filevault/internal/tag"
+	"gorm.io/gorm"
+)
+
+// maxFacetBuckets caps the buckets of the open-ended facets: MIME types, tags, owners
+// and months. The most common values are kept.
+const maxFacetBuckets = 20
+
+// sizeBuckets are the ranges of the size facet, smallest first.
+var sizeBuckets = []struct {
+	label  string
+	filter string
+	upTo   int64 // exclusive upper bound in bytes; 0 for the last bucket
+}{
+	{"Under 1 MB", "size<1MB", 1 << 20},
+	{"1 MB to 10 MB", "size>=1MB size<10MB", 10 << 20},
+	{"10 MB to 100 MB", "size>=10MB size<100MB", 100 << 20},
+	{"100 MB to 1 GB", "size>=100MB size<1GB", 1 << 30},
+	{"1 GB and over", "size>=1GB", 0},
+}
+
+type facetRow struct {
+	Value string
+	Label string
+	Count int64
+}
+
+// Facets counts the resources matching the filters along each facet with GROUP BY
+// queries over the same filtered query searches use.
+func (r *searchRepository) Facets(filters SearchFilters) (*Facets, error) {
+	query, _ := r.filtered(filters)
+	matched := query.Select("resources.id, resources.owner_id, resources.created_at, physical_files.mime_type, physical_files.size_bytes")
+	matches := func() *gorm.DB {
+		return r.db.Table("(?) AS matches", matched)
+	}
+
+	facets := &Facets{}
+	if err := matches().Count(&facets.TotalCount).Error; err != nil {
+		return nil, err
+	}
+
+	var rows []facetRow
+	if err := matches().
+		Select("matches.mime_type AS value, matches.mime_type AS label, COUNT(*) AS count").
+		Where("matches.mime_type IS NOT NULL").
+		Group("matches.mime_type").
+		Order("count DESC, value ASC").
+		Limit(maxFacetBuckets).
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	facets.MimeTypes = toBuckets(rows, "mime")
+
+	// Tags group by name, as the tag filter matches names across namespaces.
+	rows = nil
+	if err := matches().
+		Select("lower(tags.name) AS value, MIN(tags.name) AS label, COUNT(DISTINCT matches.id) AS count").
+		Joins("JOIN resource_tags ON resource_tags.resource_id = matches.id").
+		Joins("JOIN tags ON tags.id = resource_tags.tag_id AND tags.deleted_at IS NULL").
+		Scopes(tag.VisibleTo(filters.ViewerID)).
+		Group("lower(tags.name)").
+		Order("count DESC, value ASC").
+		Limit(maxFacetBuckets).
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	facets.Tags = toBuckets(rows, "tag")
+
+	rows = nil
+	if err := matches().
+		Select("users.username AS value, users.username AS label, COUNT(*) AS count").
+		Joins("JOIN users ON users.id = matches.owner_id").
+		Group("users.id, users.username").
+		Order("count DESC, value ASC").
+		Limit(maxFacetBuckets).
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	facets.Owners = toBuckets(rows, "owner")
+
+	var sizeRows []struct {
+		Bucket int
+		Count  int64
+	}
+	bucketExpr, bucketArgs := sizeBucketExpr("matches.size_bytes")
+	if err := matches().
+		Select(bucketExpr+" AS bucket, COUNT(*) AS count", bucketArgs...).
+		Where("matches.size_bytes IS NOT NULL").
+		Group("bucket").
+		Scan(&sizeRows).Error; err != nil {
+		return nil, err
+	}
+	counts := make(map[int]int64, len(sizeRows))
+	for _, row := range sizeRows {
+		counts[row.Bucket] = row.Count
+	}
+	for i, bucket := range sizeBuckets {
+		if counts[i] > 0 {
+			facets.Sizes = append(facets.Sizes, FacetBucket{Label: bucket.label, Filter: bucket.filter, Count: counts[i]})
+		}
+	}
+
+	var monthRows []struct {
+		Month time.Time
+		Count int64
+	}
+	if err := matches().
+		Select("date_trunc('month', matches.created_at) AS month, COUNT(*) AS count").
+		Group("month").
+		Order("month DESC").
+		Limit(maxFacetBuckets).
+		Scan(&monthRows).Error; err != nil {
+		return nil, err
+	}
+	for _, row := range monthRows {
+		month := row.Month.UTC()
+		facets.Months = append(facets.Months, FacetBucket{
+			Label:  month.Format("January 2006"),
+			Filter: fmt.Sprintf("after:%s before:%s", month.Format("2006-01-02"), month.AddDate(0, 1, 0).Format("2006-01-02")),
+			Count:  row.Count,
+		})
+	}
+	return facets, nil
+}
+
+// sizeBucketExpr returns a CASE expression giving the index in sizeBuckets of a size.
+func sizeBucketExpr(column string) (string, []interface{}) {
+	var sb strings.Builder
+	var args []interface{}
+	sb.WriteString("CASE")
+	for i, bucket := range sizeBuckets {
+		if bucket.upTo == 0 {
+			fmt.Fprintf(&sb, " ELSE %d", i)
+			break
+		}
+		fmt.Fprintf(&sb, " WHEN %s < ? THEN %d", column, i)
+		args = append(args, bucket.upTo)
+	}
+	sb.WriteString(" END")
+	return sb.String(), args
+}
+
+// toBuckets turns grouped rows into buckets filtering on field.
+func toBuckets(rows []facetRow, field string) []FacetBucket {
+	buckets := make([]FacetBucket, 0, len(rows))
+	for _, row := range rows {
+		buckets = append(buckets, FacetBucket{Label: row.Label, Filter: field + ":" + quoteValue(row.Value), Count: row.Count})
+	}
+	return buckets
+}
+
+// quoteValue quotes a field value for the query language when it contains characters
+// that would end the term. Values can't contain quotes themselves, so those are dropped.
+func quoteValue(value string) string {
+	if !strings.ContainsAny(value, " \t\n\r()\"") {
+		return value
+	}
+	return `"` + strings.ReplaceAll(value, `"`, "") + `"`
+}
@@ -0,0 +1,45 @@
+package search
+
+import (
+	"github.com/bhavyajaix/BalkanID-filevault/internal/database"
+	"github.com/bhavyajaix/BalkanID-filevault/internal/permission"
+)
+
+// Names are matched in the form search_name() gives them (see migration 0015): lower
+// case, without accents, and with runs of spaces, underscores, dots and dashes turned
+// into a single space. Each expression below takes the raw name as its argument.
+const (
+	// nameContains matches names containing the given one. Underscores don't survive
+	// normalization, so only % and \ need escaping for LIKE.
+	nameContains = `search_name(resources.name) LIKE '%' || replace(replace(search_name(?), '\', '\\'), '%', '\%') || '%'`
+	// nameSimilarity scores from 0 to 1 how closely the given name matches the best
+	// matching run of words of the resource name, tolerating typos.
+	nameSimilarity = "word_similarity(search_name(?), search_name(resources.name))"
+)
+
+// suggestScore ranks suggestions: names starting with the prefix first, then names
+// with a word starting with it, then fuzzy matches, each by similarity. It takes the
+// prefix three times.
+const suggestScore = `CASE
+		WHEN search_name(resources.name) LIKE replace(replace(search_name(?), '\', '\\'), '%', '\%') || '%' THEN 2
+		WHEN search_name(resources.name) LIKE '% ' || replace(replace(search_name(?), '\', '\\'), '%', '\%') || '%' THEN 1
+		ELSE 0
+	END + word_similarity(search_name(?), search_name(resources.name))`
+
+// SuggestResources returns the resources the viewer can read whose name contains the
+// prefix or fuzzily matches it, best matches and most recently updated first.
+func (r *searchRepository) SuggestResources(viewerID uint, prefix string, limit int) ([]database.Resource, error) {
+	var resources []database.Resource
+	err := r.db.Model(&database.Resource{}).
+		Select("resources.*, ("+suggestScore+")::float8 AS search_rank", prefix, prefix, prefix).
+		Where("(resources.owner_id = ? OR resources.id IN (?))", viewerID, permission.SharedWith(r.db, viewerID)).
+		Where("("+nameContains+" OR search_name(?) <% search_name(resources.name))", prefix, prefix).
+		Preload("User").
+		Preload("PhysicalFile").
+		Order("search_rank DESC").
+		Order("resources.updated_at DESC").
+		Order("resources.id DESC").
+		Limit(limit).
+		Find(&resources).Error
+	return resources, err
+}
@@ -0,0 +1,105 @@
+package search
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/bhavyajaix/BalkanID-filevault/internal/database"
+	"github.com/bhavyajaix/BalkanID-filevault/internal/metadata"
+	"gorm.io/gorm"
+)
+
+// metadataOps lists the operators that apply to each type of field.
+var metadataOps = map[database.MetadataFieldType][]MetadataOp{
+	database.MetadataString: {MetadataEq, MetadataNe, MetadataContains, MetadataExists},
+	database.MetadataNumber: {MetadataEq, MetadataNe, MetadataLt, MetadataLte, MetadataGt, MetadataGte, MetadataExists},
+	database.MetadataDate:   {MetadataEq, MetadataNe, MetadataLt, MetadataLte, MetadataGt, MetadataGte, MetadataExists},
+	database.MetadataEnum:   {MetadataEq, MetadataNe, MetadataExists},
+}
+
+var comparisons = map[MetadataOp]string{
+	MetadataLt:  "<",
+	MetadataLte: "<=",
+	MetadataGt:  ">",
+	MetadataGte: ">=",
+}
+
+func (r *searchRepository) FindMetadataField(viewerID uint, id uint) (*database.MetadataField, error) {
+	var field database.MetadataField
+	if err := r.db.Model(&database.MetadataField{}).Scopes(metadata.VisibleTo(viewerID)).First(&field, id).Error; err != nil {
+		return nil, err
+	}
+	return &field, nil
+}
+
+// prepareMetadata checks a metadata filter against its field and parses its value.
+func (s *service) prepareMetadata(viewerID uint, filter *MetadataFilter) error {
+	field, err := s.repo.FindMetadataField(viewerID, filter.FieldID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return &FilterError{Field: "metadata", Value: fmt.Sprint(filter.FieldID), Reason: "no such field"}
+	}
+	if err != nil {
+		return fmt.Errorf("failed to find metadata field: %w", err)
+	}
+
+	allowed := false
+	for _, op := range metadataOps[field.Type] {
+		allowed = allowed || op == filter.Op
+	}
+	if !allowed {
+		return &FilterError{Field: "metadata", Value: string(filter.Op), Reason: fmt.Sprintf("does not apply to %s fields such as %s", field.Type, field.Name)}
+	}
+	filter.fieldType = field.Type
+
+	if filter.Op == MetadataExists {
+		return nil
+	}
+	if filter.Value == nil {
+		return &FilterError{Field: "metadata", Value: field.Name, Reason: fmt.Sprintf("%s needs a value", filter.Op)}
+	}
+	if filter.value, err = metadata.ParseValue(field, *filter.Value); err != nil {
+		return err
+	}
+	return nil
+}
+
+// metadataCondition returns the SQL condition and arguments for a prepared filter.
+// Exact matches of typed values are written as containment, which the GIN index on
+// resources.metadata serves; text is compared ignoring case and accents.
+func metadataCondition(filter MetadataFilter) (string, []interface{}) {
+	key := metadata.Key(filter.FieldID)
+	value := "resources.metadata ->> CAST(? AS text)"
+
+	if filter.Op == MetadataExists {
+		return value + " IS NOT NULL", []interface{}{key}
+	}
+	if filter.fieldType == database.MetadataString {
+		text := filter.value.(string)
+		switch filter.Op {
+		case MetadataContains:
+			return "lower(immutable_unaccent(" + value + ")) LIKE lower(immutable_unaccent(?))", []interface{}{key, "%" + escapeLike(text) + "%"}
+		case MetadataNe:
+			return "lower(immutable_unaccent(" + value + ")) <> lower(immutable_unaccent(?))", []interface{}{key, text}
+		default:
+			return "lower(immutable_unaccent(" + value + ")) = lower(immutable_unaccent(?))", []interface{}{key, text}
+		}
+	}
+
+	switch filter.Op {
+	case MetadataEq, MetadataNe:
+		contained, _ := json.Marshal(map[string]interface{}{key: filter.value})
+		if filter.Op == MetadataNe {
+			return "(" + value + " IS NOT NULL AND NOT resources.metadata @> CAST(? AS jsonb))", []interface{}{key, string(contained)}
+		}
+		return "resources.metadata @> CAST(? AS jsonb)", []interface{}{string(contained)}
+	case MetadataLt, MetadataLte, MetadataGt, MetadataGte:
+		if filter.fieldType == database.MetadataDate {
+			// Dates are stored as YYYY-MM-DD, which sorts like the dates themselves.
+			return value + " " + comparisons[filter.Op] + " ?", []interface{}{key, filter.value}
+		}
+		number := "CASE WHEN jsonb_typeof(resources.metadata -> CAST(? AS text)) = 'number' THEN (" + value + ")::numeric END"
+		return number + " " + comparisons[filter.Op] + " ?", []interface{}{key, key, filter.value}
+	}
+	panic(fmt.Sprintf("search: unexpected metadata operator %q", filter.Op))
+}
@@ -0,0 +1,518 @@
+package search
+
+import (
+	"fmt"
+	"math"
+	"mime"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// The search query language. A query is a sequence of terms, implicitly ANDed:
+//
+//	type:pdf tag:urgent size>10MB after:2025-01-01 owner:alice "quarterly report" -draft
+//
+// Terms combine with AND, OR and NOT (or a leading -), grouped with parentheses; NOT
+// binds tightest, then AND, then OR. Bare words and "quoted phrases" are matched
+// against names and file contents. Field predicates are written field:value, and
+// fields that can be compared also take field:<value, field>=value and so on, or the
+// short form size>10MB. Dates are absolute (2025-01-01, or RFC 3339) or relative to
+// now (12h, 7d, 2w, 3mo, 1y), so modified:<7d means modified less than 7 days ago.
+
+// Expr is a node of a parsed search query.
+type Expr interface {
+	isExpr()
+}
+
+// AndExpr matches resources matching both operands.
+type AndExpr struct {
+	Left, Right Expr
+}
+
+// OrExpr matches resources matching either operand.
+type OrExpr struct {
+	Left, Right Expr
+}
+
+// NotExpr matches resources not matching its operand.
+type NotExpr struct {
+	Operand Expr
+}
+
+// TextExpr matches resources whose name or content contains all the words, or the
+// exact phrase.
+type TextExpr struct {
+	Text   string
+	Phrase bool
+}
+
+// Field is a resource attribute a query can test.
+type Field string
+
+const (
+	FieldType     Field = "type"
+	FieldMimeType Field = "mime"
+	FieldTag      Field = "tag"
+	FieldName     Field = "name"
+	FieldExt      Field = "ext"
+	FieldOwner    Field = "owner"
+	FieldSize     Field = "size"
+	FieldCreated  Field = "created"
+	FieldModified Field = "modified"
+)
+
+// Op compares a field with a value.
+type Op string
+
+const (
+	OpEq Op = "="
+	OpLt Op = "<"
+	OpLe Op = "<="
+	OpGt Op = ">"
+	OpGe Op = ">="
+)
+
+// FieldExpr tests a field against a value: a string, an int64 number of bytes for
+// FieldSize or a time.Time for FieldCreated and FieldModified.
+type FieldExpr struct {
+	Field Field
+	Op    Op
+	Value interface{}
+}
+
+func (AndExpr) isExpr()   {}
+func (OrExpr) isExpr()    {}
+func (NotExpr) isExpr()   {}
+func (TextExpr) isExpr()  {}
+func (FieldExpr) isExpr() {}
+
+// ParseError reports a malformed query. Pos is the 1-based character position of the
+// offending token.
+type ParseError struct {
+	Pos int
+	Msg string
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("invalid query at position %d: %s", e.Pos, e.Msg)
+}
+
+// ParseQuery parses a search query. It returns nil for a query without any terms.
+// Relative dates are resolved against the current time.
+func ParseQuery(input string) (Expr, error) {
+	return parseQuery(input, time.Now())
+}
+
+func parseQuery(input string, now time.Time) (Expr, error) {
+	tokens, err := lex(input)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{input: input, tokens: tokens, now: now}
+	if p.peek().kind == tokenEOF {
+		return nil, nil
+	}
+	expr, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokenEOF {
+		return nil, p.errorAt(t, "unexpected %s", t)
+	}
+	return expr, nil
+}
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenWord
+	tokenPhrase
+	tokenLParen
+	tokenRParen
+	tokenNot // NOT or a leading -
+	tokenAnd
+	tokenOr
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int // byte offset into the input
+}
+
+func (t token) String() string {
+	switch t.kind {
+	case tokenEOF:
+		return "end of query"
+	case tokenPhrase:
+		return strconv.Quote(t.text)
+	default:
+		return fmt.Sprintf("%q", t.text)
+	}
+}
+
+func lex(input string) ([]token, error) {
+	var tokens []token
+	i := 0
+	for i < len(input) {
+		c := input[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '(':
+			tokens = append(tokens, token{kind: tokenLParen, text: "(", pos: i})
+			i++
+		case c == ')':
+			tokens = append(tokens, token{kind: tokenRParen, text: ")", pos: i})
+			i++
+		case c == '"':
+			text, next, ok := readQuoted(input, i)
+			if !ok {
+				return nil, &ParseError{Pos: column(input, i), Msg: "unterminated quoted phrase"}
+			}
+			tokens = append(tokens, token{kind: tokenPhrase, text: text, pos: i})
+			i = next
+		case c == '-' && i+1 < len(input) && !isBreak(input[i+1]):
+			tokens = append(tokens, token{kind: tokenNot, text: "-", pos: i})
+			i++
+		default:
+			// A word runs to the next break. Quotes inside it, as in owner:"Ann Lee",
+			// only group the value.
+			start := i
+			var sb strings.Builder
+			for i < len(input) && !isBreak(input[i]) {
+				if input[i] == '"' {
+					text, next, ok := readQuoted(input, i)
+					if !ok {
+						return nil, &ParseError{Pos: column(input, i), Msg: "unterminated quoted value"}
+					}
+					sb.WriteString(text)
+					i = next
+					continue
+				}
+				sb.WriteByte(input[i])
+				i++
+			}
+			word := sb.String()
+			kind := tokenWord
+			switch input[start:i] {
+			case "AND":
+				kind = tokenAnd
+			case "OR":
+				kind = tokenOr
+			case "NOT":
+				kind = tokenNot
+			}
+			tokens = append(tokens, token{kind: kind, text: word, pos: start})
+		}
+	}
+	return append(tokens, token{kind: tokenEOF, pos: len(input)}), nil
+}
+
+func isBreak(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '(' || c == ')'
+}
+
+// readQuoted reads the quoted string starting at input[i], returning its contents and
+// the offset past the closing quote.
+func readQuoted(input string, i int) (string, int, bool) {
+	end := strings.IndexByte(input[i+1:], '"')
+	if end < 0 {
+		return "", 0, false
+	}
+	return input[i+1 : i+1+end], i + end + 2, true
+}
+
+// column converts a byte offset to a 1-based character position.
+func column(input string, offset int) int {
+	return utf8.RuneCountInString(input[:offset]) + 1
+}
+
+type parser struct {
+	input  string
+	tokens []token
+	next   int
+	now    time.Time
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.next]
+}
+
+func (p *parser) advance() token {
+	t := p.tokens[p.next]
+	if t.kind != tokenEOF {
+		p.next++
+	}
+	return t
+}
+
+func (p *parser) errorAt(t token, format string, args ...interface{}) error {
+	return &ParseError{Pos: column(p.input, t.pos), Msg: fmt.Sprintf(format, args...)}
+}
+
+// parseOr parses: and ("OR" and)*
+func (p *parser) parseOr() (Expr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().kind == tokenOr {
+		p.advance()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = OrExpr{Left: left, Right: right}
+	}
+	return left, nil
+}
+
+// parseAnd parses: unary (["AND"] unary)*
+func (p *parser) parseAnd() (Expr, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		switch p.peek().kind {
+		case tokenAnd:
+			p.advance()
+		case tokenWord, tokenPhrase, tokenLParen, tokenNot:
+		default:
+			return left, nil
+		}
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = AndExpr{Left: left, Right: right}
+	}
+}
+
+// parseUnary parses: ("NOT" | "-") unary | "(" or ")" | term
+func (p *parser) parseUnary() (Expr, error) {
+	t := p.advance()
+	switch t.kind {
+	case tokenNot:
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return NotExpr{Operand: operand}, nil
+	case tokenLParen:
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if closing := p.advance(); closing.kind != tokenRParen {
+			return nil, p.errorAt(closing, "expected \")\" to close the group at position %d, found %s", column(p.input, t.pos), closing)
+		}
+		return expr, nil
+	case tokenPhrase:
+		if strings.TrimSpace(t.text) == "" {
+			return nil, p.errorAt(t, "empty phrase")
+		}
+		return TextExpr{Text: t.text, Phrase: true}, nil
+	case tokenWord:
+		return p.parseTerm(t)
+	case tokenEOF:
+		return nil, p.errorAt(t, "expected a search term, found end of query")
+	default:
+		return nil, p.errorAt(t, "expected a search term, found %s", t)
+	}
+}
+
+// parseTerm parses a bare word or a field predicate.
+func (p *parser) parseTerm(t token) (Expr, error) {
+	sep := strings.IndexAny(t.text, ":<>=")
+	if sep <= 0 || !isFieldName(t.text[:sep]) {
+		return TextExpr{Text: t.text}, nil
+	}
+
+	field := Field(strings.ToLower(t.text[:sep]))
+	rest := t.text[sep:]
+	rest = strings.TrimPrefix(rest, ":")
+	op := OpEq
+	explicitOp := false
+	for _, candidate := range []Op{OpLe, OpGe, OpLt, OpGt, OpEq} {
+		if strings.HasPrefix(rest, string(candidate)) {
+			op, explicitOp = candidate, true
+			rest = rest[len(candidate):]
+			break
+		}
+	}
+	value := strings.TrimSpace(rest)
+	if value == "" {
+		return nil, p.errorAt(t, "missing value for %s", field)
+	}
+
+	switch field {
+	case FieldType, FieldMimeType, FieldTag, FieldName, FieldExt, FieldOwner:
+		if op != OpEq {
+			return nil, p.errorAt(t, "%s does not support %s", field, op)
+		}
+		switch field {
+		case FieldType:
+			return p.parseType(t, value)
+		case FieldExt:
+			value = strings.ToLower(strings.TrimPrefix(value, "."))
+		}
+		return FieldExpr{Field: field, Op: OpEq, Value: value}, nil
+	case FieldSize:
+		size, err := parseSize(value)
+		if err != nil {
+			return nil, p.errorAt(t, "%v", err)
+		}
+		return FieldExpr{Field: FieldSize, Op: op, Value: size}, nil
+	case "after", "before":
+		if explicitOp {
+			return nil, p.errorAt(t, "%s does not take an operator", field)
+		}
+		at, _, err := parseDate(value, p.now)
+		if err != nil {
+			return nil, p.errorAt(t, "%v", err)
+		}
+		if field == "after" {
+			return FieldExpr{Field: FieldCreated, Op: OpGe, Value: at}, nil
+		}
+		return FieldExpr{Field: FieldCreated, Op: OpLt, Value: at}, nil
+	case FieldCreated, FieldModified:
+		at, relative, err := parseDate(value, p.now)
+		if err != nil {
+			return nil, p.errorAt(t, "%v", err)
+		}
+		switch {
+		case relative && op == OpEq && explicitOp:
+			// created:=7d would only match files created at this very instant.
+			return nil, p.errorAt(t, "%s cannot equal a relative date, use < or >", field)
+		case relative && !explicitOp:
+			// modified:7d means within the last 7 days.
+			return FieldExpr{Field: field, Op: OpGe, Value: at}, nil
+		case relative:
+			// A relative value is an age, so "less than 7 days old" is "after 7 days ago".
+			return FieldExpr{Field: field, Op: invert(op), Value: at}, nil
+		case !explicitOp:
+			// A bare date matches the whole day.
+			return AndExpr{
+				Left:  FieldExpr{Field: field, Op: OpGe, Value: at},
+				Right: FieldExpr{Field: field, Op: OpLt, Value: at.AddDate(0, 0, 1)},
+			}, nil
+		default:
+			return FieldExpr{Field: field, Op: op, Value: at}, nil
+		}
+	}
+	return nil, p.errorAt(t, "unknown field %q", t.text[:sep])
+}
+
+// isFieldName reports whether the text before a separator names a field. Anything that
+// isn't a plain word, like the "10" of 10:30, is searched for as text instead.
+func isFieldName(name string) bool {
+	for _, r := range name {
+		if !unicode.IsLetter(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// parseType handles type:file and type:folder, and file kinds given by extension such
+// as type:pdf, which match the MIME type or the file name.
+func (p *parser) parseType(t token, value string) (Expr, error) {
+	value = strings.ToLower(value)
+	switch value {
+	case "file", "folder":
+		return FieldExpr{Field: FieldType, Op: OpEq, Value: value}, nil
+	}
+	ext := strings.TrimPrefix(value, ".")
+	if ext == "" || strings.ContainsAny(ext, "/.") {
+		return nil, p.errorAt(t, "invalid type %q", value)
+	}
+	var byName Expr = FieldExpr{Field: FieldExt, Op: OpEq, Value: ext}
+	if mimeType := mime.TypeByExtension("." + ext); mimeType != "" {
+		mimeType, _, _ = mime.ParseMediaType(mimeType)
+		return OrExpr{Left: FieldExpr{Field: FieldMimeType, Op: OpEq, Value: mimeType}, Right: byName}, nil
+	}
+	return byName, nil
+}
+
+func invert(op Op) Op {
+	switch op {
+	case OpLt:
+		return OpGt
+	case OpLe:
+		return OpGe
+	case OpGt:
+		return OpLt
+	case OpGe:
+		return OpLe
+	}
+	return op
+}
+
+var sizeUnits = []struct {
+	suffix string
+	bytes  float64
+}{
+	{"TB", 1 << 40}, {"GB", 1 << 30}, {"MB", 1 << 20}, {"KB", 1 << 10}, {"B", 1},
+}
+
+// parseSize parses sizes like 512, 10MB or 1.5GB. Units are binary.
+func parseSize(value string) (int64, error) {
+	upper := strings.ToUpper(value)
+	multiplier := 1.0
+	for _, unit := range sizeUnits {
+		if strings.HasSuffix(upper, unit.suffix) {
+			upper = strings.TrimSuffix(upper, unit.suffix)
+			multiplier = unit.bytes
+			break
+		}
+	}
+	n, err := strconv.ParseFloat(upper, 64)
+	if err != nil || n < 0 || math.IsNaN(n) || math.IsInf(n, 0) {
+		return 0, fmt.Errorf("invalid size %q, expected e.g. 10MB", value)
+	}
+	// MaxInt64 rounds up to 2^63 as a float, which no longer fits.
+	if n*multiplier >= math.MaxInt64 {
+		return 0, fmt.Errorf("size %q is too large", value)
+	}
+	return int64(n * multiplier), nil
+}
+
+// parseDate parses an absolute date or a duration back from now, reporting which it was.
+func parseDate(value string, now time.Time) (time.Time, bool, error) {
+	if at, err := time.Parse("2006-01-02", value); err == nil {
+		return at, false, nil
+	}
+	if at, err := time.Parse(time.RFC3339, value); err == nil {
+		return at, false, nil
+	}
+
+	lower := strings.ToLower(value)
+	for _, unit := range []string{"mo", "h", "d", "w", "y"} {
+		if !strings.HasSuffix(lower, unit) {
+			continue
+		}
+		n, err := strconv.Atoi(strings.TrimSuffix(lower, unit))
+		if err != nil || n < 0 {
+			break
+		}
+		switch unit {
+		case "h":
+			return now.Add(-time.Duration(n) * time.Hour), true, nil
+		case "d":
+			return now.AddDate(0, 0, -n), true, nil
+		case "w":
+			return now.AddDate(0, 0, -7*n), true, nil
+		case "mo":
+			return now.AddDate(0, -n, 0), true, nil
+		case "y":
+			return now.AddDate(-n, 0, 0), true, nil
+		}
+	}
+	return time.Time{}, false, fmt.Errorf("invalid date %q, expected e.g. 2025-01-01 or 7d", value)
+}
@@ -0,0 +1,184 @@
+package search
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestLex(t *testing.T) {
+	tokens, err := lex(`a -b NOT(c) "d e" owner:"Ann Lee" - x-y AND OR`)
+	if err != nil {
+		t.Fatalf("lex failed: %v", err)
+	}
+	want := []token{
+		{kind: tokenWord, text: "a", pos: 0},
+		{kind: tokenNot, text: "-", pos: 2},
+		{kind: tokenWord, text: "b", pos: 3},
+		{kind: tokenNot, text: "NOT", pos: 5},
+		{kind: tokenLParen, text: "(", pos: 8},
+		{kind: tokenWord, text: "c", pos: 9},
+		{kind: tokenRParen, text: ")", pos: 10},
+		{kind: tokenPhrase, text: "d e", pos: 12},
+		{kind: tokenWord, text: "owner:Ann Lee", pos: 18},
+		{kind: tokenWord, text: "-", pos: 34},
+		{kind: tokenWord, text: "x-y", pos: 36},
+		{kind: tokenAnd, text: "AND", pos: 40},
+		{kind: tokenOr, text: "OR", pos: 44},
+		{kind: tokenEOF, pos: 46},
+	}
+	if !reflect.DeepEqual(tokens, want) {
+		t.Errorf("lex returned\n%+v\nwant\n%+v", tokens, want)
+	}
+}
+
+func TestParseQuery(t *testing.T) {
+	now := time.Date(2025, 6, 15, 12, 0, 0, 0, time.UTC)
+	day := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	text := func(s string) Expr { return TextExpr{Text: s} }
+	and := func(l, r Expr) Expr { return AndExpr{Left: l, Right: r} }
+	or := func(l, r Expr) Expr { return OrExpr{Left: l, Right: r} }
+	not := func(e Expr) Expr { return NotExpr{Operand: e} }
+	field := func(f Field, op Op, v interface{}) Expr { return FieldExpr{Field: f, Op: op, Value: v} }
+
+	tests := []struct {
+		query string
+		want  Expr
+	}{
+		{query: "", want: nil},
+		{query: "  ", want: nil},
+		{query: "a b", want: and(text("a"), text("b"))},
+		{query: "a AND b", want: and(text("a"), text("b"))},
+		{query: "a OR b c", want: or(text("a"), and(text("b"), text("c")))},
+		{query: "a b OR c", want: or(and(text("a"), text("b")), text("c"))},
+		{query: "a OR b OR c", want: or(or(text("a"), text("b")), text("c"))},
+		{query: "(a OR b) c", want: and(or(text("a"), text("b")), text("c"))},
+		{query: "-a b", want: and(not(text("a")), text("b"))},
+		{query: "NOT a OR b", want: or(not(text("a")), text("b"))},
+		{query: "NOT (a OR b)", want: not(or(text("a"), text("b")))},
+		{query: "--a", want: not(not(text("a")))},
+		{query: "- a", want: and(text("-"), text("a"))},
+		{query: "x-y", want: text("x-y")},
+		{query: "not or and", want: and(and(text("not"), text("or")), text("and"))},
+		{query: `"quarterly report"`, want: TextExpr{Text: "quarterly report", Phrase: true}},
+		{query: "10:30", want: text("10:30")},
+		{query: `owner:"Ann Lee"`, want: field(FieldOwner, OpEq, "Ann Lee")},
+		{query: "TAG:urgent", want: field(FieldTag, OpEq, "urgent")},
+		{query: "ext:.PDF", want: field(FieldExt, OpEq, "pdf")},
+		{query: "type:folder", want: field(FieldType, OpEq, "folder")},
+		{query: "type:pdf", want: or(field(FieldMimeType, OpEq, "application/pdf"), field(FieldExt, OpEq, "pdf"))},
+		{query: "size>10MB", want: field(FieldSize, OpGt, int64(10<<20))},
+		{query: "size:<=1KB", want: field(FieldSize, OpLe, int64(1024))},
+		{query: "size:512", want: field(FieldSize, OpEq, int64(512))},
+		{query: "created:7d", want: field(FieldCreated, OpGe, now.AddDate(0, 0, -7))},
+		{query: "modified:<7d", want: field(FieldModified, OpGt, now.AddDate(0, 0, -7))},
+		{query: "created:>=2w", want: field(FieldCreated, OpLe, now.AddDate(0, 0, -14))},
+		{query: "modified<12h", want: field(FieldModified, OpGt, now.Add(-12*time.Hour))},
+		{query: "created>3mo", want: field(FieldCreated, OpLt, now.AddDate(0, -3, 0))},
+		{query: "created:2025-01-01", want: and(field(FieldCreated, OpGe, day), field(FieldCreated, OpLt, day.AddDate(0, 0, 1)))},
+		{query: "created:>=2025-01-01", want: field(FieldCreated, OpGe, day)},
+		{query: "created:=2025-01-01", want: field(FieldCreated, OpEq, day)},
+		{query: "after:2025-01-01", want: field(FieldCreated, OpGe, day)},
+		{query: "before:2025-01-01", want: field(FieldCreated, OpLt, day)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			got, err := parseQuery(tt.query, now)
+			if err != nil {
+				t.Fatalf("parseQuery(%q) failed: %v", tt.query, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseQuery(%q) =\n%#v\nwant\n%#v", tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseQueryErrors(t *testing.T) {
+	now := time.Date(2025, 6, 15, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		query string
+		pos   int
+	}{
+		{query: "a (b", pos: 5},
+		{query: "a )", pos: 3},
+		{query: "a OR", pos: 5},
+		{query: "NOT", pos: 4},
+		{query: "()", pos: 2},
+		{query: `a "b c`, pos: 3},
+		{query: `owner:"Ann`, pos: 7},
+		{query: `a ""`, pos: 3},
+		{query: "é colour:red", pos: 3},
+		{query: "a size>ten", pos: 3},
+		{query: "size:", pos: 1},
+		{query: "tag<x", pos: 1},
+		{query: "type:a/b", pos: 1},
+		{query: "after:>2025-01-01", pos: 1},
+		{query: "modified:yesterday", pos: 1},
+		{query: "created:=7d", pos: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			_, err := parseQuery(tt.query, now)
+			var parseErr *ParseError
+			if !errors.As(err, &parseErr) {
+				t.Fatalf("parseQuery(%q) returned %v, want a ParseError", tt.query, err)
+			}
+			if parseErr.Pos != tt.pos {
+				t.Errorf("parseQuery(%q) failed at position %d (%s), want %d", tt.query, parseErr.Pos, parseErr.Msg, tt.pos)
+			}
+		})
+	}
+}
+
+func TestParseSize(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    int64
+		wantErr bool
+	}{
+		{value: "512", want: 512},
+		{value: "10MB", want: 10 << 20},
+		{value: "1.5gb", want: 3 << 29},
+		{value: "8388607TB", want: 8388607 << 40},
+		{value: "8388608TB", wantErr: true},
+		{value: "9223372036854775807", wantErr: true},
+		{value: "1e30", wantErr: true},
+		{value: "NaN", wantErr: true},
+		{value: "Inf", wantErr: true},
+		{value: "-1", wantErr: true},
+		{value: "ten", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := parseSize(tt.value)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("parseSize(%q) = %d, want an error", tt.value, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseSize(%q) failed: %v", tt.value, err)
+			}
+			if got != tt.want {
+				t.Errorf("parseSize(%q) = %d, want %d", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseQueryRejectsExactRelativeDates(t *testing.T) {
+	now := time.Date(2025, 6, 15, 12, 0, 0, 0, time.UTC)
+	for _, query := range []string{"created:=7d", "modified:=12h"} {
+		_, err := parseQuery(query, now)
+		var parseErr *ParseError
+		if !errors.As(err, &parseErr) {
+			t.Errorf("parseQuery(%q) returned %v, want a ParseError", query, err)
+		}
+	}
+	if _, err := parseQuery("created:=2025-01-01", now); err != nil {
+		t.Errorf("exact absolute date rejected: %v", err)
+	}
+}
@@ -1,7 +1,13 @@
 package search
 
 import (
+	"html"
+	"strings"
+
 	"github.com/bhavyajaix/BalkanID-filevault/internal/database"
+	"github.com/bhavyajaix/BalkanID-filevault/internal/pagination"
+	"github.com/bhavyajaix/BalkanID-filevault/internal/permission"
+	"github.com/bhavyajaix/BalkanID-filevault/internal/tag"
 	"gorm.io/gorm"
 )
 
@@ -13,20 +19,90 @@ func NewSearchRepository(db *gorm.DB) Repository {
 	return &searchRepository{db: db}
 }
 
-func (r *searchRepository) SearchResources(filters SearchFilters, pagination Pagination) ([]database.Resource, error) {
-	var resources []database.Resource
+func (r *searchRepository) FindResourceByID(id uint) (*database.Resource, error) {
+	var resource database.Resource
+	if err := r.db.First(&resource, id).Error; err != nil {
+		return nil, err
+	}
+	return &resource, nil
+}
 
+func (r *searchRepository) SearchResources(filters SearchFilters, req pagination.Request) (*pagination.Page, error) {
+	query, compiled := r.filtered(filters)
+	if compiled == nil && filters.NameSimilarity == nil {
+		// --- Apply pagination and execute ---
+		// Preload associations to avoid N+1 queries in the GraphQL resolver
+		return pagination.Paginate(query, req, "User", "PhysicalFile")
+	}
+
+	// The query's text terms match the name or the indexed content of the file, and
+	// rank name matches higher. Fuzzy name matches add how similar the name is. The
+	// rank is computed in a subquery named after resources, so pagination can sort and
+	// seek on it like on any other column.
+	var ranks []string
+	var rankArgs []interface{}
+	if compiled != nil && compiled.tsquery != "" {
+		ranks = append(ranks, "ts_rank(setweight(to_tsvector('english', resources.name), 'A') || COALESCE(file_contents.search_vector, ''::tsvector), "+compiled.tsquery+")")
+		rankArgs = append(rankArgs, compiled.tsqueryArgs...)
+	}
+	if filters.NameSimilarity != nil {
+		ranks = append(ranks, nameSimilarity)
+		rankArgs = append(rankArgs, *filters.Name)
+	}
+	rank := "0"
+	if len(ranks) > 0 {
+		rank = strings.Join(ranks, " + ")
+	}
+	matched := query.Select("resources.*, ("+rank+")::float8 AS search_rank", rankArgs...)
+	ranked := r.db.Model(&database.Resource{}).Table("(?) AS resources", matched)
+
+	page, err := pagination.Paginate(ranked, req, "User", "PhysicalFile")
+	if err != nil {
+		return nil, err
+	}
+	if compiled != nil && compiled.tsquery != "" {
+		if page.Snippets, err = r.snippets(page.Resources, compiled.tsquery, compiled.tsqueryArgs); err != nil {
+			return nil, err
+		}
+	}
+	return page, nil
+}
+
+// filtered builds the query for the resources matching every filter: resources joined
+// with their physical files, and with file_contents when there is a query expression,
+// whose compiled form is returned too. Searches and facets both start from it, so they
+// always agree on what matches.
+func (r *searchRepository) filtered(filters SearchFilters) (*gorm.DB, *compiledQuery) {
 	// Start with the base query, joining with tables we need for filtering
 	query := r.db.Model(&database.Resource{}).
 		Joins("LEFT JOIN physical_files ON physical_files.id = resources.physical_file_id")
 
 	// --- Dynamically apply filters ---
 
-	// Always scope to the owner
-	query = query.Where("resources.owner_id = ?", filters.OwnerID)
+	// Always scope to what the viewer can read: their own resources and those shared
+	// with them. Public links don't make a resource searchable.
+	query = query.Where("(resources.owner_id = ? OR resources.id IN (?))", filters.ViewerID, permission.SharedWith(r.db, filters.ViewerID))
 
+	// Names match as substrings, ignoring case, accents and separators, or fuzzily.
+	// Fuzzy matches are checked row by row, as the trigram index only serves the fixed
+	// threshold of the similarity operators.
 	if filters.Name != nil {
-		query = query.Where("resources.name LIKE ?", "%"+*filters.Name+"%")
+		if filters.NameSimilarity != nil {
+			query = query.Where("("+nameContains+" OR "+nameSimilarity+" >= ?)", *filters.Name, *filters.Name, *filters.NameSimilarity)
+		} else {
+			query = query.Where(nameContains, *filters.Name)
+		}
+	}
+
+	// Resources are uploaded by their owner.
+	if filters.UploaderName != nil {
+		query = query.
+			Joins("JOIN users AS uploaders ON uploaders.id = resources.owner_id").
+			Where("lower(immutable_unaccent(uploaders.username)) LIKE lower(immutable_unaccent(?))", "%"+escapeLike(*filters.UploaderName)+"%")
+	}
+
+	if filters.FolderID != nil {
+		query = query.Where("resources.id IN (SELECT descendant_id FROM resource_ancestors WHERE ancestor_id = ? AND depth > 0)", *filters.FolderID)
 	}
 
 	if len(filters.Types) > 0 {
@@ -50,28 +126,86 @@ func (r *searchRepository) SearchResources(filters SearchFilters, pagination Pag
 	}
 
 	if filters.BeforeDate != nil {
-		query = query.Where("resources.created_at <= ?", *filters.BeforeDate)
+		query = query.Where("resources.created_at < ?", *filters.BeforeDate)
 	}
 
-	// Tag filtering is the most complex due to the many-to-many relationship
+	// Tag filtering is the most complex due to the many-to-many relationship.
+	// It runs as a subquery so the outer query stays one row per resource and can be
+	// sorted by any column. Only the searcher's own and group tags count, and names
+	// match regardless of case, as they do within a namespace.
 	if len(filters.Tags) > 0 {
-		query = query.Joins("JOIN resource_tags ON resource_tags.resource_id = resources.id").
+		seen := make(map[string]bool, len(filters.Tags))
+		var names []string
+		for _, name := range filters.Tags {
+			name = strings.ToLower(strings.TrimSpace(name))
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+		tagged := r.db.Table("resource_tags").
+			Select("resource_tags.resource_id").
 			Joins("JOIN tags ON tags.id = resource_tags.tag_id").
-			Where("tags.name IN ?", filters.Tags).
-			Group("resources.id").
-			Having("COUNT(DISTINCT tags.name) = ?", len(filters.Tags)) // Ensure all tags match
+			Scopes(tag.VisibleTo(filters.ViewerID)).
+			Where("lower(tags.name) IN ?", names).
+			Group("resource_tags.resource_id").
+			Having("COUNT(DISTINCT lower(tags.name)) = ?", len(names)) // Ensure all tags match
+		query = query.Where("resources.id IN (?)", tagged)
 	}
 
-	// --- Apply pagination and execute ---
-	err := query.
-		Order("resources.created_at DESC").
-		Limit(pagination.Limit).
-		Offset(pagination.Offset).
-		// Preload associations to avoid N+1 queries in the GraphQL resolver
-		Preload("User").
-		Preload("PhysicalFile").
-		Preload("Tags").
-		Find(&resources).Error
+	for _, filter := range filters.Metadata {
+		condition, args := metadataCondition(filter)
+		query = query.Where(condition, args...)
+	}
+
+	if filters.Expression == nil {
+		return query, nil
+	}
+	compiled := compileQuery(r.db, filters.Expression, filters.ViewerID)
+	query = query.
+		Joins("LEFT JOIN file_contents ON file_contents.physical_file_id = resources.physical_file_id").
+		Where(compiled.where, compiled.args...)
+	return query, &compiled
+}
+
+// Snippet highlights are marked with control characters, which indexed content never
+// contains, so they survive HTML escaping and can then be turned into <mark> tags.
+const (
+	highlightStart = "\x01"
+	highlightStop  = "\x02"
+)
 
-	return resources, err
+var snippetOptions = `StartSel="` + highlightStart + `", StopSel="` + highlightStop + `", MaxFragments=2, MaxWords=30, MinWords=10, FragmentDelimiter=" ... "`
+
+// snippets returns an HTML excerpt of the content matching tsquery for each of the
+// resources whose content matched. Only the excerpts are HTML, with matches in <mark>.
+func (r *searchRepository) snippets(resources []database.Resource, tsquery string, args []interface{}) (map[uint]string, error) {
+	if len(resources) == 0 {
+		return nil, nil
+	}
+	ids := make([]uint, len(resources))
+	for i := range resources {
+		ids[i] = resources[i].ID
+	}
+
+	var rows []struct {
+		ID      uint
+		Snippet string
+	}
+	if err := r.db.Table("resources").
+		Select("resources.id, ts_headline('english', file_contents.content, "+tsquery+", ?) AS snippet", append(args, snippetOptions)...).
+		Joins("JOIN file_contents ON file_contents.physical_file_id = resources.physical_file_id").
+		Where("resources.id IN ? AND file_contents.search_vector @@ ("+tsquery+")", append([]interface{}{ids}, args...)...).
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	snippets := make(map[uint]string, len(rows))
+	for _, row := range rows {
+		snippet := html.EscapeString(row.Snippet)
+		snippet = strings.ReplaceAll(snippet, highlightStart, "<mark>")
+		snippet = strings.ReplaceAll(snippet, highlightStop, "</mark>")
+		snippets[row.ID] = snippet
+	}
+	return snippets, nil
 }
@@ -2,37 +2,120 @@ package search
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	"github.com/bhavyajaix/BalkanID-filevault/internal/database"
+	"github.com/bhavyajaix/BalkanID-filevault/internal/pagination"
 )
 
 // SearchFilters defines the parameters for a resource search.
 type SearchFilters struct {
-	OwnerID      uint
-	Name         *string
-	Types        []string
-	MimeTypes    []string
-	MinSizeBytes *int64
-	MaxSizeBytes *int64
-	AfterDate    *time.Time
+	// ViewerID is the user searching. Searches cover the resources they own and those
+	// shared with them; the service fills it in.
+	ViewerID uint
+	Name     *string
+	// NameSimilarity makes Name match fuzzily: names at least this similar to it, from
+	// 0 to 1, match as well as those containing it.
+	NameSimilarity *float64
+	Types          []string
+	MimeTypes      []string
+	MinSizeBytes   *int64
+	MaxSizeBytes   *int64
+	AfterDate      *time.Time
+	// BeforeDate is exclusive: only resources created earlier match.
 	BeforeDate   *time.Time
 	Tags         []string
 	UploaderName *string
+	// FolderID limits the search to the contents of a folder, at any depth.
+	FolderID *uint
+	// Query is a search in the query language (see query.go). Its words and phrases
+	// are matched against resource names and file contents.
+	Query *string
+	// Expression is the parsed Query, filled in by the service.
+	Expression Expr
+	// Metadata matches custom metadata values; every filter must hold.
+	Metadata []MetadataFilter
 }
 
-// Pagination defines the offset and limit for search results.
-type Pagination struct {
-	Offset int
-	Limit  int
+// MetadataOp compares the value of a metadata field in a search.
+type MetadataOp string
+
+const (
+	MetadataEq       MetadataOp = "EQ"
+	MetadataNe       MetadataOp = "NE"
+	MetadataContains MetadataOp = "CONTAINS" // STRING fields only
+	MetadataLt       MetadataOp = "LT"       // NUMBER and DATE fields only, as are the others below
+	MetadataLte      MetadataOp = "LTE"
+	MetadataGt       MetadataOp = "GT"
+	MetadataGte      MetadataOp = "GTE"
+	MetadataExists   MetadataOp = "EXISTS" // Takes no value
+)
+
+// MetadataFilter matches resources by the value of a custom metadata field, which must
+// be one the searcher can see.
+type MetadataFilter struct {
+	FieldID uint
+	Op      MetadataOp
+	Value   *string
+	// fieldType and value, in its stored form, are filled in by the service.
+	fieldType database.MetadataFieldType
+	value     interface{}
+}
+
+// FilterError reports a search filter with an invalid value.
+type FilterError struct {
+	Field  string
+	Value  string
+	Reason string
+}
+
+func (e *FilterError) Error() string {
+	return fmt.Sprintf("invalid %s %q: %s", e.Field, e.Value, e.Reason)
+}
+
+// ParseFilterDate parses the value of a date filter, either RFC 3339 or a plain
+// YYYY-MM-DD date taken as midnight UTC. dateOnly reports the latter.
+func ParseFilterDate(field, value string) (at time.Time, dateOnly bool, err error) {
+	if at, err := time.Parse(time.RFC3339, value); err == nil {
+		return at, false, nil
+	}
+	if at, err := time.Parse("2006-01-02", value); err == nil {
+		return at, true, nil
+	}
+	return time.Time{}, false, &FilterError{Field: field, Value: value, Reason: "expected an RFC 3339 timestamp or a YYYY-MM-DD date"}
+}
+
+// FacetBucket is one value of a facet and the number of matching resources having it.
+// Filter is a query-language fragment narrowing a search down to the bucket.
+type FacetBucket struct {
+	Label  string
+	Filter string
+	Count  int64
+}
+
+// Facets summarises the resources matching a search, to help narrowing it down.
+type Facets struct {
+	TotalCount int64
+	MimeTypes  []FacetBucket
+	Tags       []FacetBucket
+	Owners     []FacetBucket
+	Sizes      []FacetBucket
+	Months     []FacetBucket
 }
 
 // Repository defines the database operations for searching.
 type Repository interface {
-	SearchResources(filters SearchFilters, pagination Pagination) ([]database.Resource, error)
+	SearchResources(filters SearchFilters, req pagination.Request) (*pagination.Page, error)
+	Facets(filters SearchFilters) (*Facets, error)
+	SuggestResources(viewerID uint, prefix string, limit int) ([]database.Resource, error)
+	FindResourceByID(id uint) (*database.Resource, error)
+	FindMetadataField(viewerID uint, id uint) (*database.MetadataField, error)
 }
 
 // Service defines the business logic for searching resources.
 type Service interface {
-	Search(ctx context.Context, filters SearchFilters, pagination Pagination) ([]database.Resource, error)
+	Search(ctx context.Context, filters SearchFilters, req pagination.Request) (*pagination.Page, error)
+	Facets(ctx context.Context, filters SearchFilters) (*Facets, error)
+	Suggest(ctx context.Context, prefix string, limit int) ([]database.Resource, error)
 }
\ No newline at end of file
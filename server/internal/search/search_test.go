@@ -0,0 +1,42 @@
+package search
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestParseFilterDate(t *testing.T) {
+	tests := []struct {
+		value    string
+		want     time.Time
+		dateOnly bool
+		wantErr  bool
+	}{
+		{value: "2025-01-31", want: time.Date(2025, 1, 31, 0, 0, 0, 0, time.UTC), dateOnly: true},
+		{value: "2025-01-31T17:30:00Z", want: time.Date(2025, 1, 31, 17, 30, 0, 0, time.UTC)},
+		{value: "2025-01-31T17:30:00+02:00", want: time.Date(2025, 1, 31, 15, 30, 0, 0, time.UTC)},
+		{value: "2025-02-30", wantErr: true},
+		{value: "31/01/2025", wantErr: true},
+		{value: "7d", wantErr: true},
+		{value: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, dateOnly, err := ParseFilterDate("beforeDate", tt.value)
+			if tt.wantErr {
+				var filterErr *FilterError
+				if !errors.As(err, &filterErr) || filterErr.Field != "beforeDate" {
+					t.Fatalf("ParseFilterDate(%q) returned %v, want a FilterError for beforeDate", tt.value, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseFilterDate(%q) failed: %v", tt.value, err)
+			}
+			if !got.Equal(tt.want) || dateOnly != tt.dateOnly {
+				t.Errorf("ParseFilterDate(%q) = %v, %v, want %v, %v", tt.value, got, dateOnly, tt.want, tt.dateOnly)
+			}
+		})
+	}
+}
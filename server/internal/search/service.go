@@ -4,9 +4,13 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/bhavyajaix/BalkanID-filevault/internal/database"
 	"github.com/bhavyajaix/BalkanID-filevault/internal/middleware"
+	"github.com/bhavyajaix/BalkanID-filevault/internal/pagination"
+	"gorm.io/gorm"
 )
 
 // getUserIDFromContext is a helper to extract the user ID from the context.
@@ -19,43 +23,152 @@ func getUserIDFromContext(ctx context.Context) (uint, error) {
 	return userID, nil
 }
 
+// ReadChecker decides whether a user may see a resource. It is satisfied by
+// folders.Service.
+type ReadChecker interface {
+	CanRead(userID uint, resource *database.Resource) (bool, error)
+}
+
 type service struct {
-	repo Repository // Assuming a Repository interface is defined in this package
+	repo   Repository // Assuming a Repository interface is defined in this package
+	access ReadChecker
 }
 
+// Suggestions are capped so the search box stays fast.
+const (
+	defaultSuggestLimit = 10
+	maxSuggestLimit     = 50
+)
+
 // NewService creates a new instance of the search service.
-func NewSearchService(repo Repository) Service {
-	return &service{repo: repo}
+func NewSearchService(repo Repository, access ReadChecker) Service {
+	return &service{repo: repo, access: access}
 }
 
 // Search validates input, applies security and business rules, then calls the repository.
-func (s *service) Search(ctx context.Context, filters SearchFilters, pagination Pagination) ([]database.Resource, error) {
-	// 1. Get the current user ID from the context.
+func (s *service) Search(ctx context.Context, filters SearchFilters, req pagination.Request) (*pagination.Page, error) {
+	// 1. Validate the filters and scope them to the current user.
+	if err := s.prepare(ctx, &filters); err != nil {
+		return nil, err
+	}
+	if req.Sort.Field == pagination.SortByRelevance && filters.Expression == nil && filters.NameSimilarity == nil {
+		return nil, errors.New("invalid sort: RELEVANCE requires a query or a fuzzy name")
+	}
+
+	// 2. Call the repository to perform the actual data retrieval.
+	page, err := s.repo.SearchResources(filters, req)
+	if err != nil {
+		// Wrap the error to provide more context if it fails at the repository level.
+		return nil, fmt.Errorf("failed to execute search: %w", err)
+	}
+
+	// 3. Return the retrieved page.
+	return page, nil
+}
+
+// Facets counts the resources matching the filters by MIME type, tag, owner, size and
+// month of creation.
+func (s *service) Facets(ctx context.Context, filters SearchFilters) (*Facets, error) {
+	if err := s.prepare(ctx, &filters); err != nil {
+		return nil, err
+	}
+	facets, err := s.repo.Facets(filters)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute facets: %w", err)
+	}
+	return facets, nil
+}
+
+// Suggest returns resources whose name matches the prefix typed so far, tolerating
+// typos, case and separators, best matches and most recently updated first.
+func (s *service) Suggest(ctx context.Context, prefix string, limit int) ([]database.Resource, error) {
 	userID, err := getUserIDFromContext(ctx)
 	if err != nil {
 		return nil, err
 	}
+	if limit <= 0 {
+		limit = defaultSuggestLimit
+	}
+	if limit > maxSuggestLimit {
+		limit = maxSuggestLimit
+	}
+	prefix = strings.TrimSpace(prefix)
+	if prefix == "" {
+		return []database.Resource{}, nil
+	}
 
-	// 2. Business Rule & Validation: Perform checks on the filter inputs.
+	resources, err := s.repo.SuggestResources(userID, prefix, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to suggest resources: %w", err)
+	}
+	return resources, nil
+}
+
+// prepare validates the filters, parses the query and scopes the filters to the
+// current user.
+func (s *service) prepare(ctx context.Context, filters *SearchFilters) error {
+	userID, err := getUserIDFromContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Business Rule & Validation: Perform checks on the filter inputs.
+	if filters.MinSizeBytes != nil && *filters.MinSizeBytes < 0 {
+		return &FilterError{Field: "minSizeBytes", Value: fmt.Sprint(*filters.MinSizeBytes), Reason: "cannot be negative"}
+	}
 	if filters.MinSizeBytes != nil && filters.MaxSizeBytes != nil {
 		if *filters.MinSizeBytes > *filters.MaxSizeBytes {
-			return nil, errors.New("invalid filters: minSizeBytes cannot be greater than maxSizeBytes")
+			return &FilterError{Field: "minSizeBytes", Value: fmt.Sprint(*filters.MinSizeBytes), Reason: "cannot be greater than maxSizeBytes"}
+		}
+	}
+	if filters.NameSimilarity != nil {
+		if filters.Name == nil {
+			return &FilterError{Field: "nameSimilarity", Value: fmt.Sprint(*filters.NameSimilarity), Reason: "requires a name"}
 		}
+		if *filters.NameSimilarity <= 0 || *filters.NameSimilarity > 1 {
+			return &FilterError{Field: "nameSimilarity", Value: fmt.Sprint(*filters.NameSimilarity), Reason: "must be greater than 0 and at most 1"}
+		}
+	}
+	if filters.AfterDate != nil && filters.BeforeDate != nil && filters.AfterDate.After(*filters.BeforeDate) {
+		return &FilterError{Field: "afterDate", Value: filters.AfterDate.Format(time.RFC3339), Reason: "cannot be later than beforeDate"}
 	}
 
-	// Add other validations for dates, etc., as needed.
+	if filters.FolderID != nil {
+		folder, err := s.repo.FindResourceByID(*filters.FolderID)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("folder not found")
+		}
+		if err != nil {
+			return fmt.Errorf("failed to find folder: %w", err)
+		}
+		if folder.Type != database.Folder {
+			return &FilterError{Field: "folderId", Value: fmt.Sprint(folder.ID), Reason: "not a folder"}
+		}
+		canRead, err := s.access.CanRead(userID, folder)
+		if err != nil {
+			return fmt.Errorf("error checking permissions: %w", err)
+		}
+		if !canRead {
+			return errors.New("access denied")
+		}
+	}
 
-	// 3. Security Check: Enforce that the search is scoped to the current user.
-	// This is a critical step to ensure users can only search for their own resources.
-	filters.OwnerID = userID
+	for i := range filters.Metadata {
+		if err := s.prepareMetadata(userID, &filters.Metadata[i]); err != nil {
+			return err
+		}
+	}
 
-	// 4. Call the repository to perform the actual data retrieval.
-	resources, err := s.repo.SearchResources(filters, pagination)
-	if err != nil {
-		// Wrap the error to provide more context if it fails at the repository level.
-		return nil, fmt.Errorf("failed to execute search: %w", err)
+	if filters.Query != nil {
+		expr, err := ParseQuery(*filters.Query)
+		if err != nil {
+			return err
+		}
+		filters.Expression = expr
 	}
 
-	// 5. Return the retrieved resources.
-	return resources, nil
+	// Security Check: Enforce that the search is scoped to the current user.
+	// This is a critical step to ensure users only find what they are allowed to read.
+	filters.ViewerID = userID
+	return nil
 }
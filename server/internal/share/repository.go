@@ -7,6 +7,7 @@ import (
 
 	"github.com/bhavyajaix/BalkanID-filevault/internal/database"
 	"github.com/bhavyajaix/BalkanID-filevault/internal/middleware"
+	"github.com/bhavyajaix/BalkanID-filevault/internal/permission"
 	"gorm.io/gorm"
 )
 
@@ -24,12 +25,13 @@ type Repository interface {
 }
 
 type repository struct {
-	db *gorm.DB
+	db             *gorm.DB
+	permissionRepo permission.Repository
 }
 
 // NewRepository creates a new share repository.
-func NewRepository(db *gorm.DB) Repository {
-	return &repository{db: db}
+func NewRepository(db *gorm.DB, permissionRepo permission.Repository) Repository {
+	return &repository{db: db, permissionRepo: permissionRepo}
 }
 
 func (r *repository) FindResourceByTokenAndUserAccess(ctx context.Context, token string, expectedType string) (*database.Resource, error) {
@@ -48,59 +50,46 @@ func (r *repository) FindResourceByTokenAndUserAccess(ctx context.Context, token
 		return nil, err
 	}
 
-	// 1.1 If resource is public, don't check for permissions
-	if resource.IsPublic {
+	if userID != 0 && resource.OwnerID == userID {
 		return &resource, nil
 	}
 
-	// 2. Check for direct permission on the resource itself
-	var directPermissionCount int64
-	if err := r.db.Model(&database.Permission{}).
-		Where("resource_id = ? AND user_id = ?", resource.ID, userID).
-		Count(&directPermissionCount).Error; err != nil {
-		return nil, err
-	}
-
-	if directPermissionCount > 0 {
+	// 2. Check for a grant on the resource or any ancestor, made either to the user
+	// directly or to one of their groups
+	if _, err := r.permissionRepo.FindPermission(resource.ID, userID); err == nil {
 		return &resource, nil
-	}
-
-	// 3. If no direct permission, check for permission on any ancestor
-	// This uses a subquery for efficiency:
-	// SELECT COUNT(*) FROM "permissions"
-	// WHERE user_id = ? AND resource_id IN (
-	//   SELECT ancestor_id FROM "resource_ancestors" WHERE descendant_id = ?
-	// )
-	var ancestorPermissionCount int64
-	subQuery := r.db.Model(&database.ResourceAncestor{}).Select("ancestor_id").Where("descendant_id = ?", resource.ID)
-
-	if err := r.db.Model(&database.Permission{}).
-		Where("user_id = ? AND resource_id IN (?)", userID, subQuery).
-		Count(&ancestorPermissionCount).Error; err != nil {
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
 		return nil, err
 	}
 
-	if ancestorPermissionCount > 0 {
-		// User has inherited access from a parent folder
-		return &resource, nil
+	// If we reach here, the user has no direct or inherited permission. An explicit deny
+	// keeps them out even when the resource is public.
+	if userID != 0 {
+		denied, err := r.permissionRepo.IsDenied(resource.ID, userID)
+		if err != nil {
+			return nil, err
+		}
+		if denied {
+			return nil, errors.New("access denied: you can request access from the owner")
+		}
 	}
 
-	// If we reach here, the user has no direct or inherited permission
-	// 4. Final check: See if access is granted via a public ancestor folder
-	var publicAncestorCount int64
-	// Re-use the subquery to find all ancestors
-	if err := r.db.Model(&database.Resource{}).
-		Where("id IN (?) AND is_public = ?", subQuery, true).
-		Count(&publicAncestorCount).Error; err != nil {
-		// This is an internal query error, not an access error
-		return nil, err
+	// 3. Final check: See if the resource is public, itself or via a public ancestor
+	// folder whose flag is not cut off by a resource that breaks inheritance
+	isPublic := resource.IsPublic
+	if !isPublic {
+		var err error
+		if isPublic, err = r.permissionRepo.IsPubliclyAccessible(resource.ID); err != nil {
+			// This is an internal query error, not an access error
+			return nil, err
+		}
 	}
 
-	if publicAncestorCount > 0 {
+	if isPublic {
 		// Access is granted because a parent folder is public
 		return &resource, nil
 	}
 
-	// If all checks fail, deny access
-	return nil, errors.New("access denied")
+	// If all checks fail, deny access. The user can still file an access request for the link.
+	return nil, errors.New("access denied: you can request access from the owner")
 }
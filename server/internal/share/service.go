@@ -5,6 +5,7 @@ import (
 	"context"
 
 	// Assume you have an auth package
+	"github.com/bhavyajaix/BalkanID-filevault/internal/activity"
 	"github.com/bhavyajaix/BalkanID-filevault/internal/database"
 	"github.com/bhavyajaix/BalkanID-filevault/internal/file"
 	"github.com/bhavyajaix/BalkanID-filevault/internal/folders"
@@ -22,14 +23,16 @@ type service struct {
 	folderRepository folders.Repository
 	fileRepository   file.Repository
 	db               *gorm.DB
+	activity         activity.Recorder
 }
 
-func NewService(repo Repository, folderRepo folders.Repository, fileRepo file.Repository, db *gorm.DB) Service {
+func NewService(repo Repository, folderRepo folders.Repository, fileRepo file.Repository, db *gorm.DB, recorder activity.Recorder) Service {
 	return &service{
 		repo:             repo,
 		folderRepository: folderRepo,
 		fileRepository:   fileRepo,
 		db:               db,
+		activity:         recorder,
 	}
 }
 
@@ -40,6 +43,11 @@ func (s *service) ResolveShareLink(ctx context.Context, token string, expectedTy
 		return nil, err
 	}
 
+	// Anonymous visitors of public links have no feed to record into.
+	if userID, err := getUserIDFromContext(ctx); err == nil {
+		activity.Record(s.activity, userID, dbResource.ID, database.ActivityOpenedShareLink)
+	}
+
 	switch dbResource.Type {
 	case database.File:
 		download, err := s.fileRepository.GetResourceByID(s.db, dbResource.ID)
@@ -9,10 +9,21 @@ type tagRepository struct {
 	db *gorm.DB
 }
 
+// TagUsage is a tag together with the number of live resources carrying it.
+type TagUsage struct {
+	database.Tag
+	UsageCount int64
+}
+
 type TagRepository interface {
 	FindResourceByID(id uint) (*database.Resource, error)
 	FindTagByID(id uint) (*database.Tag, error)
-	FindOrCreateTagByName(name string) (*database.Tag, error)
+	FindTagByName(userID uint, groupID *uint, name string) (*database.Tag, error)
+	CreateTag(tag *database.Tag) error
+	UpdateTag(tag *database.Tag) error
+	DeleteTag(id uint) error
+	MergeTags(sourceIDs []uint, targetID uint) error
+	ListVisibleTags(userID uint) ([]TagUsage, error)
 	AddTagToResource(resource *database.Resource, tag *database.Tag) error
 	RemoveTagFromResource(resource *database.Resource, tag *database.Tag) error
 }
@@ -22,9 +33,17 @@ func NewTagRepository(db *gorm.DB) TagRepository {
 	return &tagRepository{db: db}
 }
 
+// VisibleTo scopes a tag query to the namespaces a user can see: their own tags and
+// the tags of the groups they belong to.
+func VisibleTo(userID uint) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("(tags.owner_id = ? OR tags.group_id IN (SELECT group_id FROM group_members WHERE user_id = ?))", userID, userID)
+	}
+}
+
 func (r *tagRepository) FindResourceByID(id uint) (*database.Resource, error) {
 	var resource database.Resource
-	if err := r.db.Preload("PhysicalFile").Preload("Tags").First(&resource, id).Error; err != nil {
+	if err := r.db.Preload("PhysicalFile").First(&resource, id).Error; err != nil {
 		return nil, err
 	}
 	return &resource, nil
@@ -38,14 +57,64 @@ func (r *tagRepository) FindTagByID(id uint) (*database.Tag, error) {
 	return &tag, nil
 }
 
-func (r *tagRepository) FindOrCreateTagByName(name string) (*database.Tag, error) {
+// FindTagByName looks a tag up by name, ignoring case, in the group's namespace, or in
+// the user's own one when groupID is nil.
+func (r *tagRepository) FindTagByName(userID uint, groupID *uint, name string) (*database.Tag, error) {
+	query := r.db.Where("lower(name) = lower(?)", name)
+	if groupID != nil {
+		query = query.Where("group_id = ?", *groupID)
+	} else {
+		query = query.Where("owner_id = ?", userID)
+	}
+
 	var tag database.Tag
-	if err := r.db.Where(database.Tag{Name: name}).FirstOrCreate(&tag).Error; err != nil {
+	if err := query.First(&tag).Error; err != nil {
 		return nil, err
 	}
 	return &tag, nil
 }
 
+func (r *tagRepository) CreateTag(tag *database.Tag) error {
+	return r.db.Create(tag).Error
+}
+
+func (r *tagRepository) UpdateTag(tag *database.Tag) error {
+	return r.db.Save(tag).Error
+}
+
+// DeleteTag removes a tag for good; the join table cascade detaches it from resources.
+func (r *tagRepository) DeleteTag(id uint) error {
+	return r.db.Unscoped().Delete(&database.Tag{}, id).Error
+}
+
+// MergeTags moves every resource tagged with one of the sources onto the target, then
+// deletes the sources.
+func (r *tagRepository) MergeTags(sourceIDs []uint, targetID uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec(
+			"INSERT INTO resource_tags (resource_id, tag_id) SELECT resource_id, ? FROM resource_tags WHERE tag_id IN ? ON CONFLICT DO NOTHING",
+			targetID, sourceIDs,
+		).Error; err != nil {
+			return err
+		}
+		return tx.Unscoped().Delete(&database.Tag{}, sourceIDs).Error
+	})
+}
+
+// ListVisibleTags returns the tags the user can see with their usage counts, by name.
+func (r *tagRepository) ListVisibleTags(userID uint) ([]TagUsage, error) {
+	var usages []TagUsage
+	err := r.db.Model(&database.Tag{}).
+		Select("tags.*, COUNT(resources.id) AS usage_count").
+		Joins("LEFT JOIN resource_tags ON resource_tags.tag_id = tags.id").
+		Joins("LEFT JOIN resources ON resources.id = resource_tags.resource_id AND resources.deleted_at IS NULL").
+		Scopes(VisibleTo(userID)).
+		Group("tags.id").
+		Order("lower(tags.name) ASC, tags.id ASC").
+		Find(&usages).Error
+	return usages, err
+}
+
 func (r *tagRepository) AddTagToResource(resource *database.Resource, tag *database.Tag) error {
 	return r.db.Model(resource).Association("Tags").Append(tag)
 }
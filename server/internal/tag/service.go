@@ -1,64 +1,399 @@
 package tag
 
-import "github.com/bhavyajaix/BalkanID-filevault/internal/database"
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
 
+	"github.com/bhavyajaix/BalkanID-filevault/internal/database"
+	"github.com/bhavyajaix/BalkanID-filevault/internal/group"
+	"github.com/bhavyajaix/BalkanID-filevault/internal/middleware"
+	"gorm.io/gorm"
+)
+
+const (
+	maxNameLength        = 100
+	maxDescriptionLength = 500
+)
+
+// ErrTagNameTaken is returned when the namespace already has a tag with the name.
+var ErrTagNameTaken = errors.New("a tag with this name already exists")
+
+// errTagNotFound is also returned for tags in namespaces the caller can't see, so
+// other users' vocabularies can't be probed.
+var errTagNotFound = errors.New("tag not found")
+
+var errGroupNotFound = errors.New("group not found")
+
+var colorPattern = regexp.MustCompile(`^#[0-9A-Fa-f]{6}$`)
+
+// WriteChecker decides whether a user may change a resource. It is satisfied by
+// folders.Service.
+type WriteChecker interface {
+	CanWrite(userID uint, resource *database.Resource) (bool, error)
+}
+
+// TagService manages tag vocabularies and the tags attached to resources. Every tag
+// belongs to a user or to a group; group tags can be used by all members but only
+// managed by the group's admins.
 type TagService interface {
-	AddTag(resourceID uint, tagName string) (*database.Resource, error)
-	RemoveTag(resourceID uint, tagID uint) (*database.Resource, error)
+	AddTag(ctx context.Context, resourceID uint, tagName string, groupID *uint) (*database.Resource, error)
+	RemoveTag(ctx context.Context, resourceID uint, tagID uint) (*database.Resource, error)
+	CreateTag(ctx context.Context, name string, groupID *uint, color string, description string) (*database.Tag, error)
+	UpdateTag(ctx context.Context, tagID uint, color *string, description *string) (*database.Tag, error)
+	RenameTag(ctx context.Context, tagID uint, name string) (*database.Tag, error)
+	MergeTags(ctx context.Context, sourceIDs []uint, targetID uint) (*database.Tag, error)
+	DeleteTag(ctx context.Context, tagID uint) error
+	ListTags(ctx context.Context) ([]TagUsage, error)
 }
 
 type tagService struct {
-	repo TagRepository
+	repo   TagRepository
+	groups group.Repository
+	access WriteChecker
 }
 
 // NewTagService creates a new instance of the tag service.
-func NewTagService(repo TagRepository) TagService {
-	return &tagService{repo: repo}
+func NewTagService(repo TagRepository, groups group.Repository, access WriteChecker) TagService {
+	return &tagService{repo: repo, groups: groups, access: access}
 }
 
-func (s *tagService) AddTag(resourceID uint, tagName string) (*database.Resource, error) {
-	// 1. Get the resource
-	resource, err := s.repo.FindResourceByID(resourceID)
+func getUserIDFromContext(ctx context.Context) (uint, error) {
+	userID, ok := ctx.Value(middleware.UserContextKey).(uint)
+	if !ok {
+		return 0, errors.New("unauthorized")
+	}
+	return userID, nil
+}
+
+// AddTag attaches a tag from the caller's namespace, or the group's when groupID is
+// set, to a resource the caller can change. The tag is created if it doesn't exist.
+func (s *tagService) AddTag(ctx context.Context, resourceID uint, tagName string, groupID *uint) (*database.Resource, error) {
+	userID, err := getUserIDFromContext(ctx)
 	if err != nil {
-		return nil, err // e.g., resource not found
+		return nil, err
 	}
 
-	// 2. Find or create the tag
-	tag, err := s.repo.FindOrCreateTagByName(tagName)
+	resource, err := s.writableResource(userID, resourceID)
 	if err != nil {
 		return nil, err
 	}
+	name, err := normalizeName(tagName)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkNamespace(userID, groupID, false); err != nil {
+		return nil, err
+	}
 
-	// 3. Associate them
+	tag, err := s.findOrCreateTag(userID, groupID, name)
+	if err != nil {
+		return nil, err
+	}
 	if err := s.repo.AddTagToResource(resource, tag); err != nil {
+		return nil, fmt.Errorf("failed to tag resource: %w", err)
+	}
+	return resource, nil
+}
+
+// RemoveTag detaches one of the caller's visible tags from a resource they can change.
+func (s *tagService) RemoveTag(ctx context.Context, resourceID uint, tagID uint) (*database.Resource, error) {
+	userID, err := getUserIDFromContext(ctx)
+	if err != nil {
 		return nil, err
 	}
 
-	// The resource object from FindResourceByID already has the preloaded tags,
-	// so we just need to add the new one for the return value.
-	resource.Tags = append(resource.Tags, tag)
+	resource, err := s.writableResource(userID, resourceID)
+	if err != nil {
+		return nil, err
+	}
+	tag, err := s.visibleTag(userID, tagID, false)
+	if err != nil {
+		return nil, err
+	}
 
+	if err := s.repo.RemoveTagFromResource(resource, tag); err != nil {
+		return nil, fmt.Errorf("failed to untag resource: %w", err)
+	}
 	return resource, nil
 }
 
-func (s *tagService) RemoveTag(resourceID uint, tagID uint) (*database.Resource, error) {
-	// 1. Get the resource
-	resource, err := s.repo.FindResourceByID(resourceID)
+// CreateTag adds a tag to the caller's namespace, or to the group's when groupID is set.
+func (s *tagService) CreateTag(ctx context.Context, name string, groupID *uint, color string, description string) (*database.Tag, error) {
+	userID, err := getUserIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	name, err = normalizeName(name)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateColor(color); err != nil {
+		return nil, err
+	}
+	if err := validateDescription(description); err != nil {
+		return nil, err
+	}
+	if err := s.checkNamespace(userID, groupID, false); err != nil {
+		return nil, err
+	}
+
+	tag := newTag(userID, groupID, name)
+	tag.Color = strings.ToUpper(color)
+	tag.Description = strings.TrimSpace(description)
+	if err := s.repo.CreateTag(tag); err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return nil, ErrTagNameTaken
+		}
+		return nil, fmt.Errorf("failed to create tag: %w", err)
+	}
+	return tag, nil
+}
+
+// UpdateTag changes the color and description of a tag the caller manages. Nil
+// arguments are left untouched; empty strings clear the field.
+func (s *tagService) UpdateTag(ctx context.Context, tagID uint, color *string, description *string) (*database.Tag, error) {
+	userID, err := getUserIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	tag, err := s.visibleTag(userID, tagID, true)
+	if err != nil {
+		return nil, err
+	}
+	if color != nil {
+		if err := validateColor(*color); err != nil {
+			return nil, err
+		}
+		tag.Color = strings.ToUpper(*color)
+	}
+	if description != nil {
+		if err := validateDescription(*description); err != nil {
+			return nil, err
+		}
+		tag.Description = strings.TrimSpace(*description)
+	}
+
+	if err := s.repo.UpdateTag(tag); err != nil {
+		return nil, fmt.Errorf("failed to update tag: %w", err)
+	}
+	return tag, nil
+}
+
+// RenameTag renames a tag the caller manages, keeping it on every resource.
+func (s *tagService) RenameTag(ctx context.Context, tagID uint, name string) (*database.Tag, error) {
+	userID, err := getUserIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	tag, err := s.visibleTag(userID, tagID, true)
+	if err != nil {
+		return nil, err
+	}
+	tag.Name, err = normalizeName(name)
 	if err != nil {
 		return nil, err
 	}
 
-	// 2. Get the tag
+	if err := s.repo.UpdateTag(tag); err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return nil, ErrTagNameTaken
+		}
+		return nil, fmt.Errorf("failed to rename tag: %w", err)
+	}
+	return tag, nil
+}
+
+// MergeTags folds the source tags into the target: resources carrying a source end up
+// carrying the target, and the sources are deleted. All tags must share a namespace
+// the caller manages.
+func (s *tagService) MergeTags(ctx context.Context, sourceIDs []uint, targetID uint) (*database.Tag, error) {
+	userID, err := getUserIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(sourceIDs) == 0 {
+		return nil, errors.New("at least one tag to merge is required")
+	}
+
+	target, err := s.visibleTag(userID, targetID, true)
+	if err != nil {
+		return nil, err
+	}
+	for _, sourceID := range sourceIDs {
+		if sourceID == targetID {
+			return nil, errors.New("cannot merge a tag into itself")
+		}
+		source, err := s.visibleTag(userID, sourceID, true)
+		if err != nil {
+			return nil, err
+		}
+		if !sameNamespace(source, target) {
+			return nil, errors.New("tags can only be merged within the same namespace")
+		}
+	}
+
+	if err := s.repo.MergeTags(sourceIDs, targetID); err != nil {
+		return nil, fmt.Errorf("failed to merge tags: %w", err)
+	}
+	return target, nil
+}
+
+// DeleteTag deletes a tag the caller manages and detaches it from every resource.
+func (s *tagService) DeleteTag(ctx context.Context, tagID uint) error {
+	userID, err := getUserIDFromContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	if _, err := s.visibleTag(userID, tagID, true); err != nil {
+		return err
+	}
+	if err := s.repo.DeleteTag(tagID); err != nil {
+		return fmt.Errorf("failed to delete tag: %w", err)
+	}
+	return nil
+}
+
+// ListTags returns the caller's tags and those of their groups, with usage counts.
+func (s *tagService) ListTags(ctx context.Context) ([]TagUsage, error) {
+	userID, err := getUserIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	tags, err := s.repo.ListVisibleTags(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tags: %w", err)
+	}
+	return tags, nil
+}
+
+// writableResource fetches a resource and checks that the user may change it.
+func (s *tagService) writableResource(userID, resourceID uint) (*database.Resource, error) {
+	resource, err := s.repo.FindResourceByID(resourceID)
+	if err != nil {
+		return nil, errors.New("resource not found")
+	}
+	canWrite, err := s.access.CanWrite(userID, resource)
+	if err != nil {
+		return nil, fmt.Errorf("error checking permissions: %w", err)
+	}
+	if !canWrite {
+		return nil, errors.New("access denied")
+	}
+	return resource, nil
+}
+
+// visibleTag fetches a tag from one of the user's namespaces. With manage set, group
+// tags additionally require the user to be a group admin.
+func (s *tagService) visibleTag(userID, tagID uint, manage bool) (*database.Tag, error) {
 	tag, err := s.repo.FindTagByID(tagID)
 	if err != nil {
-		return nil, err // e.g., tag not found
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errTagNotFound
+		}
+		return nil, err
 	}
 
-	// 3. Disassociate them
-	if err := s.repo.RemoveTagFromResource(resource, tag); err != nil {
+	if tag.GroupID == nil {
+		if tag.OwnerID == nil || *tag.OwnerID != userID {
+			return nil, errTagNotFound
+		}
+		return tag, nil
+	}
+	if err := s.checkNamespace(userID, tag.GroupID, manage); err != nil {
+		if errors.Is(err, errGroupNotFound) {
+			return nil, errTagNotFound
+		}
 		return nil, err
 	}
+	return tag, nil
+}
+
+// checkNamespace verifies the user belongs to the group, and administers it when
+// manage is set. The user's own namespace, a nil groupID, is always allowed.
+func (s *tagService) checkNamespace(userID uint, groupID *uint, manage bool) error {
+	if groupID == nil {
+		return nil
+	}
 
-	// We can refetch the resource to ensure the tag list is accurate
-	return s.repo.FindResourceByID(resourceID)
+	member, err := s.groups.FindMember(*groupID, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errGroupNotFound
+		}
+		return err
+	}
+	if manage && !member.IsAdmin {
+		return errors.New("access denied: only group admins can manage the group's tags")
+	}
+	return nil
+}
+
+// findOrCreateTag returns the namespace's tag with the name, creating it if needed.
+func (s *tagService) findOrCreateTag(userID uint, groupID *uint, name string) (*database.Tag, error) {
+	tag, err := s.repo.FindTagByName(userID, groupID, name)
+	if err == nil {
+		return tag, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	tag = newTag(userID, groupID, name)
+	if err := s.repo.CreateTag(tag); err != nil {
+		// Someone else created it in the meantime.
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return s.repo.FindTagByName(userID, groupID, name)
+		}
+		return nil, fmt.Errorf("failed to create tag: %w", err)
+	}
+	return tag, nil
+}
+
+// newTag builds a tag in the group's namespace, or in the user's when groupID is nil.
+func newTag(userID uint, groupID *uint, name string) *database.Tag {
+	if groupID != nil {
+		return &database.Tag{GroupID: groupID, Name: name}
+	}
+	return &database.Tag{OwnerID: &userID, Name: name}
+}
+
+func sameNamespace(a, b *database.Tag) bool {
+	if a.GroupID != nil || b.GroupID != nil {
+		return a.GroupID != nil && b.GroupID != nil && *a.GroupID == *b.GroupID
+	}
+	return a.OwnerID != nil && b.OwnerID != nil && *a.OwnerID == *b.OwnerID
+}
+
+func normalizeName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	switch {
+	case name == "":
+		return "", errors.New("tag name cannot be empty")
+	case utf8.RuneCountInString(name) > maxNameLength:
+		return "", fmt.Errorf("tag name cannot be longer than %d characters", maxNameLength)
+	}
+	return name, nil
+}
+
+func validateColor(color string) error {
+	if color != "" && !colorPattern.MatchString(color) {
+		return errors.New("color must be a hex value such as #1E90FF")
+	}
+	return nil
+}
+
+func validateDescription(description string) error {
+	if utf8.RuneCountInString(strings.TrimSpace(description)) > maxDescriptionLength {
+		return fmt.Errorf("description cannot be longer than %d characters", maxDescriptionLength)
+	}
+	return nil
 }
@@ -4,6 +4,7 @@ package user
 import (
 	"github.com/bhavyajaix/BalkanID-filevault/internal/database" // Your GORM models package
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // Repository is the interface for database operations.
@@ -13,6 +14,9 @@ type Repository interface {
 	GetUserByEmail(email string) (*database.User, error)
 	IncrementStorageUsed(db *gorm.DB, userID uint, size int64) error
 	IncrementBothStorageTypes(db *gorm.DB, userID uint, size int64) error
+	DecrementStorageUsed(db *gorm.DB, userID uint, size int64) error
+	DecrementDeduplicationStorageUsed(db *gorm.DB, userID uint, size int64) error
+	GetUserForUpdate(db *gorm.DB, id uint) (*database.User, error)
 }
 
 type repository struct {
@@ -59,3 +63,27 @@ func (r *repository) IncrementBothStorageTypes(db *gorm.DB, userID uint, size in
 		"deduplication_storage_used": gorm.Expr("deduplication_storage_used + ?", size),
 	}).Error
 }
+
+// DecrementStorageUsed subtracts from the user's logical storage usage.
+func (r *repository) DecrementStorageUsed(db *gorm.DB, userID uint, size int64) error {
+	return db.Model(&database.User{}).Where("id = ?", userID).UpdateColumn(
+		"storage_used", gorm.Expr("storage_used - ?", size),
+	).Error
+}
+
+// DecrementDeduplicationStorageUsed subtracts from the user's physical storage usage.
+func (r *repository) DecrementDeduplicationStorageUsed(db *gorm.DB, userID uint, size int64) error {
+	return db.Model(&database.User{}).Where("id = ?", userID).UpdateColumn(
+		"deduplication_storage_used", gorm.Expr("deduplication_storage_used - ?", size),
+	).Error
+}
+
+// GetUserForUpdate fetches a user and locks the row until the transaction ends,
+// so quota checks and storage updates cannot race.
+func (r *repository) GetUserForUpdate(db *gorm.DB, id uint) (*database.User, error) {
+	var user database.User
+	if err := db.Clauses(clause.Locking{Strength: "UPDATE"}).First(&user, id).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
@@ -77,4 +77,12 @@ func (s *service) Login(email, password string) (*database.User, string, error)
 
 func (s *service) GetUserByID(id uint) (*database.User, error) {
 	return s.repo.GetUserByID(id)
-}
\ No newline at end of file
+}
+
+// bytesPerMB converts StorageQuotaMB into bytes.
+const bytesPerMB = 1024 * 1024
+
+// HasQuotaFor reports whether the user can take on additional bytes of logical storage.
+func HasQuotaFor(u *database.User, additional int64) bool {
+	return int64(u.StorageUsed)+additional <= int64(u.StorageQuotaMB)*bytesPerMB
+}